@@ -0,0 +1,194 @@
+// Package api provides the HTTP server of the application serving the health, build information and Prometheus
+// metrics endpoints alongside the handlers registered by other components, such as the Telegram webhook.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	defaultListen       = ":8080"
+	defaultReadyTimeout = 3 * time.Second
+	readHeaderTimeout   = 5 * time.Second
+	shutdownTimeout     = 5 * time.Second
+
+	statusOK   = "ok"
+	statusFail = "fail"
+)
+
+// Config holds the configuration of the HTTP server.
+// ReadyTimeout bounds the time the readiness probe waits for the health checks of the dependencies.
+type Config struct {
+	Listen       string        `mapstructure:"listen"`
+	ReadyTimeout time.Duration `mapstructure:"ready_timeout"`
+}
+
+// BuildInfo describes the running build of the application.
+type BuildInfo struct {
+	AppName string `json:"app"`
+	Version string `json:"version"`
+}
+
+// Service defines the interface for checking the health of the application dependencies.
+type Service interface {
+	CheckHealth(ctx context.Context) ([]core.DependencyHealth, error)
+}
+
+// Server is the HTTP server of the application.
+type Server struct {
+	svc   Service
+	mux   *http.ServeMux
+	build BuildInfo
+	cfg   Config
+}
+
+// readiness is the response of the readiness probe.
+type readiness struct {
+	Dependencies map[string]string `json:"dependencies"`
+	Status       string            `json:"status"`
+}
+
+// version is the response of the version endpoint.
+type version struct {
+	BuildInfo
+	GoVersion string `json:"go_version"`
+}
+
+// New creates a new Server with the given configuration, service and build information.
+func New(cfg Config, svc Service, build BuildInfo) *Server {
+	if cfg.Listen == "" {
+		cfg.Listen = defaultListen
+	}
+
+	if cfg.ReadyTimeout <= 0 {
+		cfg.ReadyTimeout = defaultReadyTimeout
+	}
+
+	s := &Server{
+		svc:   svc,
+		mux:   http.NewServeMux(),
+		build: build,
+		cfg:   cfg,
+	}
+
+	s.mux.HandleFunc("GET /livez", s.handleLive)
+	s.mux.HandleFunc("GET /readyz", s.handleReady)
+	s.mux.HandleFunc("GET /version", s.handleVersion)
+	s.mux.Handle("GET /metrics", promhttp.Handler())
+
+	return s
+}
+
+// Handle registers the handler for the given pattern. It has to be called before the server is run.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Run serves HTTP requests until the context is cancelled, then shuts the server down gracefully,
+// waiting for the requests being served.
+func (s *Server) Run(ctx context.Context) error {
+	lis, err := net.Listen("tcp", s.cfg.Listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	srv := &http.Server{
+		Handler:           s.mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+		BaseContext:       func(net.Listener) context.Context { return context.WithoutCancel(ctx) },
+	}
+
+	serveErr := make(chan error, 1)
+
+	go func() {
+		serveErr <- srv.Serve(lis)
+	}()
+
+	slog.InfoContext(ctx, "HTTP server started", slog.String("addr", lis.Addr().String()))
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("failed to serve: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down: %w", err)
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+
+	slog.InfoContext(ctx, "HTTP server stopped")
+
+	return nil
+}
+
+// handleLive reports that the process is alive.
+func (s *Server) handleLive(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte(statusOK))
+}
+
+// handleReady reports whether the dependencies of the application are healthy, with the status of each of them.
+// Failures are logged rather than returned, so the probe does not expose internal details.
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), s.cfg.ReadyTimeout)
+	defer cancel()
+
+	deps, err := s.svc.CheckHealth(ctx)
+
+	resp := readiness{
+		Status:       statusOK,
+		Dependencies: make(map[string]string, len(deps)),
+	}
+
+	for _, dep := range deps {
+		resp.Dependencies[dep.Name] = statusOK
+
+		if dep.Err != nil {
+			resp.Dependencies[dep.Name] = statusFail
+		}
+	}
+
+	code := http.StatusOK
+
+	if err != nil {
+		slog.WarnContext(ctx, "Readiness check failed", slog.Any("error", err))
+
+		resp.Status = statusFail
+		code = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, code, resp)
+}
+
+// handleVersion responds with the build information of the application.
+func (s *Server) handleVersion(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, version{BuildInfo: s.build, GoVersion: runtime.Version()})
+}
+
+// writeJSON writes the value as a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("Failed to write response", slog.Any("error", err))
+	}
+}
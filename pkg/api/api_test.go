@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	s := New(Config{}, NewMockService(t), BuildInfo{})
+
+	assert.Equal(t, Config{Listen: defaultListen, ReadyTimeout: defaultReadyTimeout}, s.cfg)
+
+	cfg := Config{Listen: ":9090", ReadyTimeout: time.Second}
+	assert.Equal(t, cfg, New(cfg, NewMockService(t), BuildInfo{}).cfg)
+}
+
+func TestServer_Livez(t *testing.T) {
+	s := New(Config{}, NewMockService(t), BuildInfo{})
+
+	rec := httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", http.NoBody))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "ok", rec.Body.String())
+}
+
+func TestServer_Readyz(t *testing.T) {
+	tests := []struct {
+		err        error
+		want       readiness
+		name       string
+		health     []core.DependencyHealth
+		wantStatus int
+	}{
+		{
+			name:       "all dependencies are healthy",
+			health:     []core.DependencyHealth{{Name: "redis"}, {Name: "some_api"}},
+			wantStatus: http.StatusOK,
+			want:       readiness{Status: "ok", Dependencies: map[string]string{"redis": "ok", "some_api": "ok"}},
+		},
+		{
+			name:       "dependency is unhealthy",
+			health:     []core.DependencyHealth{{Name: "redis", Err: assert.AnError}, {Name: "some_api"}},
+			err:        assert.AnError,
+			wantStatus: http.StatusServiceUnavailable,
+			want:       readiness{Status: "fail", Dependencies: map[string]string{"redis": "fail", "some_api": "ok"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMockService(t)
+			s := New(Config{ReadyTimeout: time.Minute}, svc, BuildInfo{})
+
+			svc.EXPECT().CheckHealth(mock.MatchedBy(func(ctx context.Context) bool {
+				deadline, ok := ctx.Deadline()
+				return ok && time.Until(deadline) <= time.Minute
+			})).Return(tt.health, tt.err)
+
+			rec := httptest.NewRecorder()
+			s.mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", http.NoBody))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			assert.NotContains(t, rec.Body.String(), assert.AnError.Error(), "failures should not be exposed")
+
+			var got readiness
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestServer_Version(t *testing.T) {
+	s := New(Config{}, NewMockService(t), BuildInfo{AppName: "feeder", Version: "1.2.3"})
+
+	rec := httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version", http.NoBody))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"app": "feeder", "version": "1.2.3", "go_version": "`+runtime.Version()+`"}`, rec.Body.String())
+}
+
+func TestServer_Metrics(t *testing.T) {
+	s := New(Config{}, NewMockService(t), BuildInfo{})
+
+	rec := httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "go_goroutines")
+}
+
+func TestServer_Handle(t *testing.T) {
+	s := New(Config{}, NewMockService(t), BuildInfo{})
+
+	s.Handle("POST /hook", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	rec := httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/hook", http.NoBody))
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+}
+
+func TestServer_Run(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	addr := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	s := New(Config{Listen: addr}, NewMockService(t), BuildInfo{})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+
+	go func() { done <- s.Run(ctx) }()
+
+	require.Eventually(t, func() bool {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://"+addr+"/livez", http.NoBody)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return false
+		}
+
+		_ = resp.Body.Close()
+
+		return resp.StatusCode == http.StatusOK
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
+
+func TestServer_RunListenFails(t *testing.T) {
+	s := New(Config{Listen: "invalid address"}, NewMockService(t), BuildInfo{})
+
+	assert.ErrorContains(t, s.Run(t.Context()), "failed to listen")
+}
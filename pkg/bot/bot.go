@@ -2,46 +2,102 @@ package bot
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/google/uuid"
+	"github.com/ksysoev/tg-feeder/pkg/bot/callback"
+	"github.com/ksysoev/tg-feeder/pkg/bot/dialog"
+	"github.com/ksysoev/tg-feeder/pkg/bot/middleware"
+	"github.com/ksysoev/tg-feeder/pkg/bot/ratelimit"
 	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/ksysoev/tg-feeder/pkg/i18n"
+	"github.com/ksysoev/tg-feeder/pkg/recovery"
+	"github.com/ksysoev/tg-feeder/pkg/reqctx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
 	requestTimeout = 3 * time.Second
+	tracerName     = "github.com/ksysoev/tg-feeder/pkg/bot"
 )
 
+// tracer creates the span of every update, resolving the global tracer provider lazily.
+var tracer = otel.Tracer(tracerName)
+
 // tgClient interface represents the Telegram bot API capabilities we use
 type tgClient interface {
 	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+	MakeRequest(endpoint string, params tgbotapi.Params) (*tgbotapi.APIResponse, error)
+	GetChat(config tgbotapi.ChatInfoConfig) (tgbotapi.Chat, error)
+	GetChatMember(config tgbotapi.GetChatMemberConfig) (tgbotapi.ChatMember, error)
 	StopReceivingUpdates()
 	GetUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel
 }
 
-// Config holds the configuration for the Telegram bot
+// Config holds the configuration for the Telegram bot.
+// CallbackSecret signs the data of inline keyboard buttons; when empty it is derived from the token,
+// which invalidates the buttons of sent messages whenever the token is rotated.
+// RateLimit keeps outgoing messages within the limits of the Bot API.
+// Mode selects how updates are received, ModePolling by default or ModeWebhook configured by Webhook.
 type Config struct {
-	Token string `mapstructure:"token"`
+	Token          string           `mapstructure:"token"`
+	CallbackSecret string           `mapstructure:"callback_secret"`
+	Mode           string           `mapstructure:"mode"`
+	Webhook        WebhookConfig    `mapstructure:"webhook"`
+	RateLimit      ratelimit.Config `mapstructure:"rate_limit"`
 }
 
 type Service interface {
 	Summary(ctx context.Context, url string) (*core.Response, error)
+	Subscribe(ctx context.Context, chatID int64, url string) (*core.FeedInfo, error)
+	Unsubscribe(ctx context.Context, chatID int64, urlOrID string) (*core.FeedInfo, error)
+	ListSubscriptions(ctx context.Context, chatID int64) ([]core.FeedInfo, error)
+	SubscribeTarget(ctx context.Context, ownerID int64, target core.Target, url string) (*core.FeedInfo, error)
+	SetFullText(ctx context.Context, chatID int64, urlOrID string, enabled bool) (*core.FeedInfo, error)
+	SetInterval(ctx context.Context, chatID int64, urlOrID string, interval time.Duration) (*core.FeedInfo, error)
+	GetSubscription(ctx context.Context, chatID int64, urlOrID string) (*core.Subscription, error)
+	UpdateSubscriptionSettings(
+		ctx context.Context,
+		chatID int64,
+		urlOrID string,
+		settings *core.SubscriptionSettings,
+	) (*core.Subscription, error)
+	Language(ctx context.Context, userID int64) (string, error)
+	SetLanguage(ctx context.Context, userID int64, lang string) error
+	TouchUser(ctx context.Context, userID int64) error
 }
 
 type Bot struct {
-	tg      tgClient
-	svc     Service
-	handler Handler
-	token   string
+	tg           tgClient
+	svc          Service
+	handler      Handler
+	callbacks    *callback.Codec
+	dialogs      *dialog.Manager
+	limiter      *ratelimit.Limiter
+	translations *i18n.Bundle
+	updates      chan tgbotapi.Update
+	closing      chan struct{}
+	token        string
+	mode         string
+	webhook      WebhookConfig
+	selfID       int64
 }
 
 // New initializes a new Service with the given configuration and returns an error if the configuration is invalid.
-func New(cfg *Config, svc Service) (*Bot, error) {
+// The state of the dialogs with the chats is persisted in the dialog store.
+func New(cfg *Config, svc Service, dialogs dialog.Store) (*Bot, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("config cannot be nil")
 	}
@@ -50,44 +106,77 @@ func New(cfg *Config, svc Service) (*Bot, error) {
 		return nil, fmt.Errorf("telegram token cannot be empty")
 	}
 
+	mode := cfg.Mode
+	webhook := cfg.Webhook
+
+	switch mode {
+	case "", ModePolling:
+		mode = ModePolling
+	case ModeWebhook:
+		var err error
+		if webhook, err = newWebhookConfig(webhook); err != nil {
+			return nil, fmt.Errorf("invalid webhook config: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown bot mode: %q", mode)
+	}
+
 	bot, err := tgbotapi.NewBotAPI(cfg.Token)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Telegram bot: %w", err)
 	}
 
+	secret := cfg.CallbackSecret
+	if secret == "" {
+		sum := sha256.Sum256([]byte("callback:" + cfg.Token))
+		secret = hex.EncodeToString(sum[:])
+	}
+
+	translations, err := i18n.New()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load translations: %w", err)
+	}
+
 	s := &Bot{
-		token: cfg.Token,
-		tg:    bot,
-		svc:   svc,
+		token:        cfg.Token,
+		tg:           bot,
+		svc:          svc,
+		callbacks:    callback.New(secret),
+		limiter:      ratelimit.New(cfg.RateLimit),
+		translations: translations,
+		mode:         mode,
+		webhook:      webhook,
+		updates:      make(chan tgbotapi.Update),
+		closing:      make(chan struct{}),
+		selfID:       bot.Self.ID,
 	}
 
+	s.dialogs = s.newDialogManager(dialogs)
 	s.handler = s.setupHandler()
 
 	return s, nil
 }
 
 func (s *Bot) processUpdate(ctx context.Context, update *tgbotapi.Update) {
-	if update.Message == nil {
-		return
-	}
+	ctx = withUpdateScope(ctx, update)
 
-	//nolint:staticcheck // don't want to have dependency on cmd package here for now
-	ctx = context.WithValue(ctx, "chat_id", fmt.Sprintf("%d", update.Message.Chat.ID))
-
-	msg := update.Message
+	span := trace.SpanFromContext(ctx)
+	if chatID, ok := reqctx.ChatID(ctx); ok {
+		span.SetAttributes(attribute.Int64("chat_id", chatID))
+	}
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	msgConfig, err := s.handler.Handle(ctx, msg)
+	resp, err := s.handler.Handle(ctx, update)
 
 	if errors.Is(err, context.Canceled) {
-		slog.InfoContext(ctx, "Request cancelled",
-			slog.Int64("chat_id", msg.Chat.ID),
-		)
+		slog.InfoContext(ctx, "Request cancelled")
 
 		return
 	} else if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		slog.ErrorContext(ctx, "Unexpected error",
 			slog.Any("error", err),
 		)
@@ -95,36 +184,169 @@ func (s *Bot) processUpdate(ctx context.Context, update *tgbotapi.Update) {
 		return
 	}
 
-	// Skip sending if message is empty
-	if msgConfig.Text == "" {
+	// Skip sending if there is nothing to send
+	if resp.Empty() {
 		return
 	}
 
 	cancel()
 
-	// Send response
-	if _, err := s.tg.Send(msgConfig); err != nil {
-		slog.ErrorContext(ctx, "Failed to send message",
-			slog.Any("error", err),
-		)
+	s.sendResponse(ctx, resp)
+}
+
+// withUpdateScope returns a copy of the context holding the chat, the sender and the command of the update,
+// so every record logged while handling the update carries them.
+func withUpdateScope(ctx context.Context, update *tgbotapi.Update) context.Context {
+	if update == nil {
+		return ctx
+	}
+
+	if chat := middleware.UpdateChat(update); chat != nil {
+		ctx = reqctx.WithChatID(ctx, chat.ID)
+	}
+
+	if user := middleware.UpdateSender(update); user != nil {
+		ctx = reqctx.WithUserID(ctx, user.ID)
 	}
+
+	for _, msg := range []*tgbotapi.Message{update.Message, update.EditedMessage, update.ChannelPost} {
+		if msg == nil {
+			continue
+		}
+
+		if name := msg.Command(); name != "" {
+			ctx = reqctx.WithCommand(ctx, name)
+		}
+
+		break
+	}
+
+	return ctx
 }
 
+// sendResponse performs the actions of the response in order.
+// Failures are reported for every action on its own and do not prevent the following actions from being performed.
+// Sending is not bound to the cancellation of the request since the handler is already done with it.
+func (s *Bot) sendResponse(ctx context.Context, resp middleware.Response) {
+	ctx = context.WithoutCancel(ctx)
+
+	for i, action := range resp.Actions {
+		if err := s.send(ctx, action); err != nil {
+			slog.ErrorContext(ctx, "Failed to send response action",
+				slog.Int("action", i+1),
+				slog.Int("actions", len(resp.Actions)),
+				slog.String("type", fmt.Sprintf("%T", action)),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
+
+// send performs a single outgoing action as an interactive reply within the rate limits.
+// Text messages are split into several messages when they do not fit into Telegram limits, and actions
+// that do not result in a message, such as callback query answers, are sent as plain requests.
+func (s *Bot) send(ctx context.Context, action tgbotapi.Chattable) error {
+	switch a := action.(type) {
+	case tgbotapi.MessageConfig:
+		return s.sendMessage(ctx, a)
+	case tgbotapi.EditMessageTextConfig:
+		err := s.limiter.Do(ctx, a.ChatID, ratelimit.Interactive, func() error {
+			_, err := s.tg.Send(action)
+			return err
+		})
+		if err != nil && !isNotModified(err) {
+			return fmt.Errorf("failed to send %T: %w", action, err)
+		}
+
+		return nil
+	case tgbotapi.CallbackConfig, tgbotapi.DeleteMessageConfig, tgbotapi.ChatActionConfig:
+		err := s.limiter.Do(ctx, 0, ratelimit.Interactive, func() error {
+			_, err := s.tg.Request(action)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to request %T: %w", action, err)
+		}
+
+		return nil
+	default:
+		err := s.limiter.Do(ctx, 0, ratelimit.Interactive, func() error {
+			_, err := s.tg.Send(action)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to send %T: %w", action, err)
+		}
+
+		return nil
+	}
+}
+
+// isNotModified reports whether the error is returned by Telegram for an edit that leaves the message as it is,
+// which happens when a menu button is pressed twice and is not a failure.
+func isNotModified(err error) bool {
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) {
+		return strings.Contains(tgErr.Message, "message is not modified")
+	}
+
+	return false
+}
+
+// sendMessage sends the message, splitting it into several messages when it does not fit into Telegram limits.
+// The parts are sent in order, each following part as a reply to the first one.
+func (s *Bot) sendMessage(ctx context.Context, msg tgbotapi.MessageConfig) error {
+	parts := splitMessage(msg)
+
+	var firstID int
+
+	for i := range parts {
+		if i > 0 {
+			parts[i].ReplyToMessageID = firstID
+		}
+
+		var sent tgbotapi.Message
+
+		err := s.limiter.Do(ctx, msg.ChatID, ratelimit.Interactive, func() (err error) {
+			sent, err = s.tg.Send(parts[i])
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to send part %d of %d: %w", i+1, len(parts), err)
+		}
+
+		if i == 0 {
+			firstID = sent.MessageID
+		}
+	}
+
+	return nil
+}
+
+// Run receives the updates in the configured mode and processes them until the context is cancelled.
+// On shutdown it stops receiving updates and waits for the updates being processed.
 func (s *Bot) Run(ctx context.Context) error {
-	slog.InfoContext(ctx, "Starting Telegram bot")
+	slog.InfoContext(ctx, "Starting Telegram bot", slog.String("mode", s.mode))
 
-	updateConfig := tgbotapi.NewUpdate(0)
-	updateConfig.Timeout = 30
+	if s.mode == ModeWebhook {
+		return s.runWebhook(ctx)
+	}
 
-	updates := s.tg.GetUpdatesChan(updateConfig)
+	return s.runPolling(ctx)
+}
 
+// serveUpdates processes the updates from the channel, each in its own goroutine, until the channel is closed
+// or the context is cancelled. On cancellation stop is called to stop receiving updates.
+// Each update is handled within its time budget, which commands waiting for slow external services extend.
+// A panic while processing an update, such as one raised when sending the response, only fails that update.
+func (s *Bot) serveUpdates(ctx context.Context, updates <-chan tgbotapi.Update, stop func()) {
 	var wg sync.WaitGroup
 
 	for {
 		select {
 		case update, ok := <-updates:
 			if !ok {
-				return nil
+				return
 			}
 
 			wg.Add(1)
@@ -132,19 +354,32 @@ func (s *Bot) Run(ctx context.Context) error {
 			go func() {
 				defer wg.Done()
 
-				reqCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+				reqCtx, cancel := context.WithTimeout(ctx, s.updateTimeout(&update))
 
-				//nolint:staticcheck // don't want to have dependecy on cmd package here for now
-				reqCtx = context.WithValue(reqCtx, "req_id", uuid.New().String())
+				reqCtx = reqctx.WithRequestID(reqCtx, uuid.New().String())
+				reqCtx = reqctx.WithUpdateID(reqCtx, update.UpdateID)
 
 				defer cancel()
 
-				s.processUpdate(reqCtx, &update)
+				reqCtx, span := tracer.Start(reqCtx, "bot.update",
+					trace.WithSpanKind(trace.SpanKindConsumer),
+					trace.WithAttributes(attribute.Int("update_id", update.UpdateID)),
+				)
+				defer span.End()
+
+				err := recovery.Do(reqCtx, "update", func() error {
+					s.processUpdate(reqCtx, &update)
+					return nil
+				})
+				if err != nil {
+					span.RecordError(err)
+					span.SetStatus(codes.Error, err.Error())
+				}
 			}()
 
 		case <-ctx.Done():
 			slog.Info("Starting graceful shutdown")
-			s.tg.StopReceivingUpdates()
+			stop()
 
 			// Wait for ongoing message processors with a timeout
 			done := make(chan struct{})
@@ -161,7 +396,7 @@ func (s *Bot) Run(ctx context.Context) error {
 				slog.Warn("Graceful shutdown timed out after 30 seconds")
 			}
 
-			return nil
+			return
 		}
 	}
 }
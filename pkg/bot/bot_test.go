@@ -2,11 +2,19 @@ package bot
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/bot/callback"
+	"github.com/ksysoev/tg-feeder/pkg/bot/dialog"
+	"github.com/ksysoev/tg-feeder/pkg/bot/middleware"
+	"github.com/ksysoev/tg-feeder/pkg/bot/ratelimit"
+	"github.com/ksysoev/tg-feeder/pkg/i18n"
+	"github.com/ksysoev/tg-feeder/pkg/reqctx"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNew(t *testing.T) {
@@ -25,11 +33,21 @@ func TestNew(t *testing.T) {
 			cfg:     &Config{},
 			wantErr: true,
 		},
+		{
+			name:    "unknown mode",
+			cfg:     &Config{Token: "test-token", Mode: "push"},
+			wantErr: true,
+		},
+		{
+			name:    "webhook mode without webhook url",
+			cfg:     &Config{Token: "test-token", Mode: ModeWebhook, Webhook: WebhookConfig{Secret: "s3cret"}},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := New(tt.cfg, NewMockService(t))
+			_, err := New(tt.cfg, NewMockService(t), dialog.NewMockStore(t))
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
@@ -39,7 +57,7 @@ func TestNew(t *testing.T) {
 	}
 }
 
-func TestHandle(t *testing.T) {
+func TestHandleMessage(t *testing.T) {
 	mockTokenSvc := NewMockService(t)
 	svc := &Bot{
 		token: "test-token",
@@ -96,7 +114,7 @@ func TestHandle(t *testing.T) {
 				},
 			},
 			setupMocks: func() {},
-			wantText:   helpMessage,
+			wantText:   svc.helpMessage(nil),
 			wantErr:    false,
 		},
 		{
@@ -129,7 +147,7 @@ func TestHandle(t *testing.T) {
 
 			tt.setupMocks()
 
-			msg, err := svc.Handle(context.Background(), tt.message)
+			msg, err := svc.handleMessage(context.Background(), &tgbotapi.Update{Message: tt.message})
 			if tt.wantErr {
 				assert.Error(t, err)
 				return
@@ -138,7 +156,11 @@ func TestHandle(t *testing.T) {
 			assert.NoError(t, err)
 
 			if tt.wantText != "" {
-				assert.Equal(t, tt.wantText, msg.Text)
+				require.Len(t, msg.Actions, 1)
+
+				reply, ok := msg.Actions[0].(tgbotapi.MessageConfig)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantText, reply.Text)
 			}
 		})
 	}
@@ -152,13 +174,19 @@ func TestProcessUpdate(t *testing.T) {
 		Token: "test-token",
 	}
 
+	translations, err := i18n.New()
+	require.NoError(t, err)
+
 	svc := &Bot{
-		token: cfg.Token,
-		tg:    mockTg,
-		svc:   mockTokenSvc,
+		token:        cfg.Token,
+		tg:           mockTg,
+		svc:          mockTokenSvc,
+		callbacks:    callback.New("secret"),
+		limiter:      ratelimit.New(ratelimit.Config{}),
+		translations: translations,
 	}
 
-	svc.handler = svc
+	svc.handler = svc.setupHandler()
 
 	tests := []struct {
 		update     *tgbotapi.Update
@@ -166,10 +194,8 @@ func TestProcessUpdate(t *testing.T) {
 		name       string
 	}{
 		{
-			name: "nil message",
-			update: &tgbotapi.Update{
-				Message: nil,
-			},
+			name:       "unsupported update",
+			update:     &tgbotapi.Update{},
 			setupMocks: func() {},
 		},
 		{
@@ -196,6 +222,52 @@ func TestProcessUpdate(t *testing.T) {
 				mockTg.EXPECT().Send(mock.Anything).Return(tgbotapi.Message{}, nil)
 			},
 		},
+		{
+			name: "reply in the chosen language",
+			update: &tgbotapi.Update{
+				Message: &tgbotapi.Message{
+					Text:     "/start",
+					Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 6}},
+					Chat:     &tgbotapi.Chat{ID: 123},
+					From:     &tgbotapi.User{ID: 456, LanguageCode: "en"},
+				},
+			},
+			setupMocks: func() {
+				mockTokenSvc.EXPECT().Language(mock.Anything, int64(456)).Return("ru", nil)
+				mockTg.EXPECT().Send(mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+					msg, ok := c.(tgbotapi.MessageConfig)
+					return ok && msg.Text == translations.Printer("ru").Text(welcomeMessage) && msg.Text != welcomeMessage
+				})).Return(tgbotapi.Message{}, nil)
+			},
+		},
+		{
+			name: "callback query is answered",
+			update: &tgbotapi.Update{
+				CallbackQuery: &tgbotapi.CallbackQuery{
+					ID:      "query",
+					Data:    "unknown",
+					From:    &tgbotapi.User{ID: 456},
+					Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}},
+				},
+			},
+			setupMocks: func() {
+				mockTg.EXPECT().Request(tgbotapi.NewCallback("query", unknownActionMessage)).Return(&tgbotapi.APIResponse{Ok: true}, nil)
+			},
+		},
+		{
+			name: "edited command",
+			update: &tgbotapi.Update{
+				EditedMessage: &tgbotapi.Message{
+					Text:     "/help",
+					Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 5}},
+					Chat:     &tgbotapi.Chat{ID: 123},
+					From:     &tgbotapi.User{ID: 456},
+				},
+			},
+			setupMocks: func() {
+				mockTg.EXPECT().Send(mock.Anything).Return(tgbotapi.Message{}, nil)
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -204,8 +276,219 @@ func TestProcessUpdate(t *testing.T) {
 			mockTokenSvc.ExpectedCalls = nil
 
 			tt.setupMocks()
+			mockTokenSvc.EXPECT().Language(mock.Anything, mock.Anything).Return("", nil).Maybe()
+			mockTokenSvc.EXPECT().TouchUser(mock.Anything, mock.Anything).Return(nil).Maybe()
 
 			svc.processUpdate(context.Background(), tt.update)
 		})
 	}
 }
+
+func TestWithUpdateScope(t *testing.T) {
+	command := []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 6}}
+
+	tests := []struct {
+		update      *tgbotapi.Update
+		name        string
+		wantCommand string
+		wantChatID  int64
+		wantUserID  int64
+	}{
+		{
+			name:   "nil update",
+			update: nil,
+		},
+		{
+			name: "command message",
+			update: &tgbotapi.Update{Message: &tgbotapi.Message{
+				Text:     "/start",
+				Entities: command,
+				Chat:     &tgbotapi.Chat{ID: 123},
+				From:     &tgbotapi.User{ID: 456},
+			}},
+			wantChatID:  123,
+			wantUserID:  456,
+			wantCommand: "start",
+		},
+		{
+			name: "text message",
+			update: &tgbotapi.Update{Message: &tgbotapi.Message{
+				Text: "hello",
+				Chat: &tgbotapi.Chat{ID: 123},
+				From: &tgbotapi.User{ID: 456},
+			}},
+			wantChatID: 123,
+			wantUserID: 456,
+		},
+		{
+			name: "channel command",
+			update: &tgbotapi.Update{ChannelPost: &tgbotapi.Message{
+				Text:     "/start",
+				Entities: command,
+				Chat:     &tgbotapi.Chat{ID: -100},
+			}},
+			wantChatID:  -100,
+			wantCommand: "start",
+		},
+		{
+			name: "callback query",
+			update: &tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+				From:    &tgbotapi.User{ID: 456},
+				Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}},
+			}},
+			wantChatID: 123,
+			wantUserID: 456,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := withUpdateScope(t.Context(), tt.update)
+
+			chatID, ok := reqctx.ChatID(ctx)
+			assert.Equal(t, tt.wantChatID != 0, ok)
+			assert.Equal(t, tt.wantChatID, chatID)
+
+			userID, ok := reqctx.UserID(ctx)
+			assert.Equal(t, tt.wantUserID != 0, ok)
+			assert.Equal(t, tt.wantUserID, userID)
+
+			cmd, ok := reqctx.Command(ctx)
+			assert.Equal(t, tt.wantCommand != "", ok)
+			assert.Equal(t, tt.wantCommand, cmd)
+		})
+	}
+}
+
+func TestSend(t *testing.T) {
+	long := strings.Repeat("word ", 2000)
+
+	tests := []struct {
+		action     tgbotapi.Chattable
+		setupMocks func(tg *MocktgClient)
+		name       string
+		wantErr    bool
+	}{
+		{
+			name:   "single message",
+			action: tgbotapi.NewMessage(1, "hello"),
+			setupMocks: func(tg *MocktgClient) {
+				tg.EXPECT().Send(mock.MatchedBy(func(c tgbotapi.MessageConfig) bool {
+					return c.Text == "hello" && c.ReplyToMessageID == 0
+				})).Return(tgbotapi.Message{MessageID: 10}, nil).Once()
+			},
+		},
+		{
+			name:   "long message is sent as replies to the first part",
+			action: tgbotapi.NewMessage(1, long),
+			setupMocks: func(tg *MocktgClient) {
+				tg.EXPECT().Send(mock.MatchedBy(func(c tgbotapi.MessageConfig) bool {
+					return c.ReplyToMessageID == 0
+				})).Return(tgbotapi.Message{MessageID: 10}, nil).Once()
+				tg.EXPECT().Send(mock.MatchedBy(func(c tgbotapi.MessageConfig) bool {
+					return c.ReplyToMessageID == 10
+				})).Return(tgbotapi.Message{MessageID: 11}, nil).Twice()
+			},
+		},
+		{
+			name:   "other actions are sent as is",
+			action: tgbotapi.NewEditMessageText(1, 2, "edited"),
+			setupMocks: func(tg *MocktgClient) {
+				tg.EXPECT().Send(tgbotapi.NewEditMessageText(1, 2, "edited")).Return(tgbotapi.Message{}, nil).Once()
+			},
+		},
+		{
+			name:   "edits leaving the message as it is are not failures",
+			action: tgbotapi.NewEditMessageText(1, 2, "same"),
+			setupMocks: func(tg *MocktgClient) {
+				tg.EXPECT().Send(tgbotapi.NewEditMessageText(1, 2, "same")).
+					Return(tgbotapi.Message{}, &tgbotapi.Error{Code: 400, Message: "Bad Request: message is not modified"}).Once()
+			},
+		},
+		{
+			name:   "callback answers are plain requests",
+			action: tgbotapi.NewCallback("query", "done"),
+			setupMocks: func(tg *MocktgClient) {
+				tg.EXPECT().Request(tgbotapi.NewCallback("query", "done")).Return(&tgbotapi.APIResponse{Ok: true}, nil).Once()
+			},
+		},
+		{
+			name:   "rate limited messages are retried",
+			action: tgbotapi.NewMessage(1, "hello"),
+			setupMocks: func(tg *MocktgClient) {
+				tg.EXPECT().Send(mock.Anything).Return(tgbotapi.Message{}, &tgbotapi.Error{
+					Code:               429,
+					Message:            "Too Many Requests: retry after 1",
+					ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 1},
+				}).Once()
+				tg.EXPECT().Send(mock.Anything).Return(tgbotapi.Message{MessageID: 10}, nil).Once()
+			},
+		},
+		{
+			name:   "send error stops sending",
+			action: tgbotapi.NewMessage(1, long),
+			setupMocks: func(tg *MocktgClient) {
+				tg.EXPECT().Send(mock.Anything).Return(tgbotapi.Message{}, assert.AnError).Once()
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tg := NewMocktgClient(t)
+			b := &Bot{tg: tg, limiter: ratelimit.New(ratelimit.Config{})}
+
+			tt.setupMocks(tg)
+
+			err := b.send(context.Background(), tt.action)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, assert.AnError)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSendResponse(t *testing.T) {
+	tg := NewMocktgClient(t)
+	b := &Bot{tg: tg, limiter: ratelimit.New(ratelimit.Config{})}
+
+	first := tgbotapi.NewMessage(1, "first")
+	callback := tgbotapi.NewCallback("query", "done")
+	last := tgbotapi.NewMessage(1, "last")
+
+	tg.EXPECT().Send(first).Return(tgbotapi.Message{}, assert.AnError).Once()
+	tg.EXPECT().Request(callback).Return(&tgbotapi.APIResponse{Ok: true}, nil).Once()
+	tg.EXPECT().Send(last).Return(tgbotapi.Message{}, nil).Once()
+
+	b.sendResponse(context.Background(), middleware.NewResponse(first, callback, last))
+}
+
+func TestServeUpdates_RecoversPanic(t *testing.T) {
+	handled := make(chan int, 2)
+
+	b := &Bot{handler: middleware.HandlerFunc(func(_ context.Context, update *tgbotapi.Update) (middleware.Response, error) {
+		handled <- update.UpdateID
+		panic("boom")
+	})}
+
+	updates := make(chan tgbotapi.Update)
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+
+	go func() {
+		b.serveUpdates(ctx, updates, func() {})
+		close(done)
+	}()
+
+	updates <- tgbotapi.Update{UpdateID: 1}
+	updates <- tgbotapi.Update{UpdateID: 2}
+
+	assert.ElementsMatch(t, []int{1, 2}, []int{<-handled, <-handled}, "a panicking update should not stop the others")
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,110 @@
+// Package callback encodes the payloads of inline keyboard buttons into compact signed strings
+// that fit into the 64 bytes Telegram allows for callback data, and decodes them back.
+//
+// Encoded data is the payload prefixed with a truncated HMAC-SHA256 signature bound to the chat
+// the button was sent to and to the user it is meant for, so that forged payloads, buttons copied from other chats
+// and buttons pressed by other members of a group are rejected.
+package callback
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxDataLength is the maximum length of callback data accepted by Telegram in bytes.
+	MaxDataLength = 64
+
+	signatureBytes  = 6
+	signatureLength = 8 // base64 length of signatureBytes
+	separator       = ":"
+)
+
+var (
+	// ErrTooLong is returned when the encoded data does not fit into MaxDataLength bytes.
+	ErrTooLong = errors.New("callback data too long")
+	// ErrMalformed is returned when the data cannot be encoded or decoded into menu, action and arguments.
+	ErrMalformed = errors.New("malformed callback data")
+	// ErrInvalidSignature is returned when the data was not produced by the codec for the chat and the user.
+	ErrInvalidSignature = errors.New("invalid callback data signature")
+)
+
+// Data is the payload of an inline keyboard button: the menu it belongs to, the action to perform and its arguments.
+// None of the fields may contain a colon.
+type Data struct {
+	Menu   string
+	Action string
+	Args   []string
+}
+
+// Codec signs and verifies callback data with a secret key.
+type Codec struct {
+	key []byte
+}
+
+// New creates a Codec signing callback data with the secret.
+func New(secret string) *Codec {
+	return &Codec{key: []byte(secret)}
+}
+
+// Encode encodes the payload of a button sent to the chat for the user, zero userID meaning any user of the chat.
+// It returns ErrMalformed if a field is empty or contains a colon and ErrTooLong if the result does not fit into
+// MaxDataLength bytes.
+func (c *Codec) Encode(chatID, userID int64, d Data) (string, error) {
+	if d.Menu == "" || d.Action == "" {
+		return "", fmt.Errorf("%w: menu and action are required", ErrMalformed)
+	}
+
+	fields := append([]string{d.Menu, d.Action}, d.Args...)
+
+	for _, f := range fields {
+		if strings.Contains(f, separator) {
+			return "", fmt.Errorf("%w: field %q contains %q", ErrMalformed, f, separator)
+		}
+	}
+
+	payload := strings.Join(fields, separator)
+	data := c.sign(chatID, userID, payload) + payload
+
+	if len(data) > MaxDataLength {
+		return "", fmt.Errorf("%w: %d bytes", ErrTooLong, len(data))
+	}
+
+	return data, nil
+}
+
+// Decode verifies and decodes the data of a button pressed in the chat by the user, zero userID standing
+// for buttons meant for any user of the chat. It returns ErrInvalidSignature if the data was not encoded
+// by the codec for the chat and the user and ErrMalformed if it has no menu or action.
+func (c *Codec) Decode(chatID, userID int64, data string) (Data, error) {
+	if len(data) <= signatureLength {
+		return Data{}, ErrMalformed
+	}
+
+	signature, payload := data[:signatureLength], data[signatureLength:]
+
+	if !hmac.Equal([]byte(signature), []byte(c.sign(chatID, userID, payload))) {
+		return Data{}, ErrInvalidSignature
+	}
+
+	fields := strings.Split(payload, separator)
+	if len(fields) < 2 || fields[0] == "" || fields[1] == "" {
+		return Data{}, ErrMalformed
+	}
+
+	return Data{Menu: fields[0], Action: fields[1], Args: fields[2:]}, nil
+}
+
+// sign returns the truncated signature of the payload for the chat and the user.
+func (c *Codec) sign(chatID, userID int64, payload string) string {
+	mac := hmac.New(sha256.New, c.key)
+
+	mac.Write([]byte(strconv.FormatInt(chatID, 10) + separator + strconv.FormatInt(userID, 10) + separator + payload))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:signatureBytes])
+}
@@ -0,0 +1,80 @@
+package callback
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodec_RoundTrip(t *testing.T) {
+	c := New("secret")
+
+	tests := []struct {
+		name string
+		data Data
+	}{
+		{name: "without arguments", data: Data{Menu: "f", Action: "l", Args: []string{}}},
+		{name: "with arguments", data: Data{Menu: "f", Action: "I", Args: []string{"0123456789abcdef", "60"}}},
+		{name: "empty argument", data: Data{Menu: "f", Action: "x", Args: []string{""}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := c.Encode(-100123, 42, tt.data)
+			require.NoError(t, err)
+			assert.LessOrEqual(t, len(encoded), MaxDataLength)
+
+			decoded, err := c.Decode(-100123, 42, encoded)
+			require.NoError(t, err)
+			assert.Equal(t, tt.data, decoded)
+		})
+	}
+}
+
+func TestCodec_Encode(t *testing.T) {
+	c := New("secret")
+
+	_, err := c.Encode(1, 0, Data{Menu: "f"})
+	assert.ErrorIs(t, err, ErrMalformed)
+
+	_, err = c.Encode(1, 0, Data{Menu: "f", Action: "a", Args: []string{"a:b"}})
+	assert.ErrorIs(t, err, ErrMalformed)
+
+	_, err = c.Encode(1, 0, Data{Menu: "f", Action: "a", Args: []string{strings.Repeat("x", MaxDataLength)}})
+	assert.ErrorIs(t, err, ErrTooLong)
+}
+
+func TestCodec_Decode(t *testing.T) {
+	c := New("secret")
+
+	encoded, err := c.Encode(1, 7, Data{Menu: "f", Action: "o", Args: []string{"feed"}})
+	require.NoError(t, err)
+
+	_, err = c.Decode(1, 7, encoded)
+	require.NoError(t, err)
+
+	_, err = c.Decode(2, 7, encoded)
+	assert.ErrorIs(t, err, ErrInvalidSignature, "data of another chat should be rejected")
+
+	_, err = c.Decode(1, 8, encoded)
+	assert.ErrorIs(t, err, ErrInvalidSignature, "data meant for another user should be rejected")
+
+	_, err = c.Decode(1, 0, encoded)
+	assert.ErrorIs(t, err, ErrInvalidSignature, "data meant for a user should not be accepted from the whole chat")
+
+	_, err = New("other").Decode(1, 7, encoded)
+	assert.ErrorIs(t, err, ErrInvalidSignature, "data signed with another secret should be rejected")
+
+	_, err = c.Decode(1, 7, encoded[:len(encoded)-1]+"x")
+	assert.ErrorIs(t, err, ErrInvalidSignature, "tampered data should be rejected")
+
+	_, err = c.Decode(1, 7, "short")
+	assert.ErrorIs(t, err, ErrMalformed)
+
+	noAction := c.sign(1, 7, "f") + "f"
+
+	_, err = c.Decode(1, 7, noAction)
+	assert.ErrorIs(t, err, ErrMalformed)
+}
@@ -0,0 +1,242 @@
+// Package dialog implements multi-step conversations between the bot and a chat.
+//
+// A dialog is a sequence of steps, each asking the user a question. Answers are checked by the step validator,
+// stored under the step name and the dialog moves on to the step chosen by the transition until there are no
+// steps left, when the collected values are handed to the dialog completion function.
+// The state of the active dialog of every user in a chat is persisted in a Store, so dialogs survive restarts
+// and expire when abandoned, and members of a group can run their own dialogs at the same time.
+package dialog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownDialog is returned when starting a dialog that is not registered.
+var ErrUnknownDialog = errors.New("unknown dialog")
+
+// Key identifies the user in a chat a dialog is held with.
+// Updates without a sender, such as channel posts, use the chat id as the user id.
+type Key struct {
+	ChatID int64
+	UserID int64
+}
+
+// State is the persisted state of the active dialog of a user in a chat.
+type State struct {
+	Values map[string]string `json:"values,omitempty"`
+	Dialog string            `json:"dialog"`
+	Step   string            `json:"step"`
+}
+
+// Store persists the state of the active dialog of every user in a chat.
+// Get returns nil when the user has no active dialog in the chat or its state has expired.
+type Store interface {
+	Get(ctx context.Context, key Key) (*State, error)
+	Save(ctx context.Context, key Key, state *State) error
+	Delete(ctx context.Context, key Key) error
+}
+
+// InputError rejects the answer of the user. Its message is shown to the user before the question is asked again.
+type InputError struct {
+	Message string
+}
+
+// Error returns the message explaining why the answer was rejected.
+func (e *InputError) Error() string {
+	return e.Message
+}
+
+// Reject returns an InputError with the message.
+func Reject(message string) error {
+	return &InputError{Message: message}
+}
+
+// Step is a question of a dialog.
+// Validate checks the answer and returns the value to store under the step name; it may be nil to accept any answer.
+// Next chooses the following step from the values collected so far; when nil the dialog moves on to the following
+// step of the dialog. An empty step name completes the dialog.
+type Step struct {
+	Validate func(ctx context.Context, answer string) (string, error)
+	Next     func(values map[string]string) string
+	Name     string
+	Prompt   string
+}
+
+// Dialog describes a conversation: its steps, the first one being asked first, and the function completing
+// the dialog with the collected values and returning the final reply.
+type Dialog struct {
+	Done  func(ctx context.Context, chatID int64, values map[string]string) (string, error)
+	Name  string
+	Steps []Step
+}
+
+// step returns the step with the name.
+func (d *Dialog) step(name string) (int, bool) {
+	for i := range d.Steps {
+		if d.Steps[i].Name == name {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// Reply is the text to send to the user in response to a dialog event.
+// Notice explains why the previous answer was rejected and is kept apart from the prompt in Text,
+// so each of them can be translated on its own. Waiting is set while the dialog waits for the next answer of the user.
+type Reply struct {
+	Notice  string
+	Text    string
+	Waiting bool
+}
+
+// Manager runs the dialogs of the users in the chats.
+//
+// State is read, updated and written back without locking, relying on updates of a user being processed
+// one at a time, which the request sequencer middleware of the bot guarantees. The sequencer works per sender,
+// so the state is kept per user in a chat rather than per chat.
+type Manager struct {
+	store   Store
+	dialogs map[string]*Dialog
+}
+
+// New creates a Manager running the dialogs with their state persisted in the store.
+func New(store Store, dialogs ...*Dialog) *Manager {
+	m := &Manager{
+		store:   store,
+		dialogs: make(map[string]*Dialog, len(dialogs)),
+	}
+
+	for _, d := range dialogs {
+		m.dialogs[d.Name] = d
+	}
+
+	return m
+}
+
+// Start starts the dialog with the user in the chat, replacing the active one, and returns its first question.
+func (m *Manager) Start(ctx context.Context, key Key, name string) (*Reply, error) {
+	d, ok := m.dialogs[name]
+	if !ok || len(d.Steps) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDialog, name)
+	}
+
+	return m.ask(ctx, key, &State{Dialog: name, Values: map[string]string{}}, d, 0)
+}
+
+// Handle passes the answer of the user to their active dialog in the chat.
+// It returns the reply to send, or nil if the user has no active dialog in the chat.
+func (m *Manager) Handle(ctx context.Context, key Key, answer string) (*Reply, error) {
+	state, err := m.store.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dialog state: %w", err)
+	}
+
+	if state == nil {
+		return nil, nil
+	}
+
+	d, ok := m.dialogs[state.Dialog]
+
+	var idx int
+	if ok {
+		idx, ok = d.step(state.Step)
+	}
+
+	if !ok {
+		// The dialog or its step is gone since the state was saved, most likely with a new release.
+		if err := m.store.Delete(ctx, key); err != nil {
+			return nil, fmt.Errorf("failed to delete dialog state: %w", err)
+		}
+
+		return nil, nil
+	}
+
+	step := &d.Steps[idx]
+
+	value := answer
+	if step.Validate != nil {
+		value, err = step.Validate(ctx, answer)
+
+		var inputErr *InputError
+
+		switch {
+		case errors.As(err, &inputErr):
+			return &Reply{Notice: inputErr.Message, Text: step.Prompt, Waiting: true}, nil
+		case err != nil:
+			return nil, fmt.Errorf("failed to validate %s answer: %w", step.Name, err)
+		}
+	}
+
+	if state.Values == nil {
+		state.Values = map[string]string{}
+	}
+
+	state.Values[step.Name] = value
+
+	next := ""
+
+	switch {
+	case step.Next != nil:
+		next = step.Next(state.Values)
+	case idx+1 < len(d.Steps):
+		next = d.Steps[idx+1].Name
+	}
+
+	if next == "" {
+		return m.finish(ctx, key, state, d)
+	}
+
+	nextIdx, ok := d.step(next)
+	if !ok {
+		return nil, fmt.Errorf("dialog %s has no step %s", d.Name, next)
+	}
+
+	return m.ask(ctx, key, state, d, nextIdx)
+}
+
+// Cancel ends the active dialog of the user in the chat and reports whether there was one.
+func (m *Manager) Cancel(ctx context.Context, key Key) (bool, error) {
+	state, err := m.store.Get(ctx, key)
+	if err != nil {
+		return false, fmt.Errorf("failed to get dialog state: %w", err)
+	}
+
+	if state == nil {
+		return false, nil
+	}
+
+	if err := m.store.Delete(ctx, key); err != nil {
+		return false, fmt.Errorf("failed to delete dialog state: %w", err)
+	}
+
+	return true, nil
+}
+
+// ask moves the dialog to the step and returns its question.
+func (m *Manager) ask(ctx context.Context, key Key, state *State, d *Dialog, idx int) (*Reply, error) {
+	state.Step = d.Steps[idx].Name
+
+	if err := m.store.Save(ctx, key, state); err != nil {
+		return nil, fmt.Errorf("failed to save dialog state: %w", err)
+	}
+
+	return &Reply{Text: d.Steps[idx].Prompt, Waiting: true}, nil
+}
+
+// finish ends the dialog and completes it with the collected values.
+// The state is removed before completing, so a failing completion never leaves the chat stuck in the dialog.
+func (m *Manager) finish(ctx context.Context, key Key, state *State, d *Dialog) (*Reply, error) {
+	if err := m.store.Delete(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to delete dialog state: %w", err)
+	}
+
+	text, err := d.Done(ctx, key.ChatID, state.Values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete %s dialog: %w", d.Name, err)
+	}
+
+	return &Reply{Text: text}, nil
+}
@@ -0,0 +1,260 @@
+package dialog
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testKey = Key{ChatID: -100, UserID: 1}
+
+// memStore is an in-memory Store.
+type memStore map[Key]State
+
+// Get returns a copy of the state stored under the key.
+func (s memStore) Get(_ context.Context, key Key) (*State, error) {
+	state, ok := s[key]
+	if !ok {
+		return nil, nil
+	}
+
+	state.Values = maps.Clone(state.Values)
+
+	return &state, nil
+}
+
+// Save stores a copy of the state under the key.
+func (s memStore) Save(_ context.Context, key Key, state *State) error {
+	saved := *state
+	saved.Values = maps.Clone(state.Values)
+	s[key] = saved
+
+	return nil
+}
+
+// Delete removes the state stored under the key.
+func (s memStore) Delete(_ context.Context, key Key) error {
+	delete(s, key)
+	return nil
+}
+
+func testDialog() *Dialog {
+	return &Dialog{
+		Name: "add",
+		Steps: []Step{
+			{
+				Name:   "url",
+				Prompt: "Send the URL",
+				Validate: func(_ context.Context, answer string) (string, error) {
+					answer = strings.TrimSpace(answer)
+					if !strings.HasPrefix(answer, "https://") {
+						return "", Reject("Not a URL")
+					}
+
+					return answer, nil
+				},
+				Next: func(values map[string]string) string {
+					if strings.HasSuffix(values["url"], ".json") {
+						return ""
+					}
+
+					return "filter"
+				},
+			},
+			{Name: "filter", Prompt: "Send keywords"},
+		},
+		Done: func(_ context.Context, _ int64, values map[string]string) (string, error) {
+			return "Added " + values["url"] + " " + values["filter"], nil
+		},
+	}
+}
+
+func TestManager_Start(t *testing.T) {
+	store := NewMockStore(t)
+	m := New(store, testDialog())
+
+	store.EXPECT().Save(mock.Anything, testKey, &State{Dialog: "add", Step: "url", Values: map[string]string{}}).Return(nil)
+
+	reply, err := m.Start(context.Background(), testKey, "add")
+
+	require.NoError(t, err)
+	assert.Equal(t, &Reply{Text: "Send the URL", Waiting: true}, reply)
+
+	_, err = m.Start(context.Background(), testKey, "missing")
+	assert.ErrorIs(t, err, ErrUnknownDialog)
+}
+
+func TestManager_Handle(t *testing.T) {
+	tests := []struct {
+		setupMocks func(store *MockStore)
+		want       *Reply
+		name       string
+		answer     string
+		wantErr    bool
+	}{
+		{
+			name:   "no active dialog",
+			answer: "hello",
+			setupMocks: func(store *MockStore) {
+				store.EXPECT().Get(mock.Anything, testKey).Return(nil, nil)
+			},
+		},
+		{
+			name:   "invalid answer asks again",
+			answer: "nope",
+			setupMocks: func(store *MockStore) {
+				store.EXPECT().Get(mock.Anything, testKey).Return(&State{Dialog: "add", Step: "url"}, nil)
+			},
+			want: &Reply{Notice: "Not a URL", Text: "Send the URL", Waiting: true},
+		},
+		{
+			name:   "valid answer moves to the next step",
+			answer: " https://example.com/rss ",
+			setupMocks: func(store *MockStore) {
+				store.EXPECT().Get(mock.Anything, testKey).Return(&State{Dialog: "add", Step: "url"}, nil)
+				store.EXPECT().Save(mock.Anything, testKey, &State{
+					Dialog: "add",
+					Step:   "filter",
+					Values: map[string]string{"url": "https://example.com/rss"},
+				}).Return(nil)
+			},
+			want: &Reply{Text: "Send keywords", Waiting: true},
+		},
+		{
+			name:   "transition completes the dialog",
+			answer: "https://example.com/feed.json",
+			setupMocks: func(store *MockStore) {
+				store.EXPECT().Get(mock.Anything, testKey).Return(&State{Dialog: "add", Step: "url"}, nil)
+				store.EXPECT().Delete(mock.Anything, testKey).Return(nil)
+			},
+			want: &Reply{Text: "Added https://example.com/feed.json "},
+		},
+		{
+			name:   "last step completes the dialog",
+			answer: "go",
+			setupMocks: func(store *MockStore) {
+				store.EXPECT().Get(mock.Anything, testKey).
+					Return(&State{Dialog: "add", Step: "filter", Values: map[string]string{"url": "https://example.com/rss"}}, nil)
+				store.EXPECT().Delete(mock.Anything, testKey).Return(nil)
+			},
+			want: &Reply{Text: "Added https://example.com/rss go"},
+		},
+		{
+			name:   "stale state is dropped",
+			answer: "go",
+			setupMocks: func(store *MockStore) {
+				store.EXPECT().Get(mock.Anything, testKey).Return(&State{Dialog: "add", Step: "removed"}, nil)
+				store.EXPECT().Delete(mock.Anything, testKey).Return(nil)
+			},
+		},
+		{
+			name:   "store error",
+			answer: "go",
+			setupMocks: func(store *MockStore) {
+				store.EXPECT().Get(mock.Anything, testKey).Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name:   "save error",
+			answer: "https://example.com/rss",
+			setupMocks: func(store *MockStore) {
+				store.EXPECT().Get(mock.Anything, testKey).Return(&State{Dialog: "add", Step: "url"}, nil)
+				store.EXPECT().Save(mock.Anything, testKey, mock.Anything).Return(assert.AnError)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMockStore(t)
+			m := New(store, testDialog())
+
+			tt.setupMocks(store)
+
+			reply, err := m.Handle(context.Background(), testKey, tt.answer)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, assert.AnError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, reply)
+		})
+	}
+}
+
+func TestManager_HandleDoneError(t *testing.T) {
+	store := NewMockStore(t)
+	d := testDialog()
+	d.Done = func(context.Context, int64, map[string]string) (string, error) {
+		return "", assert.AnError
+	}
+
+	m := New(store, d)
+
+	store.EXPECT().Get(mock.Anything, testKey).Return(&State{Dialog: "add", Step: "filter"}, nil)
+	store.EXPECT().Delete(mock.Anything, testKey).Return(nil).Once()
+
+	_, err := m.Handle(context.Background(), testKey, "go")
+
+	assert.ErrorIs(t, err, assert.AnError, "the state should be removed even when the completion fails")
+}
+
+func TestManager_Cancel(t *testing.T) {
+	store := NewMockStore(t)
+	m := New(store, testDialog())
+
+	store.EXPECT().Get(mock.Anything, testKey).Return(&State{Dialog: "add", Step: "url"}, nil).Once()
+	store.EXPECT().Delete(mock.Anything, testKey).Return(nil).Once()
+
+	cancelled, err := m.Cancel(context.Background(), testKey)
+
+	require.NoError(t, err)
+	assert.True(t, cancelled)
+
+	store.EXPECT().Get(mock.Anything, testKey).Return(nil, nil).Once()
+
+	cancelled, err = m.Cancel(context.Background(), testKey)
+
+	require.NoError(t, err)
+	assert.False(t, cancelled)
+}
+
+func TestManager_GroupMembers(t *testing.T) {
+	m := New(memStore{}, testDialog())
+	alice := Key{ChatID: -100, UserID: 1}
+	bob := Key{ChatID: -100, UserID: 2}
+
+	_, err := m.Start(context.Background(), alice, "add")
+	require.NoError(t, err)
+
+	reply, err := m.Handle(context.Background(), bob, "https://example.com/bob")
+	require.NoError(t, err)
+	assert.Nil(t, reply, "a member without an active dialog should not answer the dialog of another member")
+
+	_, err = m.Start(context.Background(), bob, "add")
+	require.NoError(t, err)
+
+	reply, err = m.Handle(context.Background(), alice, "https://example.com/alice")
+	require.NoError(t, err)
+	assert.Equal(t, &Reply{Text: "Send keywords", Waiting: true}, reply)
+
+	reply, err = m.Handle(context.Background(), bob, "https://example.com/bob")
+	require.NoError(t, err)
+	assert.Equal(t, &Reply{Text: "Send keywords", Waiting: true}, reply)
+
+	reply, err = m.Handle(context.Background(), alice, "go")
+	require.NoError(t, err)
+	assert.Equal(t, &Reply{Text: "Added https://example.com/alice go"}, reply)
+
+	reply, err = m.Handle(context.Background(), bob, "rust")
+	require.NoError(t, err)
+	assert.Equal(t, &Reply{Text: "Added https://example.com/bob rust"}, reply)
+}
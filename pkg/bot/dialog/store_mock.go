@@ -0,0 +1,192 @@
+// Code generated by mockery. DO NOT EDIT.
+
+//go:build !compile
+
+package dialog
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockStore is an autogenerated mock type for the Store type
+type MockStore struct {
+	mock.Mock
+}
+
+type MockStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockStore) EXPECT() *MockStore_Expecter {
+	return &MockStore_Expecter{mock: &_m.Mock}
+}
+
+// Delete provides a mock function with given fields: ctx, key
+func (_m *MockStore) Delete(ctx context.Context, key Key) error {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, Key) error); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockStore_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockStore_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key Key
+func (_e *MockStore_Expecter) Delete(ctx interface{}, key interface{}) *MockStore_Delete_Call {
+	return &MockStore_Delete_Call{Call: _e.mock.On("Delete", ctx, key)}
+}
+
+func (_c *MockStore_Delete_Call) Run(run func(ctx context.Context, key Key)) *MockStore_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(Key))
+	})
+	return _c
+}
+
+func (_c *MockStore_Delete_Call) Return(_a0 error) *MockStore_Delete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockStore_Delete_Call) RunAndReturn(run func(context.Context, Key) error) *MockStore_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function with given fields: ctx, key
+func (_m *MockStore) Get(ctx context.Context, key Key) (*State, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *State
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, Key) (*State, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, Key) *State); ok {
+		r0 = rf(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*State)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, Key) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockStore_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type MockStore_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key Key
+func (_e *MockStore_Expecter) Get(ctx interface{}, key interface{}) *MockStore_Get_Call {
+	return &MockStore_Get_Call{Call: _e.mock.On("Get", ctx, key)}
+}
+
+func (_c *MockStore_Get_Call) Run(run func(ctx context.Context, key Key)) *MockStore_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(Key))
+	})
+	return _c
+}
+
+func (_c *MockStore_Get_Call) Return(_a0 *State, _a1 error) *MockStore_Get_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockStore_Get_Call) RunAndReturn(run func(context.Context, Key) (*State, error)) *MockStore_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Save provides a mock function with given fields: ctx, key, state
+func (_m *MockStore) Save(ctx context.Context, key Key, state *State) error {
+	ret := _m.Called(ctx, key, state)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, Key, *State) error); ok {
+		r0 = rf(ctx, key, state)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockStore_Save_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Save'
+type MockStore_Save_Call struct {
+	*mock.Call
+}
+
+// Save is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key Key
+//   - state *State
+func (_e *MockStore_Expecter) Save(ctx interface{}, key interface{}, state interface{}) *MockStore_Save_Call {
+	return &MockStore_Save_Call{Call: _e.mock.On("Save", ctx, key, state)}
+}
+
+func (_c *MockStore_Save_Call) Run(run func(ctx context.Context, key Key, state *State)) *MockStore_Save_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(Key), args[2].(*State))
+	})
+	return _c
+}
+
+func (_c *MockStore_Save_Call) Return(_a0 error) *MockStore_Save_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockStore_Save_Call) RunAndReturn(run func(context.Context, Key, *State) error) *MockStore_Save_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockStore creates a new instance of MockStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockStore {
+	mock := &MockStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,131 @@
+package bot
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/bot/dialog"
+	"github.com/ksysoev/tg-feeder/pkg/i18n"
+)
+
+const (
+	subscribeDialog = "subscribe"
+
+	subscribeURLPrompt    = "Send me the URL of the RSS, Atom or JSON feed you want to subscribe to.\n\nUse /cancel to stop."
+	subscribeFilterPrompt = "Send keywords to receive only the items matching them, or - to receive every item."
+	noKeywordsMessage     = "Please send at least one keyword or -."
+	skipAnswer            = "-"
+)
+
+// newDialogManager creates the manager of the dialogs supported by the bot with their state persisted in the store.
+func (s *Bot) newDialogManager(store dialog.Store) *dialog.Manager {
+	return dialog.New(store,
+		s.subscribeDialog(),
+	)
+}
+
+// subscribeDialog asks for a feed URL and the keyword filter of the subscription, then subscribes the chat.
+func (s *Bot) subscribeDialog() *dialog.Dialog {
+	return &dialog.Dialog{
+		Name: subscribeDialog,
+		Steps: []dialog.Step{
+			{Name: "url", Prompt: subscribeURLPrompt, Validate: validateFeedURL},
+			{Name: "filter", Prompt: subscribeFilterPrompt, Validate: validateKeywords},
+		},
+		Done: func(ctx context.Context, chatID int64, values map[string]string) (string, error) {
+			return s.subscribe(ctx, chatID, values["url"], values["filter"])
+		},
+	}
+}
+
+// validateFeedURL accepts absolute http and https URLs.
+func validateFeedURL(_ context.Context, answer string) (string, error) {
+	answer = strings.TrimSpace(answer)
+
+	u, err := url.Parse(answer)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", dialog.Reject(invalidURLMessage)
+	}
+
+	return answer, nil
+}
+
+// validateKeywords normalizes the whitespace between keywords, the skip answer standing for no keywords.
+func validateKeywords(_ context.Context, answer string) (string, error) {
+	if strings.TrimSpace(answer) == skipAnswer {
+		return "", nil
+	}
+
+	keywords := strings.Join(strings.Fields(answer), " ")
+	if keywords == "" {
+		return "", dialog.Reject(noKeywordsMessage)
+	}
+
+	return keywords, nil
+}
+
+// newDialogMessage constructs the message carrying the reply of a dialog, translating the prompts and rejections
+// of the steps, which are messages of the bot, each on its own before joining them. While the dialog waits
+// for an answer the message asks the user to reply to it, which is the only way the bot receives plain text
+// in groups when its privacy mode is enabled.
+func newDialogMessage(ctx context.Context, chatID int64, reply *dialog.Reply) tgbotapi.MessageConfig {
+	p := i18n.FromContext(ctx)
+
+	text := p.Text(reply.Text)
+	if reply.Notice != "" {
+		text = p.Text(reply.Notice) + "\n\n" + text
+	}
+
+	msg := newTextMessage(chatID, text)
+
+	if reply.Waiting {
+		msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, Selective: true}
+	}
+
+	return msg
+}
+
+// dialogKey returns the key of the dialog held with the sender of the message in its chat, so members of a group
+// have their own dialogs. Messages without a sender, such as channel posts, hold the dialog with the chat itself,
+// the same way the request sequencer orders them.
+func dialogKey(msg *tgbotapi.Message) dialog.Key {
+	key := dialog.Key{ChatID: msg.Chat.ID, UserID: msg.Chat.ID}
+
+	if msg.From != nil {
+		key.UserID = msg.From.ID
+	}
+
+	return key
+}
+
+// handleCancel cancels the active dialog of the sender in the chat.
+func (s *Bot) handleCancel(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	cancelled, err := s.dialogs.Cancel(ctx, dialogKey(msg))
+	if err != nil {
+		return tgbotapi.MessageConfig{}, err
+	}
+
+	p := i18n.FromContext(ctx)
+
+	if !cancelled {
+		return newTextMessage(msg.Chat.ID, p.Text(nothingToCancelMessage)), nil
+	}
+
+	return newTextMessage(msg.Chat.ID, p.Text(cancelledMessage)), nil
+}
+
+// handleDialogAnswer passes a plain text message to the active dialog of the sender in the chat.
+func (s *Bot) handleDialogAnswer(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	reply, err := s.dialogs.Handle(ctx, dialogKey(msg), msg.Text)
+	if err != nil {
+		return tgbotapi.MessageConfig{}, err
+	}
+
+	if reply == nil {
+		return newTextMessage(msg.Chat.ID, i18n.FromContext(ctx).Text(noDialogMessage)), nil
+	}
+
+	return newDialogMessage(ctx, msg.Chat.ID, reply), nil
+}
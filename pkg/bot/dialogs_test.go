@@ -0,0 +1,177 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/bot/dialog"
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/ksysoev/tg-feeder/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testDialogKey is the dialog key of the messages built by newTextUpdate and newCommandMessage.
+var testDialogKey = dialog.Key{ChatID: 123, UserID: 456}
+
+func newTextUpdate(text string) *tgbotapi.Update {
+	return &tgbotapi.Update{Message: &tgbotapi.Message{
+		Text: text,
+		Chat: &tgbotapi.Chat{ID: 123},
+		From: &tgbotapi.User{ID: 456},
+	}}
+}
+
+func TestSubscribeDialog(t *testing.T) {
+	svc := NewMockService(t)
+	store := dialog.NewMockStore(t)
+	b := &Bot{svc: svc, tg: NewMocktgClient(t)}
+	b.dialogs = b.newDialogManager(store)
+
+	store.EXPECT().Save(mock.Anything, testDialogKey, &dialog.State{Dialog: subscribeDialog, Step: "url", Values: map[string]string{}}).
+		Return(nil).Once()
+
+	resp, err := b.handleCommand(context.Background(), newCommandMessage("subscribe", ""))
+
+	require.NoError(t, err)
+	assert.Equal(t, subscribeURLPrompt, resp.Text)
+	assert.Equal(t, tgbotapi.ForceReply{ForceReply: true, Selective: true}, resp.ReplyMarkup)
+
+	store.EXPECT().Get(mock.Anything, testDialogKey).Return(&dialog.State{Dialog: subscribeDialog, Step: "url"}, nil).Once()
+
+	reply, err := b.handleMessage(context.Background(), newTextUpdate("not a url"))
+
+	require.NoError(t, err)
+	require.Len(t, reply.Actions, 1)
+	assert.Equal(t, invalidURLMessage+"\n\n"+subscribeURLPrompt, reply.Actions[0].(tgbotapi.MessageConfig).Text)
+
+	store.EXPECT().Get(mock.Anything, testDialogKey).Return(&dialog.State{Dialog: subscribeDialog, Step: "url"}, nil).Once()
+	store.EXPECT().Save(mock.Anything, testDialogKey, &dialog.State{
+		Dialog: subscribeDialog,
+		Step:   "filter",
+		Values: map[string]string{"url": "https://example.com/rss"},
+	}).Return(nil).Once()
+
+	reply, err = b.handleMessage(context.Background(), newTextUpdate("https://example.com/rss"))
+
+	require.NoError(t, err)
+	require.Len(t, reply.Actions, 1)
+	assert.Equal(t, subscribeFilterPrompt, reply.Actions[0].(tgbotapi.MessageConfig).Text)
+
+	store.EXPECT().Get(mock.Anything, testDialogKey).
+		Return(&dialog.State{Dialog: subscribeDialog, Step: "filter", Values: map[string]string{"url": "https://example.com/rss"}}, nil).Once()
+	store.EXPECT().Delete(mock.Anything, testDialogKey).Return(nil).Once()
+	svc.EXPECT().Subscribe(mock.Anything, int64(123), "https://example.com/rss").Return(&core.FeedInfo{ID: "abc", Title: "Example"}, nil)
+	svc.EXPECT().GetSubscription(mock.Anything, int64(123), "abc").
+		Return(&core.Subscription{Feed: core.FeedInfo{ID: "abc"}, Settings: core.SubscriptionSettings{Preview: true}}, nil)
+	svc.EXPECT().UpdateSubscriptionSettings(mock.Anything, int64(123), "abc", &core.SubscriptionSettings{Preview: true, Filter: "go generics"}).
+		Return(&core.Subscription{}, nil)
+
+	reply, err = b.handleMessage(context.Background(), newTextUpdate(" go   generics "))
+
+	require.NoError(t, err)
+	require.Len(t, reply.Actions, 1)
+
+	done := reply.Actions[0].(tgbotapi.MessageConfig)
+	assert.Equal(t, "✅ Subscribed to Example\n\nid: abc\nFilter: go generics", done.Text)
+	assert.IsType(t, tgbotapi.ReplyKeyboardRemove{}, done.ReplyMarkup)
+}
+
+func TestSubscribeDialog_LocalizedRejection(t *testing.T) {
+	translations, err := i18n.New()
+	require.NoError(t, err)
+
+	ru := translations.Printer("ru")
+	ctx := i18n.WithPrinter(context.Background(), ru)
+
+	store := dialog.NewMockStore(t)
+	b := &Bot{svc: NewMockService(t), tg: NewMocktgClient(t)}
+	b.dialogs = b.newDialogManager(store)
+
+	store.EXPECT().Get(mock.Anything, testDialogKey).
+		Return(&dialog.State{Dialog: subscribeDialog, Step: "filter", Values: map[string]string{"url": "https://example.com/rss"}}, nil)
+
+	reply, err := b.handleMessage(ctx, newTextUpdate("   "))
+
+	require.NoError(t, err)
+	require.Len(t, reply.Actions, 1)
+
+	text := reply.Actions[0].(tgbotapi.MessageConfig).Text
+	assert.Equal(t, ru.Text(noKeywordsMessage)+"\n\n"+ru.Text(subscribeFilterPrompt), text)
+	assert.NotContains(t, text, noKeywordsMessage, "the rejection should be translated")
+	assert.NotContains(t, text, subscribeFilterPrompt, "the prompt should be translated")
+}
+
+func TestHandleMessage_WithoutDialog(t *testing.T) {
+	store := dialog.NewMockStore(t)
+	b := &Bot{svc: NewMockService(t), tg: NewMocktgClient(t)}
+	b.dialogs = b.newDialogManager(store)
+
+	store.EXPECT().Get(mock.Anything, testDialogKey).Return(nil, nil).Once()
+
+	resp, err := b.handleMessage(context.Background(), newTextUpdate("hello"))
+
+	require.NoError(t, err)
+	assert.Equal(t, newTextMessage(123, noDialogMessage), resp.Actions[0])
+
+	store.EXPECT().Get(mock.Anything, testDialogKey).Return(nil, assert.AnError).Once()
+
+	_, err = b.handleMessage(context.Background(), newTextUpdate("hello"))
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestHandleCancel(t *testing.T) {
+	store := dialog.NewMockStore(t)
+	b := &Bot{svc: NewMockService(t), tg: NewMocktgClient(t)}
+	b.dialogs = b.newDialogManager(store)
+
+	store.EXPECT().Get(mock.Anything, testDialogKey).Return(&dialog.State{Dialog: subscribeDialog, Step: "url"}, nil).Once()
+	store.EXPECT().Delete(mock.Anything, testDialogKey).Return(nil).Once()
+
+	resp, err := b.handleCommand(context.Background(), newCommandMessage("cancel", ""))
+
+	require.NoError(t, err)
+	assert.Equal(t, cancelledMessage, resp.Text)
+
+	store.EXPECT().Get(mock.Anything, testDialogKey).Return(nil, nil).Once()
+
+	resp, err = b.handleCommand(context.Background(), newCommandMessage("cancel", ""))
+
+	require.NoError(t, err)
+	assert.Equal(t, nothingToCancelMessage, resp.Text)
+}
+
+func TestValidateKeywords(t *testing.T) {
+	got, err := validateKeywords(context.Background(), "-")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	got, err = validateKeywords(context.Background(), "a\n b")
+	require.NoError(t, err)
+	assert.Equal(t, "a b", got)
+
+	_, err = validateKeywords(context.Background(), "  ")
+
+	var inputErr *dialog.InputError
+	assert.ErrorAs(t, err, &inputErr)
+}
+
+func TestValidateFeedURL(t *testing.T) {
+	got, err := validateFeedURL(context.Background(), " http://example.com/rss ")
+	require.NoError(t, err)
+	assert.Equal(t, "http://example.com/rss", got)
+
+	for _, answer := range []string{"example.com", "ftp://example.com", "https://", "%"} {
+		_, err := validateFeedURL(context.Background(), answer)
+		assert.Error(t, err, answer)
+	}
+}
+
+func TestDialogKey(t *testing.T) {
+	assert.Equal(t, testDialogKey, dialogKey(newTextUpdate("hello").Message))
+
+	post := &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: -100}}
+	assert.Equal(t, dialog.Key{ChatID: -100, UserID: -100}, dialogKey(post), "messages without sender should hold the dialog with the chat")
+}
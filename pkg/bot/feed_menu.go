@@ -0,0 +1,396 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/ksysoev/tg-feeder/pkg/i18n"
+)
+
+// feedMenu is the name of the subscription settings menu in callback data.
+const feedMenu = "f"
+
+// Actions of the subscription settings menu. Names are kept to a single character
+// so that the feed id and the arguments fit into callback data.
+const (
+	feedActionList           = "l"
+	feedActionOpen           = "o"
+	feedActionPause          = "p"
+	feedActionPreview        = "v"
+	feedActionIntervals      = "i"
+	feedActionSetInterval    = "I"
+	feedActionFilter         = "f"
+	feedActionClearFilter    = "F"
+	feedActionConfirmRemoval = "u"
+	feedActionUnsubscribe    = "U"
+)
+
+const (
+	goneSubscriptionNotice = "You are no longer subscribed to this feed."
+	sharedIntervalNotice   = "Other chats are subscribed to this feed as well, so its interval can't be changed."
+	intervalsPrompt        = "⏱ How often should %s be checked for new items?\n\n" +
+		"The interval is shared by every chat subscribed to the feed, so it can only be changed while no other chat is subscribed to it."
+	backButton      = "« Back"
+	intervalsPerRow = 4
+)
+
+// intervalPresets lists the polling intervals offered by the menu, zero restoring the default one.
+var intervalPresets = []time.Duration{
+	0,
+	15 * time.Minute,
+	30 * time.Minute,
+	time.Hour,
+	3 * time.Hour,
+	6 * time.Hour,
+	12 * time.Hour,
+	24 * time.Hour,
+}
+
+// feedMenu returns the actions of the subscription settings menu.
+func (s *Bot) feedMenu() menu {
+	return menu{
+		feedActionList:           s.feedListView,
+		feedActionOpen:           s.withSubscription(s.feedView),
+		feedActionPause:          s.withSubscription(s.togglePause),
+		feedActionPreview:        s.withSubscription(s.togglePreview),
+		feedActionIntervals:      s.withSubscription(s.intervalsView),
+		feedActionSetInterval:    s.withSubscription(s.setInterval),
+		feedActionFilter:         s.withSubscription(s.filterView),
+		feedActionClearFilter:    s.withSubscription(s.clearFilter),
+		feedActionConfirmRemoval: s.withSubscription(s.confirmRemovalView),
+		feedActionUnsubscribe:    s.withSubscription(s.unsubscribe),
+	}
+}
+
+// withSubscription adapts an action working on a subscription to a menu action taking the feed id
+// as its first argument. Subscriptions removed in the meantime bring the user back to the list.
+func (s *Bot) withSubscription(
+	action func(ctx context.Context, chatID int64, sub *core.Subscription, args []string) (*menuView, error),
+) menuAction {
+	return func(ctx context.Context, chatID int64, args []string) (*menuView, error) {
+		if len(args) == 0 || args[0] == "" {
+			return nil, errUnknownAction
+		}
+
+		sub, err := s.svc.GetSubscription(ctx, chatID, args[0])
+
+		var view *menuView
+		if err == nil {
+			view, err = action(ctx, chatID, sub, args[1:])
+		}
+
+		if errors.Is(err, core.ErrNotSubscribed) || errors.Is(err, core.ErrInvalidURL) {
+			return s.goneSubscriptionView(ctx, chatID)
+		}
+
+		return view, err
+	}
+}
+
+// goneSubscriptionView shows the list of subscriptions to a user who pressed a button of a removed subscription.
+func (s *Bot) goneSubscriptionView(ctx context.Context, chatID int64) (*menuView, error) {
+	view, err := s.feedListView(ctx, chatID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	view.notice = i18n.FromContext(ctx).Text(goneSubscriptionNotice)
+
+	return view, nil
+}
+
+// feedListView shows the subscriptions of the chat with a button opening the settings of each of them.
+func (s *Bot) feedListView(ctx context.Context, chatID int64, _ []string) (*menuView, error) {
+	p := i18n.FromContext(ctx)
+
+	feeds, err := s.svc.ListSubscriptions(ctx, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	if len(feeds) == 0 {
+		return &menuView{text: p.Text(noSubscriptionsMessage)}, nil
+	}
+
+	rows := make([][]menuButton, 0, len(feeds))
+
+	for i := range feeds {
+		text := fmt.Sprintf("⚙️ %d. %s", i+1, feeds[i].Title)
+		rows = append(rows, []menuButton{newButton(text, feedMenu, feedActionOpen, feeds[i].ID)})
+	}
+
+	return &menuView{text: formatSubscriptions(p, feeds), rows: rows}, nil
+}
+
+// feedView shows the settings of the subscription with buttons changing them.
+func (s *Bot) feedView(ctx context.Context, _ int64, sub *core.Subscription, _ []string) (*menuView, error) {
+	p := i18n.FromContext(ctx)
+	id := sub.Feed.ID
+
+	pause := "⏸ Pause"
+	if sub.Settings.Paused {
+		pause = "▶️ Resume"
+	}
+
+	preview := "🖼 Previews: on"
+	if !sub.Settings.Preview {
+		preview = "🖼 Previews: off"
+	}
+
+	return &menuView{
+		text: formatSubscription(p, sub),
+		rows: [][]menuButton{
+			{newButton(p.Text(pause), feedMenu, feedActionPause, id), newButton(p.Text(preview), feedMenu, feedActionPreview, id)},
+			{
+				newButton(p.Text("⏱ Interval"), feedMenu, feedActionIntervals, id),
+				newButton(p.Text("🔎 Filter"), feedMenu, feedActionFilter, id),
+			},
+			{newButton(p.Text("🗑 Unsubscribe"), feedMenu, feedActionConfirmRemoval, id)},
+			{newButton(p.Text(backButton), feedMenu, feedActionList)},
+		},
+	}, nil
+}
+
+// togglePause pauses or resumes the delivery of the feed items to the chat.
+func (s *Bot) togglePause(ctx context.Context, chatID int64, sub *core.Subscription, _ []string) (*menuView, error) {
+	settings := sub.Settings
+	settings.Paused = !settings.Paused
+
+	notice := "Resumed"
+	if settings.Paused {
+		notice = "Paused"
+	}
+
+	return s.updateSettings(ctx, chatID, sub, &settings, i18n.FromContext(ctx).Text(notice))
+}
+
+// togglePreview enables or disables link previews of the feed items delivered to the chat.
+func (s *Bot) togglePreview(ctx context.Context, chatID int64, sub *core.Subscription, _ []string) (*menuView, error) {
+	settings := sub.Settings
+	settings.Preview = !settings.Preview
+
+	notice := "Link previews disabled"
+	if settings.Preview {
+		notice = "Link previews enabled"
+	}
+
+	return s.updateSettings(ctx, chatID, sub, &settings, i18n.FromContext(ctx).Text(notice))
+}
+
+// intervalsView offers the polling interval presets for the feed.
+func (s *Bot) intervalsView(ctx context.Context, _ int64, sub *core.Subscription, _ []string) (*menuView, error) {
+	p := i18n.FromContext(ctx)
+	id := sub.Feed.ID
+
+	var (
+		rows [][]menuButton
+		row  []menuButton
+	)
+
+	for _, interval := range intervalPresets {
+		text := formatInterval(p, interval)
+		if interval == sub.Feed.Interval {
+			text = "✓ " + text
+		}
+
+		minutes := strconv.Itoa(int(interval / time.Minute))
+		row = append(row, newButton(text, feedMenu, feedActionSetInterval, id, minutes))
+
+		if len(row) == intervalsPerRow {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	rows = append(rows, []menuButton{newButton(p.Text(backButton), feedMenu, feedActionOpen, id)})
+
+	return &menuView{
+		text: p.Sprintf(intervalsPrompt, sub.Feed.Title),
+		rows: rows,
+	}, nil
+}
+
+// setInterval sets the polling interval of the feed to the number of minutes passed as the argument.
+// Feeds other chats are subscribed to keep their interval and the settings of the subscription are shown again
+// with a notice explaining why.
+func (s *Bot) setInterval(ctx context.Context, chatID int64, sub *core.Subscription, args []string) (*menuView, error) {
+	if len(args) != 1 {
+		return nil, errUnknownAction
+	}
+
+	minutes, err := strconv.Atoi(args[0])
+	if err != nil || minutes < 0 {
+		return nil, errUnknownAction
+	}
+
+	interval := time.Duration(minutes) * time.Minute
+
+	p := i18n.FromContext(ctx)
+
+	feed, err := s.svc.SetInterval(ctx, chatID, sub.Feed.ID, interval)
+
+	notice := p.Sprintf("Interval: %s", formatInterval(p, interval))
+
+	switch {
+	case errors.Is(err, core.ErrSharedFeed):
+		notice = p.Text(sharedIntervalNotice)
+	case err != nil:
+		return nil, fmt.Errorf("failed to set interval: %w", err)
+	default:
+		sub.Feed = *feed
+	}
+
+	view, err := s.feedView(ctx, chatID, sub, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	view.notice = notice
+
+	return view, nil
+}
+
+// filterView shows the keyword filter of the subscription and explains how to change it.
+func (s *Bot) filterView(ctx context.Context, _ int64, sub *core.Subscription, _ []string) (*menuView, error) {
+	p := i18n.FromContext(ctx)
+	id := sub.Feed.ID
+
+	var rows [][]menuButton
+	if sub.Settings.Filter != "" {
+		rows = append(rows, []menuButton{newButton(p.Text("❌ Clear filter"), feedMenu, feedActionClearFilter, id)})
+	}
+
+	rows = append(rows, []menuButton{newButton(p.Text(backButton), feedMenu, feedActionOpen, id)})
+
+	return &menuView{
+		text: p.Sprintf(
+			"🔎 Filter for %s: %s\n\nOnly items matching the filter keywords are delivered. To change them, send:\n/filter %s <keywords>",
+			sub.Feed.Title, formatFilter(p, sub.Settings.Filter), id,
+		),
+		rows: rows,
+	}, nil
+}
+
+// clearFilter removes the keyword filter of the subscription.
+func (s *Bot) clearFilter(ctx context.Context, chatID int64, sub *core.Subscription, _ []string) (*menuView, error) {
+	settings := sub.Settings
+	settings.Filter = ""
+
+	return s.updateSettings(ctx, chatID, sub, &settings, i18n.FromContext(ctx).Text("Filter cleared"))
+}
+
+// confirmRemovalView asks the user to confirm the removal of the subscription.
+func (s *Bot) confirmRemovalView(ctx context.Context, _ int64, sub *core.Subscription, _ []string) (*menuView, error) {
+	p := i18n.FromContext(ctx)
+	id := sub.Feed.ID
+
+	return &menuView{
+		text: p.Sprintf("🗑 Unsubscribe from %s?", sub.Feed.Title),
+		rows: [][]menuButton{
+			{
+				newButton(p.Text("Yes, unsubscribe"), feedMenu, feedActionUnsubscribe, id),
+				newButton(p.Text("Cancel"), feedMenu, feedActionOpen, id),
+			},
+		},
+	}, nil
+}
+
+// unsubscribe removes the subscription and brings the user back to the list.
+func (s *Bot) unsubscribe(ctx context.Context, chatID int64, sub *core.Subscription, _ []string) (*menuView, error) {
+	feed, err := s.svc.Unsubscribe(ctx, chatID, sub.Feed.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unsubscribe: %w", err)
+	}
+
+	view, err := s.feedListView(ctx, chatID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	view.notice = i18n.FromContext(ctx).Sprintf("Unsubscribed from %s", feed.Title)
+
+	return view, nil
+}
+
+// updateSettings stores the settings of the subscription and shows them with the notice.
+func (s *Bot) updateSettings(
+	ctx context.Context,
+	chatID int64,
+	sub *core.Subscription,
+	settings *core.SubscriptionSettings,
+	notice string,
+) (*menuView, error) {
+	updated, err := s.svc.UpdateSubscriptionSettings(ctx, chatID, sub.Feed.ID, settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update subscription settings: %w", err)
+	}
+
+	view, err := s.feedView(ctx, chatID, updated, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	view.notice = notice
+
+	return view, nil
+}
+
+// formatSubscription renders the feed of the subscription together with the chat settings for it
+// in the language of the printer.
+func formatSubscription(p *i18n.Printer, sub *core.Subscription) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "⚙️ %s\n%s\nid: %s\n\n", sub.Feed.Title, sub.Feed.URL, sub.Feed.ID)
+
+	if sub.Settings.Paused {
+		sb.WriteString(p.Text("Delivery: ⏸ paused") + "\n")
+	} else {
+		sb.WriteString(p.Text("Delivery: ▶️ active") + "\n")
+	}
+
+	sb.WriteString(p.Sprintf("Interval: %s", formatInterval(p, sub.Feed.Interval)) + "\n")
+	sb.WriteString(p.Sprintf("Link previews: %s", formatSwitch(p, sub.Settings.Preview)) + "\n")
+	sb.WriteString(p.Sprintf("Full text: %s", formatSwitch(p, sub.Feed.FullText)) + "\n")
+	sb.WriteString(p.Sprintf("Filter: %s", formatFilter(p, sub.Settings.Filter)) + "\n")
+
+	return sb.String()
+}
+
+// formatInterval renders a polling interval, zero standing for the default one.
+func formatInterval(p *i18n.Printer, d time.Duration) string {
+	switch {
+	case d <= 0:
+		return p.Text("default")
+	case d%time.Hour == 0:
+		return p.Sprintf("%dh", d/time.Hour)
+	default:
+		return p.Sprintf("%dm", d/time.Minute)
+	}
+}
+
+// formatSwitch renders a boolean setting.
+func formatSwitch(p *i18n.Printer, on bool) string {
+	if on {
+		return p.Text("on")
+	}
+
+	return p.Text("off")
+}
+
+// formatFilter renders the keyword filter of a subscription.
+func formatFilter(p *i18n.Printer, filter string) string {
+	if filter == "" {
+		return p.Text("none")
+	}
+
+	return filter
+}
@@ -0,0 +1,231 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/tg-feeder/pkg/bot/callback"
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedMenu(t *testing.T) {
+	feed := core.FeedInfo{ID: "abc", Title: "Example", URL: "https://example.com/rss"}
+	settings := core.SubscriptionSettings{Preview: true}
+
+	subscription := func() *core.Subscription {
+		return &core.Subscription{Feed: feed, Settings: settings}
+	}
+
+	tests := []struct {
+		wantErr     error
+		setupMocks  func(svc *MockService)
+		name        string
+		action      string
+		wantText    string
+		wantNotice  string
+		args        []string
+		wantButtons []string
+	}{
+		{
+			name:   "open",
+			action: feedActionOpen,
+			args:   []string{"abc"},
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().GetSubscription(mock.Anything, int64(123), "abc").Return(subscription(), nil)
+			},
+			wantText: "⚙️ Example\nhttps://example.com/rss\nid: abc\n\n" +
+				"Delivery: ▶️ active\nInterval: default\nLink previews: on\nFull text: off\nFilter: none\n",
+			wantButtons: []string{"⏸ Pause", "🖼 Previews: on", "⏱ Interval", "🔎 Filter", "🗑 Unsubscribe", "« Back"},
+		},
+		{
+			name:   "pause",
+			action: feedActionPause,
+			args:   []string{"abc"},
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().GetSubscription(mock.Anything, int64(123), "abc").Return(subscription(), nil)
+				svc.EXPECT().UpdateSubscriptionSettings(mock.Anything, int64(123), "abc", &core.SubscriptionSettings{Preview: true, Paused: true}).
+					Return(&core.Subscription{Feed: feed, Settings: core.SubscriptionSettings{Preview: true, Paused: true}}, nil)
+			},
+			wantNotice:  "Paused",
+			wantButtons: []string{"▶️ Resume", "🖼 Previews: on", "⏱ Interval", "🔎 Filter", "🗑 Unsubscribe", "« Back"},
+		},
+		{
+			name:   "toggle preview",
+			action: feedActionPreview,
+			args:   []string{"abc"},
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().GetSubscription(mock.Anything, int64(123), "abc").Return(subscription(), nil)
+				svc.EXPECT().UpdateSubscriptionSettings(mock.Anything, int64(123), "abc", &core.SubscriptionSettings{}).
+					Return(&core.Subscription{Feed: feed}, nil)
+			},
+			wantNotice:  "Link previews disabled",
+			wantButtons: []string{"⏸ Pause", "🖼 Previews: off", "⏱ Interval", "🔎 Filter", "🗑 Unsubscribe", "« Back"},
+		},
+		{
+			name:   "interval presets",
+			action: feedActionIntervals,
+			args:   []string{"abc"},
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().GetSubscription(mock.Anything, int64(123), "abc").Return(subscription(), nil)
+			},
+			wantButtons: []string{"✓ default", "15m", "30m", "1h", "3h", "6h", "12h", "24h", "« Back"},
+		},
+		{
+			name:   "set interval",
+			action: feedActionSetInterval,
+			args:   []string{"abc", "180"},
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().GetSubscription(mock.Anything, int64(123), "abc").Return(subscription(), nil)
+				svc.EXPECT().SetInterval(mock.Anything, int64(123), "abc", 3*time.Hour).
+					Return(&core.FeedInfo{ID: "abc", Title: "Example", Interval: 3 * time.Hour}, nil)
+			},
+			wantNotice:  "Interval: 3h",
+			wantButtons: []string{"⏸ Pause", "🖼 Previews: on", "⏱ Interval", "🔎 Filter", "🗑 Unsubscribe", "« Back"},
+		},
+		{
+			name:   "set interval of a shared feed",
+			action: feedActionSetInterval,
+			args:   []string{"abc", "180"},
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().GetSubscription(mock.Anything, int64(123), "abc").Return(subscription(), nil)
+				svc.EXPECT().SetInterval(mock.Anything, int64(123), "abc", 3*time.Hour).Return(nil, core.ErrSharedFeed)
+			},
+			wantNotice:  sharedIntervalNotice,
+			wantButtons: []string{"⏸ Pause", "🖼 Previews: on", "⏱ Interval", "🔎 Filter", "🗑 Unsubscribe", "« Back"},
+		},
+		{
+			name:   "invalid interval",
+			action: feedActionSetInterval,
+			args:   []string{"abc", "-5"},
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().GetSubscription(mock.Anything, int64(123), "abc").Return(subscription(), nil)
+			},
+			wantErr: errUnknownAction,
+		},
+		{
+			name:   "filter without keywords",
+			action: feedActionFilter,
+			args:   []string{"abc"},
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().GetSubscription(mock.Anything, int64(123), "abc").Return(subscription(), nil)
+			},
+			wantText: "🔎 Filter for Example: none\n\nOnly items matching the filter keywords are delivered. " +
+				"To change them, send:\n/filter abc <keywords>",
+			wantButtons: []string{"« Back"},
+		},
+		{
+			name:   "clear filter",
+			action: feedActionClearFilter,
+			args:   []string{"abc"},
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().GetSubscription(mock.Anything, int64(123), "abc").
+					Return(&core.Subscription{Feed: feed, Settings: core.SubscriptionSettings{Filter: "go"}}, nil)
+				svc.EXPECT().UpdateSubscriptionSettings(mock.Anything, int64(123), "abc", &core.SubscriptionSettings{}).
+					Return(&core.Subscription{Feed: feed}, nil)
+			},
+			wantNotice:  "Filter cleared",
+			wantButtons: []string{"⏸ Pause", "🖼 Previews: off", "⏱ Interval", "🔎 Filter", "🗑 Unsubscribe", "« Back"},
+		},
+		{
+			name:   "confirm removal",
+			action: feedActionConfirmRemoval,
+			args:   []string{"abc"},
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().GetSubscription(mock.Anything, int64(123), "abc").Return(subscription(), nil)
+			},
+			wantText:    "🗑 Unsubscribe from Example?",
+			wantButtons: []string{"Yes, unsubscribe", "Cancel"},
+		},
+		{
+			name:   "unsubscribe",
+			action: feedActionUnsubscribe,
+			args:   []string{"abc"},
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().GetSubscription(mock.Anything, int64(123), "abc").Return(subscription(), nil)
+				svc.EXPECT().Unsubscribe(mock.Anything, int64(123), "abc").Return(&feed, nil)
+				svc.EXPECT().ListSubscriptions(mock.Anything, int64(123)).Return(nil, nil)
+			},
+			wantText:   noSubscriptionsMessage,
+			wantNotice: "Unsubscribed from Example",
+		},
+		{
+			name:   "removed subscription",
+			action: feedActionPause,
+			args:   []string{"abc"},
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().GetSubscription(mock.Anything, int64(123), "abc").Return(nil, core.ErrNotSubscribed)
+				svc.EXPECT().ListSubscriptions(mock.Anything, int64(123)).Return([]core.FeedInfo{{ID: "b", Title: "Beta"}}, nil)
+			},
+			wantNotice:  goneSubscriptionNotice,
+			wantButtons: []string{"⚙️ 1. Beta"},
+		},
+		{
+			name:   "subscription removed while updating",
+			action: feedActionPreview,
+			args:   []string{"abc"},
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().GetSubscription(mock.Anything, int64(123), "abc").Return(subscription(), nil)
+				svc.EXPECT().UpdateSubscriptionSettings(mock.Anything, int64(123), "abc", mock.Anything).Return(nil, core.ErrNotSubscribed)
+				svc.EXPECT().ListSubscriptions(mock.Anything, int64(123)).Return(nil, nil)
+			},
+			wantText:   noSubscriptionsMessage,
+			wantNotice: goneSubscriptionNotice,
+		},
+		{
+			name:   "service error",
+			action: feedActionOpen,
+			args:   []string{"abc"},
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().GetSubscription(mock.Anything, int64(123), "abc").Return(nil, assert.AnError)
+			},
+			wantErr: assert.AnError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMockService(t)
+			b := &Bot{svc: svc, tg: NewMocktgClient(t), callbacks: callback.New("secret")}
+
+			tt.setupMocks(svc)
+
+			view, err := b.feedMenu()[tt.action](context.Background(), 123, tt.args)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			if tt.wantText != "" {
+				assert.Equal(t, tt.wantText, view.text)
+			}
+
+			assert.Equal(t, tt.wantNotice, view.notice)
+
+			var buttons []string
+
+			for _, row := range view.rows {
+				for _, button := range row {
+					buttons = append(buttons, button.text)
+				}
+			}
+
+			assert.Equal(t, tt.wantButtons, buttons)
+
+			_, err = b.keyboard(123, 0, view.rows)
+			assert.NoError(t, err, "buttons should fit into callback data")
+		})
+	}
+}
+
+func TestFormatInterval(t *testing.T) {
+	assert.Equal(t, "default", formatInterval(nil, 0))
+	assert.Equal(t, "45m", formatInterval(nil, 45*time.Minute))
+	assert.Equal(t, "90m", formatInterval(nil, 90*time.Minute))
+	assert.Equal(t, "6h", formatInterval(nil, 6*time.Hour))
+}
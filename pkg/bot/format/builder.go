@@ -0,0 +1,496 @@
+package format
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// style is a set of inline formatting flags applied to a span of text.
+type style uint8
+
+const (
+	styleBold style = 1 << iota
+	styleItalic
+	styleUnderline
+	styleStrike
+	styleSpoiler
+	styleCode
+)
+
+// blockKind identifies how a block is rendered.
+type blockKind uint8
+
+const (
+	blockText blockKind = iota
+	blockPre
+	blockQuote
+)
+
+const (
+	listIndent    = "  "
+	bulletPrefix  = "• "
+	cellSeparator = " | "
+	ruleText      = "———"
+)
+
+// skippedElements lists the elements that are dropped together with their content.
+var skippedElements = map[string]bool{
+	"script": true, "style": true, "head": true, "noscript": true, "template": true, "iframe": true,
+	"object": true, "embed": true, "svg": true, "math": true, "canvas": true, "button": true,
+	"select": true, "textarea": true, "input": true, "audio": true, "video": true, "picture": true,
+}
+
+// blockElements lists the elements that start a new paragraph.
+var blockElements = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "header": true, "footer": true, "main": true,
+	"aside": true, "nav": true, "figure": true, "figcaption": true, "address": true, "details": true,
+	"summary": true, "dl": true, "dt": true, "dd": true, "center": true, "table": true, "caption": true,
+}
+
+// inlineStyles maps the elements that are rendered as Telegram entities to their style.
+var inlineStyles = map[string]style{
+	"b": styleBold, "strong": styleBold,
+	"i": styleItalic, "em": styleItalic, "cite": styleItalic, "var": styleItalic, "dfn": styleItalic,
+	"u": styleUnderline, "ins": styleUnderline,
+	"s": styleStrike, "strike": styleStrike, "del": styleStrike,
+	"tg-spoiler": styleSpoiler,
+	"code":       styleCode, "kbd": styleCode, "samp": styleCode, "tt": styleCode,
+}
+
+// linkSchemes lists the URL schemes Telegram accepts in links.
+var linkSchemes = map[string]bool{"http": true, "https": true, "tg": true, "mailto": true}
+
+// span is a run of text sharing the same style and link target.
+type span struct {
+	text  string
+	href  string
+	style style
+}
+
+// block is a paragraph, a list item, a table row, a preformatted block or a block quote.
+// Tight blocks, list items and table rows, are separated from each other by a single line break.
+type block struct {
+	lang  string
+	text  string
+	spans []span
+	kind  blockKind
+	tight bool
+}
+
+// list holds the state of a list being converted.
+type list struct {
+	next    int
+	ordered bool
+}
+
+// builder walks an HTML tree and collects its content as blocks of styled text.
+type builder struct {
+	href   string
+	blocks []block
+	cur    []span
+	quoted []span
+	lists  []list
+	rows   int
+	quote  int
+	style  style
+}
+
+// walk converts the node and its descendants.
+func (b *builder) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		b.text(collapseSpaces(n.Data))
+		return
+	case html.ElementNode:
+	case html.DocumentNode:
+		b.walkChildren(n)
+		return
+	default:
+		return
+	}
+
+	if skippedElements[n.Data] {
+		return
+	}
+
+	if st, ok := inlineStyles[n.Data]; ok {
+		b.withStyle(st, n)
+		return
+	}
+
+	switch n.Data {
+	case "span":
+		if strings.Contains(" "+attr(n, "class")+" ", " tg-spoiler ") {
+			b.withStyle(styleSpoiler, n)
+		} else {
+			b.walkChildren(n)
+		}
+	case "a":
+		b.link(n)
+	case "br":
+		b.newline()
+	case "hr":
+		b.flush()
+		b.text(ruleText)
+		b.flush()
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		b.flush()
+		b.withStyle(styleBold, n)
+		b.flush()
+	case "ul", "ol":
+		b.list(n)
+	case "li":
+		b.listItem(n)
+	case "tr":
+		b.flush()
+		b.rows++
+		b.walkChildren(n)
+		b.flush()
+		b.rows--
+	case "td", "th":
+		if b.trimTrailing(" "); len(b.cur) > 0 {
+			b.appendRaw(span{text: cellSeparator})
+		}
+
+		b.walkChildren(n)
+	case "blockquote":
+		b.blockquote(n)
+	case "pre":
+		b.pre(n)
+	case "q":
+		b.text("“")
+		b.walkChildren(n)
+		b.text("”")
+	default:
+		if blockElements[n.Data] {
+			b.flush()
+			b.walkChildren(n)
+			b.flush()
+
+			return
+		}
+
+		b.walkChildren(n)
+	}
+}
+
+// walkChildren converts the children of the node.
+func (b *builder) walkChildren(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.walk(c)
+	}
+}
+
+// withStyle converts the children of the node with the style added to the current one.
+func (b *builder) withStyle(st style, n *html.Node) {
+	prev := b.style
+	b.style |= st
+
+	b.walkChildren(n)
+
+	b.style = prev
+}
+
+// link converts an anchor. Links nested in links and links with unsupported targets keep only their text.
+func (b *builder) link(n *html.Node) {
+	href := linkTarget(attr(n, "href"))
+	if b.href != "" || href == "" {
+		b.walkChildren(n)
+		return
+	}
+
+	b.href = href
+	b.walkChildren(n)
+	b.href = ""
+}
+
+// list converts an ordered or unordered list, numbering ordered lists from their start attribute.
+func (b *builder) list(n *html.Node) {
+	l := list{ordered: n.Data == "ol", next: 1}
+
+	if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+		l.next = start
+	}
+
+	b.flush()
+	b.lists = append(b.lists, l)
+	b.walkChildren(n)
+	b.flush()
+	b.lists = b.lists[:len(b.lists)-1]
+}
+
+// listItem converts a list item into a line prefixed with a bullet or its number, indented by the list depth.
+func (b *builder) listItem(n *html.Node) {
+	b.flush()
+
+	prefix := bulletPrefix
+
+	if depth := len(b.lists); depth > 0 {
+		l := &b.lists[depth-1]
+		if l.ordered {
+			prefix = strconv.Itoa(l.next) + ". "
+			l.next++
+		}
+
+		prefix = strings.Repeat(listIndent, depth-1) + prefix
+	}
+
+	b.appendRaw(span{text: prefix})
+	b.walkChildren(n)
+	b.flush()
+}
+
+// blockquote converts a block quote. Nested quotes are merged into the outermost one,
+// since Telegram does not support nested block quotes.
+func (b *builder) blockquote(n *html.Node) {
+	b.flush()
+	b.quote++
+	b.walkChildren(n)
+	b.flush()
+	b.quote--
+
+	if b.quote > 0 || len(b.quoted) == 0 {
+		return
+	}
+
+	b.blocks = append(b.blocks, block{kind: blockQuote, spans: b.quoted})
+	b.quoted = nil
+}
+
+// pre converts a preformatted block, taking its language from the class of the pre or the nested code element.
+// Inside block quotes, where Telegram does not allow preformatted blocks, every line becomes inline code.
+func (b *builder) pre(n *html.Node) {
+	text := strings.Trim(preText(n), "\n")
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+
+	b.flush()
+
+	if b.quote > 0 {
+		for i, line := range strings.Split(text, "\n") {
+			if i > 0 {
+				b.newline()
+			}
+
+			b.appendRaw(span{text: line, style: styleCode})
+		}
+
+		b.flush()
+
+		return
+	}
+
+	lang := language(attr(n, "class"))
+	if code := firstChild(n, "code"); lang == "" && code != nil {
+		lang = language(attr(code, "class"))
+	}
+
+	b.blocks = append(b.blocks, block{kind: blockPre, text: text, lang: lang})
+}
+
+// text appends text with the current style, dropping the spaces that would be invisible in a browser.
+// Code is appended without other styles and outside of links, since Telegram does not allow code entities
+// to be combined with other entities.
+func (b *builder) text(s string) {
+	if s == "" {
+		return
+	}
+
+	if b.atLineStart() || b.endsWithSpace() {
+		s = strings.TrimLeft(s, " ")
+	}
+
+	if s == "" {
+		return
+	}
+
+	if b.style&styleCode != 0 {
+		b.appendRaw(span{text: s, style: styleCode})
+		return
+	}
+
+	b.appendRaw(span{text: s, href: b.href, style: b.style})
+}
+
+// newline appends a line break to the current block.
+func (b *builder) newline() {
+	b.trimTrailing(" ")
+	b.appendRaw(span{text: "\n"})
+}
+
+// appendRaw appends the span to the current block, merging it with the previous span if they share the same style.
+func (b *builder) appendRaw(s span) {
+	if n := len(b.cur); n > 0 && b.cur[n-1].style == s.style && b.cur[n-1].href == s.href {
+		b.cur[n-1].text += s.text
+		return
+	}
+
+	b.cur = append(b.cur, s)
+}
+
+// flush ends the current block. Blocks inside quotes are collected as lines of the quote.
+func (b *builder) flush() {
+	b.trimTrailing(" \n")
+
+	if len(b.cur) == 0 {
+		return
+	}
+
+	spans := b.cur
+	b.cur = nil
+
+	if b.quote > 0 {
+		if len(b.quoted) > 0 {
+			b.quoted = append(b.quoted, span{text: "\n"})
+		}
+
+		b.quoted = append(b.quoted, spans...)
+
+		return
+	}
+
+	b.blocks = append(b.blocks, block{kind: blockText, spans: spans, tight: len(b.lists) > 0 || b.rows > 0})
+}
+
+// trimTrailing removes the characters in cutset from the end of the current block.
+func (b *builder) trimTrailing(cutset string) {
+	for n := len(b.cur); n > 0; n = len(b.cur) {
+		b.cur[n-1].text = strings.TrimRight(b.cur[n-1].text, cutset)
+		if b.cur[n-1].text != "" {
+			return
+		}
+
+		b.cur = b.cur[:n-1]
+	}
+}
+
+// atLineStart reports whether the current block is empty or ends with a line break.
+func (b *builder) atLineStart() bool {
+	n := len(b.cur)
+
+	return n == 0 || strings.HasSuffix(b.cur[n-1].text, "\n")
+}
+
+// endsWithSpace reports whether the current block ends with a space.
+func (b *builder) endsWithSpace() bool {
+	n := len(b.cur)
+
+	return n > 0 && strings.HasSuffix(b.cur[n-1].text, " ")
+}
+
+// linkTarget returns the link target if it is an absolute URL with a scheme supported by Telegram.
+func linkTarget(href string) string {
+	u, err := url.Parse(strings.TrimSpace(href))
+	if err != nil || !linkSchemes[strings.ToLower(u.Scheme)] {
+		return ""
+	}
+
+	if (u.Scheme == "http" || u.Scheme == "https") && u.Host == "" {
+		return ""
+	}
+
+	return u.String()
+}
+
+// language returns the programming language found in a "language-*" or "lang-*" class.
+func language(class string) string {
+	for _, c := range strings.Fields(class) {
+		for _, prefix := range []string{"language-", "lang-"} {
+			if lang, ok := strings.CutPrefix(c, prefix); ok && isLanguage(lang) {
+				return lang
+			}
+		}
+	}
+
+	return ""
+}
+
+// isLanguage reports whether the value is a plausible language name that is safe to use in the output.
+func isLanguage(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("+-#_.", r)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// preText returns the text of a preformatted element, converting line break elements into new lines.
+func preText(n *html.Node) string {
+	var sb strings.Builder
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			sb.WriteString(n.Data)
+		case n.Type == html.ElementNode && n.Data == "br":
+			sb.WriteByte('\n')
+		case n.Type == html.ElementNode && skippedElements[n.Data]:
+		default:
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				walk(c)
+			}
+		}
+	}
+
+	walk(n)
+
+	return sb.String()
+}
+
+// firstChild returns the first element child of the node with the given tag.
+func firstChild(n *html.Node, tag string) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == tag {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// attr returns the value of the attribute of the node or an empty string.
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+
+	return ""
+}
+
+// collapseSpaces replaces every run of whitespace with a single space.
+func collapseSpaces(s string) string {
+	var sb strings.Builder
+
+	space := false
+
+	for _, r := range s {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f' {
+			if !space {
+				sb.WriteByte(' ')
+			}
+
+			space = true
+
+			continue
+		}
+
+		space = false
+
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
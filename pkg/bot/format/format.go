@@ -0,0 +1,87 @@
+// Package format converts arbitrary HTML, such as the content of feed items, into the subset of HTML
+// and MarkdownV2 accepted by the Telegram Bot API.
+//
+// Links, bold, italic, underline, strikethrough, spoiler, inline code, preformatted blocks and block quotes
+// are kept, headings and lists are turned into readable text and every other element is dropped,
+// keeping only its text. The output is always well formed: entities never overlap and are nested
+// the way Telegram expects.
+package format
+
+import (
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	mdEscaper   = strings.NewReplacer(
+		`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, "~", `\~`, "`", "\\`",
+		">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`, "|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+	)
+	mdCodeEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`")
+	mdLinkEscaper = strings.NewReplacer(`\`, `\\`, ")", `\)`)
+)
+
+// HTML converts the HTML document fragment into Telegram HTML.
+func HTML(src string) string {
+	return render(parse(src), htmlRenderer{})
+}
+
+// MarkdownV2 converts the HTML document fragment into Telegram MarkdownV2.
+func MarkdownV2(src string) string {
+	return render(parse(src), markdownRenderer{})
+}
+
+// Text converts the HTML document fragment into plain text, appending the target of links to their text.
+func Text(src string) string {
+	return render(parse(src), textRenderer{})
+}
+
+// Format converts the HTML document fragment into the given Telegram parse mode.
+// Modes other than HTML and MarkdownV2, including the empty one, produce plain text.
+func Format(src, parseMode string) string {
+	switch parseMode {
+	case tgbotapi.ModeHTML:
+		return HTML(src)
+	case tgbotapi.ModeMarkdownV2:
+		return MarkdownV2(src)
+	default:
+		return Text(src)
+	}
+}
+
+// EscapeHTML escapes the characters that have a special meaning in Telegram HTML.
+func EscapeHTML(s string) string {
+	return htmlEscaper.Replace(s)
+}
+
+// EscapeMarkdownV2 escapes the characters that have a special meaning in Telegram MarkdownV2.
+func EscapeMarkdownV2(s string) string {
+	return mdEscaper.Replace(s)
+}
+
+// parse parses the HTML fragment and converts it into blocks of styled text.
+// Input that cannot be parsed is treated as plain text.
+func parse(src string) []block {
+	nodes, err := html.ParseFragment(strings.NewReader(src), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		nodes = []*html.Node{{Type: html.TextNode, Data: src}}
+	}
+
+	b := &builder{}
+
+	for _, n := range nodes {
+		b.walk(n)
+	}
+
+	b.flush()
+
+	return b.blocks
+}
@@ -0,0 +1,150 @@
+package format
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "plain text is escaped",
+			src:  "Tom &amp; Jerry &lt;3 <unknown>x</unknown>",
+			want: "Tom &amp; Jerry &lt;3 x",
+		},
+		{
+			name: "inline styles",
+			src:  "<b>bold</b> <strong>strong</strong> <em>em</em> <u>u</u> <del>del</del> <span class=\"tg-spoiler\">spoiler</span>",
+			want: "<b>bold</b> <b>strong</b> <i>em</i> <u>u</u> <s>del</s> <tg-spoiler>spoiler</tg-spoiler>",
+		},
+		{
+			name: "overlapping styles are nested",
+			src:  "<b>bold <i>both</b> italic</i>",
+			want: "<b>bold </b><b><i>both</i></b><i> italic</i>",
+		},
+		{
+			name: "code cannot contain other entities",
+			src:  "<b>run <code>go <i>test</i></code></b>",
+			want: "<b>run </b><code>go test</code>",
+		},
+		{
+			name: "links",
+			src: `<a href="https://go.dev/?a=1&amp;b=&quot;2&quot;">Go <b>site</b></a> ` +
+				`<a href="/relative">relative</a> <a href="javascript:alert(1)">script</a> <a href="https://a.example"><a href="https://b.example">nested</a></a>`,
+			want: `<a href="https://go.dev/?a=1&amp;b=&#34;2&#34;">Go </a><a href="https://go.dev/?a=1&amp;b=&#34;2&#34;"><b>site</b></a> ` +
+				`relative script <a href="https://b.example">nested</a>`,
+		},
+		{
+			name: "paragraphs and whitespace",
+			src:  "<p>First\n   paragraph </p>\n\n<div>Second<br>line</div><p>  </p><hr><p>Third</p>",
+			want: "First paragraph\n\nSecond\nline\n\n———\n\nThird",
+		},
+		{
+			name: "headings",
+			src:  "<h1>Title</h1><p>Text</p>",
+			want: "<b>Title</b>\n\nText",
+		},
+		{
+			name: "lists",
+			src:  "<p>Intro</p><ul><li>one</li><li>two<ol start=\"3\"><li>three</li><li>four</li></ol></li></ul><p>Outro</p>",
+			want: "Intro\n\n• one\n• two\n  3. three\n  4. four\n\nOutro",
+		},
+		{
+			name: "tables",
+			src:  "<table><tr><th>Name</th> <th>Value</th></tr><tr><td>a</td><td>1</td></tr></table>",
+			want: "Name | Value\na | 1",
+		},
+		{
+			name: "preformatted code",
+			src:  "<pre><code class=\"language-go\">if a &lt; b {\n\treturn\n}\n</code></pre><pre class=\"bad class-x\">x</pre>",
+			want: "<pre><code class=\"language-go\">if a &lt; b {\n\treturn\n}</code></pre>\n\n<pre>x</pre>",
+		},
+		{
+			name: "block quotes are flattened",
+			src:  "<blockquote><p>Quote</p><blockquote>nested <b>bold</b></blockquote><pre>code</pre></blockquote>",
+			want: "<blockquote>Quote\nnested <b>bold</b>\n<code>code</code></blockquote>",
+		},
+		{
+			name: "dropped elements",
+			src:  "<script>alert(1)</script><style>p{}</style><img src=\"x.png\" alt=\"image\"><p>Text</p><iframe>frame</iframe>",
+			want: "Text",
+		},
+		{
+			name: "empty input",
+			src:  "",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, HTML(tt.src))
+		})
+	}
+}
+
+func TestMarkdownV2(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "special characters are escaped",
+			src:  "1 + 1 = 2. (Really!) [x] a_b*c~d`e>f#g-h|i{j}k\\l",
+			want: "1 \\+ 1 \\= 2\\. \\(Really\\!\\) \\[x\\] a\\_b\\*c\\~d\\`e\\>f\\#g\\-h\\|i\\{j\\}k\\\\l",
+		},
+		{
+			name: "inline styles",
+			src:  "<b>bold</b> <i>italic</i> <s>strike</s> <tg-spoiler>spoiler</tg-spoiler> <code>a`b</code>",
+			want: "*bold* _italic_ ~strike~ ||spoiler|| `a\\`b`",
+		},
+		{
+			name: "italic and underline markers are separated",
+			src:  "<u><i>both</i></u><i>italic</i><u>underline</u>",
+			want: "__\r_both_\r__\r_italic_\r__underline__",
+		},
+		{
+			name: "links",
+			src:  `<a href="https://example.com/a_(b)">see <b>this</b></a>`,
+			want: "[see ](https://example.com/a_(b\\))[*this*](https://example.com/a_(b\\))",
+		},
+		{
+			name: "preformatted code and quotes",
+			src:  "<pre><code class=\"lang-sh\">echo `x` \\</code></pre><blockquote>one<br>two.</blockquote>",
+			want: "```sh\necho \\`x\\` \\\\\n```\n\n>one\n>two\\.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, MarkdownV2(tt.src))
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	src := `<h2>Title</h2><p>Read <a href="https://example.com">the <b>post</b></a> or <a href="https://go.dev">https://go.dev</a>.</p>` +
+		`<blockquote>quoted</blockquote><pre>a < b</pre>`
+
+	assert.Equal(t, "Title\n\nRead the post (https://example.com) or https://go.dev.\n\n> quoted\n\na < b", Text(src))
+}
+
+func TestFormat(t *testing.T) {
+	src := "<b>a.b</b>"
+
+	assert.Equal(t, "<b>a.b</b>", Format(src, tgbotapi.ModeHTML))
+	assert.Equal(t, "*a\\.b*", Format(src, tgbotapi.ModeMarkdownV2))
+	assert.Equal(t, "a.b", Format(src, ""))
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, "a &lt;b&gt; &amp; \"c\"", EscapeHTML(`a <b> & "c"`))
+	assert.Equal(t, "a\\.b\\!", EscapeMarkdownV2("a.b!"))
+}
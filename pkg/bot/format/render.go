@@ -0,0 +1,250 @@
+package format
+
+import (
+	"html"
+	"strings"
+)
+
+// renderer renders blocks of styled text in one of the output formats.
+type renderer interface {
+	line(spans []span) string
+	pre(lang, text string) string
+	quote(lines []string) string
+}
+
+// render renders the blocks, separating paragraphs by a blank line and list items or table rows by a line break.
+func render(blocks []block, r renderer) string {
+	var sb strings.Builder
+
+	for i := range blocks {
+		bl := &blocks[i]
+
+		if i > 0 {
+			if bl.tight && blocks[i-1].tight {
+				sb.WriteString("\n")
+			} else {
+				sb.WriteString("\n\n")
+			}
+		}
+
+		switch bl.kind {
+		case blockPre:
+			sb.WriteString(r.pre(bl.lang, bl.text))
+		case blockQuote:
+			sb.WriteString(r.quote(renderLines(bl.spans, r)))
+		default:
+			sb.WriteString(strings.Join(renderLines(bl.spans, r), "\n"))
+		}
+	}
+
+	return sb.String()
+}
+
+// renderLines splits the spans at line breaks and renders every line on its own,
+// so that no entity spans several lines.
+func renderLines(spans []span, r renderer) []string {
+	var (
+		lines []string
+		line  []span
+	)
+
+	for _, s := range spans {
+		parts := strings.Split(s.text, "\n")
+
+		for i, part := range parts {
+			if i > 0 {
+				lines = append(lines, r.line(line))
+				line = nil
+			}
+
+			if part != "" {
+				line = append(line, span{text: part, href: s.href, style: s.style})
+			}
+		}
+	}
+
+	return append(lines, r.line(line))
+}
+
+// htmlRenderer renders Telegram HTML.
+type htmlRenderer struct{}
+
+// htmlTags lists the tags of the styles in the order they are opened.
+var htmlTags = []struct {
+	open  string
+	close string
+	style style
+}{
+	{style: styleBold, open: "<b>", close: "</b>"},
+	{style: styleItalic, open: "<i>", close: "</i>"},
+	{style: styleUnderline, open: "<u>", close: "</u>"},
+	{style: styleStrike, open: "<s>", close: "</s>"},
+	{style: styleSpoiler, open: "<tg-spoiler>", close: "</tg-spoiler>"},
+}
+
+func (htmlRenderer) line(spans []span) string {
+	var sb strings.Builder
+
+	for _, s := range spans {
+		text := EscapeHTML(s.text)
+
+		// Code entities cannot contain or be part of other entities.
+		if s.style&styleCode != 0 {
+			sb.WriteString("<code>" + text + "</code>")
+			continue
+		}
+
+		if s.href != "" {
+			sb.WriteString(`<a href="` + html.EscapeString(s.href) + `">`)
+		}
+
+		for _, t := range htmlTags {
+			if s.style&t.style != 0 {
+				sb.WriteString(t.open)
+			}
+		}
+
+		sb.WriteString(text)
+
+		for i := len(htmlTags) - 1; i >= 0; i-- {
+			if s.style&htmlTags[i].style != 0 {
+				sb.WriteString(htmlTags[i].close)
+			}
+		}
+
+		if s.href != "" {
+			sb.WriteString("</a>")
+		}
+	}
+
+	return sb.String()
+}
+
+func (htmlRenderer) pre(lang, text string) string {
+	if lang == "" {
+		return "<pre>" + EscapeHTML(text) + "</pre>"
+	}
+
+	return `<pre><code class="language-` + lang + `">` + EscapeHTML(text) + "</code></pre>"
+}
+
+func (htmlRenderer) quote(lines []string) string {
+	return "<blockquote>" + strings.Join(lines, "\n") + "</blockquote>"
+}
+
+// markdownRenderer renders Telegram MarkdownV2.
+type markdownRenderer struct{}
+
+// markdownMarkers lists the markers of the styles in the order they are opened.
+var markdownMarkers = []struct {
+	marker string
+	style  style
+}{
+	{style: styleBold, marker: "*"},
+	{style: styleUnderline, marker: "__"},
+	{style: styleItalic, marker: "_"},
+	{style: styleStrike, marker: "~"},
+	{style: styleSpoiler, marker: "||"},
+}
+
+func (markdownRenderer) line(spans []span) string {
+	var sb markdownBuilder
+
+	for _, s := range spans {
+		// Code entities cannot contain or be part of other entities.
+		if s.style&styleCode != 0 {
+			sb.marker("`")
+			sb.text(mdCodeEscaper.Replace(s.text))
+			sb.marker("`")
+
+			continue
+		}
+
+		if s.href != "" {
+			sb.marker("[")
+		}
+
+		for _, m := range markdownMarkers {
+			if s.style&m.style != 0 {
+				sb.marker(m.marker)
+			}
+		}
+
+		sb.text(EscapeMarkdownV2(s.text))
+
+		for i := len(markdownMarkers) - 1; i >= 0; i-- {
+			if s.style&markdownMarkers[i].style != 0 {
+				sb.marker(markdownMarkers[i].marker)
+			}
+		}
+
+		if s.href != "" {
+			sb.marker("](" + mdLinkEscaper.Replace(s.href) + ")")
+		}
+	}
+
+	return sb.String()
+}
+
+func (markdownRenderer) pre(lang, text string) string {
+	return "```" + lang + "\n" + mdCodeEscaper.Replace(text) + "\n```"
+}
+
+func (markdownRenderer) quote(lines []string) string {
+	for i, l := range lines {
+		lines[i] = ">" + l
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// markdownBuilder accumulates MarkdownV2 output, separating adjacent underscore markers
+// with a carriage return, which Telegram ignores, so that italic and underline markers are not merged.
+type markdownBuilder struct {
+	strings.Builder
+	lastMarker bool
+}
+
+func (b *markdownBuilder) marker(m string) {
+	if b.lastMarker && strings.HasSuffix(b.String(), "_") && strings.HasPrefix(m, "_") {
+		b.WriteString("\r")
+	}
+
+	b.WriteString(m)
+	b.lastMarker = true
+}
+
+func (b *markdownBuilder) text(s string) {
+	b.WriteString(s)
+	b.lastMarker = false
+}
+
+// textRenderer renders plain text.
+type textRenderer struct{}
+
+func (textRenderer) line(spans []span) string {
+	var sb strings.Builder
+
+	for i, s := range spans {
+		sb.WriteString(s.text)
+
+		// A link split into several spans by its styles gets its target after the last of them.
+		if s.href != "" && s.href != strings.TrimSpace(s.text) && (i == len(spans)-1 || spans[i+1].href != s.href) {
+			sb.WriteString(" (" + s.href + ")")
+		}
+	}
+
+	return sb.String()
+}
+
+func (textRenderer) pre(_, text string) string {
+	return text
+}
+
+func (textRenderer) quote(lines []string) string {
+	for i, l := range lines {
+		lines[i] = "> " + l
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,443 @@
+package format
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// MaxTextLength is the maximum length of the text of a Telegram message in UTF-16 code units.
+	MaxTextLength = 4096
+	// MaxCaptionLength is the maximum length of the caption of a Telegram media message in UTF-16 code units.
+	MaxCaptionLength = 1024
+)
+
+// Break priorities, from the least to the most preferred place to split a message.
+const (
+	breakWord = iota
+	breakSentence
+	breakLine
+	breakParagraph
+	breakPriorities
+)
+
+// tokenKind identifies the role of a token of formatted text.
+type tokenKind uint8
+
+const (
+	tokenText tokenKind = iota
+	tokenOpen
+	tokenClose
+	tokenToggle
+	tokenRaw
+)
+
+// token is a piece of formatted text: a visible character, an entity opening or closing tag or marker,
+// or markup that has no effect on the entities.
+type token struct {
+	raw   string
+	name  string
+	close string
+	units int
+	kind  tokenKind
+}
+
+// entity is an entity open at some point of the formatted text.
+type entity struct {
+	open  string
+	close string
+	name  string
+}
+
+// breakPoint is a position where the text can be split together with the entities open there.
+type breakPoint struct {
+	open  []entity
+	index int
+	units int
+}
+
+// Split splits the text formatted in the parse mode into parts of at most limit UTF-16 code units of visible text.
+// Parts are cut at paragraph boundaries when possible, then at line, sentence and word boundaries,
+// and never inside an HTML tag, an HTML entity or a MarkdownV2 escape sequence.
+// Entities open at a cut are closed at the end of the part and reopened at the beginning of the next one.
+func Split(text, parseMode string, limit int) []string {
+	return split(text, parseMode, limit, limit)
+}
+
+// SplitCaption splits the text formatted in the parse mode into a caption that fits into a media message
+// and the remaining parts that fit into text messages.
+func SplitCaption(text, parseMode string) (caption string, rest []string) {
+	parts := split(text, parseMode, MaxCaptionLength, MaxTextLength)
+
+	return parts[0], parts[1:]
+}
+
+// split splits the text so that the first part has at most first units and the other parts at most limit units.
+func split(text, parseMode string, first, limit int) []string {
+	if first <= 0 || limit <= 0 || utf16Len(text) <= first {
+		return []string{text}
+	}
+
+	tokens := tokenize(text, parseMode)
+
+	var (
+		parts []string
+		open  []entity
+	)
+
+	for start, budget := 0, first; start < len(tokens); budget = limit {
+		bp := cut(tokens, start, open, budget)
+
+		parts = append(parts, renderPart(open, trimTrailingSpace(tokens[start:bp.index]), bp.open, parseMode))
+
+		open = bp.open
+		start = skipLeadingSpace(tokens, bp.index)
+	}
+
+	if len(parts) == 0 {
+		return []string{""}
+	}
+
+	return parts
+}
+
+// cut finds where the part starting at start has to end to hold at most limit units of visible text.
+func cut(tokens []token, start int, open []entity, limit int) breakPoint {
+	var (
+		breaks [breakPriorities]*breakPoint
+		hard   *breakPoint
+		prev   rune
+		units  int
+	)
+
+	stack := append([]entity(nil), open...)
+
+	for i := start; i < len(tokens); i++ {
+		t := &tokens[i]
+
+		if t.kind == tokenText && units+t.units > limit {
+			if i == start {
+				// A single character that does not fit is sent on its own rather than looping forever.
+				return breakPoint{index: i + 1, open: stack}
+			}
+
+			return chooseBreak(&breaks, hard, limit, breakPoint{index: i, open: stack})
+		}
+
+		stack = applyToken(stack, t)
+		units += t.units
+
+		if t.kind != tokenText {
+			continue
+		}
+
+		r, _ := utf8.DecodeLastRuneInString(t.raw)
+		bp := &breakPoint{index: i + 1, open: append([]entity(nil), stack...), units: units}
+		hard = bp
+
+		switch {
+		case r == '\n' && prev == '\n':
+			breaks[breakParagraph] = bp
+		case r == '\n':
+			breaks[breakLine] = bp
+		case r == ' ' && strings.ContainsRune(".!?…", prev):
+			breaks[breakSentence] = bp
+		case r == ' ':
+			breaks[breakWord] = bp
+		}
+
+		prev = r
+	}
+
+	return breakPoint{index: len(tokens), open: stack}
+}
+
+// chooseBreak picks the most preferred break that keeps the part at least half full, falling back to
+// the most preferred break at all, then to a cut right before the first character that does not fit.
+func chooseBreak(breaks *[breakPriorities]*breakPoint, hard *breakPoint, limit int, fallback breakPoint) breakPoint {
+	for p := breakPriorities - 1; p >= 0; p-- {
+		if bp := breaks[p]; bp != nil && bp.units >= limit/2 {
+			return *bp
+		}
+	}
+
+	for p := breakPriorities - 1; p >= 0; p-- {
+		if bp := breaks[p]; bp != nil {
+			return *bp
+		}
+	}
+
+	if hard != nil {
+		return *hard
+	}
+
+	return fallback
+}
+
+// applyToken updates the stack of open entities with the token.
+func applyToken(stack []entity, t *token) []entity {
+	switch t.kind {
+	case tokenOpen:
+		return append(stack, openEntity(t))
+	case tokenClose:
+		return closeEntity(stack, t.name)
+	case tokenToggle:
+		for i := range stack {
+			if stack[i].name == t.name {
+				return closeEntity(stack, t.name)
+			}
+		}
+
+		return append(stack, openEntity(t))
+	default:
+		return stack
+	}
+}
+
+// openEntity returns the entity opened by the token.
+func openEntity(t *token) entity {
+	return entity{name: t.name, open: t.raw, close: t.close}
+}
+
+// closeEntity removes the innermost entity with the name and the entities nested in it from the stack.
+func closeEntity(stack []entity, name string) []entity {
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].name == name {
+			return stack[:i:i]
+		}
+	}
+
+	return stack
+}
+
+// renderPart renders the tokens of a part, reopening the entities open at its beginning
+// and closing the entities open at its end.
+func renderPart(open []entity, tokens []token, stack []entity, parseMode string) string {
+	var parts []string
+
+	for i := range open {
+		parts = append(parts, open[i].open)
+	}
+
+	var body strings.Builder
+
+	for i := range tokens {
+		body.WriteString(tokens[i].raw)
+	}
+
+	parts = append(parts, body.String())
+
+	for i := len(stack) - 1; i >= 0; i-- {
+		parts = append(parts, stack[i].close)
+	}
+
+	if parseMode != tgbotapi.ModeMarkdownV2 {
+		return strings.Join(parts, "")
+	}
+
+	var sb markdownBuilder
+
+	for _, p := range parts {
+		sb.marker(p)
+	}
+
+	return sb.String()
+}
+
+// trimTrailingSpace drops the whitespace characters at the end of the tokens.
+func trimTrailingSpace(tokens []token) []token {
+	for len(tokens) > 0 && isSpace(&tokens[len(tokens)-1]) {
+		tokens = tokens[:len(tokens)-1]
+	}
+
+	return tokens
+}
+
+// skipLeadingSpace returns the index of the first token after start that is not a whitespace character.
+func skipLeadingSpace(tokens []token, start int) int {
+	for start < len(tokens) && isSpace(&tokens[start]) {
+		start++
+	}
+
+	return start
+}
+
+// isSpace reports whether the token is a whitespace character.
+func isSpace(t *token) bool {
+	return t.kind == tokenText && strings.TrimSpace(t.raw) == ""
+}
+
+// tokenize splits the text formatted in the parse mode into tokens.
+func tokenize(text, parseMode string) []token {
+	switch parseMode {
+	case tgbotapi.ModeHTML:
+		return tokenizeHTML(text)
+	case tgbotapi.ModeMarkdownV2:
+		return tokenizeMarkdown(text)
+	default:
+		tokens := make([]token, 0, len(text))
+
+		for _, r := range text {
+			tokens = append(tokens, textToken(string(r)))
+		}
+
+		return tokens
+	}
+}
+
+// tokenizeHTML splits Telegram HTML into characters, entities and tags.
+func tokenizeHTML(text string) []token {
+	tokens := make([]token, 0, len(text))
+
+	for text != "" {
+		switch {
+		case text[0] == '<' && strings.IndexByte(text, '>') > 0:
+			end := strings.IndexByte(text, '>') + 1
+			raw := text[:end]
+			name := strings.ToLower(strings.TrimLeft(strings.Trim(raw, "<>/"), "/"))
+
+			if i := strings.IndexAny(name, " \t\n"); i >= 0 {
+				name = name[:i]
+			}
+
+			kind := tokenOpen
+			if strings.HasPrefix(raw, "</") {
+				kind = tokenClose
+			}
+
+			tokens = append(tokens, token{raw: raw, name: name, close: "</" + name + ">", kind: kind})
+			text = text[end:]
+		case text[0] == '&' && strings.IndexByte(text, ';') > 0 && strings.IndexByte(text, ';') <= 10:
+			end := strings.IndexByte(text, ';') + 1
+			tokens = append(tokens, token{raw: text[:end], units: utf16Len(html.UnescapeString(text[:end])), kind: tokenText})
+			text = text[end:]
+		default:
+			_, size := utf8.DecodeRuneInString(text)
+			tokens = append(tokens, textToken(text[:size]))
+			text = text[size:]
+		}
+	}
+
+	return tokens
+}
+
+// markdownMarkerTokens lists the MarkdownV2 markers that toggle entities, longest first.
+var markdownMarkerTokens = []string{"```", "||", "__", "*", "_", "~", "`"}
+
+// tokenizeMarkdown splits Telegram MarkdownV2 into characters, escape sequences, markers and link parts.
+func tokenizeMarkdown(text string) []token {
+	var (
+		tokens  = make([]token, 0, len(text))
+		code    string
+		newLine = true
+	)
+
+	for text != "" {
+		var t token
+
+		switch {
+		case text[0] == '\\' && len(text) > 1:
+			_, size := utf8.DecodeRuneInString(text[1:])
+			t = token{raw: text[:1+size], units: utf16Len(text[1 : 1+size]), kind: tokenText}
+		case code != "" && !strings.HasPrefix(text, code):
+			_, size := utf8.DecodeRuneInString(text)
+			t = textToken(text[:size])
+		case code != "":
+			t = token{raw: code, name: code, kind: tokenToggle}
+			code = ""
+		case strings.HasPrefix(text, "```"):
+			end := strings.IndexByte(text, '\n') + 1
+			if end == 0 {
+				end = len(text)
+			}
+
+			t = token{raw: text[:end], name: "```", close: "\n```", kind: tokenToggle}
+			code = "```"
+		case text[0] == '`':
+			t = token{raw: "`", name: "`", close: "`", kind: tokenToggle}
+			code = "`"
+		case text[0] == '\r' || (text[0] == '>' && newLine):
+			t = token{raw: text[:1], kind: tokenRaw}
+		case text[0] == '[':
+			t = token{raw: "[", name: "[", kind: tokenOpen}
+		case strings.HasPrefix(text, "]("):
+			t = token{raw: text[:linkEnd(text)], name: "[", kind: tokenClose}
+		default:
+			t = markerOrText(text)
+		}
+
+		tokens = append(tokens, t)
+		text = text[len(t.raw):]
+		newLine = t.raw == "\n" || (newLine && t.kind == tokenRaw && t.raw == "\r")
+	}
+
+	pairLinks(tokens)
+
+	return tokens
+}
+
+// pairLinks stores the target part of every MarkdownV2 link in the token that opens the link,
+// so that a link cut in two can be closed with its target at the end of the first part.
+func pairLinks(tokens []token) {
+	open := -1
+
+	for i := range tokens {
+		switch {
+		case tokens[i].kind == tokenOpen && tokens[i].name == "[":
+			open = i
+		case tokens[i].kind == tokenClose && tokens[i].name == "[" && open >= 0:
+			tokens[open].close = tokens[i].raw
+			open = -1
+		}
+	}
+}
+
+// markerOrText returns the MarkdownV2 marker at the beginning of the text or its first character.
+func markerOrText(text string) token {
+	for _, m := range markdownMarkerTokens {
+		if strings.HasPrefix(text, m) {
+			return token{raw: m, name: m, close: m, kind: tokenToggle}
+		}
+	}
+
+	_, size := utf8.DecodeRuneInString(text)
+
+	return textToken(text[:size])
+}
+
+// linkEnd returns the length of the target part of a MarkdownV2 link, including the closing parenthesis.
+func linkEnd(text string) int {
+	for i := 2; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case ')':
+			return i + 1
+		}
+	}
+
+	return len(text)
+}
+
+// textToken returns the token of a visible character.
+func textToken(s string) token {
+	return token{raw: s, units: utf16Len(s), kind: tokenText}
+}
+
+// utf16Len returns the length of the string in UTF-16 code units, the unit Telegram measures text in.
+func utf16Len(s string) int {
+	n := 0
+
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+
+	return n
+}
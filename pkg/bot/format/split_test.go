@@ -0,0 +1,154 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		parseMode string
+		want      []string
+		limit     int
+	}{
+		{
+			name:  "short text",
+			text:  "Hello, world",
+			limit: 20,
+			want:  []string{"Hello, world"},
+		},
+		{
+			name:  "paragraph boundary is preferred",
+			text:  "First paragraph here.\n\nSecond one. It goes on and on",
+			limit: 40,
+			want:  []string{"First paragraph here.", "Second one. It goes on and on"},
+		},
+		{
+			name:  "line boundary",
+			text:  "one two three four\nfive six seven",
+			limit: 25,
+			want:  []string{"one two three four", "five six seven"},
+		},
+		{
+			name:  "sentence boundary",
+			text:  "First sentence. Second sentence is longer",
+			limit: 30,
+			want:  []string{"First sentence.", "Second sentence is longer"},
+		},
+		{
+			name:  "word boundary",
+			text:  "alpha beta gamma delta",
+			limit: 12,
+			want:  []string{"alpha beta", "gamma delta"},
+		},
+		{
+			name:  "hard cut",
+			text:  "abcdefghij",
+			limit: 4,
+			want:  []string{"abcd", "efgh", "ij"},
+		},
+		{
+			name:  "utf-16 code units",
+			text:  "😀😀😀",
+			limit: 4,
+			want:  []string{"😀😀", "😀"},
+		},
+		{
+			name:      "html entities reopened",
+			text:      "<b>bold <i>text that is long</i></b> tail",
+			parseMode: tgbotapi.ModeHTML,
+			limit:     14,
+			want:      []string{"<b>bold <i>text</i></b>", "<b><i>that is long</i></b>", "tail"},
+		},
+		{
+			name:      "html character entities are not broken",
+			text:      "&lt;&lt;&lt;&lt;&amp;",
+			parseMode: tgbotapi.ModeHTML,
+			limit:     3,
+			want:      []string{"&lt;&lt;&lt;", "&lt;&amp;"},
+		},
+		{
+			name:      "html links and code blocks",
+			text:      `<a href="https://example.com">a long link</a>` + "\n\n" + `<pre><code class="language-go">x := 1` + "\n" + `y := 2</code></pre>`,
+			parseMode: tgbotapi.ModeHTML,
+			limit:     8,
+			want: []string{
+				`<a href="https://example.com">a long</a>`,
+				`<a href="https://example.com">link</a>`,
+				`<pre><code class="language-go">x := 1</code></pre>`,
+				`<pre><code class="language-go">y := 2</code></pre>`,
+			},
+		},
+		{
+			name:      "markdown escapes and markers",
+			text:      "*bold \\. text* and \\_more\\_",
+			parseMode: tgbotapi.ModeMarkdownV2,
+			limit:     11,
+			want:      []string{"*bold \\.*", "*text* and", "\\_more\\_"},
+		},
+		{
+			name:      "markdown links and code blocks",
+			text:      "[a long link](https://example.com/a\\))\n\n```go\nx := 1\ny := 2\n```",
+			parseMode: tgbotapi.ModeMarkdownV2,
+			limit:     8,
+			want: []string{
+				"[a long](https://example.com/a\\))",
+				"[link](https://example.com/a\\))",
+				"```go\nx := 1\n```",
+				"```go\ny := 2\n```",
+			},
+		},
+		{
+			name:      "markdown italic and underline",
+			text:      "__\r_one two_\r__",
+			parseMode: tgbotapi.ModeMarkdownV2,
+			limit:     4,
+			want:      []string{"__\r_one_\r__", "__\r_two_\r__"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Split(tt.text, tt.parseMode, tt.limit))
+		})
+	}
+}
+
+func TestSplit_FormattedText(t *testing.T) {
+	src := strings.Repeat("<p>Some <b>bold</b> text, a <a href=\"https://example.com\">link</a> and more words.</p>", 300)
+
+	for _, mode := range []string{tgbotapi.ModeHTML, tgbotapi.ModeMarkdownV2, ""} {
+		parts := Split(Format(src, mode), mode, MaxTextLength)
+
+		assert.Greater(t, len(parts), 1, mode)
+
+		for _, part := range parts {
+			units := 0
+			for _, tok := range tokenize(part, mode) {
+				units += tok.units
+			}
+
+			assert.LessOrEqual(t, units, MaxTextLength, mode)
+		}
+	}
+}
+
+func TestSplitCaption(t *testing.T) {
+	text := strings.Repeat("word ", 1000)
+
+	caption, rest := SplitCaption(text, "")
+
+	assert.LessOrEqual(t, utf16Len(caption), MaxCaptionLength)
+	assert.Len(t, rest, 1)
+	assert.Equal(t, strings.TrimSpace(text), caption+" "+rest[0])
+
+	caption, rest = SplitCaption("short", "")
+
+	assert.Equal(t, "short", caption)
+	assert.Empty(t, rest)
+}
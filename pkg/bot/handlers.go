@@ -2,76 +2,244 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/ksysoev/tg-feeder/pkg/bot/middleware"
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/ksysoev/tg-feeder/pkg/i18n"
 )
 
 const (
-	welcomeMessage = `👋 Welcome! I am your helpful Telegram bot. Use /help to see what I can do.`
-	helpMessage    = `Available Commands:
+	welcomeMessage         = `👋 Welcome! I am your helpful Telegram bot. Use /help to see what I can do.`
+	unknownCommandMessage  = "❓ Unknown command.\n\nUse /help to see the list of available commands."
+	summaryUsageMessage    = "Usage: /summary <url>\n\nExample: /summary https://go.dev/blog/go1.24"
+	noContentMessage       = "❌ I couldn't find any readable text on this page."
+	noDialogMessage        = "I only understand commands for now. Use /help to see what I can do."
+	cancelledMessage       = "Cancelled."
+	nothingToCancelMessage = "There is nothing to cancel."
+)
 
-/start - Show welcome message
-/help - Display this help message
-`
-	unknownCommandMessage = "❓ Unknown command.\n\nUse /help to see the list of available commands."
+// Time budgets of the commands waiting for slow external services.
+const (
+	// subscribeTimeout covers fetching the feed to validate it, done by the commands and dialogs subscribing to feeds.
+	subscribeTimeout = 30 * time.Second
+	// summaryTimeout covers extracting the article and waiting for the LLM to summarize it.
+	summaryTimeout = 90 * time.Second
 )
 
-// Handler defines the interface for processing and responding to incoming messages in a Telegram bot context.
-// It handles a message by performing necessary processing and returns the configuration for the outgoing message or an error.
+// Handler defines the interface for processing and responding to incoming updates in a Telegram bot context.
+// It handles an update by performing necessary processing and returns the actions to perform in reply or an error.
 // ctx is the context for managing request lifecycle and cancellation.
-// message is the incoming Telegram message to be processed.
-// Returns a configured message object for sending a response and an error if processing fails.
+// update is the incoming Telegram update to be processed.
+// Returns a response holding the ordered list of outgoing actions and an error if processing fails.
 type Handler interface {
-	Handle(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error)
+	Handle(ctx context.Context, update *tgbotapi.Update) (middleware.Response, error)
+}
+
+// commandHandler processes a single bot command and returns the response message.
+type commandHandler func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error)
+
+// command describes a bot command registered in the command registry.
+// Hidden commands are dispatched but not listed in the help message. Commands waiting for slow external services
+// set a timeout, which replaces the default request timeout for the updates carrying them.
+type command struct {
+	handler     commandHandler
+	name        string
+	args        string
+	description string
+	timeout     time.Duration
+	hidden      bool
 }
 
 // setupHandler initializes and configures the request handler with specified middleware components.
-// It applies middleware for request reduction, concurrency throttling, metric collection, and error handling,
-// ensuring proper management of requests and enhanced error messages.
-// Returns a Handler that processes messages with the applied middleware stack.
+// Every supported update type gets its own handler chain behind the same middleware stack
+// for request reduction, concurrency throttling, metric collection, error handling and localization,
+// ensuring proper management of requests and enhanced error messages in the language of the user.
+// Each middleware is traced in its own span. Callback queries are additionally always answered,
+// so inline buttons never keep spinning. Panic recovery wraps every chain as the outermost middleware,
+// so a panic anywhere in the handling of an update is turned into a reply instead of crashing the bot.
+// Returns a Handler that routes updates to the handler chains.
 func (s *Bot) setupHandler() Handler {
-	h := middleware.Use(
-		s,
-		middleware.WithThrottler(30),
-		middleware.WithRequestSequencer(),
-		middleware.WithMetrics(),
-		middleware.WithErrorHandling(),
-	)
-
-	return h
+	names := make([]string, 0, len(s.commands()))
+	for _, cmd := range s.commands() {
+		names = append(names, cmd.name)
+	}
+
+	recovery := middleware.WithSpan("recovery", middleware.WithRecovery(s.translations))
+
+	stack := []middleware.Middleware{
+		middleware.WithSpan("throttler", middleware.WithThrottler(30)),
+		middleware.WithSpan("sequencer", middleware.WithRequestSequencer()),
+		middleware.WithSpan("metrics", middleware.WithMetrics(names...)),
+		middleware.WithSpan("error_handling", middleware.WithErrorHandling()),
+		middleware.WithSpan("localization", middleware.WithLocalization(s.translations, s.svc)),
+	}
+
+	updateStack := slices.Concat(stack, []middleware.Middleware{recovery})
+	callbackStack := slices.Concat(stack, []middleware.Middleware{
+		middleware.WithSpan("callback_answer", middleware.WithCallbackAnswer()),
+		recovery,
+	})
+
+	r := newRouter()
+
+	r.Route(updateMessage, middleware.Use(middleware.HandlerFunc(s.handleMessage), updateStack...))
+	r.Route(updateEditedMessage, middleware.Use(middleware.HandlerFunc(s.handleEditedMessage), updateStack...))
+	r.Route(updateChannelPost, middleware.Use(middleware.HandlerFunc(s.handleChannelPost), updateStack...))
+	r.Route(updateMyChatMember, middleware.Use(middleware.HandlerFunc(s.handleMyChatMember), updateStack...))
+	r.Route(updateCallbackQuery, middleware.Use(middleware.HandlerFunc(s.handleCallbackQuery), callbackStack...))
+
+	return r
+}
+
+// commands returns the registry of commands supported by the bot in the order they are listed in the help message.
+func (s *Bot) commands() []command {
+	return []command{
+		{name: "start", description: "Show welcome message", handler: s.handleStart},
+		{name: "help", description: "Display this help message", handler: s.handleHelp},
+		{name: "subscribe", args: "[url]", description: "Subscribe to an RSS, Atom or JSON feed", handler: s.handleSubscribe, timeout: subscribeTimeout},
+		{name: "unsubscribe", args: "<url|id>", description: "Unsubscribe from a feed", handler: s.handleUnsubscribe},
+		{name: "list", description: "List your subscriptions", handler: s.handleList},
+		{name: "fulltext", args: "<url|id> on|off", description: "Deliver full articles instead of feed excerpts", handler: s.handleFullText},
+		{name: "publish", args: "<@channel|chat id>[/topic id] [url]", description: "Publish a feed to a channel or group", handler: s.handlePublish, timeout: subscribeTimeout},
+		{name: "unpublish", args: "<@channel|chat id> <url|id>", description: "Stop publishing a feed to a channel or group", handler: s.handleUnpublish},
+		{name: "filter", args: "<url|id> [keywords]", description: "Deliver only items matching the keywords", handler: s.handleFilter},
+		{name: "summary", args: "<url>", description: "Summarize an article", handler: s.handleSummary, timeout: summaryTimeout},
+		{name: "language", args: "[code]", description: "Choose the language of my messages", handler: s.handleLanguage},
+		{name: "cancel", description: "Cancel the current operation", handler: s.handleCancel},
+	}
 }
 
-// Handle processes incoming telegram messages, handles commands, text messages, and generates appropriate responses.
-func (s *Bot) Handle(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+// handleMessage processes incoming telegram messages, handles commands, text messages, and generates appropriate responses.
+// Text messages are answers to the active dialog of the chat.
+func (s *Bot) handleMessage(ctx context.Context, update *tgbotapi.Update) (middleware.Response, error) {
+	msg := update.Message
+
 	slog.DebugContext(ctx, "Handling message", slog.Any("message", msg))
 
 	if msg.Command() != "" {
-		resp, err := s.handleCommand(ctx, msg)
-		if err != nil {
-			return tgbotapi.MessageConfig{}, fmt.Errorf("failed to handle command: %w", err)
-		}
+		return s.respondToCommand(ctx, msg)
+	}
+
+	resp, err := s.handleDialogAnswer(ctx, msg)
+	if err != nil {
+		return middleware.Response{}, fmt.Errorf("failed to handle dialog answer: %w", err)
+	}
+
+	return middleware.NewResponse(resp), nil
+}
 
-		return resp, nil
+// respondToCommand handles the command message and wraps the reply into a response.
+func (s *Bot) respondToCommand(ctx context.Context, msg *tgbotapi.Message) (middleware.Response, error) {
+	resp, err := s.handleCommand(ctx, msg)
+	if err != nil {
+		return middleware.Response{}, fmt.Errorf("failed to handle command: %w", err)
 	}
 
-	return tgbotapi.MessageConfig{}, fmt.Errorf("not implemented")
+	return middleware.NewResponse(resp), nil
 }
 
 // handleCommand handles Telegram command messages and generates an appropriate response based on the command received.
 func (s *Bot) handleCommand(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-	switch msg.Command() {
-	case "start":
-		return newTextMessage(msg.Chat.ID, welcomeMessage), nil
-	case "help":
-		return newTextMessage(msg.Chat.ID, helpMessage), nil
-	case "summary":
-		_, err := s.svc.Summary(ctx, msg.CommandArguments())
-
-		return tgbotapi.MessageConfig{}, err
-	default:
-		return newTextMessage(msg.Chat.ID, unknownCommandMessage), nil
+	name := msg.Command()
+
+	for _, cmd := range s.commands() {
+		if cmd.name == name {
+			return cmd.handler(ctx, msg)
+		}
 	}
+
+	return newTextMessage(msg.Chat.ID, i18n.FromContext(ctx).Text(unknownCommandMessage)), nil
+}
+
+// updateTimeout returns the time budget for handling the update: the timeout of the command it carries
+// if the command declares one, the default request timeout otherwise. Plain text messages answer dialogs,
+// which may end with subscribing to a feed, so they get the budget of the subscribe command.
+func (s *Bot) updateTimeout(update *tgbotapi.Update) time.Duration {
+	msg := update.Message
+
+	switch {
+	case update.EditedMessage != nil:
+		msg = update.EditedMessage
+	case update.ChannelPost != nil:
+		msg = update.ChannelPost
+	}
+
+	if msg == nil {
+		return requestTimeout
+	}
+
+	if msg.Command() == "" {
+		return subscribeTimeout
+	}
+
+	for _, cmd := range s.commands() {
+		if cmd.name == msg.Command() && cmd.timeout > 0 {
+			return cmd.timeout
+		}
+	}
+
+	return requestTimeout
+}
+
+// handleStart replies with the welcome message.
+func (s *Bot) handleStart(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	return newTextMessage(msg.Chat.ID, i18n.FromContext(ctx).Text(welcomeMessage)), nil
+}
+
+// handleHelp replies with the list of available commands.
+func (s *Bot) handleHelp(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	return newTextMessage(msg.Chat.ID, s.helpMessage(i18n.FromContext(ctx))), nil
+}
+
+// handleSummary summarizes the article located at the URL passed as the command argument.
+func (s *Bot) handleSummary(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	p := i18n.FromContext(ctx)
+
+	articleURL := strings.TrimSpace(msg.CommandArguments())
+	if articleURL == "" {
+		return newTextMessage(msg.Chat.ID, p.Text(summaryUsageMessage)), nil
+	}
+
+	resp, err := s.svc.Summary(ctx, articleURL)
+
+	switch {
+	case errors.Is(err, core.ErrInvalidURL):
+		return newTextMessage(msg.Chat.ID, p.Text(invalidURLMessage)), nil
+	case errors.Is(err, core.ErrNoContent):
+		return newTextMessage(msg.Chat.ID, p.Text(noContentMessage)), nil
+	case err != nil:
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to summarize article: %w", err)
+	}
+
+	return newFormattedMessage(msg.Chat.ID, resp.Message, tgbotapi.ModeHTML), nil
+}
+
+// helpMessage generates the help message from the visible commands of the registry in the language of the printer.
+func (s *Bot) helpMessage(p *i18n.Printer) string {
+	var sb strings.Builder
+
+	sb.WriteString(p.Text("Available Commands:") + "\n\n")
+
+	for _, cmd := range s.commands() {
+		if cmd.hidden {
+			continue
+		}
+
+		sb.WriteString("/" + cmd.name)
+
+		if cmd.args != "" {
+			sb.WriteString(" " + p.Text(cmd.args))
+		}
+
+		sb.WriteString(" - " + p.Text(cmd.description) + "\n")
+	}
+
+	return sb.String()
 }
@@ -2,10 +2,15 @@ package bot
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/bot/format"
+	"github.com/ksysoev/tg-feeder/pkg/core"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -53,7 +58,7 @@ func TestHandleCommand(t *testing.T) {
 			},
 			chatID:   123,
 			userID:   456,
-			wantText: helpMessage,
+			wantText: (&Bot{}).helpMessage(nil),
 			wantErr:  false,
 		},
 		{
@@ -125,3 +130,131 @@ func TestHandleCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateTimeout(t *testing.T) {
+	tests := []struct {
+		update *tgbotapi.Update
+		name   string
+		want   time.Duration
+	}{
+		{name: "summary command", update: &tgbotapi.Update{Message: newCommandMessage("summary", "https://example.com")}, want: summaryTimeout},
+		{name: "edited summary command", update: &tgbotapi.Update{EditedMessage: newCommandMessage("summary", "")}, want: summaryTimeout},
+		{name: "channel summary command", update: &tgbotapi.Update{ChannelPost: newCommandMessage("summary", "")}, want: summaryTimeout},
+		{name: "subscribe command", update: &tgbotapi.Update{Message: newCommandMessage("subscribe", "https://example.com/feed")}, want: subscribeTimeout},
+		{name: "publish command", update: &tgbotapi.Update{Message: newCommandMessage("publish", "@news")}, want: subscribeTimeout},
+		{name: "dialog answer", update: &tgbotapi.Update{Message: &tgbotapi.Message{Text: "https://example.com/feed"}}, want: subscribeTimeout},
+		{name: "command without timeout", update: &tgbotapi.Update{Message: newCommandMessage("help", "")}, want: requestTimeout},
+		{name: "unknown command", update: &tgbotapi.Update{Message: newCommandMessage("unknown", "")}, want: requestTimeout},
+		{name: "callback query", update: &tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{}}, want: requestTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Bot{}
+
+			assert.Equal(t, tt.want, b.updateTimeout(tt.update))
+		})
+	}
+}
+
+func TestHandleSummary(t *testing.T) {
+	const articleURL = "https://example.com/post"
+
+	tests := []struct {
+		setupMocks func(svc *MockService)
+		name       string
+		args       string
+		wantText   string
+		wantMode   string
+		wantErr    bool
+	}{
+		{
+			name:       "missing url",
+			setupMocks: func(_ *MockService) {},
+			wantText:   summaryUsageMessage,
+		},
+		{
+			name: "success",
+			args: articleURL,
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().Summary(mock.Anything, articleURL).Return(&core.Response{Message: "<h1>Post</h1><p>Summary &amp; more</p>"}, nil)
+			},
+			wantText: "<b>Post</b>\n\nSummary &amp; more",
+			wantMode: tgbotapi.ModeHTML,
+		},
+		{
+			name: "invalid url",
+			args: "nope",
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().Summary(mock.Anything, "nope").Return(nil, core.ErrInvalidURL)
+			},
+			wantText: invalidURLMessage,
+		},
+		{
+			name: "no content",
+			args: articleURL,
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().Summary(mock.Anything, articleURL).Return(nil, core.ErrNoContent)
+			},
+			wantText: noContentMessage,
+		},
+		{
+			name: "unexpected error",
+			args: articleURL,
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().Summary(mock.Anything, articleURL).Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMockService(t)
+			b := &Bot{svc: svc, tg: NewMocktgClient(t)}
+
+			tt.setupMocks(svc)
+
+			resp, err := b.handleCommand(context.Background(), newCommandMessage("summary", tt.args))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, resp.Text)
+			assert.Equal(t, tt.wantMode, resp.ParseMode)
+		})
+	}
+}
+
+func TestNewFormattedMessage(t *testing.T) {
+	msg := newFormattedMessage(1, "<p>a.b</p><unknown>c</unknown>", tgbotapi.ModeMarkdownV2)
+
+	assert.Equal(t, int64(1), msg.ChatID)
+	assert.Equal(t, "a\\.b\n\nc", msg.Text)
+	assert.Equal(t, tgbotapi.ModeMarkdownV2, msg.ParseMode)
+}
+
+func TestSplitMessage(t *testing.T) {
+	msg := newTextMessage(1, strings.Repeat("word ", 2000))
+
+	parts := splitMessage(msg)
+
+	require.Len(t, parts, 3)
+
+	for i, part := range parts {
+		assert.LessOrEqual(t, len(part.Text), format.MaxTextLength)
+		assert.Equal(t, int64(1), part.ChatID)
+
+		if i < len(parts)-1 {
+			assert.Nil(t, part.ReplyMarkup)
+		} else {
+			assert.Equal(t, msg.ReplyMarkup, part.ReplyMarkup)
+		}
+	}
+
+	short := newTextMessage(1, "short")
+
+	assert.Equal(t, []tgbotapi.MessageConfig{short}, splitMessage(short))
+}
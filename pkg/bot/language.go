@@ -0,0 +1,123 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/i18n"
+	"github.com/ksysoev/tg-feeder/pkg/reqctx"
+)
+
+// languageMenu is the name of the language menu in callback data.
+const languageMenu = "l"
+
+// languageActionSet is the action of the language menu choosing the language passed as its argument.
+const languageActionSet = "s"
+
+const (
+	languagePrompt         = "🌐 Your language: %s\n\nChoose the language of my messages:"
+	languageChangedMessage = "🌐 I will write to you in English from now on."
+	unknownLanguageMessage = "❌ I don't speak this language yet. Available languages: %s"
+)
+
+// languageMenu returns the actions of the language menu.
+func (s *Bot) languageMenu() menu {
+	return menu{
+		languageActionSet: s.setLanguage,
+	}
+}
+
+// handleLanguage stores the language passed as the command argument as the language of the messages to the sender,
+// or shows the language menu when no language is given.
+func (s *Bot) handleLanguage(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	p := i18n.FromContext(ctx)
+
+	if msg.From == nil {
+		return newTextMessage(msg.Chat.ID, p.Text(noUserMessage)), nil
+	}
+
+	arg := strings.TrimSpace(msg.CommandArguments())
+	if arg == "" {
+		return s.newMenuMessage(msg, s.languageView(p))
+	}
+
+	lang, ok := s.translations.Match(arg)
+	if !ok {
+		return newTextMessage(msg.Chat.ID, p.Sprintf(unknownLanguageMessage, s.languageCodes())), nil
+	}
+
+	if err := s.svc.SetLanguage(ctx, msg.From.ID, lang); err != nil {
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to set language: %w", err)
+	}
+
+	return newTextMessage(msg.Chat.ID, s.translations.Printer(lang).Text(languageChangedMessage)), nil
+}
+
+// setLanguage stores the language passed as the argument as the language of the messages to the user
+// who pressed the button and shows the menu in that language.
+func (s *Bot) setLanguage(ctx context.Context, _ int64, args []string) (*menuView, error) {
+	userID, ok := reqctx.UserID(ctx)
+	if !ok || len(args) != 1 {
+		return nil, errUnknownAction
+	}
+
+	lang, ok := s.translations.Match(args[0])
+	if !ok {
+		return nil, errUnknownAction
+	}
+
+	if err := s.svc.SetLanguage(ctx, userID, lang); err != nil {
+		return nil, fmt.Errorf("failed to set language: %w", err)
+	}
+
+	p := s.translations.Printer(lang)
+
+	view := s.languageView(p)
+	view.notice = p.Text(languageChangedMessage)
+
+	return view, nil
+}
+
+// languageView shows the language of the printer with a button choosing each of the languages the bot speaks.
+func (s *Bot) languageView(p *i18n.Printer) *menuView {
+	languages := s.translations.Languages()
+	current := p.Language()
+
+	rows := make([][]menuButton, 0, len(languages))
+
+	for _, lang := range languages {
+		text := lang.Name
+		if lang.Code == current {
+			text = "✓ " + text
+		}
+
+		rows = append(rows, []menuButton{newButton(text, languageMenu, languageActionSet, lang.Code)})
+	}
+
+	return &menuView{text: p.Sprintf(languagePrompt, languageName(languages, current)), rows: rows}
+}
+
+// languageCodes returns the comma-separated codes of the languages the bot speaks.
+func (s *Bot) languageCodes() string {
+	languages := s.translations.Languages()
+	codes := make([]string, 0, len(languages))
+
+	for _, lang := range languages {
+		codes = append(codes, lang.Code)
+	}
+
+	return strings.Join(codes, ", ")
+}
+
+// languageName returns the name of the language with the code, or the code itself for unknown languages.
+func languageName(languages []i18n.Language, code string) string {
+	for _, lang := range languages {
+		if lang.Code == code {
+			return lang.Name
+		}
+	}
+
+	return code
+}
@@ -0,0 +1,203 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/bot/callback"
+	"github.com/ksysoev/tg-feeder/pkg/i18n"
+	"github.com/ksysoev/tg-feeder/pkg/reqctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleLanguage(t *testing.T) {
+	translations, err := i18n.New()
+	require.NoError(t, err)
+
+	ru := translations.Printer("ru")
+
+	tests := []struct {
+		setupMocks  func(t *testing.T, svc *MockService)
+		from        *tgbotapi.User
+		name        string
+		args        string
+		wantText    string
+		wantButtons int
+		wantErr     bool
+	}{
+		{
+			name:       "no sender",
+			setupMocks: func(_ *testing.T, _ *MockService) {},
+			wantText:   noUserMessage,
+		},
+		{
+			name:        "menu",
+			from:        &tgbotapi.User{ID: 42},
+			setupMocks:  func(_ *testing.T, _ *MockService) {},
+			wantText:    "🌐 Your language: English\n\nChoose the language of my messages:",
+			wantButtons: 2,
+		},
+		{
+			name:       "unknown language",
+			from:       &tgbotapi.User{ID: 42},
+			args:       "de",
+			setupMocks: func(_ *testing.T, _ *MockService) {},
+			wantText:   "❌ I don't speak this language yet. Available languages: en, ru",
+		},
+		{
+			name: "language set",
+			from: &tgbotapi.User{ID: 42},
+			args: "ru-RU",
+			setupMocks: func(_ *testing.T, svc *MockService) {
+				svc.EXPECT().SetLanguage(mock.Anything, int64(42), "ru").Return(nil)
+			},
+			wantText: ru.Text(languageChangedMessage),
+		},
+		{
+			name: "service error",
+			from: &tgbotapi.User{ID: 42},
+			args: "ru",
+			setupMocks: func(_ *testing.T, svc *MockService) {
+				svc.EXPECT().SetLanguage(mock.Anything, int64(42), "ru").Return(assert.AnError)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMockService(t)
+			tt.setupMocks(t, svc)
+
+			b := &Bot{svc: svc, tg: NewMocktgClient(t), callbacks: callback.New("secret"), translations: translations}
+
+			msg := &tgbotapi.Message{
+				Text:     "/language " + tt.args,
+				Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/language")}},
+				Chat:     &tgbotapi.Chat{ID: 123},
+				From:     tt.from,
+			}
+
+			resp, err := b.handleLanguage(context.Background(), msg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, resp.Text)
+
+			if tt.wantButtons == 0 {
+				return
+			}
+
+			markup, ok := resp.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+			require.True(t, ok)
+			assert.Len(t, markup.InlineKeyboard, tt.wantButtons)
+			assert.Equal(t, "✓ English", markup.InlineKeyboard[0][0].Text)
+		})
+	}
+}
+
+func TestSetLanguage(t *testing.T) {
+	translations, err := i18n.New()
+	require.NoError(t, err)
+
+	ru := translations.Printer("ru")
+
+	tests := []struct {
+		wantErr    error
+		setupMocks func(t *testing.T, svc *MockService)
+		name       string
+		args       []string
+		userID     int64
+	}{
+		{
+			name:       "no user",
+			args:       []string{"ru"},
+			setupMocks: func(_ *testing.T, _ *MockService) {},
+			wantErr:    errUnknownAction,
+		},
+		{
+			name:       "unknown language",
+			userID:     42,
+			args:       []string{"de"},
+			setupMocks: func(_ *testing.T, _ *MockService) {},
+			wantErr:    errUnknownAction,
+		},
+		{
+			name:       "no arguments",
+			userID:     42,
+			setupMocks: func(_ *testing.T, _ *MockService) {},
+			wantErr:    errUnknownAction,
+		},
+		{
+			name:   "language set",
+			userID: 42,
+			args:   []string{"ru"},
+			setupMocks: func(_ *testing.T, svc *MockService) {
+				svc.EXPECT().SetLanguage(mock.Anything, int64(42), "ru").Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMockService(t)
+			tt.setupMocks(t, svc)
+
+			b := &Bot{svc: svc, translations: translations}
+
+			ctx := context.Background()
+			if tt.userID != 0 {
+				ctx = reqctx.WithUserID(ctx, tt.userID)
+			}
+
+			view, err := b.setLanguage(ctx, 123, tt.args)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, ru.Sprintf(languagePrompt, "Русский"), view.text)
+			assert.Equal(t, ru.Text(languageChangedMessage), view.notice)
+			assert.Equal(t, "✓ Русский", view.rows[1][0].text)
+		})
+	}
+}
+
+func TestTranslations(t *testing.T) {
+	translations, err := i18n.New()
+	require.NoError(t, err)
+
+	messages := []string{
+		welcomeMessage, unknownCommandMessage, summaryUsageMessage, noContentMessage, noDialogMessage,
+		cancelledMessage, nothingToCancelMessage, unknownActionMessage, languagePrompt, languageChangedMessage,
+		unknownLanguageMessage, unsubscribeUsageMessage, invalidURLMessage, invalidFeedMessage, alreadySubscribedMsg,
+		notSubscribedMessage, noSubscriptionsMessage, fullTextUsageMessage, filterUsageMessage, subscribeURLPrompt,
+		subscribeFilterPrompt, noKeywordsMessage, goneSubscriptionNotice, backButton, publishUsageMessage,
+		unpublishUsageMessage, invalidTargetMessage, targetNotFoundMessage, privateTargetMessage, topicTargetMessage,
+		botNotAdminMessage, userNotAdminMessage, noUserMessage, forbiddenReportMessage, readMoreMessage,
+		sharedIntervalNotice, intervalsPrompt,
+	}
+
+	for _, cmd := range (&Bot{}).commands() {
+		messages = append(messages, cmd.description)
+	}
+
+	for _, lang := range translations.Languages() {
+		if lang.Code == i18n.DefaultLanguage {
+			continue
+		}
+
+		p := translations.Printer(lang.Code)
+
+		for _, msg := range messages {
+			assert.NotEqual(t, msg, p.Text(msg), "%s translation is missing", lang.Code)
+		}
+	}
+}
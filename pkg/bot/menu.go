@@ -0,0 +1,154 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/bot/callback"
+	"github.com/ksysoev/tg-feeder/pkg/bot/middleware"
+	"github.com/ksysoev/tg-feeder/pkg/i18n"
+)
+
+// errUnknownAction is returned by menu actions called with arguments they cannot handle.
+var errUnknownAction = errors.New("unknown menu action")
+
+// menuButton is an inline keyboard button that performs a menu action when pressed.
+type menuButton struct {
+	text string
+	data callback.Data
+}
+
+// menuView is the content of a menu message: its text and the keyboard under it.
+// Notice is shown to the user as a short popup when the view is the result of a button press.
+type menuView struct {
+	text   string
+	notice string
+	rows   [][]menuButton
+}
+
+// menuAction renders the view resulting from a button press in the chat with the arguments stored in the button.
+type menuAction func(ctx context.Context, chatID int64, args []string) (*menuView, error)
+
+// menu maps the names of the actions of a menu to their handlers.
+type menu map[string]menuAction
+
+// newButton returns a button performing the action of the menu with the arguments.
+func newButton(text, menuName, action string, args ...string) menuButton {
+	return menuButton{text: text, data: callback.Data{Menu: menuName, Action: action, Args: args}}
+}
+
+// menus returns the registry of menus the bot can show, keyed by the names stored in callback data.
+func (s *Bot) menus() map[string]menu {
+	return map[string]menu{
+		feedMenu:     s.feedMenu(),
+		languageMenu: s.languageMenu(),
+	}
+}
+
+// keyboard builds the inline keyboard of a view sent to the chat for the user, signing the data of every button
+// for both of them. Zero userID leaves the buttons to any user of the chat.
+func (s *Bot) keyboard(chatID, userID int64, rows [][]menuButton) (tgbotapi.InlineKeyboardMarkup, error) {
+	keyboard := make([][]tgbotapi.InlineKeyboardButton, 0, len(rows))
+
+	for _, row := range rows {
+		buttons := make([]tgbotapi.InlineKeyboardButton, 0, len(row))
+
+		for _, b := range row {
+			data, err := s.callbacks.Encode(chatID, userID, b.data)
+			if err != nil {
+				return tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("failed to encode button %q: %w", b.text, err)
+			}
+
+			buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(b.text, data))
+		}
+
+		keyboard = append(keyboard, buttons)
+	}
+
+	return tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}, nil
+}
+
+// newMenuMessage constructs a message showing the view in the chat of the command message,
+// with buttons only its sender can press in groups.
+func (s *Bot) newMenuMessage(cmd *tgbotapi.Message, view *menuView) (tgbotapi.MessageConfig, error) {
+	chatID := cmd.Chat.ID
+	msg := tgbotapi.NewMessage(chatID, view.text)
+
+	if len(view.rows) == 0 {
+		return msg, nil
+	}
+
+	markup, err := s.keyboard(chatID, buttonOwner(cmd.Chat, cmd.From), view.rows)
+	if err != nil {
+		return tgbotapi.MessageConfig{}, err
+	}
+
+	msg.ReplyMarkup = markup
+
+	return msg, nil
+}
+
+// buttonOwner returns the user allowed to press the buttons sent to the chat for the user. In groups every member
+// sees the buttons, so they are bound to the user, while in private chats and channels they are left to the chat,
+// zero being returned.
+func buttonOwner(chat *tgbotapi.Chat, user *tgbotapi.User) int64 {
+	if user == nil || chat == nil || !(chat.IsGroup() || chat.IsSuperGroup()) {
+		return 0
+	}
+
+	return user.ID
+}
+
+// handleCallbackQuery handles presses of inline keyboard buttons.
+// The menu action stored in the button is performed and the message holding the button is edited in place
+// to show the resulting view. Buttons with data that cannot be verified, including buttons of a group member
+// pressed by another one, or that no longer map to an action are answered with a notice.
+func (s *Bot) handleCallbackQuery(ctx context.Context, update *tgbotapi.Update) (middleware.Response, error) {
+	query := update.CallbackQuery
+
+	slog.DebugContext(ctx, "Handling callback query", slog.String("data", query.Data))
+
+	unknown := middleware.NewResponse(tgbotapi.NewCallback(query.ID, i18n.FromContext(ctx).Text(unknownActionMessage)))
+
+	// Buttons of inline mode messages carry no message to edit and are never sent by the bot.
+	if query.Message == nil {
+		return unknown, nil
+	}
+
+	chatID := query.Message.Chat.ID
+	userID := buttonOwner(query.Message.Chat, query.From)
+
+	data, err := s.callbacks.Decode(chatID, userID, query.Data)
+	if err != nil {
+		slog.DebugContext(ctx, "Invalid callback data", slog.Any("error", err))
+
+		return unknown, nil
+	}
+
+	action, ok := s.menus()[data.Menu][data.Action]
+	if !ok {
+		return unknown, nil
+	}
+
+	view, err := action(ctx, chatID, data.Args)
+
+	switch {
+	case errors.Is(err, errUnknownAction):
+		return unknown, nil
+	case err != nil:
+		return middleware.Response{}, fmt.Errorf("failed to perform %s:%s menu action: %w", data.Menu, data.Action, err)
+	}
+
+	markup, err := s.keyboard(chatID, userID, view.rows)
+	if err != nil {
+		return middleware.Response{}, err
+	}
+
+	return middleware.NewResponse(
+		tgbotapi.NewEditMessageTextAndMarkup(chatID, query.Message.MessageID, view.text, markup),
+		tgbotapi.NewCallback(query.ID, view.notice),
+	), nil
+}
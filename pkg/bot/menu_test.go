@@ -0,0 +1,210 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/bot/callback"
+	"github.com/ksysoev/tg-feeder/pkg/bot/middleware"
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newCallbackUpdate(t *testing.T, b *Bot, chatID int64, data callback.Data) *tgbotapi.Update {
+	t.Helper()
+
+	encoded, err := b.callbacks.Encode(chatID, 0, data)
+	require.NoError(t, err)
+
+	return &tgbotapi.Update{
+		CallbackQuery: &tgbotapi.CallbackQuery{
+			ID:      "query",
+			Data:    encoded,
+			From:    &tgbotapi.User{ID: 456},
+			Message: &tgbotapi.Message{MessageID: 7, Chat: &tgbotapi.Chat{ID: chatID}},
+		},
+	}
+}
+
+func TestHandleCallbackQuery(t *testing.T) {
+	unknown := middleware.NewResponse(tgbotapi.NewCallback("query", unknownActionMessage))
+
+	tests := []struct {
+		update     func(t *testing.T, b *Bot) *tgbotapi.Update
+		setupMocks func(svc *MockService)
+		name       string
+		wantErr    bool
+	}{
+		{
+			name: "inline mode message",
+			update: func(_ *testing.T, _ *Bot) *tgbotapi.Update {
+				return &tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{ID: "query", Data: "stale"}}
+			},
+			setupMocks: func(_ *MockService) {},
+		},
+		{
+			name: "forged data",
+			update: func(_ *testing.T, _ *Bot) *tgbotapi.Update {
+				return &tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+					ID:      "query",
+					Data:    "AAAAAAAAf:l",
+					Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}},
+				}}
+			},
+			setupMocks: func(_ *MockService) {},
+		},
+		{
+			name: "data of another chat",
+			update: func(t *testing.T, b *Bot) *tgbotapi.Update {
+				update := newCallbackUpdate(t, b, 999, callback.Data{Menu: feedMenu, Action: feedActionList})
+				update.CallbackQuery.Message.Chat.ID = 123
+
+				return update
+			},
+			setupMocks: func(_ *MockService) {},
+		},
+		{
+			name: "unknown menu",
+			update: func(t *testing.T, b *Bot) *tgbotapi.Update {
+				return newCallbackUpdate(t, b, 123, callback.Data{Menu: "x", Action: feedActionList})
+			},
+			setupMocks: func(_ *MockService) {},
+		},
+		{
+			name: "unknown action",
+			update: func(t *testing.T, b *Bot) *tgbotapi.Update {
+				return newCallbackUpdate(t, b, 123, callback.Data{Menu: feedMenu, Action: "x"})
+			},
+			setupMocks: func(_ *MockService) {},
+		},
+		{
+			name: "missing arguments",
+			update: func(t *testing.T, b *Bot) *tgbotapi.Update {
+				return newCallbackUpdate(t, b, 123, callback.Data{Menu: feedMenu, Action: feedActionOpen})
+			},
+			setupMocks: func(_ *MockService) {},
+		},
+		{
+			name: "service error",
+			update: func(t *testing.T, b *Bot) *tgbotapi.Update {
+				return newCallbackUpdate(t, b, 123, callback.Data{Menu: feedMenu, Action: feedActionList})
+			},
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().ListSubscriptions(mock.Anything, int64(123)).Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMockService(t)
+			b := &Bot{svc: svc, tg: NewMocktgClient(t), callbacks: callback.New("secret")}
+
+			tt.setupMocks(svc)
+
+			resp, err := b.handleCallbackQuery(context.Background(), tt.update(t, b))
+			if tt.wantErr {
+				assert.ErrorIs(t, err, assert.AnError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, unknown, resp)
+		})
+	}
+}
+
+func TestHandleCallbackQuery_EditsMessage(t *testing.T) {
+	svc := NewMockService(t)
+	b := &Bot{svc: svc, tg: NewMocktgClient(t), callbacks: callback.New("secret")}
+
+	svc.EXPECT().ListSubscriptions(mock.Anything, int64(123)).Return([]core.FeedInfo{{ID: "a", Title: "Alpha"}}, nil)
+
+	resp, err := b.handleCallbackQuery(context.Background(), newCallbackUpdate(t, b, 123, callback.Data{Menu: feedMenu, Action: feedActionList}))
+
+	require.NoError(t, err)
+	require.Len(t, resp.Actions, 2)
+
+	edit, ok := resp.Actions[0].(tgbotapi.EditMessageTextConfig)
+	require.True(t, ok)
+	assert.Equal(t, int64(123), edit.ChatID)
+	assert.Equal(t, 7, edit.MessageID)
+	assert.Equal(t, formatSubscriptions(nil, []core.FeedInfo{{ID: "a", Title: "Alpha"}}), edit.Text)
+	require.NotNil(t, edit.ReplyMarkup)
+	require.Len(t, edit.ReplyMarkup.InlineKeyboard, 1)
+	assert.Equal(t, "⚙️ 1. Alpha", edit.ReplyMarkup.InlineKeyboard[0][0].Text)
+
+	assert.Equal(t, tgbotapi.NewCallback("query", ""), resp.Actions[1])
+}
+
+func TestHandleCallbackQuery_GroupMember(t *testing.T) {
+	svc := NewMockService(t)
+	b := &Bot{svc: svc, tg: NewMocktgClient(t), callbacks: callback.New("secret")}
+
+	group := &tgbotapi.Chat{ID: -100, Type: "supergroup"}
+
+	msg, err := b.newMenuMessage(&tgbotapi.Message{Chat: group, From: &tgbotapi.User{ID: 456}}, &menuView{
+		text: "Feeds",
+		rows: [][]menuButton{{newButton("list", feedMenu, feedActionList)}},
+	})
+	require.NoError(t, err)
+
+	data := *msg.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup).InlineKeyboard[0][0].CallbackData
+
+	press := func(userID int64) *tgbotapi.Update {
+		return &tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+			ID:      "query",
+			Data:    data,
+			From:    &tgbotapi.User{ID: userID},
+			Message: &tgbotapi.Message{MessageID: 7, Chat: group},
+		}}
+	}
+
+	resp, err := b.handleCallbackQuery(context.Background(), press(789))
+	require.NoError(t, err)
+	assert.Equal(t, middleware.NewResponse(tgbotapi.NewCallback("query", unknownActionMessage)), resp,
+		"buttons of a member should not be pressed by another one")
+
+	svc.EXPECT().ListSubscriptions(mock.Anything, int64(-100)).Return([]core.FeedInfo{{ID: "a", Title: "Alpha"}}, nil)
+
+	resp, err = b.handleCallbackQuery(context.Background(), press(456))
+	require.NoError(t, err)
+	require.Len(t, resp.Actions, 2)
+
+	edit := resp.Actions[0].(tgbotapi.EditMessageTextConfig)
+	_, err = b.callbacks.Decode(-100, 456, *edit.ReplyMarkup.InlineKeyboard[0][0].CallbackData)
+	assert.NoError(t, err, "buttons of the resulting view should stay bound to the member")
+}
+
+func TestButtonOwner(t *testing.T) {
+	user := &tgbotapi.User{ID: 456}
+
+	assert.Equal(t, int64(456), buttonOwner(&tgbotapi.Chat{ID: -1, Type: "group"}, user))
+	assert.Equal(t, int64(456), buttonOwner(&tgbotapi.Chat{ID: -100, Type: "supergroup"}, user))
+	assert.Zero(t, buttonOwner(&tgbotapi.Chat{ID: 456, Type: "private"}, user))
+	assert.Zero(t, buttonOwner(&tgbotapi.Chat{ID: -100, Type: "channel"}, user))
+	assert.Zero(t, buttonOwner(&tgbotapi.Chat{ID: -100, Type: "supergroup"}, nil))
+}
+
+func TestKeyboard(t *testing.T) {
+	b := &Bot{callbacks: callback.New("secret")}
+
+	markup, err := b.keyboard(1, 0, [][]menuButton{
+		{newButton("a", feedMenu, feedActionOpen, "id"), newButton("b", feedMenu, feedActionList)},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, markup.InlineKeyboard, 1)
+	require.Len(t, markup.InlineKeyboard[0], 2)
+
+	data, err := b.callbacks.Decode(1, 0, *markup.InlineKeyboard[0][0].CallbackData)
+	require.NoError(t, err)
+	assert.Equal(t, callback.Data{Menu: feedMenu, Action: feedActionOpen, Args: []string{"id"}}, data)
+
+	_, err = b.keyboard(1, 0, [][]menuButton{{newButton("bad", feedMenu, feedActionOpen, "a:b")}})
+	assert.ErrorIs(t, err, callback.ErrMalformed)
+}
@@ -2,6 +2,7 @@ package bot
 
 import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/bot/format"
 )
 
 // newTextMessage constructs a Telegram message configuration with text and removes the keyboard from the chat.
@@ -15,3 +16,30 @@ func newTextMessage(chatID int64, text string) tgbotapi.MessageConfig {
 
 	return msg
 }
+
+// newFormattedMessage constructs a Telegram message configuration from an HTML fragment converted into the parse mode.
+func newFormattedMessage(chatID int64, src, parseMode string) tgbotapi.MessageConfig {
+	msg := newTextMessage(chatID, format.Format(src, parseMode))
+	msg.ParseMode = parseMode
+
+	return msg
+}
+
+// splitMessage splits the message into messages that fit into Telegram limits.
+// Every part after the first one replies to the first part, so the caller has to set ReplyToMessageID
+// once the first part is sent; the reply markup is kept on the last part only.
+func splitMessage(msg tgbotapi.MessageConfig) []tgbotapi.MessageConfig {
+	texts := format.Split(msg.Text, msg.ParseMode, format.MaxTextLength)
+	parts := make([]tgbotapi.MessageConfig, len(texts))
+
+	for i, text := range texts {
+		parts[i] = msg
+		parts[i].Text = text
+
+		if i < len(texts)-1 {
+			parts[i].ReplyMarkup = nil
+		}
+	}
+
+	return parts
+}
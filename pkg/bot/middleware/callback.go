@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"context"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// WithCallbackAnswer ensures that every callback query gets answered, so that Telegram clients stop showing
+// the progress indicator on the pressed inline button.
+// When the response of the next Handler does not answer the callback query, an empty answer is put in front of its actions.
+// Returns a Middleware that adds the missing answers to callback query responses.
+func WithCallbackAnswer() Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+			resp, err := next.Handle(ctx, update)
+			if err != nil || update == nil || update.CallbackQuery == nil {
+				return resp, err
+			}
+
+			for _, action := range resp.Actions {
+				if answer, ok := action.(tgbotapi.CallbackConfig); ok && answer.CallbackQueryID == update.CallbackQuery.ID {
+					return resp, nil
+				}
+			}
+
+			resp.Actions = append([]tgbotapi.Chattable{tgbotapi.NewCallback(update.CallbackQuery.ID, "")}, resp.Actions...)
+
+			return resp, nil
+		})
+	}
+}
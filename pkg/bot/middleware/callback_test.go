@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithCallbackAnswer(t *testing.T) {
+	callbackUpdate := &tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{ID: "query"}}
+	edit := tgbotapi.NewEditMessageText(1, 2, "edited")
+
+	tests := []struct {
+		handler      Handler
+		update       *tgbotapi.Update
+		expectedErr  error
+		name         string
+		expectedResp Response
+	}{
+		{
+			name: "adds missing answer",
+			handler: HandlerFunc(func(_ context.Context, _ *tgbotapi.Update) (Response, error) {
+				return NewResponse(edit), nil
+			}),
+			update:       callbackUpdate,
+			expectedResp: NewResponse(tgbotapi.NewCallback("query", ""), edit),
+		},
+		{
+			name: "keeps existing answer",
+			handler: HandlerFunc(func(_ context.Context, _ *tgbotapi.Update) (Response, error) {
+				return NewResponse(edit, tgbotapi.NewCallback("query", "done")), nil
+			}),
+			update:       callbackUpdate,
+			expectedResp: NewResponse(edit, tgbotapi.NewCallback("query", "done")),
+		},
+		{
+			name: "ignores other updates",
+			handler: HandlerFunc(func(_ context.Context, _ *tgbotapi.Update) (Response, error) {
+				return NewResponse(edit), nil
+			}),
+			update:       &tgbotapi.Update{Message: &tgbotapi.Message{}},
+			expectedResp: NewResponse(edit),
+		},
+		{
+			name: "passes errors through",
+			handler: HandlerFunc(func(_ context.Context, _ *tgbotapi.Update) (Response, error) {
+				return Response{}, assert.AnError
+			}),
+			update:       callbackUpdate,
+			expectedResp: Response{},
+			expectedErr:  assert.AnError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := WithCallbackAnswer()(tt.handler).Handle(context.Background(), tt.update)
+
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expectedResp, resp)
+		})
+	}
+}
@@ -6,32 +6,75 @@ import (
 	"log/slog"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/ksysoev/tg-feeder/pkg/i18n"
+)
+
+const (
+	validationErrorMessage    = "⚠️ The request is not valid. Please check it and try again, or use /help to see how to use the commands."
+	notFoundErrorMessage      = "🔍 Nothing was found for your request."
+	alreadyExistsErrorMessage = "ℹ️ This has already been done, there is nothing to change."
+	quotaErrorMessage         = "⏳ Too many requests right now. Please wait a moment and try again."
+	upstreamErrorMessage      = "🌐 An external service is not available at the moment. Please try again later."
+	permissionErrorMessage    = "🚫 I don't have the permissions required for this request."
+	internalErrorMessage      = "Sorry, I encountered an error while processing your request. Please try again later."
 )
 
 // WithErrorHandling adds error handling middleware to a Handler.
-// It intercepts errors returned by the next Handler and generates an appropriate error message response for the user.
-// It uses the localized message printer from the context to create user-friendly error messages.
+// It intercepts errors returned by the next Handler and replies to the user with a message explaining
+// the class of the error, such as invalid input or an unavailable external service, without exposing its details.
+// The reply is written in the language of the printer placed into the context by WithLocalization.
+// The original error is logged, as a warning for the errors the user can act upon and as an error otherwise.
 // Returns a Middleware wrapping the original Handler with error handling logic.
 func WithErrorHandling() Middleware {
 	return func(next Handler) Handler {
-		return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-			if message == nil {
-				return tgbotapi.MessageConfig{}, errors.New("message is nil")
+		return HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+			if update == nil {
+				return Response{}, errors.New("update is nil")
 			}
 
-			msgConfig, err := next.Handle(ctx, message)
+			resp, err := next.Handle(ctx, update)
 			if err != nil {
 				var chatID int64
-				if message.Chat != nil {
-					chatID = message.Chat.ID
+				if chat := UpdateChat(update); chat != nil {
+					chatID = chat.ID
 				}
 
-				slog.ErrorContext(ctx, "Failed to handle message", slog.Any("error", err))
+				text, internal := errorMessage(err)
+
+				if internal {
+					slog.ErrorContext(ctx, "Failed to handle update", slog.Any("error", err))
+				} else {
+					slog.WarnContext(ctx, "Failed to handle update", slog.Any("error", err))
+				}
 
-				return tgbotapi.NewMessage(chatID, "Sorry, I encountered an error while processing your request. Please try again later."), nil
+				return NewResponse(tgbotapi.NewMessage(chatID, i18n.FromContext(ctx).Text(text))), nil
 			}
 
-			return msgConfig, nil
+			return resp, nil
 		})
 	}
 }
+
+// errorMessage returns the reply explaining the class of the error and whether the error is internal.
+// Cancelled requests are internal whatever the error they interrupted.
+func errorMessage(err error) (text string, internal bool) {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return internalErrorMessage, true
+	case errors.Is(err, core.ErrValidation):
+		return validationErrorMessage, false
+	case errors.Is(err, core.ErrNotFound):
+		return notFoundErrorMessage, false
+	case errors.Is(err, core.ErrAlreadyExists):
+		return alreadyExistsErrorMessage, false
+	case errors.Is(err, core.ErrQuotaExceeded):
+		return quotaErrorMessage, false
+	case errors.Is(err, core.ErrUpstreamUnavailable):
+		return upstreamErrorMessage, false
+	case errors.Is(err, core.ErrPermissionDenied):
+		return permissionErrorMessage, false
+	default:
+		return internalErrorMessage, true
+	}
+}
@@ -3,10 +3,15 @@ package middleware
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
 	"testing"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/ksysoev/tg-feeder/pkg/i18n"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestWithErrorHandling(t *testing.T) {
@@ -20,8 +25,8 @@ func TestWithErrorHandling(t *testing.T) {
 	}{
 		{
 			name: "handles error from handler",
-			handler: HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-				return tgbotapi.MessageConfig{}, errors.New("handler error")
+			handler: HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+				return Response{}, errors.New("handler error")
 			}),
 			message: &tgbotapi.Message{
 				Chat: &tgbotapi.Chat{ID: 123},
@@ -32,8 +37,8 @@ func TestWithErrorHandling(t *testing.T) {
 		},
 		{
 			name: "passes through successful response",
-			handler: HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-				return tgbotapi.NewMessage(123, "success"), nil
+			handler: HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+				return NewResponse(tgbotapi.NewMessage(123, "success")), nil
 			}),
 			message: &tgbotapi.Message{
 				Chat: &tgbotapi.Chat{ID: 123},
@@ -44,8 +49,8 @@ func TestWithErrorHandling(t *testing.T) {
 		},
 		{
 			name: "handles message without From field",
-			handler: HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-				return tgbotapi.MessageConfig{}, errors.New("handler error")
+			handler: HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+				return Response{}, errors.New("handler error")
 			}),
 			message: &tgbotapi.Message{
 				Chat: &tgbotapi.Chat{ID: 123},
@@ -55,8 +60,8 @@ func TestWithErrorHandling(t *testing.T) {
 		},
 		{
 			name: "handles message with empty language code",
-			handler: HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-				return tgbotapi.MessageConfig{}, errors.New("handler error")
+			handler: HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+				return Response{}, errors.New("handler error")
 			}),
 			message: &tgbotapi.Message{
 				Chat: &tgbotapi.Chat{ID: 123},
@@ -67,8 +72,8 @@ func TestWithErrorHandling(t *testing.T) {
 		},
 		{
 			name: "handles context cancellation",
-			handler: HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-				return tgbotapi.MessageConfig{}, context.Canceled
+			handler: HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+				return Response{}, context.Canceled
 			}),
 			message: &tgbotapi.Message{
 				Chat: &tgbotapi.Chat{ID: 123},
@@ -79,20 +84,83 @@ func TestWithErrorHandling(t *testing.T) {
 		},
 		{
 			name: "handles nil chat",
-			handler: HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-				return tgbotapi.MessageConfig{}, errors.New("handler error")
+			handler: HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+				return Response{}, errors.New("handler error")
 			}),
 			message:       &tgbotapi.Message{},
 			expectedError: nil,
 			expectedMsg:   "Sorry, I encountered an error while processing your request. Please try again later.",
 		},
 		{
-			name: "handles nil message",
-			handler: HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-				return tgbotapi.MessageConfig{}, errors.New("handler error")
+			name: "handles validation error",
+			handler: HandlerFunc(func(_ context.Context, _ *tgbotapi.Update) (Response, error) {
+				return Response{}, fmt.Errorf("failed to summarize article: %w", core.ErrInvalidURL)
+			}),
+			message:       &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}},
+			expectedError: nil,
+			expectedMsg:   validationErrorMessage,
+		},
+		{
+			name: "handles not found error",
+			handler: HandlerFunc(func(_ context.Context, _ *tgbotapi.Update) (Response, error) {
+				return Response{}, core.ErrNotSubscribed
+			}),
+			message:       &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}},
+			expectedError: nil,
+			expectedMsg:   notFoundErrorMessage,
+		},
+		{
+			name: "handles already exists error",
+			handler: HandlerFunc(func(_ context.Context, _ *tgbotapi.Update) (Response, error) {
+				return Response{}, core.ErrAlreadySubscribed
+			}),
+			message:       &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}},
+			expectedError: nil,
+			expectedMsg:   alreadyExistsErrorMessage,
+		},
+		{
+			name: "handles quota exceeded error",
+			handler: HandlerFunc(func(_ context.Context, _ *tgbotapi.Update) (Response, error) {
+				return Response{}, core.StatusError(http.StatusTooManyRequests)
+			}),
+			message:       &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}},
+			expectedError: nil,
+			expectedMsg:   quotaErrorMessage,
+		},
+		{
+			name: "handles upstream unavailable error",
+			handler: HandlerFunc(func(_ context.Context, _ *tgbotapi.Update) (Response, error) {
+				return Response{}, fmt.Errorf("%w: failed to fetch page: %w", core.ErrUpstreamUnavailable, errors.New("connection refused"))
+			}),
+			message:       &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}},
+			expectedError: nil,
+			expectedMsg:   upstreamErrorMessage,
+		},
+		{
+			name: "handles permission denied error",
+			handler: HandlerFunc(func(_ context.Context, _ *tgbotapi.Update) (Response, error) {
+				return Response{}, core.ErrPublishForbidden
+			}),
+			message:       &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}},
+			expectedError: nil,
+			expectedMsg:   permissionErrorMessage,
+		},
+		{
+			name: "handles cancelled request of classified error",
+			handler: HandlerFunc(func(_ context.Context, _ *tgbotapi.Update) (Response, error) {
+				return Response{}, fmt.Errorf("%w: %w", core.ErrUpstreamUnavailable, context.Canceled)
+			}),
+			message:       &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}},
+			expectedError: nil,
+			expectedMsg:   internalErrorMessage,
+		},
+		{
+			name: "handles nil update",
+			handler: HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+				return Response{}, errors.New("handler error")
 			}),
 			message:       nil,
-			expectedError: errors.New("message is nil"),
+			expectedError: errors.New("update is nil"),
 			expectedMsg:   "",
 		},
 	}
@@ -100,14 +168,38 @@ func TestWithErrorHandling(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			handler := WithErrorHandling()(tt.handler)
-			msgConfig, err := handler.Handle(context.Background(), tt.message)
+			resp, err := handler.Handle(context.Background(), messageUpdate(tt.message))
 
 			if tt.expectedError != nil {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.expectedMsg, msgConfig.Text)
+				require.Len(t, resp.Actions, 1)
+
+				msg, ok := resp.Actions[0].(tgbotapi.MessageConfig)
+				require.True(t, ok)
+				assert.Equal(t, tt.expectedMsg, msg.Text)
 			}
 		})
 	}
 }
+
+func TestWithErrorHandling_Localized(t *testing.T) {
+	bundle, err := i18n.New()
+	require.NoError(t, err)
+
+	handler := WithErrorHandling()(HandlerFunc(func(_ context.Context, _ *tgbotapi.Update) (Response, error) {
+		return Response{}, core.ErrNotSubscribed
+	}))
+
+	ctx := i18n.WithPrinter(context.Background(), bundle.Printer("ru"))
+
+	resp, err := handler.Handle(ctx, messageUpdate(&tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}}))
+	require.NoError(t, err)
+	require.Len(t, resp.Actions, 1)
+
+	msg, ok := resp.Actions[0].(tgbotapi.MessageConfig)
+	require.True(t, ok)
+	assert.Equal(t, bundle.Printer("ru").Text(notFoundErrorMessage), msg.Text)
+	assert.NotEqual(t, notFoundErrorMessage, msg.Text)
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/i18n"
+)
+
+// UserStore provides the languages users have chosen for the messages of the bot and records their activity.
+type UserStore interface {
+	Language(ctx context.Context, userID int64) (string, error)
+	TouchUser(ctx context.Context, userID int64) error
+}
+
+// WithLocalization adds localization middleware to a Handler.
+// It places into the context the printer of the language of the user who caused the update, so the next Handler
+// and the middleware it is wrapped into reply in that language. The language chosen by the user takes precedence
+// over the one of the user's Telegram client; updates with no sender, such as channel posts, and languages
+// the bundle has no catalog for get the default language. Failures to load the chosen language are logged
+// and the update is handled in the language of the client.
+// As the user profile is loaded for every update anyway, the middleware also records that the user was seen;
+// failures to do so are only logged.
+func WithLocalization(bundle *i18n.Bundle, store UserStore) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+			user := UpdateSender(update)
+			if user != nil {
+				if err := store.TouchUser(ctx, user.ID); err != nil {
+					slog.WarnContext(ctx, "Failed to record user activity", slog.Any("error", err))
+				}
+			}
+
+			ctx = i18n.WithPrinter(ctx, bundle.Printer(userLanguage(ctx, bundle, store, user)))
+
+			return next.Handle(ctx, update)
+		})
+	}
+}
+
+// userLanguage returns the language tag of the user, or an empty string if there is no user.
+func userLanguage(ctx context.Context, bundle *i18n.Bundle, store UserStore, user *tgbotapi.User) string {
+	if user == nil {
+		return ""
+	}
+
+	lang, err := store.Language(ctx, user.ID)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to get user language", slog.Any("error", err))
+	}
+
+	if _, ok := bundle.Match(lang); ok {
+		return lang
+	}
+
+	return user.LanguageCode
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithLocalization(t *testing.T) {
+	bundle, err := i18n.New()
+	require.NoError(t, err)
+
+	tests := []struct {
+		setupMocks func(t *testing.T, store *MockUserStore)
+		update     *tgbotapi.Update
+		name       string
+		wantLang   string
+	}{
+		{
+			name:   "chosen language",
+			update: &tgbotapi.Update{Message: &tgbotapi.Message{From: &tgbotapi.User{ID: 1, LanguageCode: "en"}}},
+			setupMocks: func(_ *testing.T, store *MockUserStore) {
+				store.EXPECT().TouchUser(mock.Anything, int64(1)).Return(nil)
+				store.EXPECT().Language(mock.Anything, int64(1)).Return("ru", nil)
+			},
+			wantLang: "ru",
+		},
+		{
+			name:   "client language",
+			update: &tgbotapi.Update{Message: &tgbotapi.Message{From: &tgbotapi.User{ID: 1, LanguageCode: "ru-RU"}}},
+			setupMocks: func(_ *testing.T, store *MockUserStore) {
+				store.EXPECT().TouchUser(mock.Anything, int64(1)).Return(nil)
+				store.EXPECT().Language(mock.Anything, int64(1)).Return("", nil)
+			},
+			wantLang: "ru",
+		},
+		{
+			name:   "unsupported chosen language",
+			update: &tgbotapi.Update{Message: &tgbotapi.Message{From: &tgbotapi.User{ID: 1, LanguageCode: "ru"}}},
+			setupMocks: func(_ *testing.T, store *MockUserStore) {
+				store.EXPECT().TouchUser(mock.Anything, int64(1)).Return(nil)
+				store.EXPECT().Language(mock.Anything, int64(1)).Return("de", nil)
+			},
+			wantLang: "ru",
+		},
+		{
+			name:   "unsupported client language",
+			update: &tgbotapi.Update{Message: &tgbotapi.Message{From: &tgbotapi.User{ID: 1, LanguageCode: "de"}}},
+			setupMocks: func(_ *testing.T, store *MockUserStore) {
+				store.EXPECT().TouchUser(mock.Anything, int64(1)).Return(nil)
+				store.EXPECT().Language(mock.Anything, int64(1)).Return("", nil)
+			},
+			wantLang: "en",
+		},
+		{
+			name:   "store failure",
+			update: &tgbotapi.Update{Message: &tgbotapi.Message{From: &tgbotapi.User{ID: 1, LanguageCode: "ru"}}},
+			setupMocks: func(_ *testing.T, store *MockUserStore) {
+				store.EXPECT().TouchUser(mock.Anything, int64(1)).Return(assert.AnError)
+				store.EXPECT().Language(mock.Anything, int64(1)).Return("", assert.AnError)
+			},
+			wantLang: "ru",
+		},
+		{
+			name:       "no sender",
+			update:     &tgbotapi.Update{ChannelPost: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: -100}}},
+			setupMocks: func(_ *testing.T, _ *MockUserStore) {},
+			wantLang:   "en",
+		},
+		{
+			name:       "nil update",
+			setupMocks: func(_ *testing.T, _ *MockUserStore) {},
+			wantLang:   "en",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMockUserStore(t)
+			tt.setupMocks(t, store)
+
+			var gotLang string
+
+			handler := WithLocalization(bundle, store)(HandlerFunc(func(ctx context.Context, _ *tgbotapi.Update) (Response, error) {
+				gotLang = i18n.FromContext(ctx).Language()
+				return Response{}, nil
+			}))
+
+			_, err := handler.Handle(context.Background(), tt.update)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLang, gotLang)
+		})
+	}
+}
@@ -2,24 +2,144 @@ package middleware
 
 import (
 	"context"
-	"log/slog"
+	"errors"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
-// WithMetrics wraps a Handler to record processing time and error occurrence metrics for each message processed.
-// It logs the duration of message processing and whether an error occurred during execution.
-// Returns a Middleware that measures and logs performance metrics for the wrapped Handler.
-func WithMetrics() Middleware {
+const (
+	metricsNamespace = "feeder"
+	metricsSubsystem = "bot"
+
+	unknownCommand = "unknown"
+)
+
+var (
+	updateDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "update_duration_seconds",
+		Help:      "Time spent handling updates, by command or update kind.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"command"})
+
+	updateErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "update_errors_total",
+		Help:      "Number of updates whose handling failed, by error class.",
+	}, []string{"class"})
+
+	updatesInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "updates_in_flight",
+		Help:      "Number of updates being handled.",
+	})
+
+	throttlerWait = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "throttler_wait_seconds",
+		Help:      "Time updates wait for a free handler slot.",
+		Buckets:   []float64{.001, .005, .01, .05, .1, .25, .5, 1, 2.5},
+	})
+
+	sequencerWaiting = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "sequencer_waiting",
+		Help:      "Number of updates waiting for the previous updates of the same user to be handled.",
+	})
+)
+
+// WithMetrics wraps a Handler to record Prometheus metrics for each update processed: the processing time
+// by command, the number of failures by error class and the number of updates in flight.
+// Commands are labeled by name when they are among the given known commands and as unknown otherwise,
+// which keeps the label cardinality bounded; updates without a command are labeled by their kind.
+// Returns a Middleware that measures performance metrics for the wrapped Handler.
+func WithMetrics(commands ...string) Middleware {
+	known := make(map[string]struct{}, len(commands))
+	for _, cmd := range commands {
+		known[cmd] = struct{}{}
+	}
+
 	return func(next Handler) Handler {
-		return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+		return HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+			updatesInFlight.Inc()
+			defer updatesInFlight.Dec()
+
 			start := time.Now()
-			resp, err := next.Handle(ctx, message)
+			resp, err := next.Handle(ctx, update)
+
+			updateDuration.WithLabelValues(commandLabel(update, known)).Observe(time.Since(start).Seconds())
 
-			slog.InfoContext(ctx, "Message processing time", slog.Duration("duration", time.Since(start)), slog.Bool("error", err != nil))
+			if err != nil {
+				updateErrors.WithLabelValues(errorClass(err)).Inc()
+			}
 
 			return resp, err
 		})
 	}
 }
+
+// commandLabel returns the metric label of the update: the name of its command or the kind of the update.
+func commandLabel(update *tgbotapi.Update, known map[string]struct{}) string {
+	var msg *tgbotapi.Message
+
+	switch {
+	case update == nil:
+		return unknownCommand
+	case update.Message != nil:
+		msg = update.Message
+	case update.EditedMessage != nil:
+		msg = update.EditedMessage
+	case update.ChannelPost != nil:
+		msg = update.ChannelPost
+	case update.CallbackQuery != nil:
+		return "callback_query"
+	case update.MyChatMember != nil:
+		return "my_chat_member"
+	default:
+		return unknownCommand
+	}
+
+	cmd := msg.Command()
+	if cmd == "" {
+		return "text"
+	}
+
+	if _, ok := known[cmd]; !ok {
+		return unknownCommand
+	}
+
+	return cmd
+}
+
+// errorClass returns the class of the error used as the metric label.
+func errorClass(err error) string {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, core.ErrValidation):
+		return "validation"
+	case errors.Is(err, core.ErrNotFound):
+		return "not_found"
+	case errors.Is(err, core.ErrAlreadyExists):
+		return "already_exists"
+	case errors.Is(err, core.ErrQuotaExceeded):
+		return "quota_exceeded"
+	case errors.Is(err, core.ErrUpstreamUnavailable):
+		return "upstream_unavailable"
+	case errors.Is(err, core.ErrPermissionDenied):
+		return "permission_denied"
+	default:
+		return "internal"
+	}
+}
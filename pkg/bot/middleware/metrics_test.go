@@ -2,46 +2,54 @@ package middleware
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"testing"
-	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestWithMetrics(t *testing.T) {
 	tests := []struct {
-		handler        Handler
-		message        *tgbotapi.Message
-		name           string
-		expectedResult tgbotapi.MessageConfig
-		expectedError  bool
+		handler       Handler
+		message       *tgbotapi.Message
+		name          string
+		wantClass     string
+		expectedError bool
 	}{
 		{
 			name: "successful handler execution",
-			handler: HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-				return tgbotapi.MessageConfig{BaseChat: tgbotapi.BaseChat{ChatID: msg.Chat.ID}}, nil
+			handler: HandlerFunc(func(_ context.Context, update *tgbotapi.Update) (Response, error) {
+				return NewResponse(tgbotapi.NewMessage(update.Message.Chat.ID, "ok")), nil
 			}),
 			message:       &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 12345}},
 			expectedError: false,
 		},
 		{
 			name: "handler execution with error",
-			handler: HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-				return tgbotapi.MessageConfig{}, assert.AnError
+			handler: HandlerFunc(func(_ context.Context, _ *tgbotapi.Update) (Response, error) {
+				return Response{}, assert.AnError
 			}),
 			message:       &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 12345}},
+			wantClass:     "internal",
 			expectedError: true,
 		},
 		{
 			name: "nil message",
-			handler: HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-				if msg == nil {
-					return tgbotapi.MessageConfig{}, assert.AnError
+			handler: HandlerFunc(func(_ context.Context, update *tgbotapi.Update) (Response, error) {
+				if update == nil {
+					return Response{}, assert.AnError
 				}
-				return tgbotapi.MessageConfig{}, nil
+				return Response{}, nil
 			}),
 			message:       nil,
+			wantClass:     "internal",
 			expectedError: true,
 		},
 	}
@@ -51,17 +59,96 @@ func TestWithMetrics(t *testing.T) {
 			middleware := WithMetrics()
 			wrappedHandler := middleware(tt.handler)
 
-			start := time.Now()
-			_, err := wrappedHandler.Handle(context.Background(), tt.message)
-			duration := time.Since(start)
+			var errorsBefore float64
+			if tt.wantClass != "" {
+				errorsBefore = testutil.ToFloat64(updateErrors.WithLabelValues(tt.wantClass))
+			}
+
+			update := messageUpdate(tt.message)
+			duration := updateDuration.WithLabelValues(commandLabel(update, nil))
+			observations := histogramCount(t, duration)
+
+			_, err := wrappedHandler.Handle(context.Background(), update)
 
 			if (err != nil) != tt.expectedError {
 				t.Errorf("expected error: %v, got: %v", tt.expectedError, err)
 			}
 
-			if duration <= 0 {
-				t.Error("expected positive duration, got:", duration)
+			assert.Equal(t, observations+1, histogramCount(t, duration))
+			assert.Zero(t, testutil.ToFloat64(updatesInFlight))
+
+			if tt.wantClass != "" {
+				assert.Equal(t, errorsBefore+1, testutil.ToFloat64(updateErrors.WithLabelValues(tt.wantClass)))
 			}
 		})
 	}
 }
+
+// histogramCount returns the number of observations of the histogram.
+func histogramCount(t *testing.T, o prometheus.Observer) uint64 {
+	t.Helper()
+
+	var m dto.Metric
+
+	h, ok := o.(prometheus.Histogram)
+	require.True(t, ok)
+	require.NoError(t, h.Write(&m))
+
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestWithMetrics_InFlight(t *testing.T) {
+	handler := WithMetrics()(HandlerFunc(func(_ context.Context, _ *tgbotapi.Update) (Response, error) {
+		assert.Equal(t, 1.0, testutil.ToFloat64(updatesInFlight))
+		return Response{}, nil
+	}))
+
+	_, err := handler.Handle(t.Context(), messageUpdate(&tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 1}}))
+
+	assert.NoError(t, err)
+	assert.Zero(t, testutil.ToFloat64(updatesInFlight))
+}
+
+func TestCommandLabel(t *testing.T) {
+	known := map[string]struct{}{"start": {}}
+	command := func(text string) *tgbotapi.Message {
+		return &tgbotapi.Message{
+			Text:     text,
+			Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(text)}},
+		}
+	}
+
+	tests := []struct {
+		update *tgbotapi.Update
+		name   string
+		want   string
+	}{
+		{name: "nil update", update: nil, want: "unknown"},
+		{name: "known command", update: &tgbotapi.Update{Message: command("/start")}, want: "start"},
+		{name: "unknown command", update: &tgbotapi.Update{Message: command("/whatever")}, want: "unknown"},
+		{name: "edited command", update: &tgbotapi.Update{EditedMessage: command("/start")}, want: "start"},
+		{name: "channel command", update: &tgbotapi.Update{ChannelPost: command("/start")}, want: "start"},
+		{name: "text message", update: &tgbotapi.Update{Message: &tgbotapi.Message{Text: "hello"}}, want: "text"},
+		{name: "callback query", update: &tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{}}, want: "callback_query"},
+		{name: "membership change", update: &tgbotapi.Update{MyChatMember: &tgbotapi.ChatMemberUpdated{}}, want: "my_chat_member"},
+		{name: "unsupported update", update: &tgbotapi.Update{}, want: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, commandLabel(tt.update, known))
+		})
+	}
+}
+
+func TestErrorClass(t *testing.T) {
+	assert.Equal(t, "canceled", errorClass(fmt.Errorf("wrapped: %w", context.Canceled)))
+	assert.Equal(t, "timeout", errorClass(context.DeadlineExceeded))
+	assert.Equal(t, "validation", errorClass(fmt.Errorf("wrapped: %w", core.ErrInvalidURL)))
+	assert.Equal(t, "not_found", errorClass(core.ErrNotSubscribed))
+	assert.Equal(t, "already_exists", errorClass(core.ErrAlreadySubscribed))
+	assert.Equal(t, "quota_exceeded", errorClass(core.StatusError(http.StatusTooManyRequests)))
+	assert.Equal(t, "upstream_unavailable", errorClass(core.StatusError(http.StatusBadGateway)))
+	assert.Equal(t, "permission_denied", errorClass(core.ErrPublishForbidden))
+	assert.Equal(t, "internal", errorClass(assert.AnError))
+}
@@ -10,23 +10,23 @@ import (
 // It takes a Handler as input and returns a new, modified Handler that incorporates the middleware's functionality.
 type Middleware func(next Handler) Handler
 
-// Handler defines the interface for processing incoming messages in a bot framework.
-// It accepts a context for request-scoped values and cancellation signals, and the message to be handled.
-// Returns a configured message response and an error if the processing fails.
+// Handler defines the interface for processing incoming updates in a bot framework.
+// It accepts a context for request-scoped values and cancellation signals, and the update to be handled.
+// Returns the response holding the actions to perform in reply and an error if the processing fails.
 type Handler interface {
-	Handle(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error)
+	Handle(ctx context.Context, update *tgbotapi.Update) (Response, error)
 }
 
-// HandlerFunc processes an incoming Telegram message within a given context and generates a response configuration.
-// It takes a context for controlling execution and a pointer to the incoming Telegram message as input parameters.
-// Returns a MessageConfig containing the response to be sent and an error if message handling fails.
-type HandlerFunc func(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error)
+// HandlerFunc processes an incoming Telegram update within a given context and generates a response.
+// It takes a context for controlling execution and a pointer to the incoming Telegram update as input parameters.
+// Returns a Response containing the actions to be performed and an error if update handling fails.
+type HandlerFunc func(ctx context.Context, update *tgbotapi.Update) (Response, error)
 
-// Handle executes the HandlerFunc with the provided context and Telegram message.
-// It processes the incoming message and generates a response configuration.
-// Returns MessageConfig containing the response settings and error if the handler execution fails.
-func (h HandlerFunc) Handle(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-	return h(ctx, message)
+// Handle executes the HandlerFunc with the provided context and Telegram update.
+// It processes the incoming update and generates a response.
+// Returns a Response containing the actions to be performed and error if the handler execution fails.
+func (h HandlerFunc) Handle(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+	return h(ctx, update)
 }
 
 // Use composes a new Handler by wrapping the provided handler with the given middlewares in the specified order.
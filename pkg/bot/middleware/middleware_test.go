@@ -11,13 +11,22 @@ import (
 
 type testHandler struct {
 	err      error
-	response tgbotapi.MessageConfig
+	response Response
 }
 
-func (h *testHandler) Handle(_ context.Context, _ *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+func (h *testHandler) Handle(_ context.Context, _ *tgbotapi.Update) (Response, error) {
 	return h.response, h.err
 }
 
+// messageUpdate wraps the message into an update, keeping nil messages as nil updates.
+func messageUpdate(msg *tgbotapi.Message) *tgbotapi.Update {
+	if msg == nil {
+		return nil
+	}
+
+	return &tgbotapi.Update{Message: msg}
+}
+
 func TestUse(t *testing.T) {
 	type testCase struct {
 		handler         Handler
@@ -25,93 +34,89 @@ func TestUse(t *testing.T) {
 		message         *tgbotapi.Message
 		name            string
 		middlewares     []Middleware
-		expectedMessage tgbotapi.MessageConfig
+		expectedMessage Response
 	}
 
 	tests := []testCase{
 		{
-			name:    "no_middlewares",
-			handler: &testHandler{response: tgbotapi.MessageConfig{}, err: nil},
-			message: &tgbotapi.Message{Text: "test"},
-			expectedMessage: tgbotapi.MessageConfig{
-				Text: "",
-			},
-			expectedErr: nil,
+			name:            "no_middlewares",
+			handler:         &testHandler{response: Response{}, err: nil},
+			message:         &tgbotapi.Message{Text: "test"},
+			expectedMessage: Response{},
+			expectedErr:     nil,
 		},
 		{
 			name: "single_middleware_modifies_response",
 			handler: &testHandler{
-				response: tgbotapi.MessageConfig{Text: "hello"},
+				response: NewResponse(tgbotapi.NewMessage(1, "hello")),
 				err:      nil,
 			},
 			middlewares: []Middleware{
 				func(next Handler) Handler {
-					return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-						res, err := next.Handle(ctx, message)
-						res.Text += " world"
+					return HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+						res, err := next.Handle(ctx, update)
+						res.Add(tgbotapi.NewMessage(1, "world"))
 						return res, err
 					})
 				},
 			},
-			message: &tgbotapi.Message{Text: "test"},
-			expectedMessage: tgbotapi.MessageConfig{
-				Text: "hello world",
-			},
-			expectedErr: nil,
+			message:         &tgbotapi.Message{Text: "test"},
+			expectedMessage: NewResponse(tgbotapi.NewMessage(1, "hello"), tgbotapi.NewMessage(1, "world")),
+			expectedErr:     nil,
 		},
 		{
 			name: "multiple_middlewares_applied_in_order",
 			handler: &testHandler{
-				response: tgbotapi.MessageConfig{Text: "start"},
+				response: NewResponse(tgbotapi.NewMessage(1, "start")),
 				err:      nil,
 			},
 			middlewares: []Middleware{
 				func(next Handler) Handler {
-					return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-						res, err := next.Handle(ctx, message)
-						res.Text += " middle"
+					return HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+						res, err := next.Handle(ctx, update)
+						res.Add(tgbotapi.NewMessage(1, "middle"))
 						return res, err
 					})
 				},
 				func(next Handler) Handler {
-					return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-						res, err := next.Handle(ctx, message)
-						res.Text += " end"
+					return HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+						res, err := next.Handle(ctx, update)
+						res.Add(tgbotapi.NewMessage(1, "end"))
 						return res, err
 					})
 				},
 			},
 			message: &tgbotapi.Message{Text: "test"},
-			expectedMessage: tgbotapi.MessageConfig{
-				Text: "start middle end",
-			},
+			expectedMessage: NewResponse(
+				tgbotapi.NewMessage(1, "start"),
+				tgbotapi.NewMessage(1, "middle"),
+				tgbotapi.NewMessage(1, "end"),
+			),
 			expectedErr: nil,
 		},
 		{
 			name: "middleware_returns_error",
 			handler: &testHandler{
-				response: tgbotapi.MessageConfig{Text: ""},
+				response: NewResponse(tgbotapi.NewMessage(1, "ignored")),
 				err:      nil,
 			},
 			middlewares: []Middleware{
 				func(next Handler) Handler {
-					return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-						return tgbotapi.MessageConfig{}, errors.New("middleware error")
+					return HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+						return Response{}, errors.New("middleware error")
 					})
 				},
 			},
-			message: &tgbotapi.Message{Text: "test"},
-			expectedMessage: tgbotapi.MessageConfig{
-				Text: "",
-			},
-			expectedErr: errors.New("middleware error"),
+			message:         &tgbotapi.Message{Text: "test"},
+			expectedMessage: Response{},
+			expectedErr:     errors.New("middleware error"),
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			handler := Use(tc.handler, tc.middlewares...)
-			res, err := handler.Handle(context.Background(), tc.message)
+			res, err := handler.Handle(context.Background(), messageUpdate(tc.message))
 			assert.Equal(t, tc.expectedMessage, res)
 			assert.Equal(t, tc.expectedErr, err)
 		})
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/i18n"
+	"github.com/ksysoev/tg-feeder/pkg/recovery"
+)
+
+const panicErrorMessage = "💥 Something went wrong on my side while handling your request. It has been reported, please try again later."
+
+// WithRecovery adds panic recovery middleware to a Handler.
+// It converts a panic of the next Handler into an error logged with its stack trace and counted in metrics,
+// and replies to the chat of the update with a message telling that something went wrong, so a bug hit
+// by a single update does not crash the bot. It is meant to be the outermost middleware; since the language
+// chosen by the user is loaded by the inner middleware, the reply is written in the language of the user's client.
+// Returns a Middleware wrapping the original Handler with panic recovery.
+func WithRecovery(bundle *i18n.Bundle) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (resp Response, err error) {
+			err = recovery.Do(ctx, "bot", func() error {
+				resp, err = next.Handle(ctx, update)
+				return err
+			})
+
+			var panicErr *recovery.PanicError
+			if !errors.As(err, &panicErr) {
+				return resp, err
+			}
+
+			chat := UpdateChat(update)
+			if chat == nil {
+				return Response{}, nil
+			}
+
+			var lang string
+			if user := UpdateSender(update); user != nil {
+				lang = user.LanguageCode
+			}
+
+			return NewResponse(tgbotapi.NewMessage(chat.ID, bundle.Printer(lang).Text(panicErrorMessage))), nil
+		})
+	}
+}
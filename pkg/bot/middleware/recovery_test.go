@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRecovery(t *testing.T) {
+	bundle, err := i18n.New()
+	require.NoError(t, err)
+
+	panicking := HandlerFunc(func(_ context.Context, _ *tgbotapi.Update) (Response, error) {
+		panic("boom")
+	})
+
+	tests := []struct {
+		handler  Handler
+		wantErr  error
+		update   *tgbotapi.Update
+		name     string
+		wantText string
+	}{
+		{
+			name:     "passes through response",
+			handler:  &testHandler{response: NewResponse(tgbotapi.NewMessage(123, "success"))},
+			update:   messageUpdate(&tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}}),
+			wantText: "success",
+		},
+		{
+			name:    "passes through error",
+			handler: &testHandler{err: assert.AnError},
+			update:  messageUpdate(&tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}}),
+			wantErr: assert.AnError,
+		},
+		{
+			name:     "replies on panic",
+			handler:  panicking,
+			update:   messageUpdate(&tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}}),
+			wantText: panicErrorMessage,
+		},
+		{
+			name:     "replies on panic in the client language",
+			handler:  panicking,
+			update:   messageUpdate(&tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 123}, From: &tgbotapi.User{ID: 1, LanguageCode: "ru"}}),
+			wantText: bundle.Printer("ru").Text(panicErrorMessage),
+		},
+		{
+			name:    "panic without chat",
+			handler: panicking,
+			update:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := WithRecovery(bundle)(tt.handler).Handle(context.Background(), tt.update)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			if tt.wantText == "" {
+				assert.True(t, resp.Empty())
+				return
+			}
+
+			require.Len(t, resp.Actions, 1)
+
+			msg, ok := resp.Actions[0].(tgbotapi.MessageConfig)
+			require.True(t, ok)
+			assert.Equal(t, int64(123), msg.ChatID)
+			assert.Equal(t, tt.wantText, msg.Text)
+		})
+	}
+
+	assert.NotEqual(t, panicErrorMessage, bundle.Printer("ru").Text(panicErrorMessage), "the reply should be translated")
+}
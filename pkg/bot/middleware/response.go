@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Response holds the actions the bot performs in reply to an incoming message, such as sending messages,
+// photos, documents or media groups, editing messages or answering callback queries.
+// Actions are performed in the order they appear in the list.
+type Response struct {
+	Actions []tgbotapi.Chattable
+}
+
+// NewResponse creates a Response performing the given actions in order.
+func NewResponse(actions ...tgbotapi.Chattable) Response {
+	return Response{Actions: actions}
+}
+
+// Add appends the actions to the end of the response.
+func (r *Response) Add(actions ...tgbotapi.Chattable) {
+	r.Actions = append(r.Actions, actions...)
+}
+
+// Empty reports whether the response has no actions to perform.
+func (r Response) Empty() bool {
+	return len(r.Actions) == 0
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponse(t *testing.T) {
+	var resp Response
+
+	assert.True(t, resp.Empty())
+
+	resp.Add(tgbotapi.NewMessage(1, "first"))
+	resp.Add(tgbotapi.NewCallback("query", ""), tgbotapi.NewMessage(1, "second"))
+
+	assert.False(t, resp.Empty())
+	assert.Equal(t, NewResponse(
+		tgbotapi.NewMessage(1, "first"),
+		tgbotapi.NewCallback("query", ""),
+		tgbotapi.NewMessage(1, "second"),
+	), resp)
+}
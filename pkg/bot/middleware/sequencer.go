@@ -10,8 +10,10 @@ import (
 )
 
 // WithRequestSequencer creates middleware that ensures requests for the same user are processed
-// sequentially in the order they were received. If there are already active requests for a user,
+// sequentially in the order they were received. Updates without a sender, such as channel posts,
+// are sequenced per chat. If there are already active requests for a user,
 // new requests will wait until previous ones finish or be canceled if the request context is canceled.
+// The number of waiting requests is reported by the sequencer waiting metric.
 // Returns a Middleware that enforces the sequential processing policy.
 func WithRequestSequencer() Middleware {
 	// Map to store request queues for each user
@@ -21,12 +23,16 @@ func WithRequestSequencer() Middleware {
 	)
 
 	return func(next Handler) Handler {
-		return HandlerFunc(func(ctx context.Context, message *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-			if message == nil || message.From == nil {
-				return tgbotapi.MessageConfig{}, errors.New("message or user is nil")
-			}
+		return HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+			var userID int64
 
-			userID := message.From.ID
+			if user := UpdateSender(update); user != nil {
+				userID = user.ID
+			} else if chat := UpdateChat(update); chat != nil {
+				userID = chat.ID
+			} else {
+				return Response{}, errors.New("update has neither user nor chat")
+			}
 
 			// Get or create a queue for this user
 			mu.Lock()
@@ -42,9 +48,13 @@ func WithRequestSequencer() Middleware {
 
 			mu.Unlock()
 
+			sequencerWaiting.Inc()
+
 			// Try to acquire the lock or wait for context cancellation
 			select {
 			case <-queue: // Wait for our turn
+				sequencerWaiting.Dec()
+
 				// We got the lock, ensure we release it when done
 				defer func() {
 					mu.Lock()
@@ -64,13 +74,15 @@ func WithRequestSequencer() Middleware {
 					mu.Unlock()
 				}()
 
-				// Process the message
-				resp, err := next.Handle(ctx, message)
+				// Process the update
+				resp, err := next.Handle(ctx, update)
 
 				return resp, err
 			case <-ctx.Done():
+				sequencerWaiting.Dec()
+
 				// Context was cancelled while waiting for our turn
-				return tgbotapi.MessageConfig{}, fmt.Errorf("context cancelled while waiting for user's previous requests to complete: %w", ctx.Err())
+				return Response{}, fmt.Errorf("context cancelled while waiting for user's previous requests to complete: %w", ctx.Err())
 			}
 		})
 	}
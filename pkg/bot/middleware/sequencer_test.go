@@ -8,6 +8,8 @@ import (
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestWithRequestSequencerLimitsPerUserProcessing(t *testing.T) {
@@ -19,8 +21,8 @@ func TestWithRequestSequencerLimitsPerUserProcessing(t *testing.T) {
 	)
 
 	// Create a handler that tracks concurrent executions per user
-	handler := HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-		userID := msg.From.ID
+	handler := HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+		userID := update.Message.From.ID
 
 		mu.Lock()
 
@@ -40,7 +42,7 @@ func TestWithRequestSequencerLimitsPerUserProcessing(t *testing.T) {
 
 		mu.Unlock()
 
-		return tgbotapi.MessageConfig{}, nil
+		return Response{}, nil
 	})
 
 	// Create sequenced handler
@@ -58,7 +60,7 @@ func TestWithRequestSequencerLimitsPerUserProcessing(t *testing.T) {
 				msg := &tgbotapi.Message{
 					From: &tgbotapi.User{ID: id},
 				}
-				_, _ = sequenced.Handle(context.Background(), msg)
+				_, _ = sequenced.Handle(context.Background(), messageUpdate(msg))
 			}(userID)
 		}
 	}
@@ -76,9 +78,9 @@ func TestWithRequestSequencerLimitsPerUserProcessing(t *testing.T) {
 func TestWithRequestSequencerHandlesContextCancellation(t *testing.T) {
 	// Create a handler that blocks until explicitly unblocked
 	blockCh := make(chan struct{})
-	handler := HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	handler := HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
 		<-blockCh // Block until channel is closed
-		return tgbotapi.MessageConfig{}, nil
+		return Response{}, nil
 	})
 
 	// Create sequenced handler
@@ -97,7 +99,7 @@ func TestWithRequestSequencerHandlesContextCancellation(t *testing.T) {
 		msg := &tgbotapi.Message{
 			From: &tgbotapi.User{ID: userID},
 		}
-		_, _ = sequenced.Handle(context.Background(), msg)
+		_, _ = sequenced.Handle(context.Background(), messageUpdate(msg))
 	}()
 
 	// Wait a bit to ensure the first request has acquired the slot
@@ -111,7 +113,7 @@ func TestWithRequestSequencerHandlesContextCancellation(t *testing.T) {
 		From: &tgbotapi.User{ID: userID},
 	}
 
-	_, err := sequenced.Handle(ctx, msg)
+	_, err := sequenced.Handle(ctx, messageUpdate(msg))
 	if err == nil {
 		t.Error("expected error when context is cancelled, got nil")
 	}
@@ -125,17 +127,55 @@ func TestWithRequestSequencerHandlesContextCancellation(t *testing.T) {
 	wg.Wait()
 }
 
+func TestWithRequestSequencerReportsWaiting(t *testing.T) {
+	started := make(chan struct{})
+	blockCh := make(chan struct{})
+	handler := HandlerFunc(func(_ context.Context, _ *tgbotapi.Update) (Response, error) {
+		started <- struct{}{}
+		<-blockCh
+
+		return Response{}, nil
+	})
+
+	sequenced := WithRequestSequencer()(handler)
+	update := messageUpdate(&tgbotapi.Message{From: &tgbotapi.User{ID: 42}})
+
+	var wg sync.WaitGroup
+
+	for range 2 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, _ = sequenced.Handle(context.Background(), update)
+		}()
+	}
+
+	<-started
+
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(sequencerWaiting) == 1
+	}, time.Second, time.Millisecond, "second request should wait for the first one")
+
+	close(blockCh)
+	<-started
+	wg.Wait()
+
+	assert.Zero(t, testutil.ToFloat64(sequencerWaiting))
+}
+
 func TestWithRequestSequencerHandlerError(t *testing.T) {
 	expectedErr := errors.New("handler error")
-	handler := HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-		return tgbotapi.MessageConfig{}, expectedErr
+	handler := HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+		return Response{}, expectedErr
 	})
 
 	sequenced := WithRequestSequencer()(handler)
 
-	_, err := sequenced.Handle(context.Background(), &tgbotapi.Message{
+	_, err := sequenced.Handle(context.Background(), messageUpdate(&tgbotapi.Message{
 		From: &tgbotapi.User{ID: 123},
-	})
+	}))
 	if err == nil {
 		t.Error("expected error from handler, got nil")
 	}
@@ -145,28 +185,47 @@ func TestWithRequestSequencerHandlerError(t *testing.T) {
 	}
 }
 
-func TestWithRequestSequencerNilMessage(t *testing.T) {
-	handler := HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-		return tgbotapi.MessageConfig{}, nil
+func TestWithRequestSequencerNilUpdate(t *testing.T) {
+	handler := HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+		return Response{}, nil
 	})
 
 	sequenced := WithRequestSequencer()(handler)
 
 	_, err := sequenced.Handle(context.Background(), nil)
 	if err == nil {
-		t.Error("expected error for nil message, got nil")
+		t.Error("expected error for nil update, got nil")
 	}
 }
 
-func TestWithRequestSequencerNilUser(t *testing.T) {
-	handler := HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-		return tgbotapi.MessageConfig{}, nil
+func TestWithRequestSequencerNilUserAndChat(t *testing.T) {
+	handler := HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+		return Response{}, nil
 	})
 
 	sequenced := WithRequestSequencer()(handler)
 
-	_, err := sequenced.Handle(context.Background(), &tgbotapi.Message{})
+	_, err := sequenced.Handle(context.Background(), messageUpdate(&tgbotapi.Message{}))
 	if err == nil {
-		t.Error("expected error for nil user, got nil")
+		t.Error("expected error for nil user and chat, got nil")
+	}
+}
+
+func TestWithRequestSequencerChannelPost(t *testing.T) {
+	handler := HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+		return NewResponse(tgbotapi.NewMessage(update.ChannelPost.Chat.ID, "ok")), nil
+	})
+
+	sequenced := WithRequestSequencer()(handler)
+
+	resp, err := sequenced.Handle(context.Background(), &tgbotapi.Update{
+		ChannelPost: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: -100}},
+	})
+	if err != nil {
+		t.Errorf("expected channel posts to be sequenced per chat, got %v", err)
+	}
+
+	if len(resp.Actions) != 1 {
+		t.Errorf("expected handler response, got %v", resp)
 	}
 }
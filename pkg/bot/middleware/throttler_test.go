@@ -20,7 +20,7 @@ func TestWithThrottlerLimitsConcurrentProcessing(t *testing.T) {
 	)
 
 	// Create a handler that tracks concurrent executions
-	handler := HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	handler := HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
 		mu.Lock()
 
 		currentCount++
@@ -39,11 +39,12 @@ func TestWithThrottlerLimitsConcurrentProcessing(t *testing.T) {
 
 		mu.Unlock()
 
-		return tgbotapi.MessageConfig{}, nil
+		return Response{}, nil
 	})
 
 	// Create throttled handler with limit of 5 for testing
 	throttled := WithThrottler(5)(handler)
+	waits := histogramCount(t, throttlerWait)
 
 	// Send 10 concurrent requests
 	for i := 0; i < 10; i++ {
@@ -52,21 +53,22 @@ func TestWithThrottlerLimitsConcurrentProcessing(t *testing.T) {
 		go func() {
 			defer wg.Done()
 
-			_, _ = throttled.Handle(context.Background(), &tgbotapi.Message{})
+			_, _ = throttled.Handle(context.Background(), messageUpdate(&tgbotapi.Message{}))
 		}()
 	}
 
 	wg.Wait()
 
 	assert.LessOrEqual(t, maxCount, 5, "concurrent processing exceeded limit")
+	assert.Equal(t, waits+10, histogramCount(t, throttlerWait), "wait time should be recorded for every request")
 }
 
 func TestWithThrottlerHandlesContextCancellation(t *testing.T) {
 	// Create a handler that blocks until explicitly unblocked
 	blockCh := make(chan struct{})
-	handler := HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	handler := HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
 		<-blockCh // Block until channel is closed
-		return tgbotapi.MessageConfig{}, nil
+		return Response{}, nil
 	})
 
 	// Create throttled handler with limit of 1
@@ -79,7 +81,7 @@ func TestWithThrottlerHandlesContextCancellation(t *testing.T) {
 	go func() {
 		defer wg.Done()
 
-		_, _ = throttled.Handle(context.Background(), &tgbotapi.Message{})
+		_, _ = throttled.Handle(context.Background(), messageUpdate(&tgbotapi.Message{}))
 	}()
 
 	// Wait a bit to ensure the first request has acquired the slot
@@ -89,7 +91,7 @@ func TestWithThrottlerHandlesContextCancellation(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // Cancel immediately
 
-	_, err := throttled.Handle(ctx, &tgbotapi.Message{})
+	_, err := throttled.Handle(ctx, messageUpdate(&tgbotapi.Message{}))
 	assert.Error(t, err, "should return error when context is cancelled")
 	assert.Contains(t, err.Error(), "context cancelled")
 
@@ -98,43 +100,43 @@ func TestWithThrottlerHandlesContextCancellation(t *testing.T) {
 	wg.Wait()
 }
 
-func TestWithThrottlerNilMessage(t *testing.T) {
-	handler := HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-		return tgbotapi.MessageConfig{}, nil
+func TestWithThrottlerNilUpdate(t *testing.T) {
+	handler := HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+		return Response{}, nil
 	})
 
 	throttled := WithThrottler(1)(handler)
 	_, err := throttled.Handle(context.Background(), nil)
 
 	assert.Error(t, err)
-	assert.Equal(t, "message is nil", err.Error(), "should handle nil message")
+	assert.Equal(t, "update is nil", err.Error(), "should handle nil update")
 }
 
 func TestWithThrottlerHandlerError(t *testing.T) {
 	expectedErr := errors.New("handler error")
-	handler := HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-		return tgbotapi.MessageConfig{}, expectedErr
+	handler := HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+		return Response{}, expectedErr
 	})
 
 	throttled := WithThrottler(1)(handler)
-	_, err := throttled.Handle(context.Background(), &tgbotapi.Message{})
+	_, err := throttled.Handle(context.Background(), messageUpdate(&tgbotapi.Message{}))
 
 	assert.Error(t, err)
 	assert.Equal(t, expectedErr, err, "should propagate handler error")
 }
 
 func TestWithThrottlerReleasesSlots(t *testing.T) {
-	handler := HandlerFunc(func(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
-		return tgbotapi.MessageConfig{}, nil
+	handler := HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+		return Response{}, nil
 	})
 
 	throttled := WithThrottler(1)(handler)
 
 	// First call should succeed
-	_, err1 := throttled.Handle(context.Background(), &tgbotapi.Message{})
+	_, err1 := throttled.Handle(context.Background(), messageUpdate(&tgbotapi.Message{}))
 	assert.NoError(t, err1, "first call should succeed")
 
 	// Second call should also succeed because slot was released
-	_, err2 := throttled.Handle(context.Background(), &tgbotapi.Message{})
+	_, err2 := throttled.Handle(context.Background(), messageUpdate(&tgbotapi.Message{}))
 	assert.NoError(t, err2, "second call should succeed after slot is released")
 }
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const tracerName = "github.com/ksysoev/tg-feeder/pkg/bot/middleware"
+
+// tracer creates the spans of the middlewares, resolving the global tracer provider lazily.
+var tracer = otel.Tracer(tracerName)
+
+// WithSpan wraps the given middleware so that its processing of every update, including the handlers it calls,
+// is traced in a child span of the span of the update named after the middleware.
+// Errors returned through the middleware are recorded on the span.
+func WithSpan(name string, mw Middleware) Middleware {
+	return func(next Handler) Handler {
+		wrapped := mw(next)
+
+		return HandlerFunc(func(ctx context.Context, update *tgbotapi.Update) (Response, error) {
+			ctx, span := tracer.Start(ctx, "middleware."+name)
+			defer span.End()
+
+			resp, err := wrapped.Handle(ctx, update)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+
+			return resp, err
+		})
+	}
+}
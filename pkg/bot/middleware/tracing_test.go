@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	spanProvider     *sdktrace.TracerProvider
+	spanProviderOnce sync.Once
+)
+
+// recordSpans returns a recorder of the spans ended during the test.
+// The global tracer provider can be set only once, so it is shared by the tests of the package.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	spanProviderOnce.Do(func() {
+		spanProvider = sdktrace.NewTracerProvider()
+		otel.SetTracerProvider(spanProvider)
+	})
+
+	rec := tracetest.NewSpanRecorder()
+	spanProvider.RegisterSpanProcessor(rec)
+	t.Cleanup(func() { spanProvider.UnregisterSpanProcessor(rec) })
+
+	return rec
+}
+
+func TestWithSpan(t *testing.T) {
+	tests := []struct {
+		err        error
+		name       string
+		wantStatus codes.Code
+	}{
+		{name: "successful handler execution", wantStatus: codes.Unset},
+		{name: "handler execution with error", err: assert.AnError, wantStatus: codes.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := recordSpans(t)
+
+			var parent trace.SpanContext
+
+			passthrough := func(next Handler) Handler { return next }
+			handler := Use(HandlerFunc(func(ctx context.Context, _ *tgbotapi.Update) (Response, error) {
+				parent = trace.SpanContextFromContext(ctx)
+				return Response{}, tt.err
+			}), WithSpan("inner", passthrough), WithSpan("outer", passthrough))
+
+			_, err := handler.Handle(t.Context(), &tgbotapi.Update{})
+			assert.ErrorIs(t, err, tt.err)
+
+			spans := rec.Ended()
+			require.Len(t, spans, 2)
+
+			inner, outer := spans[0], spans[1]
+
+			assert.Equal(t, "middleware.inner", inner.Name())
+			assert.Equal(t, "middleware.outer", outer.Name())
+			assert.Equal(t, outer.SpanContext().SpanID(), inner.Parent().SpanID())
+			assert.Equal(t, inner.SpanContext().SpanID(), parent.SpanID())
+			assert.Equal(t, tt.wantStatus, inner.Status().Code)
+			assert.Equal(t, tt.wantStatus, outer.Status().Code)
+		})
+	}
+}
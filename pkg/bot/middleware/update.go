@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// UpdateChat returns the chat the update belongs to, or nil if the update is not bound to a chat.
+// Unlike tgbotapi.Update.FromChat it covers chat member updates and callback queries of inline messages.
+func UpdateChat(update *tgbotapi.Update) *tgbotapi.Chat {
+	switch {
+	case update == nil:
+		return nil
+	case update.CallbackQuery != nil:
+		if update.CallbackQuery.Message == nil {
+			return nil
+		}
+
+		return update.CallbackQuery.Message.Chat
+	case update.MyChatMember != nil:
+		return &update.MyChatMember.Chat
+	default:
+		return update.FromChat()
+	}
+}
+
+// UpdateSender returns the user who caused the update, or nil if Telegram did not provide one,
+// as for channel posts.
+func UpdateSender(update *tgbotapi.Update) *tgbotapi.User {
+	switch {
+	case update == nil:
+		return nil
+	case update.MyChatMember != nil:
+		return &update.MyChatMember.From
+	default:
+		return update.SentFrom()
+	}
+}
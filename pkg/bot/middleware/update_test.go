@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateChatAndSender(t *testing.T) {
+	chat := &tgbotapi.Chat{ID: 1}
+	user := &tgbotapi.User{ID: 2}
+
+	tests := []struct {
+		update     *tgbotapi.Update
+		wantChat   *tgbotapi.Chat
+		wantSender *tgbotapi.User
+		name       string
+	}{
+		{
+			name: "nil update",
+		},
+		{
+			name:       "message",
+			update:     &tgbotapi.Update{Message: &tgbotapi.Message{Chat: chat, From: user}},
+			wantChat:   chat,
+			wantSender: user,
+		},
+		{
+			name:     "channel post",
+			update:   &tgbotapi.Update{ChannelPost: &tgbotapi.Message{Chat: chat}},
+			wantChat: chat,
+		},
+		{
+			name:       "callback query",
+			update:     &tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{From: user, Message: &tgbotapi.Message{Chat: chat}}},
+			wantChat:   chat,
+			wantSender: user,
+		},
+		{
+			name:       "inline message callback query",
+			update:     &tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{From: user}},
+			wantSender: user,
+		},
+		{
+			name:       "chat member update",
+			update:     &tgbotapi.Update{MyChatMember: &tgbotapi.ChatMemberUpdated{Chat: *chat, From: *user}},
+			wantChat:   chat,
+			wantSender: user,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantChat, UpdateChat(tt.update))
+			assert.Equal(t, tt.wantSender, UpdateSender(tt.update))
+		})
+	}
+}
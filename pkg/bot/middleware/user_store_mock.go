@@ -0,0 +1,142 @@
+// Code generated by mockery. DO NOT EDIT.
+
+//go:build !compile
+
+package middleware
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockUserStore is an autogenerated mock type for the UserStore type
+type MockUserStore struct {
+	mock.Mock
+}
+
+type MockUserStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockUserStore) EXPECT() *MockUserStore_Expecter {
+	return &MockUserStore_Expecter{mock: &_m.Mock}
+}
+
+// Language provides a mock function with given fields: ctx, userID
+func (_m *MockUserStore) Language(ctx context.Context, userID int64) (string, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Language")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (string, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) string); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUserStore_Language_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Language'
+type MockUserStore_Language_Call struct {
+	*mock.Call
+}
+
+// Language is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+func (_e *MockUserStore_Expecter) Language(ctx interface{}, userID interface{}) *MockUserStore_Language_Call {
+	return &MockUserStore_Language_Call{Call: _e.mock.On("Language", ctx, userID)}
+}
+
+func (_c *MockUserStore_Language_Call) Run(run func(ctx context.Context, userID int64)) *MockUserStore_Language_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserStore_Language_Call) Return(_a0 string, _a1 error) *MockUserStore_Language_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUserStore_Language_Call) RunAndReturn(run func(context.Context, int64) (string, error)) *MockUserStore_Language_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TouchUser provides a mock function with given fields: ctx, userID
+func (_m *MockUserStore) TouchUser(ctx context.Context, userID int64) error {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TouchUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUserStore_TouchUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TouchUser'
+type MockUserStore_TouchUser_Call struct {
+	*mock.Call
+}
+
+// TouchUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+func (_e *MockUserStore_Expecter) TouchUser(ctx interface{}, userID interface{}) *MockUserStore_TouchUser_Call {
+	return &MockUserStore_TouchUser_Call{Call: _e.mock.On("TouchUser", ctx, userID)}
+}
+
+func (_c *MockUserStore_TouchUser_Call) Run(run func(ctx context.Context, userID int64)) *MockUserStore_TouchUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserStore_TouchUser_Call) Return(_a0 error) *MockUserStore_TouchUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUserStore_TouchUser_Call) RunAndReturn(run func(context.Context, int64) error) *MockUserStore_TouchUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockUserStore creates a new instance of MockUserStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockUserStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockUserStore {
+	mock := &MockUserStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
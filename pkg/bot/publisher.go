@@ -0,0 +1,193 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/bot/format"
+	"github.com/ksysoev/tg-feeder/pkg/bot/ratelimit"
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/ksysoev/tg-feeder/pkg/i18n"
+)
+
+const (
+	// readMoreReserve is the room kept at the end of a truncated post for the link to the full article.
+	readMoreReserve = 32
+
+	readMoreMessage        = "Read more"
+	forbiddenReportMessage = "⚠️ I couldn't publish %s to %s: %s\n\n" +
+		"Delivery there is paused. Make sure I am an administrator allowed to post messages, " +
+		"then run /publish again to resume it."
+)
+
+// forbiddenErrors lists the descriptions of Telegram errors telling that the bot cannot post to a chat.
+var forbiddenErrors = []string{
+	"not enough rights",
+	"chat not found",
+	"chat_write_forbidden",
+	"topic_closed",
+	"message thread not found",
+}
+
+// Publish sends the post to its target chat, posting into the forum topic of the target when it has one.
+// Posts are sent with bulk priority, yielding to the replies to the users of the bot, and are written
+// in the language of the user the subscription belongs to.
+// When the bot is not allowed to post to the chat anymore the owner of the subscription is told about it
+// and the returned error wraps core.ErrPublishForbidden.
+func (s *Bot) Publish(ctx context.Context, post *core.Post) error {
+	p := s.translations.Printer(s.postLanguage(ctx, post))
+
+	err := s.limiter.Do(ctx, post.Target.ChatID, ratelimit.Bulk, func() error {
+		_, err := s.tg.MakeRequest("sendMessage", postParams(p, post))
+		return err
+	})
+	if err == nil {
+		return nil
+	}
+
+	if !isForbidden(err) {
+		return fmt.Errorf("failed to publish post: %w", err)
+	}
+
+	s.reportForbidden(ctx, p, post, err)
+
+	return fmt.Errorf("%w: %w", core.ErrPublishForbidden, err)
+}
+
+// postLanguage returns the language the user the subscription belongs to has chosen: the owner of the subscription,
+// or the user of a private chat subscribed for itself. Groups and channels subscribed for themselves have no user
+// and get the default language, as do posts whose user language fails to load, which is logged.
+func (s *Bot) postLanguage(ctx context.Context, post *core.Post) string {
+	userID := post.OwnerID
+	if userID == 0 {
+		userID = post.Target.ChatID
+	}
+
+	// Ids of groups and channels are negative.
+	if userID <= 0 {
+		return ""
+	}
+
+	lang, err := s.svc.Language(ctx, userID)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to get user language", slog.Int64("user_id", userID), slog.Any("error", err))
+	}
+
+	return lang
+}
+
+// reportForbidden tells the owner of the subscription that the post could not be published to the target,
+// in the language of the printer, which is the one the owner has chosen.
+// Subscriptions a chat made for itself have nobody else to report to.
+func (s *Bot) reportForbidden(ctx context.Context, p *i18n.Printer, post *core.Post, cause error) {
+	if post.OwnerID == 0 || post.OwnerID == post.Target.ChatID {
+		return
+	}
+
+	reason := cause.Error()
+
+	var tgErr *tgbotapi.Error
+	if errors.As(cause, &tgErr) {
+		reason = tgErr.Message
+	}
+
+	text := p.Sprintf(forbiddenReportMessage, post.FeedTitle, formatTarget(post.Target), reason)
+
+	if err := s.send(ctx, newTextMessage(post.OwnerID, text)); err != nil {
+		slog.WarnContext(ctx, "Failed to report publishing error to owner",
+			slog.Int64("owner_id", post.OwnerID),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// postParams returns the parameters of the sendMessage request publishing the post in the language of the printer.
+// The request is built by hand since message configs of the Telegram client do not support forum topics.
+func postParams(p *i18n.Printer, post *core.Post) tgbotapi.Params {
+	params := tgbotapi.Params{
+		"text":       renderPost(p, post),
+		"parse_mode": tgbotapi.ModeHTML,
+	}
+
+	params.AddNonZero64("chat_id", post.Target.ChatID)
+	params.AddNonZero("message_thread_id", post.Target.ThreadID)
+	params.AddBool("disable_web_page_preview", !post.Preview)
+
+	return params
+}
+
+// renderPost renders the item of the post as Telegram HTML: its title linking to the article, the feed title
+// and the item content. Content that does not fit into a single message is cut with a link to the article
+// labelled in the language of the printer.
+func renderPost(p *i18n.Printer, post *core.Post) string {
+	item := &post.Item
+
+	title := item.Title
+	if title == "" {
+		title = item.Link
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("<p><b>")
+
+	if item.Link != "" {
+		fmt.Fprintf(&sb, `<a href="%s">%s</a>`, html.EscapeString(item.Link), html.EscapeString(title))
+	} else {
+		sb.WriteString(html.EscapeString(title))
+	}
+
+	sb.WriteString("</b><br><i>" + html.EscapeString(post.FeedTitle) + "</i></p>")
+	sb.WriteString(item.Content)
+
+	text := format.Format(sb.String(), tgbotapi.ModeHTML)
+
+	parts := format.Split(text, tgbotapi.ModeHTML, format.MaxTextLength-readMoreReserve)
+	if len(parts) == 1 {
+		return text
+	}
+
+	if item.Link == "" {
+		return parts[0] + " …"
+	}
+
+	return parts[0] + " …\n\n" + fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(item.Link), html.EscapeString(p.Text(readMoreMessage)))
+}
+
+// isForbidden reports whether the Telegram error tells that the bot cannot post to the chat.
+func isForbidden(err error) bool {
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) {
+		return false
+	}
+
+	if tgErr.Code == 403 {
+		return true
+	}
+
+	message := strings.ToLower(tgErr.Message)
+
+	for _, e := range forbiddenErrors {
+		if strings.Contains(message, e) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// formatTarget renders the target chat, with its topic when it has one.
+func formatTarget(target core.Target) string {
+	chat := strconv.FormatInt(target.ChatID, 10)
+	if target.ThreadID == 0 {
+		return chat
+	}
+
+	return chat + "/" + strconv.Itoa(target.ThreadID)
+}
@@ -0,0 +1,268 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/bot/format"
+	"github.com/ksysoev/tg-feeder/pkg/bot/ratelimit"
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/ksysoev/tg-feeder/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublish(t *testing.T) {
+	post := &core.Post{
+		FeedID:    "abc",
+		FeedTitle: "Example",
+		Item:      core.FeedItem{Title: "Hello", Link: "https://example.com/1", Content: "Body"},
+		Target:    core.Target{ChatID: -100123, ThreadID: 7},
+		OwnerID:   42,
+	}
+
+	tests := []struct {
+		setupMocks    func(tg *MocktgClient, svc *MockService)
+		name          string
+		wantForbidden bool
+		wantErr       bool
+	}{
+		{
+			name: "success",
+			setupMocks: func(tg *MocktgClient, svc *MockService) {
+				svc.EXPECT().Language(mock.Anything, int64(42)).Return("", nil)
+				tg.EXPECT().MakeRequest("sendMessage", mock.MatchedBy(func(p tgbotapi.Params) bool {
+					return p["chat_id"] == "-100123" && p["message_thread_id"] == "7" && p["disable_web_page_preview"] == "true"
+				})).Return(&tgbotapi.APIResponse{Ok: true}, nil)
+			},
+		},
+		{
+			name: "forbidden",
+			setupMocks: func(tg *MocktgClient, svc *MockService) {
+				tg.EXPECT().MakeRequest("sendMessage", mock.Anything).
+					Return(nil, &tgbotapi.Error{Code: 403, Message: "Forbidden: bot was kicked from the channel chat"})
+				svc.EXPECT().Language(mock.Anything, int64(42)).Return("", nil)
+				tg.EXPECT().Send(mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+					msg, ok := c.(tgbotapi.MessageConfig)
+					return ok && msg.ChatID == 42 && strings.Contains(msg.Text, "bot was kicked from the channel chat")
+				})).Return(tgbotapi.Message{}, nil)
+			},
+			wantErr:       true,
+			wantForbidden: true,
+		},
+		{
+			name: "forbidden report fails",
+			setupMocks: func(tg *MocktgClient, svc *MockService) {
+				tg.EXPECT().MakeRequest("sendMessage", mock.Anything).
+					Return(nil, &tgbotapi.Error{Code: 400, Message: "Bad Request: TOPIC_CLOSED"})
+				svc.EXPECT().Language(mock.Anything, int64(42)).Return("", assert.AnError)
+				tg.EXPECT().Send(mock.Anything).Return(tgbotapi.Message{}, assert.AnError)
+			},
+			wantErr:       true,
+			wantForbidden: true,
+		},
+		{
+			name: "forbidden report in the owner language",
+			setupMocks: func(tg *MocktgClient, svc *MockService) {
+				tg.EXPECT().MakeRequest("sendMessage", mock.Anything).
+					Return(nil, &tgbotapi.Error{Code: 403, Message: "Forbidden: bot was kicked from the channel chat"})
+				svc.EXPECT().Language(mock.Anything, int64(42)).Return("ru", nil)
+				tg.EXPECT().Send(mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+					msg, ok := c.(tgbotapi.MessageConfig)
+					return ok && strings.HasPrefix(msg.Text, "⚠️ Мне не удалось опубликовать Example")
+				})).Return(tgbotapi.Message{}, nil)
+			},
+			wantErr:       true,
+			wantForbidden: true,
+		},
+		{
+			name: "other error",
+			setupMocks: func(tg *MocktgClient, svc *MockService) {
+				svc.EXPECT().Language(mock.Anything, int64(42)).Return("", nil)
+				tg.EXPECT().MakeRequest("sendMessage", mock.Anything).Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+	}
+
+	translations, err := i18n.New()
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tg := NewMocktgClient(t)
+			svc := NewMockService(t)
+			b := &Bot{tg: tg, svc: svc, translations: translations, limiter: ratelimit.New(ratelimit.Config{})}
+
+			tt.setupMocks(tg, svc)
+
+			err := b.Publish(context.Background(), post)
+			if !tt.wantErr {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Equal(t, tt.wantForbidden, errors.Is(err, core.ErrPublishForbidden))
+		})
+	}
+}
+
+func TestPublish_ForbiddenWithoutOwner(t *testing.T) {
+	tg := NewMocktgClient(t)
+	svc := NewMockService(t)
+	b := &Bot{tg: tg, svc: svc, limiter: ratelimit.New(ratelimit.Config{})}
+
+	svc.EXPECT().Language(mock.Anything, int64(42)).Return("", nil)
+	tg.EXPECT().MakeRequest("sendMessage", mock.Anything).Return(nil, &tgbotapi.Error{Code: 403, Message: "Forbidden"})
+
+	err := b.Publish(context.Background(), &core.Post{Target: core.Target{ChatID: 42}, OwnerID: 42})
+	assert.ErrorIs(t, err, core.ErrPublishForbidden, "chats subscribed for themselves have nobody to report to")
+}
+
+func TestPublish_Language(t *testing.T) {
+	translations, err := i18n.New()
+	require.NoError(t, err)
+
+	long := core.FeedItem{Title: "Long", Link: "https://example.com/long", Content: "<p>" + strings.Repeat("word ", format.MaxTextLength) + "</p>"}
+
+	tests := []struct {
+		setupMocks func(svc *MockService)
+		name       string
+		wantLink   string
+		post       core.Post
+	}{
+		{
+			name:       "owner language",
+			post:       core.Post{Item: long, Target: core.Target{ChatID: -100123}, OwnerID: 42},
+			setupMocks: func(svc *MockService) { svc.EXPECT().Language(mock.Anything, int64(42)).Return("ru", nil) },
+			wantLink:   "Читать далее",
+		},
+		{
+			name:       "private chat language",
+			post:       core.Post{Item: long, Target: core.Target{ChatID: 42}},
+			setupMocks: func(svc *MockService) { svc.EXPECT().Language(mock.Anything, int64(42)).Return("ru", nil) },
+			wantLink:   "Читать далее",
+		},
+		{
+			name:       "failed to get language",
+			post:       core.Post{Item: long, Target: core.Target{ChatID: 42}},
+			setupMocks: func(svc *MockService) { svc.EXPECT().Language(mock.Anything, int64(42)).Return("", assert.AnError) },
+			wantLink:   "Read more",
+		},
+		{
+			name:       "group without owner",
+			post:       core.Post{Item: long, Target: core.Target{ChatID: -100123}},
+			setupMocks: func(_ *MockService) {},
+			wantLink:   "Read more",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tg := NewMocktgClient(t)
+			svc := NewMockService(t)
+			b := &Bot{tg: tg, svc: svc, translations: translations, limiter: ratelimit.New(ratelimit.Config{})}
+
+			tt.setupMocks(svc)
+			tg.EXPECT().MakeRequest("sendMessage", mock.MatchedBy(func(p tgbotapi.Params) bool {
+				return strings.HasSuffix(p["text"], `<a href="https://example.com/long">`+tt.wantLink+`</a>`)
+			})).Return(&tgbotapi.APIResponse{Ok: true}, nil)
+
+			require.NoError(t, b.Publish(context.Background(), &tt.post))
+		})
+	}
+}
+
+func TestPostParams(t *testing.T) {
+	params := postParams(nil, &core.Post{
+		FeedTitle: "Example",
+		Item:      core.FeedItem{Title: "Hello", Link: "https://example.com/1"},
+		Target:    core.Target{ChatID: -100123},
+		Preview:   true,
+	})
+
+	assert.Equal(t, "-100123", params["chat_id"])
+	assert.Equal(t, tgbotapi.ModeHTML, params["parse_mode"])
+	assert.NotContains(t, params, "message_thread_id")
+	assert.NotContains(t, params, "disable_web_page_preview")
+}
+
+func TestRenderPost(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		post core.Post
+	}{
+		{
+			name: "title with link",
+			post: core.Post{FeedTitle: "Feed & Co", Item: core.FeedItem{Title: "A <b> title", Link: "https://example.com/?a=1&b=2", Content: "Body"}},
+			want: `<a href="https://example.com/?a=1&amp;b=2"><b>A &lt;b&gt; title</b></a>` + "\n<i>Feed &amp; Co</i>\n\nBody",
+		},
+		{
+			name: "link without title",
+			post: core.Post{FeedTitle: "Feed", Item: core.FeedItem{Link: "https://example.com/1"}},
+			want: `<a href="https://example.com/1"><b>https://example.com/1</b></a>` + "\n<i>Feed</i>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, renderPost(nil, &tt.post))
+		})
+	}
+}
+
+func TestRenderPost_Truncates(t *testing.T) {
+	post := &core.Post{
+		FeedTitle: "Feed",
+		Item: core.FeedItem{
+			Title:   "Long",
+			Link:    "https://example.com/long",
+			Content: "<p>" + strings.Repeat("word ", format.MaxTextLength) + "</p>",
+		},
+	}
+
+	text := renderPost(nil, post)
+
+	assert.Len(t, format.Split(text, tgbotapi.ModeHTML, format.MaxTextLength), 1)
+	assert.True(t, strings.HasSuffix(text, " …\n\n"+`<a href="https://example.com/long">Read more</a>`))
+
+	post.Item.Link = ""
+
+	text = renderPost(nil, post)
+
+	assert.Len(t, format.Split(text, tgbotapi.ModeHTML, format.MaxTextLength), 1)
+	assert.True(t, strings.HasSuffix(text, " …"))
+}
+
+func TestIsForbidden(t *testing.T) {
+	tests := []struct {
+		err  error
+		name string
+		want bool
+	}{
+		{name: "forbidden", err: &tgbotapi.Error{Code: 403, Message: "Forbidden: bot is not a member of the channel chat"}, want: true},
+		{name: "not enough rights", err: &tgbotapi.Error{Code: 400, Message: "Bad Request: not enough rights to send text messages to the chat"}, want: true},
+		{name: "chat not found", err: &tgbotapi.Error{Code: 400, Message: "Bad Request: chat not found"}, want: true},
+		{name: "topic closed", err: &tgbotapi.Error{Code: 400, Message: "Bad Request: TOPIC_CLOSED"}, want: true},
+		{name: "thread not found", err: &tgbotapi.Error{Code: 400, Message: "Bad Request: message thread not found"}, want: true},
+		{name: "bad request", err: &tgbotapi.Error{Code: 400, Message: "Bad Request: can't parse entities"}},
+		{name: "network error", err: assert.AnError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isForbidden(tt.err))
+		})
+	}
+}
+
+func TestFormatTarget(t *testing.T) {
+	assert.Equal(t, "-100123", formatTarget(core.Target{ChatID: -100123}))
+	assert.Equal(t, "-100123/7", formatTarget(core.Target{ChatID: -100123, ThreadID: 7}))
+}
@@ -0,0 +1,40 @@
+package ratelimit
+
+import "time"
+
+// bucket is a token bucket refilled at a constant rate up to its burst size.
+type bucket struct {
+	last   time.Time
+	tokens float64
+	rate   float64
+	burst  float64
+}
+
+// newBucket creates a full bucket refilled with rate tokens per second.
+func newBucket(rate, burst float64, now time.Time) *bucket {
+	return &bucket{
+		last:   now,
+		tokens: burst,
+		rate:   rate,
+		burst:  burst,
+	}
+}
+
+// delay refills the bucket and returns how long it takes until it holds n tokens.
+func (b *bucket) delay(now time.Time, n float64) time.Duration {
+	if now.After(b.last) {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+
+	if b.tokens >= n {
+		return 0
+	}
+
+	return max(time.Duration((n-b.tokens)/b.rate*float64(time.Second)), minDelay)
+}
+
+// take spends a token.
+func (b *bucket) take() {
+	b.tokens--
+}
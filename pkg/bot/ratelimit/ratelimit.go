@@ -0,0 +1,294 @@
+// Package ratelimit keeps outgoing Telegram requests within the limits of the Bot API: about 30 messages
+// per second overall, one message per second to a single chat and 20 messages per minute to a single group.
+// Requests rejected by Telegram with 429 Too Many Requests are retried after the delay it asks for.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	defaultGlobal        = 30
+	defaultPerChat       = 1
+	defaultPerGroup      = 20
+	defaultChatBurst     = 3
+	defaultReserved      = 5
+	defaultMaxRetries    = 3
+	defaultMaxRetryAfter = time.Minute
+
+	// minDelay is the shortest wait for a token, which keeps rounding errors from turning a wait into a busy loop.
+	minDelay = time.Millisecond
+	// sweepInterval is how often the limits of chats that have been idle for a while are dropped.
+	sweepInterval = time.Minute
+)
+
+// Priority defines the order in which requests waiting for the global limit are sent.
+type Priority uint8
+
+const (
+	// Interactive is the priority of replies to the users of the bot.
+	Interactive Priority = iota
+	// Bulk is the priority of feed items published to chats, which yields to interactive replies.
+	Bulk
+)
+
+// Config holds the configuration of the outgoing request limits.
+// Global and PerChat are measured in messages per second and PerGroup in messages per minute.
+// Reserved is the number of global tokens bulk requests leave to interactive replies, so that users get
+// answers without waiting for the published feed items queued in front of them.
+type Config struct {
+	MaxRetryAfter time.Duration `mapstructure:"max_retry_after"`
+	Global        int           `mapstructure:"global"`
+	PerChat       int           `mapstructure:"per_chat"`
+	PerGroup      int           `mapstructure:"per_group"`
+	ChatBurst     int           `mapstructure:"chat_burst"`
+	Reserved      int           `mapstructure:"reserved"`
+	MaxRetries    int           `mapstructure:"max_retries"`
+}
+
+// chatLimit holds the limits of a single chat. Groups and channels are limited per minute as well.
+type chatLimit struct {
+	blockedUntil time.Time
+	lastUsed     time.Time
+	second       *bucket
+	minute       *bucket
+}
+
+// Limiter delays outgoing requests to keep them within the global and per-chat limits of the Bot API.
+type Limiter struct {
+	blockedUntil time.Time
+	swept        time.Time
+	now          func() time.Time
+	global       *bucket
+	chats        map[int64]*chatLimit
+	cfg          Config
+	mu           sync.Mutex
+}
+
+// New creates a new Limiter, applying defaults for unset values.
+func New(cfg Config) *Limiter {
+	if cfg.Global <= 0 {
+		cfg.Global = defaultGlobal
+	}
+
+	if cfg.PerChat <= 0 {
+		cfg.PerChat = defaultPerChat
+	}
+
+	if cfg.PerGroup <= 0 {
+		cfg.PerGroup = defaultPerGroup
+	}
+
+	if cfg.ChatBurst <= 0 {
+		cfg.ChatBurst = defaultChatBurst
+	}
+
+	if cfg.Reserved < 0 {
+		cfg.Reserved = 0
+	} else if cfg.Reserved == 0 {
+		cfg.Reserved = defaultReserved
+	}
+
+	if cfg.Reserved >= cfg.Global {
+		cfg.Reserved = cfg.Global - 1
+	}
+
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	} else if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = defaultMaxRetries
+	}
+
+	if cfg.MaxRetryAfter <= 0 {
+		cfg.MaxRetryAfter = defaultMaxRetryAfter
+	}
+
+	now := time.Now()
+
+	return &Limiter{
+		cfg:    cfg,
+		now:    time.Now,
+		global: newBucket(float64(cfg.Global), float64(cfg.Global), now),
+		chats:  make(map[int64]*chatLimit),
+		swept:  now,
+	}
+}
+
+// Do calls fn once the request it sends to the chat fits into the limits. The chat id is zero for requests
+// that do not send messages, which are subject to the global limit only. When Telegram rejects the request
+// with 429 Too Many Requests, the chat is held back for the requested delay and fn is called again,
+// up to the configured number of retries. The error of the last call of fn is returned as is.
+func (l *Limiter) Do(ctx context.Context, chatID int64, prio Priority, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		if err := l.wait(ctx, chatID, prio); err != nil {
+			return err
+		}
+
+		err := fn()
+
+		retryAfter, ok := retryAfterOf(err)
+		if !ok || attempt >= l.cfg.MaxRetries || retryAfter > l.cfg.MaxRetryAfter {
+			return err
+		}
+
+		slog.WarnContext(ctx, "Telegram rate limit exceeded, retrying",
+			slog.Int64("chat_id", chatID),
+			slog.Duration("retry_after", retryAfter),
+			slog.Int("attempt", attempt+1),
+		)
+
+		l.block(chatID, retryAfter)
+	}
+}
+
+// wait blocks until a request to the chat fits into the limits, taking its tokens.
+func (l *Limiter) wait(ctx context.Context, chatID int64, prio Priority) error {
+	for {
+		delay := l.reserve(chatID, prio)
+		if delay == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return fmt.Errorf("failed to wait for rate limit: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes the tokens of a request to the chat when it fits into the limits.
+// Otherwise, it returns how long to wait before trying again.
+// Bulk requests only take a global token when the reserve for interactive replies stays untouched.
+func (l *Limiter) reserve(chatID int64, prio Priority) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	l.sweep(now)
+
+	need := 1.0
+	if prio == Bulk {
+		need += float64(l.cfg.Reserved)
+	}
+
+	delay := max(l.global.delay(now, need), l.blockedUntil.Sub(now))
+
+	var chat *chatLimit
+
+	if chatID != 0 {
+		chat = l.chat(chatID, now)
+		delay = max(delay, chat.delay(now))
+	}
+
+	if delay > 0 {
+		return delay
+	}
+
+	l.global.take()
+
+	if chat != nil {
+		chat.take(now)
+	}
+
+	return 0
+}
+
+// block holds back the requests to the chat, or every request when the chat is unknown, for the delay.
+func (l *Limiter) block(chatID int64, delay time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	until := l.now().Add(delay)
+
+	if chatID == 0 {
+		l.blockedUntil = until
+		return
+	}
+
+	l.chat(chatID, l.now()).blockedUntil = until
+}
+
+// chat returns the limits of the chat, creating them on the first request to the chat.
+// Negative ids belong to groups and channels, which are limited per minute as well.
+func (l *Limiter) chat(chatID int64, now time.Time) *chatLimit {
+	chat, ok := l.chats[chatID]
+	if ok {
+		return chat
+	}
+
+	chat = &chatLimit{
+		lastUsed: now,
+		second:   newBucket(float64(l.cfg.PerChat), float64(l.cfg.ChatBurst), now),
+	}
+
+	if chatID < 0 {
+		chat.minute = newBucket(float64(l.cfg.PerGroup)/60, float64(l.cfg.PerGroup), now)
+	}
+
+	l.chats[chatID] = chat
+
+	return chat
+}
+
+// sweep drops the limits of the chats that have not been sent anything for a while, since their buckets are full again.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < sweepInterval {
+		return
+	}
+
+	l.swept = now
+
+	for id, chat := range l.chats {
+		if now.Sub(chat.lastUsed) >= sweepInterval && !now.Before(chat.blockedUntil) {
+			delete(l.chats, id)
+		}
+	}
+}
+
+// delay returns how long the chat has to wait for its next message.
+func (c *chatLimit) delay(now time.Time) time.Duration {
+	delay := max(c.blockedUntil.Sub(now), c.second.delay(now, 1))
+
+	if c.minute != nil {
+		delay = max(delay, c.minute.delay(now, 1))
+	}
+
+	return delay
+}
+
+// take spends the tokens of a message to the chat.
+func (c *chatLimit) take(now time.Time) {
+	c.lastUsed = now
+	c.second.take()
+
+	if c.minute != nil {
+		c.minute.take()
+	}
+}
+
+// retryAfterOf returns the delay Telegram asks for when the error is a 429 Too Many Requests response.
+func retryAfterOf(err error) (time.Duration, bool) {
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) {
+		return 0, false
+	}
+
+	if tgErr.Code != 429 && tgErr.RetryAfter <= 0 {
+		return 0, false
+	}
+
+	return max(time.Duration(tgErr.RetryAfter)*time.Second, time.Second), true
+}
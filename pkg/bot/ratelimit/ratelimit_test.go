@@ -0,0 +1,231 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestLimiter creates a limiter driven by the returned clock.
+func newTestLimiter(cfg Config) (*Limiter, *time.Time) {
+	l := New(cfg)
+
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+	l.global = newBucket(float64(l.cfg.Global), float64(l.cfg.Global), now)
+	l.swept = now
+
+	return l, &now
+}
+
+func TestNew(t *testing.T) {
+	l := New(Config{})
+
+	assert.Equal(t, Config{
+		MaxRetryAfter: defaultMaxRetryAfter,
+		Global:        defaultGlobal,
+		PerChat:       defaultPerChat,
+		PerGroup:      defaultPerGroup,
+		ChatBurst:     defaultChatBurst,
+		Reserved:      defaultReserved,
+		MaxRetries:    defaultMaxRetries,
+	}, l.cfg)
+
+	l = New(Config{Global: 3, Reserved: 5, MaxRetries: -1})
+
+	assert.Equal(t, 2, l.cfg.Reserved, "the reserve should leave a token to bulk requests")
+	assert.Zero(t, l.cfg.MaxRetries)
+}
+
+func TestLimiter_Reserve(t *testing.T) {
+	l, now := newTestLimiter(Config{Global: 10, Reserved: 2, PerChat: 1, ChatBurst: 2, PerGroup: 3})
+
+	assert.Zero(t, l.reserve(1, Interactive))
+	assert.Zero(t, l.reserve(1, Interactive))
+	assert.Equal(t, time.Second, l.reserve(1, Interactive), "private chats should be limited per second")
+
+	*now = now.Add(time.Second)
+
+	assert.Zero(t, l.reserve(1, Interactive))
+
+	for range 2 {
+		assert.Zero(t, l.reserve(-100, Interactive))
+
+		*now = now.Add(time.Second)
+	}
+
+	assert.Zero(t, l.reserve(-100, Interactive))
+
+	*now = now.Add(time.Second)
+
+	assert.Equal(t, 17*time.Second, l.reserve(-100, Interactive), "groups should be limited per minute")
+}
+
+func TestLimiter_ReserveBulkYieldsToInteractive(t *testing.T) {
+	l, now := newTestLimiter(Config{Global: 4, Reserved: 2})
+
+	assert.Zero(t, l.reserve(0, Bulk))
+	assert.Zero(t, l.reserve(0, Bulk))
+	assert.Equal(t, 250*time.Millisecond, l.reserve(0, Bulk), "bulk requests should not touch the reserve")
+
+	assert.Zero(t, l.reserve(0, Interactive))
+	assert.Zero(t, l.reserve(0, Interactive))
+	assert.Equal(t, 250*time.Millisecond, l.reserve(0, Interactive))
+
+	*now = now.Add(250 * time.Millisecond)
+
+	assert.Equal(t, 500*time.Millisecond, l.reserve(0, Bulk))
+	assert.Zero(t, l.reserve(0, Interactive))
+}
+
+func TestLimiter_Block(t *testing.T) {
+	l, now := newTestLimiter(Config{})
+
+	l.block(1, 5*time.Second)
+
+	assert.Equal(t, 5*time.Second, l.reserve(1, Interactive))
+	assert.Zero(t, l.reserve(2, Interactive), "other chats should not be held back")
+
+	l.block(0, 3*time.Second)
+
+	assert.Equal(t, 3*time.Second, l.reserve(2, Interactive), "unknown chats should hold back every request")
+
+	*now = now.Add(5 * time.Second)
+
+	assert.Zero(t, l.reserve(1, Interactive))
+}
+
+func TestLimiter_Sweep(t *testing.T) {
+	l, now := newTestLimiter(Config{})
+
+	require.Zero(t, l.reserve(1, Interactive))
+	require.Zero(t, l.reserve(2, Interactive))
+
+	l.block(2, 2*sweepInterval)
+
+	*now = now.Add(sweepInterval)
+
+	require.Zero(t, l.reserve(3, Interactive))
+
+	assert.NotContains(t, l.chats, int64(1), "idle chats should be dropped")
+	assert.Contains(t, l.chats, int64(2), "blocked chats should be kept")
+	assert.Contains(t, l.chats, int64(3))
+}
+
+func TestLimiter_Do(t *testing.T) {
+	tooMany := func(retryAfter int) error {
+		return &tgbotapi.Error{
+			Code:               429,
+			Message:            "Too Many Requests",
+			ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: retryAfter},
+		}
+	}
+
+	tests := []struct {
+		wantErr   error
+		name      string
+		results   []error
+		cfg       Config
+		wantCalls int
+	}{
+		{
+			name:      "success",
+			results:   []error{nil},
+			wantCalls: 1,
+		},
+		{
+			name:      "other errors are not retried",
+			results:   []error{assert.AnError},
+			wantErr:   assert.AnError,
+			wantCalls: 1,
+		},
+		{
+			name:      "retry after exceeds the limit",
+			cfg:       Config{MaxRetryAfter: time.Second},
+			results:   []error{tooMany(2)},
+			wantErr:   tooMany(2),
+			wantCalls: 1,
+		},
+		{
+			name:      "retries disabled",
+			cfg:       Config{MaxRetries: -1},
+			results:   []error{tooMany(1)},
+			wantErr:   tooMany(1),
+			wantCalls: 1,
+		},
+		{
+			name:      "retried after the requested delay",
+			results:   []error{tooMany(1), nil},
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(tt.cfg)
+			calls := 0
+			start := time.Now()
+
+			err := l.Do(context.Background(), 1, Interactive, func() error {
+				calls++
+				return tt.results[calls-1]
+			})
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantCalls, calls)
+
+			if tt.wantCalls > 1 {
+				assert.GreaterOrEqual(t, time.Since(start), time.Second)
+			}
+		})
+	}
+}
+
+func TestLimiter_DoCancelled(t *testing.T) {
+	l := New(Config{})
+	l.block(1, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := l.Do(ctx, 1, Interactive, func() error {
+		t.Fatal("request should not be sent")
+		return nil
+	})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRetryAfterOf(t *testing.T) {
+	tests := []struct {
+		err    error
+		name   string
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "nil", err: nil},
+		{name: "other error", err: assert.AnError},
+		{name: "bad request", err: &tgbotapi.Error{Code: 400, Message: "Bad Request"}},
+		{
+			name:   "too many requests",
+			err:    fmt.Errorf("wrapped: %w", &tgbotapi.Error{Code: 429, ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 7}}),
+			want:   7 * time.Second,
+			wantOK: true,
+		},
+		{name: "too many requests without delay", err: &tgbotapi.Error{Code: 429}, want: time.Second, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := retryAfterOf(tt.err)
+
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
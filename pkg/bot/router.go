@@ -0,0 +1,74 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/bot/middleware"
+)
+
+// updateType identifies the kind of an incoming Telegram update.
+type updateType string
+
+const (
+	updateUnknown           updateType = ""
+	updateMessage           updateType = "message"
+	updateEditedMessage     updateType = "edited_message"
+	updateChannelPost       updateType = "channel_post"
+	updateEditedChannelPost updateType = "edited_channel_post"
+	updateCallbackQuery     updateType = "callback_query"
+	updateMyChatMember      updateType = "my_chat_member"
+)
+
+// typeOfUpdate returns the kind of the update.
+func typeOfUpdate(update *tgbotapi.Update) updateType {
+	switch {
+	case update == nil:
+		return updateUnknown
+	case update.Message != nil:
+		return updateMessage
+	case update.EditedMessage != nil:
+		return updateEditedMessage
+	case update.ChannelPost != nil:
+		return updateChannelPost
+	case update.EditedChannelPost != nil:
+		return updateEditedChannelPost
+	case update.CallbackQuery != nil:
+		return updateCallbackQuery
+	case update.MyChatMember != nil:
+		return updateMyChatMember
+	default:
+		return updateUnknown
+	}
+}
+
+// router dispatches incoming updates to the handler chain registered for their type.
+// Updates of types without a registered chain are skipped.
+type router struct {
+	routes map[updateType]Handler
+}
+
+// newRouter creates a router without any routes.
+func newRouter() *router {
+	return &router{routes: make(map[updateType]Handler)}
+}
+
+// Route registers the handler chain for updates of the given type, replacing the previous one.
+func (r *router) Route(t updateType, h Handler) {
+	r.routes[t] = h
+}
+
+// Handle dispatches the update to the handler chain registered for its type.
+func (r *router) Handle(ctx context.Context, update *tgbotapi.Update) (middleware.Response, error) {
+	t := typeOfUpdate(update)
+
+	h, ok := r.routes[t]
+	if !ok {
+		slog.DebugContext(ctx, "Skipping unsupported update", slog.String("type", string(t)))
+
+		return middleware.Response{}, nil
+	}
+
+	return h.Handle(ctx, update)
+}
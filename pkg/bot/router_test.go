@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/bot/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypeOfUpdate(t *testing.T) {
+	tests := []struct {
+		update *tgbotapi.Update
+		name   string
+		want   updateType
+	}{
+		{name: "nil", update: nil, want: updateUnknown},
+		{name: "empty", update: &tgbotapi.Update{}, want: updateUnknown},
+		{name: "message", update: &tgbotapi.Update{Message: &tgbotapi.Message{}}, want: updateMessage},
+		{name: "edited message", update: &tgbotapi.Update{EditedMessage: &tgbotapi.Message{}}, want: updateEditedMessage},
+		{name: "channel post", update: &tgbotapi.Update{ChannelPost: &tgbotapi.Message{}}, want: updateChannelPost},
+		{name: "edited channel post", update: &tgbotapi.Update{EditedChannelPost: &tgbotapi.Message{}}, want: updateEditedChannelPost},
+		{name: "callback query", update: &tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{}}, want: updateCallbackQuery},
+		{name: "my chat member", update: &tgbotapi.Update{MyChatMember: &tgbotapi.ChatMemberUpdated{}}, want: updateMyChatMember},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, typeOfUpdate(tt.update))
+		})
+	}
+}
+
+func TestRouter_Handle(t *testing.T) {
+	r := newRouter()
+
+	r.Route(updateMessage, middleware.HandlerFunc(func(_ context.Context, u *tgbotapi.Update) (middleware.Response, error) {
+		return middleware.NewResponse(tgbotapi.NewMessage(u.Message.Chat.ID, "message")), nil
+	}))
+	r.Route(updateCallbackQuery, middleware.HandlerFunc(func(_ context.Context, u *tgbotapi.Update) (middleware.Response, error) {
+		return middleware.NewResponse(tgbotapi.NewCallback(u.CallbackQuery.ID, "callback")), nil
+	}))
+
+	resp, err := r.Handle(context.Background(), &tgbotapi.Update{Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 1}}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, middleware.NewResponse(tgbotapi.NewMessage(1, "message")), resp)
+
+	resp, err = r.Handle(context.Background(), &tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{ID: "query"}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, middleware.NewResponse(tgbotapi.NewCallback("query", "callback")), resp)
+
+	resp, err = r.Handle(context.Background(), &tgbotapi.Update{ChannelPost: &tgbotapi.Message{}})
+
+	assert.NoError(t, err)
+	assert.True(t, resp.Empty())
+}
@@ -9,6 +9,8 @@ import (
 
 	core "github.com/ksysoev/tg-feeder/pkg/core"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // MockService is an autogenerated mock type for the Service type
@@ -24,6 +26,473 @@ func (_m *MockService) EXPECT() *MockService_Expecter {
 	return &MockService_Expecter{mock: &_m.Mock}
 }
 
+// GetSubscription provides a mock function with given fields: ctx, chatID, urlOrID
+func (_m *MockService) GetSubscription(ctx context.Context, chatID int64, urlOrID string) (*core.Subscription, error) {
+	ret := _m.Called(ctx, chatID, urlOrID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSubscription")
+	}
+
+	var r0 *core.Subscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) (*core.Subscription, error)); ok {
+		return rf(ctx, chatID, urlOrID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) *core.Subscription); ok {
+		r0 = rf(ctx, chatID, urlOrID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Subscription)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, chatID, urlOrID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_GetSubscription_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSubscription'
+type MockService_GetSubscription_Call struct {
+	*mock.Call
+}
+
+// GetSubscription is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID int64
+//   - urlOrID string
+func (_e *MockService_Expecter) GetSubscription(ctx interface{}, chatID interface{}, urlOrID interface{}) *MockService_GetSubscription_Call {
+	return &MockService_GetSubscription_Call{Call: _e.mock.On("GetSubscription", ctx, chatID, urlOrID)}
+}
+
+func (_c *MockService_GetSubscription_Call) Run(run func(ctx context.Context, chatID int64, urlOrID string)) *MockService_GetSubscription_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_GetSubscription_Call) Return(_a0 *core.Subscription, _a1 error) *MockService_GetSubscription_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_GetSubscription_Call) RunAndReturn(run func(context.Context, int64, string) (*core.Subscription, error)) *MockService_GetSubscription_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Language provides a mock function with given fields: ctx, userID
+func (_m *MockService) Language(ctx context.Context, userID int64) (string, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Language")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (string, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) string); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_Language_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Language'
+type MockService_Language_Call struct {
+	*mock.Call
+}
+
+// Language is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+func (_e *MockService_Expecter) Language(ctx interface{}, userID interface{}) *MockService_Language_Call {
+	return &MockService_Language_Call{Call: _e.mock.On("Language", ctx, userID)}
+}
+
+func (_c *MockService_Language_Call) Run(run func(ctx context.Context, userID int64)) *MockService_Language_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockService_Language_Call) Return(_a0 string, _a1 error) *MockService_Language_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_Language_Call) RunAndReturn(run func(context.Context, int64) (string, error)) *MockService_Language_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListSubscriptions provides a mock function with given fields: ctx, chatID
+func (_m *MockService) ListSubscriptions(ctx context.Context, chatID int64) ([]core.FeedInfo, error) {
+	ret := _m.Called(ctx, chatID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSubscriptions")
+	}
+
+	var r0 []core.FeedInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]core.FeedInfo, error)); ok {
+		return rf(ctx, chatID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []core.FeedInfo); ok {
+		r0 = rf(ctx, chatID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]core.FeedInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, chatID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_ListSubscriptions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListSubscriptions'
+type MockService_ListSubscriptions_Call struct {
+	*mock.Call
+}
+
+// ListSubscriptions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID int64
+func (_e *MockService_Expecter) ListSubscriptions(ctx interface{}, chatID interface{}) *MockService_ListSubscriptions_Call {
+	return &MockService_ListSubscriptions_Call{Call: _e.mock.On("ListSubscriptions", ctx, chatID)}
+}
+
+func (_c *MockService_ListSubscriptions_Call) Run(run func(ctx context.Context, chatID int64)) *MockService_ListSubscriptions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockService_ListSubscriptions_Call) Return(_a0 []core.FeedInfo, _a1 error) *MockService_ListSubscriptions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_ListSubscriptions_Call) RunAndReturn(run func(context.Context, int64) ([]core.FeedInfo, error)) *MockService_ListSubscriptions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetFullText provides a mock function with given fields: ctx, chatID, urlOrID, enabled
+func (_m *MockService) SetFullText(ctx context.Context, chatID int64, urlOrID string, enabled bool) (*core.FeedInfo, error) {
+	ret := _m.Called(ctx, chatID, urlOrID, enabled)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetFullText")
+	}
+
+	var r0 *core.FeedInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, bool) (*core.FeedInfo, error)); ok {
+		return rf(ctx, chatID, urlOrID, enabled)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, bool) *core.FeedInfo); ok {
+		r0 = rf(ctx, chatID, urlOrID, enabled)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.FeedInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, bool) error); ok {
+		r1 = rf(ctx, chatID, urlOrID, enabled)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_SetFullText_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetFullText'
+type MockService_SetFullText_Call struct {
+	*mock.Call
+}
+
+// SetFullText is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID int64
+//   - urlOrID string
+//   - enabled bool
+func (_e *MockService_Expecter) SetFullText(ctx interface{}, chatID interface{}, urlOrID interface{}, enabled interface{}) *MockService_SetFullText_Call {
+	return &MockService_SetFullText_Call{Call: _e.mock.On("SetFullText", ctx, chatID, urlOrID, enabled)}
+}
+
+func (_c *MockService_SetFullText_Call) Run(run func(ctx context.Context, chatID int64, urlOrID string, enabled bool)) *MockService_SetFullText_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string), args[3].(bool))
+	})
+	return _c
+}
+
+func (_c *MockService_SetFullText_Call) Return(_a0 *core.FeedInfo, _a1 error) *MockService_SetFullText_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_SetFullText_Call) RunAndReturn(run func(context.Context, int64, string, bool) (*core.FeedInfo, error)) *MockService_SetFullText_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetInterval provides a mock function with given fields: ctx, chatID, urlOrID, interval
+func (_m *MockService) SetInterval(ctx context.Context, chatID int64, urlOrID string, interval time.Duration) (*core.FeedInfo, error) {
+	ret := _m.Called(ctx, chatID, urlOrID, interval)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetInterval")
+	}
+
+	var r0 *core.FeedInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, time.Duration) (*core.FeedInfo, error)); ok {
+		return rf(ctx, chatID, urlOrID, interval)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, time.Duration) *core.FeedInfo); ok {
+		r0 = rf(ctx, chatID, urlOrID, interval)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.FeedInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, time.Duration) error); ok {
+		r1 = rf(ctx, chatID, urlOrID, interval)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_SetInterval_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetInterval'
+type MockService_SetInterval_Call struct {
+	*mock.Call
+}
+
+// SetInterval is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID int64
+//   - urlOrID string
+//   - interval time.Duration
+func (_e *MockService_Expecter) SetInterval(ctx interface{}, chatID interface{}, urlOrID interface{}, interval interface{}) *MockService_SetInterval_Call {
+	return &MockService_SetInterval_Call{Call: _e.mock.On("SetInterval", ctx, chatID, urlOrID, interval)}
+}
+
+func (_c *MockService_SetInterval_Call) Run(run func(ctx context.Context, chatID int64, urlOrID string, interval time.Duration)) *MockService_SetInterval_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string), args[3].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockService_SetInterval_Call) Return(_a0 *core.FeedInfo, _a1 error) *MockService_SetInterval_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_SetInterval_Call) RunAndReturn(run func(context.Context, int64, string, time.Duration) (*core.FeedInfo, error)) *MockService_SetInterval_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetLanguage provides a mock function with given fields: ctx, userID, lang
+func (_m *MockService) SetLanguage(ctx context.Context, userID int64, lang string) error {
+	ret := _m.Called(ctx, userID, lang)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetLanguage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = rf(ctx, userID, lang)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockService_SetLanguage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetLanguage'
+type MockService_SetLanguage_Call struct {
+	*mock.Call
+}
+
+// SetLanguage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - lang string
+func (_e *MockService_Expecter) SetLanguage(ctx interface{}, userID interface{}, lang interface{}) *MockService_SetLanguage_Call {
+	return &MockService_SetLanguage_Call{Call: _e.mock.On("SetLanguage", ctx, userID, lang)}
+}
+
+func (_c *MockService_SetLanguage_Call) Run(run func(ctx context.Context, userID int64, lang string)) *MockService_SetLanguage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_SetLanguage_Call) Return(_a0 error) *MockService_SetLanguage_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockService_SetLanguage_Call) RunAndReturn(run func(context.Context, int64, string) error) *MockService_SetLanguage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Subscribe provides a mock function with given fields: ctx, chatID, url
+func (_m *MockService) Subscribe(ctx context.Context, chatID int64, url string) (*core.FeedInfo, error) {
+	ret := _m.Called(ctx, chatID, url)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Subscribe")
+	}
+
+	var r0 *core.FeedInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) (*core.FeedInfo, error)); ok {
+		return rf(ctx, chatID, url)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) *core.FeedInfo); ok {
+		r0 = rf(ctx, chatID, url)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.FeedInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, chatID, url)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_Subscribe_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Subscribe'
+type MockService_Subscribe_Call struct {
+	*mock.Call
+}
+
+// Subscribe is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID int64
+//   - url string
+func (_e *MockService_Expecter) Subscribe(ctx interface{}, chatID interface{}, url interface{}) *MockService_Subscribe_Call {
+	return &MockService_Subscribe_Call{Call: _e.mock.On("Subscribe", ctx, chatID, url)}
+}
+
+func (_c *MockService_Subscribe_Call) Run(run func(ctx context.Context, chatID int64, url string)) *MockService_Subscribe_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_Subscribe_Call) Return(_a0 *core.FeedInfo, _a1 error) *MockService_Subscribe_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_Subscribe_Call) RunAndReturn(run func(context.Context, int64, string) (*core.FeedInfo, error)) *MockService_Subscribe_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SubscribeTarget provides a mock function with given fields: ctx, ownerID, target, url
+func (_m *MockService) SubscribeTarget(ctx context.Context, ownerID int64, target core.Target, url string) (*core.FeedInfo, error) {
+	ret := _m.Called(ctx, ownerID, target, url)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SubscribeTarget")
+	}
+
+	var r0 *core.FeedInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, core.Target, string) (*core.FeedInfo, error)); ok {
+		return rf(ctx, ownerID, target, url)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, core.Target, string) *core.FeedInfo); ok {
+		r0 = rf(ctx, ownerID, target, url)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.FeedInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, core.Target, string) error); ok {
+		r1 = rf(ctx, ownerID, target, url)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_SubscribeTarget_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SubscribeTarget'
+type MockService_SubscribeTarget_Call struct {
+	*mock.Call
+}
+
+// SubscribeTarget is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ownerID int64
+//   - target core.Target
+//   - url string
+func (_e *MockService_Expecter) SubscribeTarget(ctx interface{}, ownerID interface{}, target interface{}, url interface{}) *MockService_SubscribeTarget_Call {
+	return &MockService_SubscribeTarget_Call{Call: _e.mock.On("SubscribeTarget", ctx, ownerID, target, url)}
+}
+
+func (_c *MockService_SubscribeTarget_Call) Run(run func(ctx context.Context, ownerID int64, target core.Target, url string)) *MockService_SubscribeTarget_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(core.Target), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_SubscribeTarget_Call) Return(_a0 *core.FeedInfo, _a1 error) *MockService_SubscribeTarget_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_SubscribeTarget_Call) RunAndReturn(run func(context.Context, int64, core.Target, string) (*core.FeedInfo, error)) *MockService_SubscribeTarget_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Summary provides a mock function with given fields: ctx, url
 func (_m *MockService) Summary(ctx context.Context, url string) (*core.Response, error) {
 	ret := _m.Called(ctx, url)
@@ -83,6 +552,174 @@ func (_c *MockService_Summary_Call) RunAndReturn(run func(context.Context, strin
 	return _c
 }
 
+// TouchUser provides a mock function with given fields: ctx, userID
+func (_m *MockService) TouchUser(ctx context.Context, userID int64) error {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TouchUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockService_TouchUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TouchUser'
+type MockService_TouchUser_Call struct {
+	*mock.Call
+}
+
+// TouchUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+func (_e *MockService_Expecter) TouchUser(ctx interface{}, userID interface{}) *MockService_TouchUser_Call {
+	return &MockService_TouchUser_Call{Call: _e.mock.On("TouchUser", ctx, userID)}
+}
+
+func (_c *MockService_TouchUser_Call) Run(run func(ctx context.Context, userID int64)) *MockService_TouchUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockService_TouchUser_Call) Return(_a0 error) *MockService_TouchUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockService_TouchUser_Call) RunAndReturn(run func(context.Context, int64) error) *MockService_TouchUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Unsubscribe provides a mock function with given fields: ctx, chatID, urlOrID
+func (_m *MockService) Unsubscribe(ctx context.Context, chatID int64, urlOrID string) (*core.FeedInfo, error) {
+	ret := _m.Called(ctx, chatID, urlOrID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Unsubscribe")
+	}
+
+	var r0 *core.FeedInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) (*core.FeedInfo, error)); ok {
+		return rf(ctx, chatID, urlOrID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) *core.FeedInfo); ok {
+		r0 = rf(ctx, chatID, urlOrID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.FeedInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, chatID, urlOrID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_Unsubscribe_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Unsubscribe'
+type MockService_Unsubscribe_Call struct {
+	*mock.Call
+}
+
+// Unsubscribe is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID int64
+//   - urlOrID string
+func (_e *MockService_Expecter) Unsubscribe(ctx interface{}, chatID interface{}, urlOrID interface{}) *MockService_Unsubscribe_Call {
+	return &MockService_Unsubscribe_Call{Call: _e.mock.On("Unsubscribe", ctx, chatID, urlOrID)}
+}
+
+func (_c *MockService_Unsubscribe_Call) Run(run func(ctx context.Context, chatID int64, urlOrID string)) *MockService_Unsubscribe_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_Unsubscribe_Call) Return(_a0 *core.FeedInfo, _a1 error) *MockService_Unsubscribe_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_Unsubscribe_Call) RunAndReturn(run func(context.Context, int64, string) (*core.FeedInfo, error)) *MockService_Unsubscribe_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateSubscriptionSettings provides a mock function with given fields: ctx, chatID, urlOrID, settings
+func (_m *MockService) UpdateSubscriptionSettings(ctx context.Context, chatID int64, urlOrID string, settings *core.SubscriptionSettings) (*core.Subscription, error) {
+	ret := _m.Called(ctx, chatID, urlOrID, settings)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSubscriptionSettings")
+	}
+
+	var r0 *core.Subscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, *core.SubscriptionSettings) (*core.Subscription, error)); ok {
+		return rf(ctx, chatID, urlOrID, settings)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, *core.SubscriptionSettings) *core.Subscription); ok {
+		r0 = rf(ctx, chatID, urlOrID, settings)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Subscription)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, *core.SubscriptionSettings) error); ok {
+		r1 = rf(ctx, chatID, urlOrID, settings)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockService_UpdateSubscriptionSettings_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateSubscriptionSettings'
+type MockService_UpdateSubscriptionSettings_Call struct {
+	*mock.Call
+}
+
+// UpdateSubscriptionSettings is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID int64
+//   - urlOrID string
+//   - settings *core.SubscriptionSettings
+func (_e *MockService_Expecter) UpdateSubscriptionSettings(ctx interface{}, chatID interface{}, urlOrID interface{}, settings interface{}) *MockService_UpdateSubscriptionSettings_Call {
+	return &MockService_UpdateSubscriptionSettings_Call{Call: _e.mock.On("UpdateSubscriptionSettings", ctx, chatID, urlOrID, settings)}
+}
+
+func (_c *MockService_UpdateSubscriptionSettings_Call) Run(run func(ctx context.Context, chatID int64, urlOrID string, settings *core.SubscriptionSettings)) *MockService_UpdateSubscriptionSettings_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string), args[3].(*core.SubscriptionSettings))
+	})
+	return _c
+}
+
+func (_c *MockService_UpdateSubscriptionSettings_Call) Return(_a0 *core.Subscription, _a1 error) *MockService_UpdateSubscriptionSettings_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockService_UpdateSubscriptionSettings_Call) RunAndReturn(run func(context.Context, int64, string, *core.SubscriptionSettings) (*core.Subscription, error)) *MockService_UpdateSubscriptionSettings_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockService creates a new instance of MockService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockService(t interface {
@@ -0,0 +1,229 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/ksysoev/tg-feeder/pkg/i18n"
+)
+
+const (
+	unsubscribeUsageMessage = "Usage: /unsubscribe <url|id>\n\nUse /list to see the ids of your subscriptions."
+	invalidURLMessage       = "❌ This doesn't look like a valid URL. Please send an http or https link."
+	invalidFeedMessage      = "❌ I couldn't load an RSS, Atom or JSON feed from this URL."
+	alreadySubscribedMsg    = "ℹ️ You are already subscribed to this feed."
+	notSubscribedMessage    = "ℹ️ You are not subscribed to this feed. Use /list to see your subscriptions."
+	noSubscriptionsMessage  = "You have no subscriptions yet. Use /subscribe <url> to add one."
+	fullTextUsageMessage    = "Usage: /fulltext <url|id> on|off\n\nWhen on, new items come with the full article instead of the feed excerpt."
+	filterUsageMessage      = "Usage: /filter <url|id> [keywords]\n\nOnly items matching the keywords are delivered. Omit the keywords to clear the filter."
+	timeLayout              = "2006-01-02 15:04 UTC"
+)
+
+// handleSubscribe subscribes the chat to the feed passed as the command argument.
+// Without an argument it starts a dialog asking for the feed and the keyword filter of the subscription.
+func (s *Bot) handleSubscribe(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	feedURL := strings.TrimSpace(msg.CommandArguments())
+	if feedURL == "" {
+		reply, err := s.dialogs.Start(ctx, dialogKey(msg), subscribeDialog)
+		if err != nil {
+			return tgbotapi.MessageConfig{}, fmt.Errorf("failed to start subscribe dialog: %w", err)
+		}
+
+		return newDialogMessage(ctx, msg.Chat.ID, reply), nil
+	}
+
+	text, err := s.subscribe(ctx, msg.Chat.ID, feedURL, "")
+	if err != nil {
+		return tgbotapi.MessageConfig{}, err
+	}
+
+	return newTextMessage(msg.Chat.ID, text), nil
+}
+
+// subscribe subscribes the chat to the feed, applies the keyword filter when not empty and returns the reply text.
+func (s *Bot) subscribe(ctx context.Context, chatID int64, feedURL, filter string) (string, error) {
+	p := i18n.FromContext(ctx)
+
+	feed, err := s.svc.Subscribe(ctx, chatID, feedURL)
+
+	switch {
+	case errors.Is(err, core.ErrInvalidURL):
+		return p.Text(invalidURLMessage), nil
+	case errors.Is(err, core.ErrInvalidFeed):
+		return p.Text(invalidFeedMessage), nil
+	case errors.Is(err, core.ErrAlreadySubscribed):
+		return p.Text(alreadySubscribedMsg), nil
+	case err != nil:
+		return "", fmt.Errorf("failed to subscribe: %w", err)
+	}
+
+	text := p.Sprintf("✅ Subscribed to %s\n\nid: %s", feed.Title, feed.ID)
+
+	if filter == "" {
+		return text, nil
+	}
+
+	sub, err := s.svc.GetSubscription(ctx, chatID, feed.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get subscription: %w", err)
+	}
+
+	settings := sub.Settings
+	settings.Filter = filter
+
+	if _, err := s.svc.UpdateSubscriptionSettings(ctx, chatID, feed.ID, &settings); err != nil {
+		return "", fmt.Errorf("failed to set filter: %w", err)
+	}
+
+	return text + "\n" + p.Sprintf("Filter: %s", filter), nil
+}
+
+// handleUnsubscribe removes the subscription identified by the URL or id passed as the command argument.
+func (s *Bot) handleUnsubscribe(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	p := i18n.FromContext(ctx)
+
+	arg := strings.TrimSpace(msg.CommandArguments())
+	if arg == "" {
+		return newTextMessage(msg.Chat.ID, p.Text(unsubscribeUsageMessage)), nil
+	}
+
+	feed, err := s.svc.Unsubscribe(ctx, msg.Chat.ID, arg)
+
+	switch {
+	case errors.Is(err, core.ErrInvalidURL):
+		return newTextMessage(msg.Chat.ID, p.Text(invalidURLMessage)), nil
+	case errors.Is(err, core.ErrNotSubscribed):
+		return newTextMessage(msg.Chat.ID, p.Text(notSubscribedMessage)), nil
+	case err != nil:
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to unsubscribe: %w", err)
+	}
+
+	return newTextMessage(msg.Chat.ID, p.Sprintf("🗑 Unsubscribed from %s", feed.Title)), nil
+}
+
+// handleFullText enables or disables fetching the full text of the items of a subscribed feed.
+func (s *Bot) handleFullText(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	p := i18n.FromContext(ctx)
+
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) != 2 {
+		return newTextMessage(msg.Chat.ID, p.Text(fullTextUsageMessage)), nil
+	}
+
+	var enabled bool
+
+	switch strings.ToLower(args[1]) {
+	case "on":
+		enabled = true
+	case "off":
+		enabled = false
+	default:
+		return newTextMessage(msg.Chat.ID, p.Text(fullTextUsageMessage)), nil
+	}
+
+	feed, err := s.svc.SetFullText(ctx, msg.Chat.ID, args[0], enabled)
+
+	switch {
+	case errors.Is(err, core.ErrInvalidURL):
+		return newTextMessage(msg.Chat.ID, p.Text(invalidURLMessage)), nil
+	case errors.Is(err, core.ErrNotSubscribed):
+		return newTextMessage(msg.Chat.ID, p.Text(notSubscribedMessage)), nil
+	case err != nil:
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to set full text: %w", err)
+	}
+
+	if enabled {
+		return newTextMessage(msg.Chat.ID, p.Sprintf("📰 Full text enabled for %s", feed.Title)), nil
+	}
+
+	return newTextMessage(msg.Chat.ID, p.Sprintf("📰 Full text disabled for %s", feed.Title)), nil
+}
+
+// handleFilter sets the keyword filter of a subscription to the command arguments following the feed,
+// clearing the filter when no keywords are given.
+func (s *Bot) handleFilter(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	p := i18n.FromContext(ctx)
+
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) == 0 {
+		return newTextMessage(msg.Chat.ID, p.Text(filterUsageMessage)), nil
+	}
+
+	sub, err := s.svc.GetSubscription(ctx, msg.Chat.ID, args[0])
+	if err == nil {
+		settings := sub.Settings
+		settings.Filter = strings.Join(args[1:], " ")
+
+		sub, err = s.svc.UpdateSubscriptionSettings(ctx, msg.Chat.ID, sub.Feed.ID, &settings)
+	}
+
+	switch {
+	case errors.Is(err, core.ErrInvalidURL):
+		return newTextMessage(msg.Chat.ID, p.Text(invalidURLMessage)), nil
+	case errors.Is(err, core.ErrNotSubscribed):
+		return newTextMessage(msg.Chat.ID, p.Text(notSubscribedMessage)), nil
+	case err != nil:
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to set filter: %w", err)
+	}
+
+	if sub.Settings.Filter == "" {
+		return newTextMessage(msg.Chat.ID, p.Sprintf("🔎 Filter cleared for %s", sub.Feed.Title)), nil
+	}
+
+	return newTextMessage(msg.Chat.ID, p.Sprintf("🔎 Filter for %s set to: %s", sub.Feed.Title, sub.Settings.Filter)), nil
+}
+
+// handleList replies with a numbered list of the chat subscriptions and buttons opening their settings.
+func (s *Bot) handleList(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	view, err := s.feedListView(ctx, msg.Chat.ID, nil)
+	if err != nil {
+		return tgbotapi.MessageConfig{}, err
+	}
+
+	if len(view.rows) == 0 {
+		return newTextMessage(msg.Chat.ID, view.text), nil
+	}
+
+	return s.newMenuMessage(msg, view)
+}
+
+// formatSubscriptions renders the subscriptions as a numbered list with the last item time and health state.
+func formatSubscriptions(p *i18n.Printer, feeds []core.FeedInfo) string {
+	return formatFeedList(p, p.Plural(len(feeds), "You are subscribed to %d feed:", "You are subscribed to %d feeds:", len(feeds)), feeds)
+}
+
+// formatFeedList renders the feeds under the header as a numbered list with the last item time and health state
+// in the language of the printer.
+func formatFeedList(p *i18n.Printer, header string, feeds []core.FeedInfo) string {
+	var sb strings.Builder
+
+	sb.WriteString(header + "\n")
+
+	for i := range feeds {
+		feed := &feeds[i]
+
+		lastItem := p.Text("never")
+		if !feed.LastItemAt.IsZero() {
+			lastItem = feed.LastItemAt.UTC().Format(timeLayout)
+		}
+
+		health := p.Text("✅ healthy")
+		if !feed.Healthy() {
+			health = p.Sprintf("⚠️ failing: %s", feed.LastError)
+		}
+
+		fmt.Fprintf(&sb, "\n%d. %s\n   %s\n   id: %s\n", i+1, feed.Title, feed.URL, feed.ID)
+		sb.WriteString("   " + p.Sprintf("Last item: %s", lastItem) + "\n")
+		sb.WriteString("   " + p.Sprintf("Status: %s", health) + "\n")
+
+		if feed.FullText {
+			sb.WriteString("   " + p.Text("Full text: on") + "\n")
+		}
+	}
+
+	return sb.String()
+}
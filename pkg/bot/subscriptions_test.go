@@ -0,0 +1,427 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/bot/callback"
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newCommandMessage(command, args string) *tgbotapi.Message {
+	text := "/" + command
+	if args != "" {
+		text += " " + args
+	}
+
+	return &tgbotapi.Message{
+		Text: text,
+		Entities: []tgbotapi.MessageEntity{
+			{
+				Type:   "bot_command",
+				Offset: 0,
+				Length: len(command) + 1,
+			},
+		},
+		Chat: &tgbotapi.Chat{ID: 123},
+		From: &tgbotapi.User{ID: 456},
+	}
+}
+
+func TestHandleSubscribe(t *testing.T) {
+	tests := []struct {
+		setupMocks func(svc *MockService)
+		name       string
+		args       string
+		wantText   string
+		wantErr    bool
+	}{
+		{
+			name: "success",
+			args: "https://example.com/rss",
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().Subscribe(mock.Anything, int64(123), "https://example.com/rss").
+					Return(&core.FeedInfo{ID: "abc", Title: "Example"}, nil)
+			},
+			wantText: "✅ Subscribed to Example\n\nid: abc",
+		},
+		{
+			name: "invalid url",
+			args: "nope",
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().Subscribe(mock.Anything, int64(123), "nope").Return(nil, core.ErrInvalidURL)
+			},
+			wantText: invalidURLMessage,
+		},
+		{
+			name: "invalid feed",
+			args: "https://example.com",
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().Subscribe(mock.Anything, int64(123), "https://example.com").
+					Return(nil, fmt.Errorf("%w: boom", core.ErrInvalidFeed))
+			},
+			wantText: invalidFeedMessage,
+		},
+		{
+			name: "already subscribed",
+			args: "https://example.com/rss",
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().Subscribe(mock.Anything, int64(123), "https://example.com/rss").Return(nil, core.ErrAlreadySubscribed)
+			},
+			wantText: alreadySubscribedMsg,
+		},
+		{
+			name: "unexpected error",
+			args: "https://example.com/rss",
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().Subscribe(mock.Anything, int64(123), "https://example.com/rss").Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMockService(t)
+			b := &Bot{svc: svc, tg: NewMocktgClient(t)}
+
+			tt.setupMocks(svc)
+
+			resp, err := b.handleCommand(context.Background(), newCommandMessage("subscribe", tt.args))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, resp.Text)
+		})
+	}
+}
+
+func TestHandleUnsubscribe(t *testing.T) {
+	tests := []struct {
+		setupMocks func(svc *MockService)
+		name       string
+		args       string
+		wantText   string
+		wantErr    bool
+	}{
+		{
+			name:       "missing argument",
+			setupMocks: func(_ *MockService) {},
+			wantText:   unsubscribeUsageMessage,
+		},
+		{
+			name: "success",
+			args: "abc",
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().Unsubscribe(mock.Anything, int64(123), "abc").Return(&core.FeedInfo{ID: "abc", Title: "Example"}, nil)
+			},
+			wantText: "🗑 Unsubscribed from Example",
+		},
+		{
+			name: "invalid url",
+			args: "https://",
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().Unsubscribe(mock.Anything, int64(123), "https://").Return(nil, core.ErrInvalidURL)
+			},
+			wantText: invalidURLMessage,
+		},
+		{
+			name: "not subscribed",
+			args: "abc",
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().Unsubscribe(mock.Anything, int64(123), "abc").Return(nil, core.ErrNotSubscribed)
+			},
+			wantText: notSubscribedMessage,
+		},
+		{
+			name: "unexpected error",
+			args: "abc",
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().Unsubscribe(mock.Anything, int64(123), "abc").Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMockService(t)
+			b := &Bot{svc: svc, tg: NewMocktgClient(t)}
+
+			tt.setupMocks(svc)
+
+			resp, err := b.handleCommand(context.Background(), newCommandMessage("unsubscribe", tt.args))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, resp.Text)
+		})
+	}
+}
+
+func TestHandleFullText(t *testing.T) {
+	tests := []struct {
+		setupMocks func(svc *MockService)
+		name       string
+		args       string
+		wantText   string
+		wantErr    bool
+	}{
+		{
+			name:       "missing arguments",
+			args:       "abc",
+			setupMocks: func(_ *MockService) {},
+			wantText:   fullTextUsageMessage,
+		},
+		{
+			name:       "invalid mode",
+			args:       "abc maybe",
+			setupMocks: func(_ *MockService) {},
+			wantText:   fullTextUsageMessage,
+		},
+		{
+			name: "enable",
+			args: "abc ON",
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().SetFullText(mock.Anything, int64(123), "abc", true).
+					Return(&core.FeedInfo{ID: "abc", Title: "Example", FullText: true}, nil)
+			},
+			wantText: "📰 Full text enabled for Example",
+		},
+		{
+			name: "disable",
+			args: "abc off",
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().SetFullText(mock.Anything, int64(123), "abc", false).
+					Return(&core.FeedInfo{ID: "abc", Title: "Example"}, nil)
+			},
+			wantText: "📰 Full text disabled for Example",
+		},
+		{
+			name: "invalid url",
+			args: "https:// on",
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().SetFullText(mock.Anything, int64(123), "https://", true).Return(nil, core.ErrInvalidURL)
+			},
+			wantText: invalidURLMessage,
+		},
+		{
+			name: "not subscribed",
+			args: "abc on",
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().SetFullText(mock.Anything, int64(123), "abc", true).Return(nil, core.ErrNotSubscribed)
+			},
+			wantText: notSubscribedMessage,
+		},
+		{
+			name: "unexpected error",
+			args: "abc on",
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().SetFullText(mock.Anything, int64(123), "abc", true).Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMockService(t)
+			b := &Bot{svc: svc, tg: NewMocktgClient(t)}
+
+			tt.setupMocks(svc)
+
+			resp, err := b.handleCommand(context.Background(), newCommandMessage("fulltext", tt.args))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, resp.Text)
+		})
+	}
+}
+
+func TestHandleList(t *testing.T) {
+	lastItem := time.Date(2024, 1, 2, 15, 4, 0, 0, time.UTC)
+
+	tests := []struct {
+		setupMocks  func(svc *MockService)
+		name        string
+		wantText    string
+		wantButtons []string
+		wantErr     bool
+	}{
+		{
+			name: "no subscriptions",
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().ListSubscriptions(mock.Anything, int64(123)).Return(nil, nil)
+			},
+			wantText: noSubscriptionsMessage,
+		},
+		{
+			name: "subscriptions",
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().ListSubscriptions(mock.Anything, int64(123)).Return([]core.FeedInfo{
+					{ID: "a", Title: "Alpha", URL: "https://a.example.com/rss", LastItemAt: lastItem},
+					{ID: "b", Title: "Beta", URL: "https://b.example.com/rss", LastError: "timeout", FullText: true},
+				}, nil)
+			},
+			wantText: "You are subscribed to 2 feeds:\n" +
+				"\n1. Alpha\n   https://a.example.com/rss\n   id: a\n   Last item: 2024-01-02 15:04 UTC\n   Status: ✅ healthy\n" +
+				"\n2. Beta\n   https://b.example.com/rss\n   id: b\n   Last item: never\n   Status: ⚠️ failing: timeout\n   Full text: on\n",
+			wantButtons: []string{"a", "b"},
+		},
+		{
+			name: "service error",
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().ListSubscriptions(mock.Anything, int64(123)).Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMockService(t)
+			b := &Bot{svc: svc, tg: NewMocktgClient(t), callbacks: callback.New("secret")}
+
+			tt.setupMocks(svc)
+
+			resp, err := b.handleCommand(context.Background(), newCommandMessage("list", ""))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, resp.Text)
+
+			if len(tt.wantButtons) == 0 {
+				assert.NotContains(t, fmt.Sprintf("%T", resp.ReplyMarkup), "InlineKeyboard")
+				return
+			}
+
+			markup, ok := resp.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+			require.True(t, ok)
+			require.Len(t, markup.InlineKeyboard, len(tt.wantButtons))
+
+			for i, feedID := range tt.wantButtons {
+				data, err := b.callbacks.Decode(123, 0, *markup.InlineKeyboard[i][0].CallbackData)
+				require.NoError(t, err)
+				assert.Equal(t, callback.Data{Menu: feedMenu, Action: feedActionOpen, Args: []string{feedID}}, data)
+			}
+		})
+	}
+}
+
+func TestHelpMessage(t *testing.T) {
+	help := (&Bot{}).helpMessage(nil)
+
+	assert.Contains(t, help, "/start - Show welcome message\n")
+	assert.Contains(t, help, "/subscribe [url] - Subscribe to an RSS, Atom or JSON feed\n")
+	assert.Contains(t, help, "/unsubscribe <url|id> - Unsubscribe from a feed\n")
+	assert.Contains(t, help, "/list - List your subscriptions\n")
+	assert.Contains(t, help, "/fulltext <url|id> on|off - Deliver full articles instead of feed excerpts\n")
+	assert.Contains(t, help, "/publish <@channel|chat id>[/topic id] [url] - Publish a feed to a channel or group\n")
+	assert.Contains(t, help, "/unpublish <@channel|chat id> <url|id> - Stop publishing a feed to a channel or group\n")
+	assert.Contains(t, help, "/filter <url|id> [keywords] - Deliver only items matching the keywords\n")
+	assert.Contains(t, help, "/summary <url> - Summarize an article\n")
+}
+
+func TestHandleFilter(t *testing.T) {
+	sub := &core.Subscription{
+		Feed:     core.FeedInfo{ID: "abc", Title: "Example"},
+		Settings: core.SubscriptionSettings{Preview: true, Filter: "old"},
+	}
+
+	tests := []struct {
+		setupMocks func(svc *MockService)
+		name       string
+		args       string
+		wantText   string
+		wantErr    bool
+	}{
+		{
+			name:       "missing feed",
+			setupMocks: func(_ *MockService) {},
+			wantText:   filterUsageMessage,
+		},
+		{
+			name: "set keywords",
+			args: "abc golang  generics",
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().GetSubscription(mock.Anything, int64(123), "abc").Return(sub, nil)
+				svc.EXPECT().UpdateSubscriptionSettings(mock.Anything, int64(123), "abc",
+					&core.SubscriptionSettings{Preview: true, Filter: "golang generics"}).
+					Return(&core.Subscription{Feed: sub.Feed, Settings: core.SubscriptionSettings{Filter: "golang generics"}}, nil)
+			},
+			wantText: "🔎 Filter for Example set to: golang generics",
+		},
+		{
+			name: "clear filter",
+			args: "abc",
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().GetSubscription(mock.Anything, int64(123), "abc").Return(sub, nil)
+				svc.EXPECT().UpdateSubscriptionSettings(mock.Anything, int64(123), "abc", &core.SubscriptionSettings{Preview: true}).
+					Return(&core.Subscription{Feed: sub.Feed}, nil)
+			},
+			wantText: "🔎 Filter cleared for Example",
+		},
+		{
+			name: "not subscribed",
+			args: "abc go",
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().GetSubscription(mock.Anything, int64(123), "abc").Return(nil, core.ErrNotSubscribed)
+			},
+			wantText: notSubscribedMessage,
+		},
+		{
+			name: "invalid url",
+			args: "ftp://x go",
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().GetSubscription(mock.Anything, int64(123), "ftp://x").Return(nil, core.ErrInvalidURL)
+			},
+			wantText: invalidURLMessage,
+		},
+		{
+			name: "service error",
+			args: "abc go",
+			setupMocks: func(svc *MockService) {
+				svc.EXPECT().GetSubscription(mock.Anything, int64(123), "abc").Return(sub, nil)
+				svc.EXPECT().UpdateSubscriptionSettings(mock.Anything, int64(123), "abc", mock.Anything).Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMockService(t)
+			b := &Bot{svc: svc, tg: NewMocktgClient(t)}
+
+			tt.setupMocks(svc)
+
+			resp, err := b.handleCommand(context.Background(), newCommandMessage("filter", tt.args))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, resp.Text)
+		})
+	}
+}
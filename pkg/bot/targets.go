@@ -0,0 +1,216 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/ksysoev/tg-feeder/pkg/i18n"
+)
+
+const (
+	publishUsageMessage = "Usage: /publish <@channel|chat id>[/topic id] [url]\n\n" +
+		"Publishes new items of the feed to a channel or group you administer. " +
+		"Add me to the chat as an administrator allowed to post messages first.\n\n" +
+		"Without a URL, lists the feeds published to the chat."
+	unpublishUsageMessage = "Usage: /unpublish <@channel|chat id> <url|id>"
+	invalidTargetMessage  = "❌ Send the @username or the id of a channel or group, optionally followed by /<topic id>."
+	targetNotFoundMessage = "❌ I can't find this chat. Add me to it as an administrator first."
+	privateTargetMessage  = "❌ Feeds can only be published to channels and groups."
+	topicTargetMessage    = "❌ Only supergroups have topics."
+	botNotAdminMessage    = "❌ I need to be an administrator of this chat allowed to post messages."
+	userNotAdminMessage   = "❌ Only administrators of the chat can manage the feeds published to it."
+	noUserMessage         = "❌ Send this command in a private chat with me."
+)
+
+// publishTarget is a channel or group feeds are published to, as resolved by Telegram.
+type publishTarget struct {
+	title  string
+	target core.Target
+}
+
+// handlePublish publishes a feed to a channel or group administered by the user, or lists the feeds
+// published there when no feed is given. The bot and the user have to be administrators of the chat.
+func (s *Bot) handlePublish(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	p := i18n.FromContext(ctx)
+
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) == 0 || len(args) > 2 {
+		return newTextMessage(msg.Chat.ID, p.Text(publishUsageMessage)), nil
+	}
+
+	target, refusal, err := s.resolveTarget(msg, args[0])
+	if err != nil {
+		return tgbotapi.MessageConfig{}, err
+	}
+
+	if refusal != "" {
+		return newTextMessage(msg.Chat.ID, p.Text(refusal)), nil
+	}
+
+	if len(args) == 1 {
+		return s.listPublished(ctx, msg.Chat.ID, target)
+	}
+
+	feed, err := s.svc.SubscribeTarget(ctx, msg.Chat.ID, target.target, args[1])
+
+	switch {
+	case errors.Is(err, core.ErrInvalidURL):
+		return newTextMessage(msg.Chat.ID, p.Text(invalidURLMessage)), nil
+	case errors.Is(err, core.ErrInvalidFeed):
+		return newTextMessage(msg.Chat.ID, p.Text(invalidFeedMessage)), nil
+	case err != nil:
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to publish feed: %w", err)
+	}
+
+	return newTextMessage(msg.Chat.ID, p.Sprintf("📣 New items of %s will be published to %s\n\nid: %s", feed.Title, target.title, feed.ID)), nil
+}
+
+// handleUnpublish stops publishing a feed to a channel or group administered by the user.
+func (s *Bot) handleUnpublish(ctx context.Context, msg *tgbotapi.Message) (tgbotapi.MessageConfig, error) {
+	p := i18n.FromContext(ctx)
+
+	args := strings.Fields(msg.CommandArguments())
+	if len(args) != 2 {
+		return newTextMessage(msg.Chat.ID, p.Text(unpublishUsageMessage)), nil
+	}
+
+	target, refusal, err := s.resolveTarget(msg, args[0])
+	if err != nil {
+		return tgbotapi.MessageConfig{}, err
+	}
+
+	if refusal != "" {
+		return newTextMessage(msg.Chat.ID, p.Text(refusal)), nil
+	}
+
+	feed, err := s.svc.Unsubscribe(ctx, target.target.ChatID, args[1])
+
+	switch {
+	case errors.Is(err, core.ErrInvalidURL):
+		return newTextMessage(msg.Chat.ID, p.Text(invalidURLMessage)), nil
+	case errors.Is(err, core.ErrNotSubscribed):
+		return newTextMessage(msg.Chat.ID, p.Sprintf("ℹ️ This feed is not published to %s.", target.title)), nil
+	case err != nil:
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to unpublish feed: %w", err)
+	}
+
+	return newTextMessage(msg.Chat.ID, p.Sprintf("🗑 Stopped publishing %s to %s", feed.Title, target.title)), nil
+}
+
+// listPublished replies with the feeds published to the target.
+func (s *Bot) listPublished(ctx context.Context, chatID int64, target *publishTarget) (tgbotapi.MessageConfig, error) {
+	feeds, err := s.svc.ListSubscriptions(ctx, target.target.ChatID)
+	if err != nil {
+		return tgbotapi.MessageConfig{}, fmt.Errorf("failed to list published feeds: %w", err)
+	}
+
+	p := i18n.FromContext(ctx)
+
+	if len(feeds) == 0 {
+		return newTextMessage(chatID, p.Sprintf("Nothing is published to %s yet.", target.title)), nil
+	}
+
+	header := p.Plural(len(feeds), "%d feed is published to %s:", "%d feeds are published to %s:", len(feeds), target.title)
+
+	return newTextMessage(chatID, formatFeedList(p, header, feeds)), nil
+}
+
+// resolveTarget resolves the chat referenced by the argument and checks that it can be managed by the sender
+// of the message: the chat has to be a channel or a group where both the bot and the sender are administrators,
+// the bot being allowed to post messages. It returns the untranslated message explaining why the chat cannot be used otherwise.
+func (s *Bot) resolveTarget(msg *tgbotapi.Message, arg string) (target *publishTarget, refusal string, err error) {
+	chatCfg, threadID, ok := parseTarget(arg)
+	if !ok {
+		return nil, invalidTargetMessage, nil
+	}
+
+	if msg.From == nil {
+		return nil, noUserMessage, nil
+	}
+
+	chat, err := s.tg.GetChat(tgbotapi.ChatInfoConfig{ChatConfig: chatCfg})
+	if err != nil {
+		return refusalOf(err, targetNotFoundMessage)
+	}
+
+	switch {
+	case chat.IsPrivate():
+		return nil, privateTargetMessage, nil
+	case threadID != 0 && !chat.IsSuperGroup():
+		return nil, topicTargetMessage, nil
+	}
+
+	botMember, err := s.tg.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: chat.ID, UserID: s.selfID},
+	})
+	if err != nil {
+		return refusalOf(err, botNotAdminMessage)
+	}
+
+	if !botMember.IsAdministrator() || (chat.IsChannel() && !botMember.CanPostMessages) {
+		return nil, botNotAdminMessage, nil
+	}
+
+	userMember, err := s.tg.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: chat.ID, UserID: msg.From.ID},
+	})
+	if err != nil {
+		return refusalOf(err, userNotAdminMessage)
+	}
+
+	if !userMember.IsCreator() && !userMember.IsAdministrator() {
+		return nil, userNotAdminMessage, nil
+	}
+
+	title := chat.Title
+	if chat.UserName != "" {
+		title = "@" + chat.UserName
+	}
+
+	if title == "" {
+		title = strconv.FormatInt(chat.ID, 10)
+	}
+
+	return &publishTarget{title: title, target: core.Target{ChatID: chat.ID, ThreadID: threadID}}, "", nil
+}
+
+// refusalOf returns the refusal for Telegram API errors, which mean that the chat or its member cannot be accessed
+// by the bot, and the error itself for other failures.
+func refusalOf(err error, refusal string) (*publishTarget, string, error) {
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) {
+		return nil, refusal, nil
+	}
+
+	return nil, "", fmt.Errorf("failed to check publishing target: %w", err)
+}
+
+// parseTarget parses a chat reference: an @username or a numeric chat id, optionally followed by /<topic id>.
+func parseTarget(arg string) (chat tgbotapi.ChatConfig, threadID int, ok bool) {
+	ref, thread, hasThread := strings.Cut(arg, "/")
+
+	if hasThread {
+		id, err := strconv.Atoi(thread)
+		if err != nil || id <= 0 {
+			return tgbotapi.ChatConfig{}, 0, false
+		}
+
+		threadID = id
+	}
+
+	if strings.HasPrefix(ref, "@") && len(ref) > 1 {
+		return tgbotapi.ChatConfig{SuperGroupUsername: ref}, threadID, true
+	}
+
+	id, err := strconv.ParseInt(ref, 10, 64)
+	if err != nil || id == 0 {
+		return tgbotapi.ChatConfig{}, 0, false
+	}
+
+	return tgbotapi.ChatConfig{ChatID: id}, threadID, true
+}
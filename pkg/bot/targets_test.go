@@ -0,0 +1,281 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testBotID = 999
+
+// expectTarget sets up the Telegram client to resolve the chat as administered by both the bot and the user.
+func expectTarget(tg *MocktgClient, chat tgbotapi.Chat) {
+	tg.EXPECT().GetChat(mock.Anything).Return(chat, nil)
+	tg.EXPECT().GetChatMember(mock.MatchedBy(func(cfg tgbotapi.GetChatMemberConfig) bool {
+		return cfg.UserID == testBotID
+	})).Return(tgbotapi.ChatMember{Status: "administrator", CanPostMessages: true}, nil)
+	tg.EXPECT().GetChatMember(mock.MatchedBy(func(cfg tgbotapi.GetChatMemberConfig) bool {
+		return cfg.UserID == 456
+	})).Return(tgbotapi.ChatMember{Status: "creator"}, nil)
+}
+
+func TestHandlePublish(t *testing.T) {
+	channel := tgbotapi.Chat{ID: -100123, Type: "channel", Title: "News", UserName: "news"}
+	forum := tgbotapi.Chat{ID: -100456, Type: "supergroup", Title: "Forum"}
+
+	tests := []struct {
+		setupMocks func(svc *MockService, tg *MocktgClient)
+		name       string
+		args       string
+		wantText   string
+		wantErr    bool
+	}{
+		{
+			name:       "missing arguments",
+			setupMocks: func(_ *MockService, _ *MocktgClient) {},
+			wantText:   publishUsageMessage,
+		},
+		{
+			name:       "invalid target",
+			args:       "news https://example.com/rss",
+			setupMocks: func(_ *MockService, _ *MocktgClient) {},
+			wantText:   invalidTargetMessage,
+		},
+		{
+			name: "channel",
+			args: "@news https://example.com/rss",
+			setupMocks: func(svc *MockService, tg *MocktgClient) {
+				expectTarget(tg, channel)
+				svc.EXPECT().SubscribeTarget(mock.Anything, int64(123), core.Target{ChatID: -100123}, "https://example.com/rss").
+					Return(&core.FeedInfo{ID: "abc", Title: "Example"}, nil)
+			},
+			wantText: "📣 New items of Example will be published to @news\n\nid: abc",
+		},
+		{
+			name: "forum topic",
+			args: "-100456/7 https://example.com/rss",
+			setupMocks: func(svc *MockService, tg *MocktgClient) {
+				expectTarget(tg, forum)
+				svc.EXPECT().SubscribeTarget(mock.Anything, int64(123), core.Target{ChatID: -100456, ThreadID: 7}, "https://example.com/rss").
+					Return(&core.FeedInfo{ID: "abc", Title: "Example"}, nil)
+			},
+			wantText: "📣 New items of Example will be published to Forum\n\nid: abc",
+		},
+		{
+			name: "list published feeds",
+			args: "@news",
+			setupMocks: func(svc *MockService, tg *MocktgClient) {
+				expectTarget(tg, channel)
+				svc.EXPECT().ListSubscriptions(mock.Anything, int64(-100123)).Return([]core.FeedInfo{{ID: "abc", Title: "Example"}}, nil)
+			},
+			wantText: formatFeedList(nil, "1 feed is published to @news:", []core.FeedInfo{{ID: "abc", Title: "Example"}}),
+		},
+		{
+			name: "nothing published",
+			args: "@news",
+			setupMocks: func(svc *MockService, tg *MocktgClient) {
+				expectTarget(tg, channel)
+				svc.EXPECT().ListSubscriptions(mock.Anything, int64(-100123)).Return(nil, nil)
+			},
+			wantText: "Nothing is published to @news yet.",
+		},
+		{
+			name: "invalid feed",
+			args: "@news https://example.com",
+			setupMocks: func(svc *MockService, tg *MocktgClient) {
+				expectTarget(tg, channel)
+				svc.EXPECT().SubscribeTarget(mock.Anything, int64(123), mock.Anything, "https://example.com").Return(nil, core.ErrInvalidFeed)
+			},
+			wantText: invalidFeedMessage,
+		},
+		{
+			name: "chat not found",
+			args: "@missing https://example.com/rss",
+			setupMocks: func(_ *MockService, tg *MocktgClient) {
+				tg.EXPECT().GetChat(mock.Anything).Return(tgbotapi.Chat{}, &tgbotapi.Error{Code: 400, Message: "Bad Request: chat not found"})
+			},
+			wantText: targetNotFoundMessage,
+		},
+		{
+			name: "private chat",
+			args: "42 https://example.com/rss",
+			setupMocks: func(_ *MockService, tg *MocktgClient) {
+				tg.EXPECT().GetChat(mock.Anything).Return(tgbotapi.Chat{ID: 42, Type: "private"}, nil)
+			},
+			wantText: privateTargetMessage,
+		},
+		{
+			name: "topic of a channel",
+			args: "@news/7 https://example.com/rss",
+			setupMocks: func(_ *MockService, tg *MocktgClient) {
+				tg.EXPECT().GetChat(mock.Anything).Return(channel, nil)
+			},
+			wantText: topicTargetMessage,
+		},
+		{
+			name: "bot cannot post",
+			args: "@news https://example.com/rss",
+			setupMocks: func(_ *MockService, tg *MocktgClient) {
+				tg.EXPECT().GetChat(mock.Anything).Return(channel, nil)
+				tg.EXPECT().GetChatMember(mock.Anything).Return(tgbotapi.ChatMember{Status: "administrator"}, nil)
+			},
+			wantText: botNotAdminMessage,
+		},
+		{
+			name: "user is not an administrator",
+			args: "@news https://example.com/rss",
+			setupMocks: func(_ *MockService, tg *MocktgClient) {
+				tg.EXPECT().GetChat(mock.Anything).Return(channel, nil)
+				tg.EXPECT().GetChatMember(mock.MatchedBy(func(cfg tgbotapi.GetChatMemberConfig) bool {
+					return cfg.UserID == testBotID
+				})).Return(tgbotapi.ChatMember{Status: "administrator", CanPostMessages: true}, nil)
+				tg.EXPECT().GetChatMember(mock.Anything).Return(tgbotapi.ChatMember{Status: "member"}, nil)
+			},
+			wantText: userNotAdminMessage,
+		},
+		{
+			name: "telegram unavailable",
+			args: "@news https://example.com/rss",
+			setupMocks: func(_ *MockService, tg *MocktgClient) {
+				tg.EXPECT().GetChat(mock.Anything).Return(tgbotapi.Chat{}, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "unexpected error",
+			args: "@news https://example.com/rss",
+			setupMocks: func(svc *MockService, tg *MocktgClient) {
+				expectTarget(tg, channel)
+				svc.EXPECT().SubscribeTarget(mock.Anything, int64(123), mock.Anything, mock.Anything).Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMockService(t)
+			tg := NewMocktgClient(t)
+			b := &Bot{svc: svc, tg: tg, selfID: testBotID}
+
+			tt.setupMocks(svc, tg)
+
+			resp, err := b.handleCommand(context.Background(), newCommandMessage("publish", tt.args))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, resp.Text)
+		})
+	}
+}
+
+func TestHandleUnpublish(t *testing.T) {
+	channel := tgbotapi.Chat{ID: -100123, Type: "channel", Title: "News"}
+
+	tests := []struct {
+		setupMocks func(svc *MockService, tg *MocktgClient)
+		name       string
+		args       string
+		wantText   string
+		wantErr    bool
+	}{
+		{
+			name:       "missing feed",
+			args:       "-100123",
+			setupMocks: func(_ *MockService, _ *MocktgClient) {},
+			wantText:   unpublishUsageMessage,
+		},
+		{
+			name: "success",
+			args: "-100123 abc",
+			setupMocks: func(svc *MockService, tg *MocktgClient) {
+				expectTarget(tg, channel)
+				svc.EXPECT().Unsubscribe(mock.Anything, int64(-100123), "abc").Return(&core.FeedInfo{ID: "abc", Title: "Example"}, nil)
+			},
+			wantText: "🗑 Stopped publishing Example to News",
+		},
+		{
+			name: "not published",
+			args: "-100123 abc",
+			setupMocks: func(svc *MockService, tg *MocktgClient) {
+				expectTarget(tg, channel)
+				svc.EXPECT().Unsubscribe(mock.Anything, int64(-100123), "abc").Return(nil, core.ErrNotSubscribed)
+			},
+			wantText: "ℹ️ This feed is not published to News.",
+		},
+		{
+			name: "bot is not a member",
+			args: "-100123 abc",
+			setupMocks: func(_ *MockService, tg *MocktgClient) {
+				tg.EXPECT().GetChat(mock.Anything).Return(channel, nil)
+				tg.EXPECT().GetChatMember(mock.Anything).Return(tgbotapi.ChatMember{}, &tgbotapi.Error{Code: 400, Message: "Bad Request: user not found"})
+			},
+			wantText: botNotAdminMessage,
+		},
+		{
+			name: "unexpected error",
+			args: "-100123 abc",
+			setupMocks: func(svc *MockService, tg *MocktgClient) {
+				expectTarget(tg, channel)
+				svc.EXPECT().Unsubscribe(mock.Anything, int64(-100123), "abc").Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewMockService(t)
+			tg := NewMocktgClient(t)
+			b := &Bot{svc: svc, tg: tg, selfID: testBotID}
+
+			tt.setupMocks(svc, tg)
+
+			resp, err := b.handleCommand(context.Background(), newCommandMessage("unpublish", tt.args))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, resp.Text)
+		})
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		name       string
+		arg        string
+		wantChat   tgbotapi.ChatConfig
+		wantThread int
+		wantOK     bool
+	}{
+		{name: "username", arg: "@news", wantChat: tgbotapi.ChatConfig{SuperGroupUsername: "@news"}, wantOK: true},
+		{name: "chat id", arg: "-100123", wantChat: tgbotapi.ChatConfig{ChatID: -100123}, wantOK: true},
+		{name: "topic", arg: "-100123/7", wantChat: tgbotapi.ChatConfig{ChatID: -100123}, wantThread: 7, wantOK: true},
+		{name: "bare at", arg: "@"},
+		{name: "username without at", arg: "news"},
+		{name: "zero id", arg: "0"},
+		{name: "invalid topic", arg: "-100123/abc"},
+		{name: "negative topic", arg: "-100123/-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chat, threadID, ok := parseTarget(tt.arg)
+
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantChat, chat)
+			assert.Equal(t, tt.wantThread, threadID)
+		})
+	}
+}
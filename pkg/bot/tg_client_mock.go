@@ -22,6 +22,118 @@ func (_m *MocktgClient) EXPECT() *MocktgClient_Expecter {
 	return &MocktgClient_Expecter{mock: &_m.Mock}
 }
 
+// GetChat provides a mock function with given fields: config
+func (_m *MocktgClient) GetChat(config tgbotapi.ChatInfoConfig) (tgbotapi.Chat, error) {
+	ret := _m.Called(config)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChat")
+	}
+
+	var r0 tgbotapi.Chat
+	var r1 error
+	if rf, ok := ret.Get(0).(func(tgbotapi.ChatInfoConfig) (tgbotapi.Chat, error)); ok {
+		return rf(config)
+	}
+	if rf, ok := ret.Get(0).(func(tgbotapi.ChatInfoConfig) tgbotapi.Chat); ok {
+		r0 = rf(config)
+	} else {
+		r0 = ret.Get(0).(tgbotapi.Chat)
+	}
+
+	if rf, ok := ret.Get(1).(func(tgbotapi.ChatInfoConfig) error); ok {
+		r1 = rf(config)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MocktgClient_GetChat_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetChat'
+type MocktgClient_GetChat_Call struct {
+	*mock.Call
+}
+
+// GetChat is a helper method to define mock.On call
+//   - config tgbotapi.ChatInfoConfig
+func (_e *MocktgClient_Expecter) GetChat(config interface{}) *MocktgClient_GetChat_Call {
+	return &MocktgClient_GetChat_Call{Call: _e.mock.On("GetChat", config)}
+}
+
+func (_c *MocktgClient_GetChat_Call) Run(run func(config tgbotapi.ChatInfoConfig)) *MocktgClient_GetChat_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(tgbotapi.ChatInfoConfig))
+	})
+	return _c
+}
+
+func (_c *MocktgClient_GetChat_Call) Return(_a0 tgbotapi.Chat, _a1 error) *MocktgClient_GetChat_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MocktgClient_GetChat_Call) RunAndReturn(run func(tgbotapi.ChatInfoConfig) (tgbotapi.Chat, error)) *MocktgClient_GetChat_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetChatMember provides a mock function with given fields: config
+func (_m *MocktgClient) GetChatMember(config tgbotapi.GetChatMemberConfig) (tgbotapi.ChatMember, error) {
+	ret := _m.Called(config)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChatMember")
+	}
+
+	var r0 tgbotapi.ChatMember
+	var r1 error
+	if rf, ok := ret.Get(0).(func(tgbotapi.GetChatMemberConfig) (tgbotapi.ChatMember, error)); ok {
+		return rf(config)
+	}
+	if rf, ok := ret.Get(0).(func(tgbotapi.GetChatMemberConfig) tgbotapi.ChatMember); ok {
+		r0 = rf(config)
+	} else {
+		r0 = ret.Get(0).(tgbotapi.ChatMember)
+	}
+
+	if rf, ok := ret.Get(1).(func(tgbotapi.GetChatMemberConfig) error); ok {
+		r1 = rf(config)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MocktgClient_GetChatMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetChatMember'
+type MocktgClient_GetChatMember_Call struct {
+	*mock.Call
+}
+
+// GetChatMember is a helper method to define mock.On call
+//   - config tgbotapi.GetChatMemberConfig
+func (_e *MocktgClient_Expecter) GetChatMember(config interface{}) *MocktgClient_GetChatMember_Call {
+	return &MocktgClient_GetChatMember_Call{Call: _e.mock.On("GetChatMember", config)}
+}
+
+func (_c *MocktgClient_GetChatMember_Call) Run(run func(config tgbotapi.GetChatMemberConfig)) *MocktgClient_GetChatMember_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(tgbotapi.GetChatMemberConfig))
+	})
+	return _c
+}
+
+func (_c *MocktgClient_GetChatMember_Call) Return(_a0 tgbotapi.ChatMember, _a1 error) *MocktgClient_GetChatMember_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MocktgClient_GetChatMember_Call) RunAndReturn(run func(tgbotapi.GetChatMemberConfig) (tgbotapi.ChatMember, error)) *MocktgClient_GetChatMember_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetUpdatesChan provides a mock function with given fields: config
 func (_m *MocktgClient) GetUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel {
 	ret := _m.Called(config)
@@ -70,6 +182,123 @@ func (_c *MocktgClient_GetUpdatesChan_Call) RunAndReturn(run func(tgbotapi.Updat
 	return _c
 }
 
+// MakeRequest provides a mock function with given fields: endpoint, params
+func (_m *MocktgClient) MakeRequest(endpoint string, params tgbotapi.Params) (*tgbotapi.APIResponse, error) {
+	ret := _m.Called(endpoint, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MakeRequest")
+	}
+
+	var r0 *tgbotapi.APIResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, tgbotapi.Params) (*tgbotapi.APIResponse, error)); ok {
+		return rf(endpoint, params)
+	}
+	if rf, ok := ret.Get(0).(func(string, tgbotapi.Params) *tgbotapi.APIResponse); ok {
+		r0 = rf(endpoint, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*tgbotapi.APIResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, tgbotapi.Params) error); ok {
+		r1 = rf(endpoint, params)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MocktgClient_MakeRequest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MakeRequest'
+type MocktgClient_MakeRequest_Call struct {
+	*mock.Call
+}
+
+// MakeRequest is a helper method to define mock.On call
+//   - endpoint string
+//   - params tgbotapi.Params
+func (_e *MocktgClient_Expecter) MakeRequest(endpoint interface{}, params interface{}) *MocktgClient_MakeRequest_Call {
+	return &MocktgClient_MakeRequest_Call{Call: _e.mock.On("MakeRequest", endpoint, params)}
+}
+
+func (_c *MocktgClient_MakeRequest_Call) Run(run func(endpoint string, params tgbotapi.Params)) *MocktgClient_MakeRequest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(tgbotapi.Params))
+	})
+	return _c
+}
+
+func (_c *MocktgClient_MakeRequest_Call) Return(_a0 *tgbotapi.APIResponse, _a1 error) *MocktgClient_MakeRequest_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MocktgClient_MakeRequest_Call) RunAndReturn(run func(string, tgbotapi.Params) (*tgbotapi.APIResponse, error)) *MocktgClient_MakeRequest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Request provides a mock function with given fields: c
+func (_m *MocktgClient) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	ret := _m.Called(c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Request")
+	}
+
+	var r0 *tgbotapi.APIResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(tgbotapi.Chattable) (*tgbotapi.APIResponse, error)); ok {
+		return rf(c)
+	}
+	if rf, ok := ret.Get(0).(func(tgbotapi.Chattable) *tgbotapi.APIResponse); ok {
+		r0 = rf(c)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*tgbotapi.APIResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(tgbotapi.Chattable) error); ok {
+		r1 = rf(c)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MocktgClient_Request_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Request'
+type MocktgClient_Request_Call struct {
+	*mock.Call
+}
+
+// Request is a helper method to define mock.On call
+//   - c tgbotapi.Chattable
+func (_e *MocktgClient_Expecter) Request(c interface{}) *MocktgClient_Request_Call {
+	return &MocktgClient_Request_Call{Call: _e.mock.On("Request", c)}
+}
+
+func (_c *MocktgClient_Request_Call) Run(run func(c tgbotapi.Chattable)) *MocktgClient_Request_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(tgbotapi.Chattable))
+	})
+	return _c
+}
+
+func (_c *MocktgClient_Request_Call) Return(_a0 *tgbotapi.APIResponse, _a1 error) *MocktgClient_Request_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MocktgClient_Request_Call) RunAndReturn(run func(tgbotapi.Chattable) (*tgbotapi.APIResponse, error)) *MocktgClient_Request_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Send provides a mock function with given fields: c
 func (_m *MocktgClient) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
 	ret := _m.Called(c)
@@ -0,0 +1,67 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/bot/middleware"
+	"github.com/ksysoev/tg-feeder/pkg/i18n"
+)
+
+const (
+	unknownActionMessage = "This button is no longer available."
+)
+
+// handleEditedMessage handles edited messages. Commands fixed by editing the message are handled as new ones,
+// other edits are ignored.
+func (s *Bot) handleEditedMessage(ctx context.Context, update *tgbotapi.Update) (middleware.Response, error) {
+	msg := update.EditedMessage
+
+	if msg.Command() == "" {
+		return middleware.Response{}, nil
+	}
+
+	slog.DebugContext(ctx, "Handling edited command", slog.Any("message", msg))
+
+	return s.respondToCommand(ctx, msg)
+}
+
+// handleChannelPost handles posts in channels where the bot is an administrator.
+// Commands manage the subscriptions of the channel, other posts are ignored.
+func (s *Bot) handleChannelPost(ctx context.Context, update *tgbotapi.Update) (middleware.Response, error) {
+	msg := update.ChannelPost
+
+	if msg.Command() == "" {
+		return middleware.Response{}, nil
+	}
+
+	slog.DebugContext(ctx, "Handling channel command", slog.Any("message", msg))
+
+	return s.respondToCommand(ctx, msg)
+}
+
+// handleMyChatMember handles changes of the bot membership in chats.
+// The bot greets groups it has been added to; being removed or blocked is only logged.
+func (s *Bot) handleMyChatMember(ctx context.Context, update *tgbotapi.Update) (middleware.Response, error) {
+	m := update.MyChatMember
+
+	slog.InfoContext(ctx, "Bot membership changed",
+		slog.Int64("chat_id", m.Chat.ID),
+		slog.String("chat_type", m.Chat.Type),
+		slog.String("old_status", m.OldChatMember.Status),
+		slog.String("new_status", m.NewChatMember.Status),
+	)
+
+	joined := !isChatMember(&m.OldChatMember) && isChatMember(&m.NewChatMember)
+	if !joined || (!m.Chat.IsGroup() && !m.Chat.IsSuperGroup()) {
+		return middleware.Response{}, nil
+	}
+
+	return middleware.NewResponse(newTextMessage(m.Chat.ID, i18n.FromContext(ctx).Text(welcomeMessage))), nil
+}
+
+// isChatMember reports whether the member is present in the chat.
+func isChatMember(m *tgbotapi.ChatMember) bool {
+	return !m.HasLeft() && !m.WasKicked()
+}
@@ -0,0 +1,77 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/ksysoev/tg-feeder/pkg/bot/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleEditedMessageAndChannelPost(t *testing.T) {
+	b := &Bot{svc: NewMockService(t), tg: NewMocktgClient(t)}
+
+	command := newCommandMessage("start", "")
+	text := &tgbotapi.Message{Text: "hello", Chat: &tgbotapi.Chat{ID: 123}}
+
+	resp, err := b.handleEditedMessage(context.Background(), &tgbotapi.Update{EditedMessage: command})
+
+	require.NoError(t, err)
+	assert.Equal(t, middleware.NewResponse(newTextMessage(command.Chat.ID, welcomeMessage)), resp)
+
+	resp, err = b.handleEditedMessage(context.Background(), &tgbotapi.Update{EditedMessage: text})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Empty())
+
+	resp, err = b.handleChannelPost(context.Background(), &tgbotapi.Update{ChannelPost: command})
+
+	require.NoError(t, err)
+	assert.Equal(t, middleware.NewResponse(newTextMessage(command.Chat.ID, welcomeMessage)), resp)
+
+	resp, err = b.handleChannelPost(context.Background(), &tgbotapi.Update{ChannelPost: text})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Empty())
+}
+
+func TestHandleMyChatMember(t *testing.T) {
+	tests := []struct {
+		name      string
+		chatType  string
+		oldStatus string
+		newStatus string
+		wantReply bool
+	}{
+		{name: "added to group", chatType: "group", oldStatus: "left", newStatus: "member", wantReply: true},
+		{name: "added to supergroup as admin", chatType: "supergroup", oldStatus: "kicked", newStatus: "administrator", wantReply: true},
+		{name: "promoted in group", chatType: "group", oldStatus: "member", newStatus: "administrator"},
+		{name: "removed from group", chatType: "group", oldStatus: "member", newStatus: "left"},
+		{name: "added to channel", chatType: "channel", oldStatus: "left", newStatus: "administrator"},
+		{name: "blocked in private chat", chatType: "private", oldStatus: "member", newStatus: "kicked"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Bot{svc: NewMockService(t), tg: NewMocktgClient(t)}
+
+			resp, err := b.handleMyChatMember(context.Background(), &tgbotapi.Update{
+				MyChatMember: &tgbotapi.ChatMemberUpdated{
+					Chat:          tgbotapi.Chat{ID: -100, Type: tt.chatType},
+					OldChatMember: tgbotapi.ChatMember{Status: tt.oldStatus},
+					NewChatMember: tgbotapi.ChatMember{Status: tt.newStatus},
+				},
+			})
+
+			require.NoError(t, err)
+
+			if tt.wantReply {
+				assert.Equal(t, middleware.NewResponse(newTextMessage(-100, welcomeMessage)), resp)
+			} else {
+				assert.True(t, resp.Empty())
+			}
+		})
+	}
+}
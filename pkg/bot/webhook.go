@@ -0,0 +1,155 @@
+package bot
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// ModePolling receives updates by long polling the Bot API.
+	ModePolling = "polling"
+	// ModeWebhook receives updates pushed by Telegram to the webhook URL.
+	ModeWebhook = "webhook"
+
+	maxUpdateSize     = 1 << 20
+	secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token" //nolint:gosec // header name, not a credential
+)
+
+// WebhookConfig holds the configuration of receiving updates through a webhook.
+// URL is the public address Telegram sends updates to; its path is served by the HTTP server of the application.
+// Secret is sent back by Telegram with every update, so requests that do not come from Telegram are rejected.
+// MaxConnections limits the number of simultaneous requests Telegram makes to the webhook.
+type WebhookConfig struct {
+	URL            string `mapstructure:"url"`
+	Secret         string `mapstructure:"secret"`
+	MaxConnections int    `mapstructure:"max_connections"`
+}
+
+// newWebhookConfig validates the webhook configuration.
+func newWebhookConfig(cfg WebhookConfig) (WebhookConfig, error) {
+	if cfg.URL == "" {
+		return cfg, fmt.Errorf("webhook url cannot be empty")
+	}
+
+	u, err := url.Parse(cfg.URL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return cfg, fmt.Errorf("webhook url must be an absolute https url: %q", cfg.URL)
+	}
+
+	if cfg.Secret == "" {
+		return cfg, fmt.Errorf("webhook secret cannot be empty")
+	}
+
+	return cfg, nil
+}
+
+// webhookPath returns the path of the webhook URL the updates are served on.
+func webhookPath(webhookURL string) string {
+	u, err := url.Parse(webhookURL)
+	if err != nil || u.Path == "" {
+		return "/"
+	}
+
+	return u.Path
+}
+
+// runPolling receives updates by long polling until the context is cancelled.
+// A webhook left registered by a previous run in webhook mode is removed first, since Telegram
+// does not serve polling requests while a webhook is set.
+func (s *Bot) runPolling(ctx context.Context) error {
+	if _, err := s.tg.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		slog.WarnContext(ctx, "Failed to delete webhook", slog.Any("error", err))
+	}
+
+	updateConfig := tgbotapi.NewUpdate(0)
+	updateConfig.Timeout = 30
+
+	s.serveUpdates(ctx, s.tg.GetUpdatesChan(updateConfig), s.tg.StopReceivingUpdates)
+
+	return nil
+}
+
+// runWebhook registers the webhook with Telegram and processes the updates pushed to it until the context
+// is cancelled. The updates are received by the webhook handler served by the HTTP server of the application.
+// The webhook stays registered on shutdown, so the updates keep going to the other replicas and are queued
+// by Telegram while none is running.
+func (s *Bot) runWebhook(ctx context.Context) error {
+	if err := s.setWebhook(); err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "Receiving updates through webhook", slog.String("path", webhookPath(s.webhook.URL)))
+
+	s.serveUpdates(ctx, s.updates, func() { close(s.closing) })
+
+	return nil
+}
+
+// Handlers returns the HTTP handlers of the bot keyed by their patterns, which have to be served
+// by the HTTP server of the application. Only the webhook mode has a handler.
+func (s *Bot) Handlers() map[string]http.Handler {
+	if s.mode != ModeWebhook {
+		return nil
+	}
+
+	return map[string]http.Handler{
+		"POST " + webhookPath(s.webhook.URL): s.webhookHandler(),
+	}
+}
+
+// setWebhook registers the webhook URL and secret with Telegram.
+// The request is made directly since the secret token is not supported by the Bot API library.
+func (s *Bot) setWebhook() error {
+	params := tgbotapi.Params{
+		"url":          s.webhook.URL,
+		"secret_token": s.webhook.Secret,
+	}
+
+	if s.webhook.MaxConnections > 0 {
+		params["max_connections"] = strconv.Itoa(s.webhook.MaxConnections)
+	}
+
+	if _, err := s.tg.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("failed to set webhook: %w", err)
+	}
+
+	return nil
+}
+
+// webhookHandler returns the HTTP handler that hands the updates pushed by Telegram over to the update loop.
+// Requests without the webhook secret are rejected. Once the bot is shutting down updates are refused
+// with a temporary error, so Telegram delivers them again later.
+func (s *Bot) webhookHandler() http.Handler {
+	secret := []byte(s.webhook.Secret)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(secretTokenHeader)), secret) != 1 {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		var update tgbotapi.Update
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUpdateSize)).Decode(&update); err != nil {
+			slog.WarnContext(r.Context(), "Failed to decode webhook update", slog.Any("error", err))
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+			return
+		}
+
+		select {
+		case s.updates <- update:
+			w.WriteHeader(http.StatusOK)
+		case <-s.closing:
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		case <-r.Context().Done():
+		}
+	})
+}
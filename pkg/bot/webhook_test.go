@@ -0,0 +1,232 @@
+package bot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWebhookConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     WebhookConfig
+		want    WebhookConfig
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			cfg:  WebhookConfig{URL: "https://bot.example.com/tg", Secret: "s3cret", MaxConnections: 10},
+			want: WebhookConfig{URL: "https://bot.example.com/tg", Secret: "s3cret", MaxConnections: 10},
+		},
+		{
+			name:    "empty url",
+			cfg:     WebhookConfig{Secret: "s3cret"},
+			wantErr: true,
+		},
+		{
+			name:    "plain http url",
+			cfg:     WebhookConfig{URL: "http://bot.example.com/tg", Secret: "s3cret"},
+			wantErr: true,
+		},
+		{
+			name:    "relative url",
+			cfg:     WebhookConfig{URL: "/tg", Secret: "s3cret"},
+			wantErr: true,
+		},
+		{
+			name:    "empty secret",
+			cfg:     WebhookConfig{URL: "https://bot.example.com/tg"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := newWebhookConfig(tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cfg)
+		})
+	}
+}
+
+func TestWebhookPath(t *testing.T) {
+	assert.Equal(t, "/tg/updates", webhookPath("https://bot.example.com/tg/updates"))
+	assert.Equal(t, "/", webhookPath("https://bot.example.com"))
+	assert.Equal(t, "/", webhookPath("://invalid"))
+}
+
+func TestSetWebhook(t *testing.T) {
+	tg := NewMocktgClient(t)
+	s := &Bot{tg: tg, webhook: WebhookConfig{URL: "https://bot.example.com/tg", Secret: "s3cret", MaxConnections: 20}}
+
+	tg.EXPECT().MakeRequest("setWebhook", tgbotapi.Params{
+		"url":             "https://bot.example.com/tg",
+		"secret_token":    "s3cret",
+		"max_connections": "20",
+	}).Return(&tgbotapi.APIResponse{Ok: true}, nil).Once()
+
+	require.NoError(t, s.setWebhook())
+
+	tg.EXPECT().MakeRequest("setWebhook", tgbotapi.Params{
+		"url":             "https://bot.example.com/tg",
+		"secret_token":    "s3cret",
+		"max_connections": "20",
+	}).Return(nil, assert.AnError).Once()
+
+	assert.ErrorIs(t, s.setWebhook(), assert.AnError)
+}
+
+func TestWebhookHandler(t *testing.T) {
+	const body = `{"update_id": 42, "message": {"message_id": 1, "text": "/start", "chat": {"id": 123}}}`
+
+	tests := []struct {
+		name       string
+		secret     string
+		body       string
+		wantStatus int
+		shutdown   bool
+		wantUpdate bool
+	}{
+		{
+			name:       "update is handed over",
+			secret:     "s3cret",
+			body:       body,
+			wantStatus: http.StatusOK,
+			wantUpdate: true,
+		},
+		{
+			name:       "missing secret",
+			body:       body,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "wrong secret",
+			secret:     "guess",
+			body:       body,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "malformed update",
+			secret:     "s3cret",
+			body:       "{",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "shutting down",
+			secret:     "s3cret",
+			body:       body,
+			shutdown:   true,
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updates := make(chan tgbotapi.Update, 1)
+			s := &Bot{webhook: WebhookConfig{Secret: "s3cret"}, updates: updates, closing: make(chan struct{})}
+
+			if tt.shutdown {
+				// Nobody reads the updates once the update loop has stopped.
+				s.updates = make(chan tgbotapi.Update)
+
+				close(s.closing)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/tg", strings.NewReader(tt.body))
+			if tt.secret != "" {
+				req.Header.Set(secretTokenHeader, tt.secret)
+			}
+
+			rec := httptest.NewRecorder()
+
+			s.webhookHandler().ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			if !tt.wantUpdate {
+				assert.Empty(t, updates)
+				return
+			}
+
+			require.Len(t, updates, 1)
+
+			update := <-updates
+			assert.Equal(t, 42, update.UpdateID)
+			assert.Equal(t, "/start", update.Message.Text)
+		})
+	}
+}
+
+func TestRunPolling(t *testing.T) {
+	tg := NewMocktgClient(t)
+	s := &Bot{tg: tg, mode: ModePolling}
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	tg.EXPECT().Request(tgbotapi.DeleteWebhookConfig{}).Return(nil, assert.AnError)
+	tg.EXPECT().GetUpdatesChan(tgbotapi.UpdateConfig{Timeout: 30}).
+		RunAndReturn(func(tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel {
+			cancel()
+			return make(chan tgbotapi.Update)
+		})
+	tg.EXPECT().StopReceivingUpdates().Return()
+
+	require.NoError(t, s.Run(ctx))
+}
+
+func TestRunWebhook(t *testing.T) {
+	webhook := WebhookConfig{URL: "https://bot.example.com/tg", Secret: "s3cret"}
+
+	t.Run("serves until cancelled", func(t *testing.T) {
+		tg := NewMocktgClient(t)
+		s := &Bot{tg: tg, mode: ModeWebhook, webhook: webhook, updates: make(chan tgbotapi.Update), closing: make(chan struct{})}
+
+		ctx, cancel := context.WithCancel(t.Context())
+
+		tg.EXPECT().MakeRequest("setWebhook", tgbotapi.Params{"url": webhook.URL, "secret_token": webhook.Secret}).
+			RunAndReturn(func(string, tgbotapi.Params) (*tgbotapi.APIResponse, error) {
+				cancel()
+				return &tgbotapi.APIResponse{Ok: true}, nil
+			})
+
+		require.NoError(t, s.Run(ctx))
+
+		select {
+		case <-s.closing:
+		default:
+			t.Error("webhook handler should refuse updates after shutdown")
+		}
+	})
+
+	t.Run("failed to set webhook", func(t *testing.T) {
+		tg := NewMocktgClient(t)
+		s := &Bot{tg: tg, mode: ModeWebhook, webhook: webhook}
+
+		tg.EXPECT().MakeRequest("setWebhook", tgbotapi.Params{"url": webhook.URL, "secret_token": webhook.Secret}).
+			Return(nil, assert.AnError)
+
+		assert.ErrorIs(t, s.Run(t.Context()), assert.AnError)
+	})
+}
+
+func TestHandlers(t *testing.T) {
+	s := &Bot{mode: ModePolling}
+	assert.Empty(t, s.Handlers())
+
+	s = &Bot{mode: ModeWebhook, webhook: WebhookConfig{URL: "https://bot.example.com/tg/updates"}}
+
+	handlers := s.Handlers()
+	require.Len(t, handlers, 1)
+	assert.Contains(t, handlers, "POST /tg/updates")
+}
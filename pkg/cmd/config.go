@@ -5,15 +5,27 @@ import (
 	"log/slog"
 	"strings"
 
+	"github.com/ksysoev/tg-feeder/pkg/api"
 	"github.com/ksysoev/tg-feeder/pkg/bot"
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/ksysoev/tg-feeder/pkg/prov/extract"
+	"github.com/ksysoev/tg-feeder/pkg/prov/feed"
+	"github.com/ksysoev/tg-feeder/pkg/prov/llm"
 	"github.com/ksysoev/tg-feeder/pkg/prov/someapi"
+	"github.com/ksysoev/tg-feeder/pkg/repo/dedup"
+	"github.com/ksysoev/tg-feeder/pkg/repo/dialog"
+	"github.com/ksysoev/tg-feeder/pkg/repo/outbox"
 	"github.com/spf13/viper"
 )
 
 type appConfig struct {
-	Bot      bot.Config  `mapstructure:"bot"`
-	Redis    RedisConfig `mapstructure:"redis"`
-	Provider Provider    `mapstructure:"provider"`
+	Tracing  TracingConfig `mapstructure:"tracing"`
+	HTTP     api.Config    `mapstructure:"http"`
+	Redis    RedisConfig   `mapstructure:"redis"`
+	Provider Provider      `mapstructure:"provider"`
+	Repo     Repo          `mapstructure:"repo"`
+	Bot      bot.Config    `mapstructure:"bot"`
+	Core     core.Config   `mapstructure:"core"`
 }
 
 type RedisConfig struct {
@@ -21,8 +33,17 @@ type RedisConfig struct {
 	Password string `mapstructure:"password"`
 }
 
+type Repo struct {
+	Outbox outbox.Config `mapstructure:"outbox"`
+	Dedup  dedup.Config  `mapstructure:"dedup"`
+	Dialog dialog.Config `mapstructure:"dialog"`
+}
+
 type Provider struct {
 	SomeAPI someapi.Config `mapstructure:"some_api"`
+	LLM     llm.Config     `mapstructure:"llm"`
+	Feed    feed.Config    `mapstructure:"feed"`
+	Extract extract.Config `mapstructure:"extract"`
 }
 
 // loadConfig loads the application configuration from the specified file path and environment variables.
@@ -4,6 +4,9 @@ import (
 	"context"
 	"log/slog"
 	"os"
+
+	"github.com/ksysoev/tg-feeder/pkg/reqctx"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ContextHandler is a custom slog.Handler that enriches log records with application-specific attributes.
@@ -15,16 +18,90 @@ type ContextHandler struct {
 }
 
 // Handle processes a log record by enriching it with context and application-specific attributes.
-// It adds attributes such as "req_id" from the context, "app", and "ver" before delegating to the embedded handler.
+// It adds the "app" and "ver" attributes, the "trace_id" and "span_id" of the span from the context for records
+// emitted within a span, and the request-scoped "req_id", "update_id", "chat_id", "user_id", "command" and "feed_id"
+// stored in the context, before delegating to the embedded handler.
+// Request-scoped attributes already set on the record are kept as they are.
 // Returns error if the embedded handler fails.
 
 //nolint:gocritic // ignore this linting rule
 func (h ContextHandler) Handle(ctx context.Context, r slog.Record) error {
 	r.AddAttrs(slog.String("app", h.app), slog.String("ver", h.ver))
 
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+
+	attrs := requestAttrs(ctx)
+	if len(attrs) == 0 {
+		return h.Handler.Handle(ctx, r)
+	}
+
+	present := make(map[string]struct{}, r.NumAttrs())
+
+	r.Attrs(func(a slog.Attr) bool {
+		present[a.Key] = struct{}{}
+		return true
+	})
+
+	for _, a := range attrs {
+		if _, ok := present[a.Key]; !ok {
+			r.AddAttrs(a)
+		}
+	}
+
 	return h.Handler.Handle(ctx, r)
 }
 
+// WithAttrs returns a ContextHandler whose embedded handler has the given attributes,
+// so loggers derived with slog.With keep enriching their records.
+func (h ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h.Handler = h.Handler.WithAttrs(attrs)
+	return h
+}
+
+// WithGroup returns a ContextHandler whose embedded handler qualifies the following attributes with the group name,
+// so loggers derived with slog.WithGroup keep enriching their records.
+func (h ContextHandler) WithGroup(name string) slog.Handler {
+	h.Handler = h.Handler.WithGroup(name)
+	return h
+}
+
+// requestAttrs returns the log attributes of the request-scoped values stored in the context.
+func requestAttrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+
+	var attrs []slog.Attr
+
+	if id, ok := reqctx.RequestID(ctx); ok {
+		attrs = append(attrs, slog.String("req_id", id))
+	}
+
+	if id, ok := reqctx.UpdateID(ctx); ok {
+		attrs = append(attrs, slog.Int("update_id", id))
+	}
+
+	if id, ok := reqctx.ChatID(ctx); ok {
+		attrs = append(attrs, slog.Int64("chat_id", id))
+	}
+
+	if id, ok := reqctx.UserID(ctx); ok {
+		attrs = append(attrs, slog.Int64("user_id", id))
+	}
+
+	if name, ok := reqctx.Command(ctx); ok {
+		attrs = append(attrs, slog.String("command", name))
+	}
+
+	if id, ok := reqctx.FeedID(ctx); ok {
+		attrs = append(attrs, slog.String("feed_id", id))
+	}
+
+	return attrs
+}
+
 // initLogger initializes the default logger for the application using slog.
 // It does not take any parameters.
 // It returns an error if the logger initialization fails, although in this implementation, it always returns nil.
@@ -1,9 +1,16 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
 	"testing"
 
+	"github.com/ksysoev/tg-feeder/pkg/reqctx"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestInitLogger(t *testing.T) {
@@ -56,3 +63,106 @@ func TestInitLogger(t *testing.T) {
 		})
 	}
 }
+
+func TestContextHandler_Handle(t *testing.T) {
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x01, 0x02, 0x03},
+		SpanID:  trace.SpanID{0x04, 0x05},
+	})
+
+	reqCtx := reqctx.WithRequestID(context.Background(), "req")
+	reqCtx = reqctx.WithUpdateID(reqCtx, 7)
+	reqCtx = reqctx.WithChatID(reqCtx, 42)
+	reqCtx = reqctx.WithUserID(reqCtx, 43)
+	reqCtx = reqctx.WithCommand(reqCtx, "start")
+	reqCtx = reqctx.WithFeedID(reqCtx, "feed")
+
+	tests := []struct {
+		ctx   context.Context
+		want  map[string]any
+		name  string
+		attrs []any
+	}{
+		{
+			name: "without span",
+			ctx:  context.Background(),
+			want: map[string]any{"app": "test-app", "ver": "1.0.0"},
+		},
+		{
+			name: "within span",
+			ctx:  trace.ContextWithSpanContext(context.Background(), spanCtx),
+			want: map[string]any{
+				"app":      "test-app",
+				"ver":      "1.0.0",
+				"trace_id": spanCtx.TraceID().String(),
+				"span_id":  spanCtx.SpanID().String(),
+			},
+		},
+		{
+			name: "with request values",
+			ctx:  reqCtx,
+			want: map[string]any{
+				"app":       "test-app",
+				"ver":       "1.0.0",
+				"req_id":    "req",
+				"update_id": float64(7),
+				"chat_id":   float64(42),
+				"user_id":   float64(43),
+				"command":   "start",
+				"feed_id":   "feed",
+			},
+		},
+		{
+			name:  "record attributes take precedence",
+			ctx:   reqctx.WithFeedID(reqctx.WithChatID(context.Background(), 42), "feed"),
+			attrs: []any{slog.String("feed_id", "other")},
+			want: map[string]any{
+				"app":     "test-app",
+				"ver":     "1.0.0",
+				"chat_id": float64(42),
+				"feed_id": "other",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			logger := slog.New(ContextHandler{
+				Handler: slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+					ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+						if a.Key == slog.TimeKey || a.Key == slog.LevelKey || a.Key == slog.MessageKey {
+							return slog.Attr{}
+						}
+
+						return a
+					},
+				}),
+				app: "test-app",
+				ver: "1.0.0",
+			})
+
+			logger.InfoContext(tt.ctx, "test", tt.attrs...)
+
+			var got map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestContextHandler_WithAttrs(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := slog.New(ContextHandler{Handler: slog.NewJSONHandler(&buf, nil), app: "test-app", ver: "1.0.0"})
+
+	logger.With(slog.String("link", "https://example.com")).WithGroup("group").
+		InfoContext(reqctx.WithChatID(t.Context(), 42), "test")
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+
+	assert.Equal(t, "https://example.com", got["link"])
+	assert.Equal(t, map[string]any{"app": "test-app", "ver": "1.0.0", "chat_id": float64(42)}, got["group"])
+}
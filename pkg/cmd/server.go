@@ -3,16 +3,29 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"time"
 
+	"github.com/ksysoev/tg-feeder/pkg/api"
 	"github.com/ksysoev/tg-feeder/pkg/bot"
 	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/ksysoev/tg-feeder/pkg/prov/extract"
+	"github.com/ksysoev/tg-feeder/pkg/prov/feed"
+	"github.com/ksysoev/tg-feeder/pkg/prov/llm"
 	"github.com/ksysoev/tg-feeder/pkg/prov/someapi"
+	"github.com/ksysoev/tg-feeder/pkg/repo/dedup"
+	"github.com/ksysoev/tg-feeder/pkg/repo/dialog"
+	"github.com/ksysoev/tg-feeder/pkg/repo/outbox"
 	"github.com/ksysoev/tg-feeder/pkg/repo/user"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/errgroup"
 )
 
-// RunCommand initializes the logger, loads configuration, creates the core and API services,
-// and starts the API service. It returns an error if any step fails.
+const tracingShutdownTimeout = 5 * time.Second
+
+// RunCommand initializes the logger, loads configuration, sets up tracing, creates the core and API services,
+// and starts the API service alongside the feed scheduler and the HTTP server. It returns an error if any step fails.
 func RunCommand(ctx context.Context, flags *cmdFlags) error {
 	if err := initLogger(flags); err != nil {
 		return fmt.Errorf("failed to init logger: %w", err)
@@ -23,24 +36,80 @@ func RunCommand(ctx context.Context, flags *cmdFlags) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	shutdownTracing, err := initTracing(ctx, cfg.Tracing, flags)
+	if err != nil {
+		return fmt.Errorf("failed to init tracing: %w", err)
+	}
+
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), tracingShutdownTimeout)
+		defer cancel()
+
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			slog.Warn("Failed to shut down tracing", slog.Any("error", err))
+		}
+	}()
+
 	rdb := redis.NewClient(&redis.Options{
 		Addr:     cfg.Redis.Addr,
 		Password: cfg.Redis.Password,
 	})
 
+	if err := redisotel.InstrumentTracing(rdb); err != nil {
+		return fmt.Errorf("failed to instrument redis client: %w", err)
+	}
+
 	someAPI := someapi.New(cfg.Provider.SomeAPI)
+	feeds := feed.New(cfg.Provider.Feed)
 	userRepo := user.New(rdb)
-	svc := core.New(userRepo, someAPI)
+	seenStore := dedup.New(cfg.Repo.Dedup, rdb)
+	articles := extract.New(cfg.Provider.Extract)
+	dialogs := dialog.New(cfg.Repo.Dialog, rdb)
+	posts := outbox.New(cfg.Repo.Outbox, rdb)
 
-	tgBot, err := bot.New(&cfg.Bot, svc)
+	summarizer, err := llm.New(cfg.Provider.LLM)
 	if err != nil {
-		return fmt.Errorf("failed to create API service: %w", err)
+		return fmt.Errorf("failed to create summarizer: %w", err)
 	}
 
-	err = tgBot.Run(ctx)
+	svc := core.New(&cfg.Core, userRepo, someAPI, feeds, userRepo, seenStore, articles, summarizer, posts)
+
+	tgBot, err := bot.New(&cfg.Bot, svc, dialogs)
 	if err != nil {
-		return fmt.Errorf("failed to run API service: %w", err)
+		return fmt.Errorf("failed to create API service: %w", err)
+	}
+
+	srv := api.New(cfg.HTTP, svc, api.BuildInfo{AppName: flags.appName, Version: flags.version})
+
+	for pattern, handler := range tgBot.Handlers() {
+		srv.Handle(pattern, handler)
 	}
 
-	return nil
+	eg, ctx := errgroup.WithContext(ctx)
+
+	eg.Go(func() error {
+		if err := srv.Run(ctx); err != nil {
+			return fmt.Errorf("failed to run HTTP server: %w", err)
+		}
+
+		return nil
+	})
+
+	eg.Go(func() error {
+		if err := tgBot.Run(ctx); err != nil {
+			return fmt.Errorf("failed to run API service: %w", err)
+		}
+
+		return nil
+	})
+
+	eg.Go(func() error {
+		if err := svc.Run(ctx, tgBot); err != nil {
+			return fmt.Errorf("failed to run feed scheduler: %w", err)
+		}
+
+		return nil
+	})
+
+	return eg.Wait()
 }
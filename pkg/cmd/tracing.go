@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+const defaultSampleRatio = 1.0
+
+// TracingConfig holds the configuration of the OpenTelemetry tracing.
+// Endpoint is the host and port of the OTLP/HTTP collector the spans are exported to; when empty, tracing is
+// disabled and spans are not recorded, which suits offline runs. SampleRatio is the fraction of the traces
+// started by the application that are sampled, all of them by default.
+type TracingConfig struct {
+	Endpoint    string  `mapstructure:"endpoint"`
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+	Insecure    bool    `mapstructure:"insecure"`
+}
+
+// initTracing installs the global tracer provider exporting the spans to the configured OTLP endpoint
+// and the W3C trace context propagator used by the outbound HTTP requests.
+// It returns the function flushing the pending spans and shutting the provider down, or an error if the exporter
+// cannot be created. Without an endpoint the no-op tracer provider is kept.
+func initTracing(ctx context.Context, cfg TracingConfig, flags *cmdFlags) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if cfg.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	if cfg.SampleRatio <= 0 {
+		cfg.SampleRatio = defaultSampleRatio
+	}
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(flags.appName),
+		semconv.ServiceVersion(flags.version),
+	)
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
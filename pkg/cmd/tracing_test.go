@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestInitTracing(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          TracingConfig
+		wantProvider bool
+	}{
+		{
+			name:         "no endpoint keeps no-op provider",
+			cfg:          TracingConfig{},
+			wantProvider: false,
+		},
+		{
+			name:         "endpoint installs SDK provider",
+			cfg:          TracingConfig{Endpoint: "localhost:4318", Insecure: true, SampleRatio: 0.5},
+			wantProvider: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev := otel.GetTracerProvider()
+			t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+			shutdown, err := initTracing(t.Context(), tt.cfg, &cmdFlags{appName: "test-app", version: "1.0.0"})
+			require.NoError(t, err)
+
+			_, isSDK := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+			assert.Equal(t, tt.wantProvider, isSDK)
+			assert.Contains(t, otel.GetTextMapPropagator().Fields(), "traceparent")
+
+			assert.NoError(t, shutdown(t.Context()))
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Code generated by mockery. DO NOT EDIT.
+
+//go:build !compile
+
+package core
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockarticleProv is an autogenerated mock type for the articleProv type
+type MockarticleProv struct {
+	mock.Mock
+}
+
+type MockarticleProv_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockarticleProv) EXPECT() *MockarticleProv_Expecter {
+	return &MockarticleProv_Expecter{mock: &_m.Mock}
+}
+
+// Extract provides a mock function with given fields: ctx, url
+func (_m *MockarticleProv) Extract(ctx context.Context, url string) (*Article, error) {
+	ret := _m.Called(ctx, url)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Extract")
+	}
+
+	var r0 *Article
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*Article, error)); ok {
+		return rf(ctx, url)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *Article); ok {
+		r0 = rf(ctx, url)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Article)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, url)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockarticleProv_Extract_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Extract'
+type MockarticleProv_Extract_Call struct {
+	*mock.Call
+}
+
+// Extract is a helper method to define mock.On call
+//   - ctx context.Context
+//   - url string
+func (_e *MockarticleProv_Expecter) Extract(ctx interface{}, url interface{}) *MockarticleProv_Extract_Call {
+	return &MockarticleProv_Extract_Call{Call: _e.mock.On("Extract", ctx, url)}
+}
+
+func (_c *MockarticleProv_Extract_Call) Run(run func(ctx context.Context, url string)) *MockarticleProv_Extract_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockarticleProv_Extract_Call) Return(_a0 *Article, _a1 error) *MockarticleProv_Extract_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockarticleProv_Extract_Call) RunAndReturn(run func(context.Context, string) (*Article, error)) *MockarticleProv_Extract_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockarticleProv creates a new instance of MockarticleProv. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockarticleProv(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockarticleProv {
+	mock := &MockarticleProv{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
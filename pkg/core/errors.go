@@ -0,0 +1,66 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error classes group the errors of the service by what the user can do about them, so callers can react to a whole
+// class, for example by choosing the reply, without knowing every error. Errors of no class are internal failures.
+// The specific errors of the service belong to a class, that is errors.Is reports them as their class too,
+// and providers wrap their failures with the class matching the cause.
+var (
+	// ErrValidation is the class of the errors caused by invalid input.
+	ErrValidation = errors.New("validation failed")
+	// ErrNotFound is the class of the errors caused by a missing resource.
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists is the class of the errors caused by a resource that already exists.
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrQuotaExceeded is the class of the errors caused by exceeding a usage limit, such as a rate limit.
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrUpstreamUnavailable is the class of the errors caused by an external service that cannot be reached
+	// or fails to handle the request.
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
+	// ErrPermissionDenied is the class of the errors caused by missing permissions.
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// classError is an error belonging to an error class.
+type classError struct {
+	class error
+	msg   string
+}
+
+// newError returns an error with the given message belonging to the given error class.
+func newError(class error, msg string) error {
+	return &classError{class: class, msg: msg}
+}
+
+// Error returns the message of the error.
+func (e *classError) Error() string {
+	return e.msg
+}
+
+// Is reports whether the target is the class of the error.
+func (e *classError) Is(target error) bool {
+	return target == e.class
+}
+
+// StatusError returns the error for an unexpected status code in a response of an external service.
+// Too many requests belong to ErrQuotaExceeded, missing and gone resources to ErrNotFound, refused access
+// to ErrPermissionDenied and every other status, such as a server error, to ErrUpstreamUnavailable.
+func StatusError(code int) error {
+	class := ErrUpstreamUnavailable
+
+	switch code {
+	case http.StatusTooManyRequests:
+		class = ErrQuotaExceeded
+	case http.StatusNotFound, http.StatusGone:
+		class = ErrNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		class = ErrPermissionDenied
+	}
+
+	return fmt.Errorf("%w: unexpected status code: %d", class, code)
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorClasses(t *testing.T) {
+	tests := []struct {
+		err   error
+		class error
+		name  string
+	}{
+		{name: "invalid url", err: ErrInvalidURL, class: ErrValidation},
+		{name: "invalid feed", err: ErrInvalidFeed, class: ErrValidation},
+		{name: "invalid interval", err: ErrInvalidInterval, class: ErrValidation},
+		{name: "already subscribed", err: ErrAlreadySubscribed, class: ErrAlreadyExists},
+		{name: "not subscribed", err: ErrNotSubscribed, class: ErrNotFound},
+		{name: "no content", err: ErrNoContent, class: ErrNotFound},
+		{name: "publish forbidden", err: ErrPublishForbidden, class: ErrPermissionDenied},
+	}
+
+	classes := []error{
+		ErrValidation, ErrNotFound, ErrAlreadyExists, ErrQuotaExceeded, ErrUpstreamUnavailable, ErrPermissionDenied,
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := fmt.Errorf("failed to do something: %w", tt.err)
+
+			assert.ErrorIs(t, wrapped, tt.err)
+
+			for _, class := range classes {
+				assert.Equal(t, class == tt.class, errors.Is(wrapped, class), class.Error())
+			}
+		})
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		class error
+		name  string
+		code  int
+	}{
+		{name: "too many requests", code: http.StatusTooManyRequests, class: ErrQuotaExceeded},
+		{name: "server error", code: http.StatusInternalServerError, class: ErrUpstreamUnavailable},
+		{name: "bad gateway", code: http.StatusBadGateway, class: ErrUpstreamUnavailable},
+		{name: "not found", code: http.StatusNotFound, class: ErrNotFound},
+		{name: "gone", code: http.StatusGone, class: ErrNotFound},
+		{name: "forbidden", code: http.StatusForbidden, class: ErrPermissionDenied},
+		{name: "unauthorized", code: http.StatusUnauthorized, class: ErrPermissionDenied},
+		{name: "other client error", code: http.StatusBadRequest, class: ErrUpstreamUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := StatusError(tt.code)
+
+			assert.ErrorContains(t, err, fmt.Sprintf("unexpected status code: %d", tt.code))
+			assert.ErrorIs(t, err, tt.class)
+		})
+	}
+}
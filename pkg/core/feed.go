@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// feedProv defines the interface for a provider that fetches and parses remote feeds.
+// The provided state is used to issue conditional requests.
+type feedProv interface {
+	Fetch(ctx context.Context, url string, state FetchState) (*FetchResult, error)
+}
+
+// fetchCache defines the interface for persisting HTTP cache validators of polled feeds.
+type fetchCache interface {
+	GetFetchState(ctx context.Context, feedID string) (FetchState, error)
+	SaveFetchState(ctx context.Context, feedID string, state FetchState) error
+}
+
+// Feed represents a parsed feed in a format-independent form.
+// TTL, SkipHours and SkipDays carry the publisher's polling hints when the format supports them.
+type Feed struct {
+	Title       string
+	Link        string
+	Description string
+	Items       []FeedItem
+	SkipHours   []int
+	SkipDays    []time.Weekday
+	TTL         time.Duration
+}
+
+// FetchState holds the cache validators of the last successful fetch of a feed.
+type FetchState struct {
+	ETag         string
+	LastModified string
+	ContentHash  string
+}
+
+// FetchResult holds the outcome of a conditional feed fetch.
+// When NotModified is true Feed is nil and the feed has no new items since the previous fetch.
+type FetchResult struct {
+	Feed        *Feed
+	State       FetchState
+	MaxAge      time.Duration
+	NotModified bool
+}
+
+// FeedItem represents a single normalized entry of a feed regardless of its source format.
+type FeedItem struct {
+	Published  time.Time
+	Updated    time.Time
+	GUID       string
+	Title      string
+	Link       string
+	Author     string
+	Content    string
+	Enclosures []Enclosure
+	Categories []string
+}
+
+// Enclosure describes a media object attached to a feed item.
+type Enclosure struct {
+	URL    string
+	Type   string
+	Length int64
+}
+
+// FetchFeed downloads and parses the feed located at the given url unconditionally.
+// It returns the normalized feed or an error if the feed cannot be fetched or parsed.
+func (s *Service) FetchFeed(ctx context.Context, url string) (_ *Feed, err error) {
+	ctx, span := startSpan(ctx, "FetchFeed", attribute.String("url", url))
+	defer func() { endSpan(span, err) }()
+
+	res, err := s.feeds.Fetch(ctx, url, FetchState{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed: %w", err)
+	}
+
+	if res.Feed == nil {
+		return nil, fmt.Errorf("%w: feed %s returned no content", ErrInvalidFeed, url)
+	}
+
+	return res.Feed, nil
+}
@@ -0,0 +1,98 @@
+// Code generated by mockery. DO NOT EDIT.
+
+//go:build !compile
+
+package core
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockfeedProv is an autogenerated mock type for the feedProv type
+type MockfeedProv struct {
+	mock.Mock
+}
+
+type MockfeedProv_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockfeedProv) EXPECT() *MockfeedProv_Expecter {
+	return &MockfeedProv_Expecter{mock: &_m.Mock}
+}
+
+// Fetch provides a mock function with given fields: ctx, url, state
+func (_m *MockfeedProv) Fetch(ctx context.Context, url string, state FetchState) (*FetchResult, error) {
+	ret := _m.Called(ctx, url, state)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Fetch")
+	}
+
+	var r0 *FetchResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, FetchState) (*FetchResult, error)); ok {
+		return rf(ctx, url, state)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, FetchState) *FetchResult); ok {
+		r0 = rf(ctx, url, state)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*FetchResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, FetchState) error); ok {
+		r1 = rf(ctx, url, state)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockfeedProv_Fetch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Fetch'
+type MockfeedProv_Fetch_Call struct {
+	*mock.Call
+}
+
+// Fetch is a helper method to define mock.On call
+//   - ctx context.Context
+//   - url string
+//   - state FetchState
+func (_e *MockfeedProv_Expecter) Fetch(ctx interface{}, url interface{}, state interface{}) *MockfeedProv_Fetch_Call {
+	return &MockfeedProv_Fetch_Call{Call: _e.mock.On("Fetch", ctx, url, state)}
+}
+
+func (_c *MockfeedProv_Fetch_Call) Run(run func(ctx context.Context, url string, state FetchState)) *MockfeedProv_Fetch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(FetchState))
+	})
+	return _c
+}
+
+func (_c *MockfeedProv_Fetch_Call) Return(_a0 *FetchResult, _a1 error) *MockfeedProv_Fetch_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockfeedProv_Fetch_Call) RunAndReturn(run func(context.Context, string, FetchState) (*FetchResult, error)) *MockfeedProv_Fetch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockfeedProv creates a new instance of MockfeedProv. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockfeedProv(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockfeedProv {
+	mock := &MockfeedProv{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_FetchFeed(t *testing.T) {
+	tests := []struct {
+		setupMocks func(t *testing.T, feeds *MockfeedProv)
+		want       *Feed
+		name       string
+		wantErr    bool
+	}{
+		{
+			name: "success",
+			setupMocks: func(t *testing.T, feeds *MockfeedProv) {
+				t.Helper()
+				feeds.EXPECT().Fetch(mock.Anything, "https://example.com/rss", FetchState{}).
+					Return(&FetchResult{Feed: &Feed{Title: "Example"}}, nil)
+			},
+			want: &Feed{Title: "Example"},
+		},
+		{
+			name: "no content",
+			setupMocks: func(t *testing.T, feeds *MockfeedProv) {
+				t.Helper()
+				feeds.EXPECT().Fetch(mock.Anything, "https://example.com/rss", FetchState{}).
+					Return(&FetchResult{NotModified: true}, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "provider failure",
+			setupMocks: func(t *testing.T, feeds *MockfeedProv) {
+				t.Helper()
+				feeds.EXPECT().Fetch(mock.Anything, "https://example.com/rss", FetchState{}).Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feeds := NewMockfeedProv(t)
+			s := New(&Config{}, NewMockuserRepo(t), NewMocksomeAPIProv(t), feeds, NewMockfetchCache(t), NewMockseenStore(t), NewMockarticleProv(t), NewMocksummarizer(t), NewMockoutbox(t))
+
+			tt.setupMocks(t, feeds)
+
+			feed, err := s.FetchFeed(t.Context(), "https://example.com/rss")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, feed)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+// Code generated by mockery. DO NOT EDIT.
+
+//go:build !compile
+
+package core
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockfetchCache is an autogenerated mock type for the fetchCache type
+type MockfetchCache struct {
+	mock.Mock
+}
+
+type MockfetchCache_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockfetchCache) EXPECT() *MockfetchCache_Expecter {
+	return &MockfetchCache_Expecter{mock: &_m.Mock}
+}
+
+// GetFetchState provides a mock function with given fields: ctx, feedID
+func (_m *MockfetchCache) GetFetchState(ctx context.Context, feedID string) (FetchState, error) {
+	ret := _m.Called(ctx, feedID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFetchState")
+	}
+
+	var r0 FetchState
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (FetchState, error)); ok {
+		return rf(ctx, feedID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) FetchState); ok {
+		r0 = rf(ctx, feedID)
+	} else {
+		r0 = ret.Get(0).(FetchState)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, feedID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockfetchCache_GetFetchState_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetFetchState'
+type MockfetchCache_GetFetchState_Call struct {
+	*mock.Call
+}
+
+// GetFetchState is a helper method to define mock.On call
+//   - ctx context.Context
+//   - feedID string
+func (_e *MockfetchCache_Expecter) GetFetchState(ctx interface{}, feedID interface{}) *MockfetchCache_GetFetchState_Call {
+	return &MockfetchCache_GetFetchState_Call{Call: _e.mock.On("GetFetchState", ctx, feedID)}
+}
+
+func (_c *MockfetchCache_GetFetchState_Call) Run(run func(ctx context.Context, feedID string)) *MockfetchCache_GetFetchState_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockfetchCache_GetFetchState_Call) Return(_a0 FetchState, _a1 error) *MockfetchCache_GetFetchState_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockfetchCache_GetFetchState_Call) RunAndReturn(run func(context.Context, string) (FetchState, error)) *MockfetchCache_GetFetchState_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveFetchState provides a mock function with given fields: ctx, feedID, state
+func (_m *MockfetchCache) SaveFetchState(ctx context.Context, feedID string, state FetchState) error {
+	ret := _m.Called(ctx, feedID, state)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveFetchState")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, FetchState) error); ok {
+		r0 = rf(ctx, feedID, state)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockfetchCache_SaveFetchState_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveFetchState'
+type MockfetchCache_SaveFetchState_Call struct {
+	*mock.Call
+}
+
+// SaveFetchState is a helper method to define mock.On call
+//   - ctx context.Context
+//   - feedID string
+//   - state FetchState
+func (_e *MockfetchCache_Expecter) SaveFetchState(ctx interface{}, feedID interface{}, state interface{}) *MockfetchCache_SaveFetchState_Call {
+	return &MockfetchCache_SaveFetchState_Call{Call: _e.mock.On("SaveFetchState", ctx, feedID, state)}
+}
+
+func (_c *MockfetchCache_SaveFetchState_Call) Run(run func(ctx context.Context, feedID string, state FetchState)) *MockfetchCache_SaveFetchState_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(FetchState))
+	})
+	return _c
+}
+
+func (_c *MockfetchCache_SaveFetchState_Call) Return(_a0 error) *MockfetchCache_SaveFetchState_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockfetchCache_SaveFetchState_Call) RunAndReturn(run func(context.Context, string, FetchState) error) *MockfetchCache_SaveFetchState_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockfetchCache creates a new instance of MockfetchCache. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockfetchCache(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockfetchCache {
+	mock := &MockfetchCache{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
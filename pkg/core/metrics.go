@@ -0,0 +1,103 @@
+package core
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	metricsNamespace = "feeder"
+	feedSubsystem    = "feed"
+	outboxSubsystem  = "outbox"
+
+	pollOK          = "ok"
+	pollNotModified = "not_modified"
+	pollError       = "error"
+
+	deliveryPublished = "published"
+	deliveryDropped   = "dropped"
+	deliveryForbidden = "forbidden"
+	deliveryRetried   = "retried"
+	deliveryDead      = "dead"
+)
+
+var (
+	feedPolls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: feedSubsystem,
+		Name:      "polls_total",
+		Help:      "Number of feed polls, by result.",
+	}, []string{"result"})
+
+	feedFetchDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: feedSubsystem,
+		Name:      "fetch_duration_seconds",
+		Help:      "Time spent fetching feeds.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	})
+
+	feedNewItems = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: feedSubsystem,
+		Name:      "new_items_total",
+		Help:      "Number of new feed items found by polls.",
+	})
+
+	postDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: outboxSubsystem,
+		Name:      "deliveries_total",
+		Help:      "Number of deliveries of queued posts, by result: published, dropped, forbidden, retried or dead.",
+	}, []string{"result"})
+
+	outboxQueued = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: outboxSubsystem,
+		Name:      "queued",
+		Help:      "Number of posts waiting for delivery or being delivered.",
+	})
+
+	outboxRetrying = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: outboxSubsystem,
+		Name:      "retrying",
+		Help:      "Number of posts waiting for a retry after a failed delivery.",
+	})
+
+	outboxDead = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: outboxSubsystem,
+		Name:      "dead",
+		Help:      "Number of posts in the dead-letter queue.",
+	})
+
+	outboxOldestAge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: outboxSubsystem,
+		Name:      "oldest_age_seconds",
+		Help:      "Age of the oldest queued post.",
+	})
+)
+
+// observePoll records the outcome of a feed poll.
+func observePoll(res *PollResult) {
+	feedFetchDuration.Observe(res.Duration.Seconds())
+
+	switch {
+	case res.Err != nil:
+		feedPolls.WithLabelValues(pollError).Inc()
+	case res.NotModified:
+		feedPolls.WithLabelValues(pollNotModified).Inc()
+	default:
+		feedPolls.WithLabelValues(pollOK).Inc()
+	}
+}
+
+// observeOutboxStats exports the backlog of the outbox.
+func observeOutboxStats(stats *OutboxStats) {
+	outboxQueued.Set(float64(stats.Queued))
+	outboxRetrying.Set(float64(stats.Retrying))
+	outboxDead.Set(float64(stats.Dead))
+	outboxOldestAge.Set(stats.OldestAge.Seconds())
+}
@@ -0,0 +1,31 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObservePoll(t *testing.T) {
+	tests := []struct {
+		res    *PollResult
+		name   string
+		result string
+	}{
+		{name: "ok", res: &PollResult{Feed: &Feed{}, Duration: time.Second}, result: pollOK},
+		{name: "not modified", res: &PollResult{NotModified: true}, result: pollNotModified},
+		{name: "error", res: &PollResult{Err: assert.AnError}, result: pollError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := testutil.ToFloat64(feedPolls.WithLabelValues(tt.result))
+
+			observePoll(tt.res)
+
+			assert.Equal(t, before+1, testutil.ToFloat64(feedPolls.WithLabelValues(tt.result)))
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/ksysoev/tg-feeder/pkg/recovery"
+	"github.com/ksysoev/tg-feeder/pkg/reqctx"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	defaultOutboxWorkers       = 4
+	defaultOutboxBatch         = 10
+	defaultOutboxMaxAttempts   = 5
+	defaultOutboxRetryBackoff  = 30 * time.Second
+	defaultOutboxMaxBackoff    = 30 * time.Minute
+	defaultOutboxStatsInterval = time.Minute
+)
+
+// OutboxConfig holds the configuration of the delivery of queued posts.
+// A post that failed MaxAttempts times is moved to the dead-letter queue; retries are delayed exponentially
+// starting at RetryBackoff and capped at MaxBackoff.
+type OutboxConfig struct {
+	RetryBackoff  time.Duration `mapstructure:"retry_backoff"`
+	MaxBackoff    time.Duration `mapstructure:"max_backoff"`
+	StatsInterval time.Duration `mapstructure:"stats_interval"`
+	Workers       int           `mapstructure:"workers"`
+	Batch         int           `mapstructure:"batch"`
+	MaxAttempts   int           `mapstructure:"max_attempts"`
+}
+
+// OutboxMessage is a post received from the outbox for delivery.
+// Attempts is the number of failed deliveries of the post so far.
+type OutboxMessage struct {
+	ID       string
+	Post     Post
+	Attempts int
+}
+
+// OutboxStats describes the backlog of the outbox.
+// Queued counts the posts waiting for delivery or being delivered, Retrying the posts waiting for a retry
+// after a failed delivery and Dead the posts moved to the dead-letter queue.
+// OldestAge is the age of the oldest queued post, zero when nothing is queued.
+type OutboxStats struct {
+	OldestAge time.Duration
+	Queued    int64
+	Retrying  int64
+	Dead      int64
+}
+
+// outbox defines the interface for the durable queue of posts waiting to be published.
+// Received messages stay pending until they are acknowledged, rescheduled or moved to the dead-letter queue,
+// so posts received by a process that crashed are received again.
+type outbox interface {
+	Enqueue(ctx context.Context, posts []Post) error
+	Receive(ctx context.Context, count int) ([]OutboxMessage, error)
+	Ack(ctx context.Context, id string) error
+	Retry(ctx context.Context, msg *OutboxMessage, at time.Time) error
+	DeadLetter(ctx context.Context, msg *OutboxMessage, reason string) error
+	Stats(ctx context.Context) (*OutboxStats, error)
+}
+
+// newOutboxConfig applies defaults for unset values of the outbox configuration.
+func newOutboxConfig(cfg OutboxConfig) OutboxConfig {
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = defaultOutboxRetryBackoff
+	}
+
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultOutboxMaxBackoff
+	}
+
+	if cfg.StatsInterval <= 0 {
+		cfg.StatsInterval = defaultOutboxStatsInterval
+	}
+
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultOutboxWorkers
+	}
+
+	if cfg.Batch <= 0 {
+		cfg.Batch = defaultOutboxBatch
+	}
+
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultOutboxMaxAttempts
+	}
+
+	return cfg
+}
+
+// OutboxStats returns the current backlog of the outbox.
+func (s *Service) OutboxStats(ctx context.Context) (_ *OutboxStats, err error) {
+	ctx, span := startSpan(ctx, "OutboxStats")
+	defer func() { endSpan(span, err) }()
+
+	return s.outbox.Stats(ctx)
+}
+
+// runOutbox delivers the queued posts with the publisher until the context is cancelled.
+// Posts are spread across the workers by target chat, so the posts of a chat are delivered in order
+// while a chat held back by rate limits does not stop the delivery to the others. A delivery that panics
+// is recovered and leaves the post unacknowledged, so it is received again once the outbox reclaims it.
+func (s *Service) runOutbox(ctx context.Context, pub Publisher) error {
+	workers := make([]chan OutboxMessage, s.outboxCfg.Workers)
+
+	eg, ctx := errgroup.WithContext(ctx)
+
+	for i := range workers {
+		workers[i] = make(chan OutboxMessage)
+
+		eg.Go(func() error {
+			for msg := range workers[i] {
+				_ = recovery.Do(ctx, "outbox", func() error {
+					s.deliver(ctx, pub, &msg)
+					return nil
+				})
+			}
+
+			return nil
+		})
+	}
+
+	eg.Go(func() error {
+		defer func() {
+			for _, w := range workers {
+				close(w)
+			}
+		}()
+
+		return s.receiveOutbox(ctx, workers)
+	})
+
+	eg.Go(func() error {
+		s.reportOutboxStats(ctx)
+		return nil
+	})
+
+	return eg.Wait()
+}
+
+// receiveOutbox receives the queued posts and hands them over to the workers until the context is cancelled.
+func (s *Service) receiveOutbox(ctx context.Context, workers []chan OutboxMessage) error {
+	for ctx.Err() == nil {
+		msgs, err := s.outbox.Receive(ctx, s.outboxCfg.Batch)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+
+			slog.WarnContext(ctx, "Failed to receive posts from outbox", slog.Any("error", err))
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+
+			continue
+		}
+
+		for _, msg := range msgs {
+			i := msg.Post.Target.ChatID % int64(len(workers))
+			if i < 0 {
+				i = -i
+			}
+
+			select {
+			case workers[i] <- msg:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// deliver publishes the queued post and settles it in the outbox. Posts of subscriptions that were paused
+// or removed after the post was queued are dropped. A post the bot is forbidden to publish pauses the
+// subscription, and other failures are retried with backoff until the post is moved to the dead-letter queue.
+// Posts interrupted by shutdown stay pending and are delivered again after restart.
+func (s *Service) deliver(ctx context.Context, pub Publisher, msg *OutboxMessage) {
+	post := &msg.Post
+
+	ctx = reqctx.WithFeedID(ctx, post.FeedID)
+	ctx = reqctx.WithChatID(ctx, post.Target.ChatID)
+
+	ctx, span := startSpan(ctx, "deliver",
+		attribute.String("feed_id", post.FeedID),
+		attribute.Int64("chat_id", post.Target.ChatID),
+		attribute.Int("attempts", msg.Attempts),
+	)
+
+	var err error
+	defer func() { endSpan(span, err) }()
+
+	log := slog.With(slog.String("link", post.Item.Link))
+
+	settings, err := s.users.GetSubscriptionSettings(ctx, post.Target.ChatID, post.FeedID)
+	if err == nil {
+		if settings == nil || settings.Paused {
+			postDeliveries.WithLabelValues(deliveryDropped).Inc()
+			s.ackOutbox(ctx, log, msg)
+
+			return
+		}
+
+		err = pub.Publish(ctx, post)
+	}
+
+	switch {
+	case err == nil:
+		postDeliveries.WithLabelValues(deliveryPublished).Inc()
+		s.ackOutbox(ctx, log, msg)
+	case ctx.Err() != nil:
+		return
+	case errors.Is(err, ErrPublishForbidden):
+		postDeliveries.WithLabelValues(deliveryForbidden).Inc()
+		s.pauseForbidden(ctx, post.Target.ChatID, post.FeedID, settings, err)
+		s.ackOutbox(ctx, log, msg)
+	case msg.Attempts+1 >= s.outboxCfg.MaxAttempts:
+		postDeliveries.WithLabelValues(deliveryDead).Inc()
+		log.ErrorContext(ctx, "Failed to publish feed item, moving it to dead-letter queue",
+			slog.Int("attempts", msg.Attempts+1),
+			slog.Any("error", err),
+		)
+
+		if err := s.outbox.DeadLetter(ctx, msg, err.Error()); err != nil {
+			log.WarnContext(ctx, "Failed to move post to dead-letter queue", slog.Any("error", err))
+		}
+	default:
+		postDeliveries.WithLabelValues(deliveryRetried).Inc()
+
+		delay := s.retryDelay(msg.Attempts)
+
+		log.WarnContext(ctx, "Failed to publish feed item, retrying",
+			slog.Int("attempts", msg.Attempts+1),
+			slog.Duration("retry_in", delay),
+			slog.Any("error", err),
+		)
+
+		if err := s.outbox.Retry(ctx, msg, time.Now().Add(delay)); err != nil {
+			log.WarnContext(ctx, "Failed to reschedule post", slog.Any("error", err))
+		}
+	}
+}
+
+// ackOutbox removes the delivered or dropped post from the outbox.
+func (s *Service) ackOutbox(ctx context.Context, log *slog.Logger, msg *OutboxMessage) {
+	if err := s.outbox.Ack(ctx, msg.ID); err != nil {
+		log.WarnContext(ctx, "Failed to acknowledge post", slog.Any("error", err))
+	}
+}
+
+// retryDelay returns the delay before the next delivery of a post that failed attempts times before,
+// doubling the configured backoff with every failure.
+func (s *Service) retryDelay(attempts int) time.Duration {
+	delay := s.outboxCfg.RetryBackoff
+
+	for range attempts {
+		delay *= 2
+		if delay >= s.outboxCfg.MaxBackoff {
+			return s.outboxCfg.MaxBackoff
+		}
+	}
+
+	return delay
+}
+
+// reportOutboxStats periodically exports the backlog of the outbox to the metrics until the context is cancelled.
+func (s *Service) reportOutboxStats(ctx context.Context) {
+	ticker := time.NewTicker(s.outboxCfg.StatsInterval)
+	defer ticker.Stop()
+
+	for {
+		stats, err := s.outbox.Stats(ctx)
+
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			slog.WarnContext(ctx, "Failed to get outbox stats", slog.Any("error", err))
+		default:
+			observeOutboxStats(stats)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,346 @@
+// Code generated by mockery. DO NOT EDIT.
+
+//go:build !compile
+
+package core
+
+import (
+	context "context"
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Mockoutbox is an autogenerated mock type for the outbox type
+type Mockoutbox struct {
+	mock.Mock
+}
+
+type Mockoutbox_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Mockoutbox) EXPECT() *Mockoutbox_Expecter {
+	return &Mockoutbox_Expecter{mock: &_m.Mock}
+}
+
+// Ack provides a mock function with given fields: ctx, id
+func (_m *Mockoutbox) Ack(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Ack")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Mockoutbox_Ack_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Ack'
+type Mockoutbox_Ack_Call struct {
+	*mock.Call
+}
+
+// Ack is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *Mockoutbox_Expecter) Ack(ctx interface{}, id interface{}) *Mockoutbox_Ack_Call {
+	return &Mockoutbox_Ack_Call{Call: _e.mock.On("Ack", ctx, id)}
+}
+
+func (_c *Mockoutbox_Ack_Call) Run(run func(ctx context.Context, id string)) *Mockoutbox_Ack_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Mockoutbox_Ack_Call) Return(_a0 error) *Mockoutbox_Ack_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Mockoutbox_Ack_Call) RunAndReturn(run func(context.Context, string) error) *Mockoutbox_Ack_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeadLetter provides a mock function with given fields: ctx, msg, reason
+func (_m *Mockoutbox) DeadLetter(ctx context.Context, msg *OutboxMessage, reason string) error {
+	ret := _m.Called(ctx, msg, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeadLetter")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *OutboxMessage, string) error); ok {
+		r0 = rf(ctx, msg, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Mockoutbox_DeadLetter_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeadLetter'
+type Mockoutbox_DeadLetter_Call struct {
+	*mock.Call
+}
+
+// DeadLetter is a helper method to define mock.On call
+//   - ctx context.Context
+//   - msg *OutboxMessage
+//   - reason string
+func (_e *Mockoutbox_Expecter) DeadLetter(ctx interface{}, msg interface{}, reason interface{}) *Mockoutbox_DeadLetter_Call {
+	return &Mockoutbox_DeadLetter_Call{Call: _e.mock.On("DeadLetter", ctx, msg, reason)}
+}
+
+func (_c *Mockoutbox_DeadLetter_Call) Run(run func(ctx context.Context, msg *OutboxMessage, reason string)) *Mockoutbox_DeadLetter_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*OutboxMessage), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Mockoutbox_DeadLetter_Call) Return(_a0 error) *Mockoutbox_DeadLetter_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Mockoutbox_DeadLetter_Call) RunAndReturn(run func(context.Context, *OutboxMessage, string) error) *Mockoutbox_DeadLetter_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Enqueue provides a mock function with given fields: ctx, posts
+func (_m *Mockoutbox) Enqueue(ctx context.Context, posts []Post) error {
+	ret := _m.Called(ctx, posts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Enqueue")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []Post) error); ok {
+		r0 = rf(ctx, posts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Mockoutbox_Enqueue_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Enqueue'
+type Mockoutbox_Enqueue_Call struct {
+	*mock.Call
+}
+
+// Enqueue is a helper method to define mock.On call
+//   - ctx context.Context
+//   - posts []Post
+func (_e *Mockoutbox_Expecter) Enqueue(ctx interface{}, posts interface{}) *Mockoutbox_Enqueue_Call {
+	return &Mockoutbox_Enqueue_Call{Call: _e.mock.On("Enqueue", ctx, posts)}
+}
+
+func (_c *Mockoutbox_Enqueue_Call) Run(run func(ctx context.Context, posts []Post)) *Mockoutbox_Enqueue_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]Post))
+	})
+	return _c
+}
+
+func (_c *Mockoutbox_Enqueue_Call) Return(_a0 error) *Mockoutbox_Enqueue_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Mockoutbox_Enqueue_Call) RunAndReturn(run func(context.Context, []Post) error) *Mockoutbox_Enqueue_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Receive provides a mock function with given fields: ctx, count
+func (_m *Mockoutbox) Receive(ctx context.Context, count int) ([]OutboxMessage, error) {
+	ret := _m.Called(ctx, count)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Receive")
+	}
+
+	var r0 []OutboxMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]OutboxMessage, error)); ok {
+		return rf(ctx, count)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []OutboxMessage); ok {
+		r0 = rf(ctx, count)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]OutboxMessage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, count)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Mockoutbox_Receive_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Receive'
+type Mockoutbox_Receive_Call struct {
+	*mock.Call
+}
+
+// Receive is a helper method to define mock.On call
+//   - ctx context.Context
+//   - count int
+func (_e *Mockoutbox_Expecter) Receive(ctx interface{}, count interface{}) *Mockoutbox_Receive_Call {
+	return &Mockoutbox_Receive_Call{Call: _e.mock.On("Receive", ctx, count)}
+}
+
+func (_c *Mockoutbox_Receive_Call) Run(run func(ctx context.Context, count int)) *Mockoutbox_Receive_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *Mockoutbox_Receive_Call) Return(_a0 []OutboxMessage, _a1 error) *Mockoutbox_Receive_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Mockoutbox_Receive_Call) RunAndReturn(run func(context.Context, int) ([]OutboxMessage, error)) *Mockoutbox_Receive_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Retry provides a mock function with given fields: ctx, msg, at
+func (_m *Mockoutbox) Retry(ctx context.Context, msg *OutboxMessage, at time.Time) error {
+	ret := _m.Called(ctx, msg, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Retry")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *OutboxMessage, time.Time) error); ok {
+		r0 = rf(ctx, msg, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Mockoutbox_Retry_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Retry'
+type Mockoutbox_Retry_Call struct {
+	*mock.Call
+}
+
+// Retry is a helper method to define mock.On call
+//   - ctx context.Context
+//   - msg *OutboxMessage
+//   - at time.Time
+func (_e *Mockoutbox_Expecter) Retry(ctx interface{}, msg interface{}, at interface{}) *Mockoutbox_Retry_Call {
+	return &Mockoutbox_Retry_Call{Call: _e.mock.On("Retry", ctx, msg, at)}
+}
+
+func (_c *Mockoutbox_Retry_Call) Run(run func(ctx context.Context, msg *OutboxMessage, at time.Time)) *Mockoutbox_Retry_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*OutboxMessage), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *Mockoutbox_Retry_Call) Return(_a0 error) *Mockoutbox_Retry_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Mockoutbox_Retry_Call) RunAndReturn(run func(context.Context, *OutboxMessage, time.Time) error) *Mockoutbox_Retry_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Stats provides a mock function with given fields: ctx
+func (_m *Mockoutbox) Stats(ctx context.Context) (*OutboxStats, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Stats")
+	}
+
+	var r0 *OutboxStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*OutboxStats, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *OutboxStats); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*OutboxStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Mockoutbox_Stats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Stats'
+type Mockoutbox_Stats_Call struct {
+	*mock.Call
+}
+
+// Stats is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Mockoutbox_Expecter) Stats(ctx interface{}) *Mockoutbox_Stats_Call {
+	return &Mockoutbox_Stats_Call{Call: _e.mock.On("Stats", ctx)}
+}
+
+func (_c *Mockoutbox_Stats_Call) Run(run func(ctx context.Context)) *Mockoutbox_Stats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Mockoutbox_Stats_Call) Return(_a0 *OutboxStats, _a1 error) *Mockoutbox_Stats_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Mockoutbox_Stats_Call) RunAndReturn(run func(context.Context) (*OutboxStats, error)) *Mockoutbox_Stats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockoutbox creates a new instance of Mockoutbox. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockoutbox(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Mockoutbox {
+	mock := &Mockoutbox{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
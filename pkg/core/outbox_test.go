@@ -0,0 +1,227 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOutboxConfig(t *testing.T) {
+	assert.Equal(t, OutboxConfig{
+		RetryBackoff:  defaultOutboxRetryBackoff,
+		MaxBackoff:    defaultOutboxMaxBackoff,
+		StatsInterval: defaultOutboxStatsInterval,
+		Workers:       defaultOutboxWorkers,
+		Batch:         defaultOutboxBatch,
+		MaxAttempts:   defaultOutboxMaxAttempts,
+	}, newOutboxConfig(OutboxConfig{}))
+
+	cfg := OutboxConfig{RetryBackoff: time.Second, MaxBackoff: time.Minute, StatsInterval: time.Hour, Workers: 1, Batch: 2, MaxAttempts: 3}
+	assert.Equal(t, cfg, newOutboxConfig(cfg))
+}
+
+func TestService_Deliver(t *testing.T) {
+	post := Post{FeedID: "f", Item: FeedItem{Link: "https://example.com/1"}, Target: Target{ChatID: 1}, OwnerID: 9}
+
+	tests := []struct {
+		setupMocks func(t *testing.T, users *MockuserRepo, pub *MockPublisher, posts *Mockoutbox)
+		name       string
+		wantResult string
+		attempts   int
+	}{
+		{
+			name:       "published",
+			wantResult: deliveryPublished,
+			setupMocks: func(t *testing.T, users *MockuserRepo, pub *MockPublisher, posts *Mockoutbox) {
+				t.Helper()
+				users.EXPECT().GetSubscriptionSettings(mock.Anything, int64(1), "f").Return(&SubscriptionSettings{}, nil)
+				pub.EXPECT().Publish(mock.Anything, &post).Return(nil)
+				posts.EXPECT().Ack(mock.Anything, "1-0").Return(nil)
+			},
+		},
+		{
+			name:       "subscription paused after the post was queued",
+			wantResult: deliveryDropped,
+			setupMocks: func(t *testing.T, users *MockuserRepo, _ *MockPublisher, posts *Mockoutbox) {
+				t.Helper()
+				users.EXPECT().GetSubscriptionSettings(mock.Anything, int64(1), "f").Return(&SubscriptionSettings{Paused: true}, nil)
+				posts.EXPECT().Ack(mock.Anything, "1-0").Return(nil)
+			},
+		},
+		{
+			name:       "subscription removed after the post was queued",
+			wantResult: deliveryDropped,
+			setupMocks: func(t *testing.T, users *MockuserRepo, _ *MockPublisher, posts *Mockoutbox) {
+				t.Helper()
+				users.EXPECT().GetSubscriptionSettings(mock.Anything, int64(1), "f").Return(nil, nil)
+				posts.EXPECT().Ack(mock.Anything, "1-0").Return(assert.AnError)
+			},
+		},
+		{
+			name:       "forbidden chat is paused",
+			wantResult: deliveryForbidden,
+			setupMocks: func(t *testing.T, users *MockuserRepo, pub *MockPublisher, posts *Mockoutbox) {
+				t.Helper()
+				users.EXPECT().GetSubscriptionSettings(mock.Anything, int64(1), "f").Return(&SubscriptionSettings{OwnerID: 9}, nil)
+				pub.EXPECT().Publish(mock.Anything, &post).Return(fmt.Errorf("%w: kicked", ErrPublishForbidden))
+				users.EXPECT().UpdateSubscriptionSettings(mock.Anything, int64(1), "f", &SubscriptionSettings{OwnerID: 9, Paused: true}).
+					Return(true, nil)
+				posts.EXPECT().Ack(mock.Anything, "1-0").Return(nil)
+			},
+		},
+		{
+			name:       "failure is retried with backoff",
+			wantResult: deliveryRetried,
+			attempts:   1,
+			setupMocks: func(t *testing.T, users *MockuserRepo, pub *MockPublisher, posts *Mockoutbox) {
+				t.Helper()
+				users.EXPECT().GetSubscriptionSettings(mock.Anything, int64(1), "f").Return(&SubscriptionSettings{}, nil)
+				pub.EXPECT().Publish(mock.Anything, &post).Return(assert.AnError)
+				posts.EXPECT().Retry(mock.Anything, &OutboxMessage{ID: "1-0", Post: post, Attempts: 1}, mock.MatchedBy(func(at time.Time) bool {
+					delay := time.Until(at)
+					return delay > time.Second && delay <= 2*time.Second
+				})).Return(nil)
+			},
+		},
+		{
+			name:       "failure to get settings is retried",
+			wantResult: deliveryRetried,
+			setupMocks: func(t *testing.T, users *MockuserRepo, _ *MockPublisher, posts *Mockoutbox) {
+				t.Helper()
+				users.EXPECT().GetSubscriptionSettings(mock.Anything, int64(1), "f").Return(nil, assert.AnError)
+				posts.EXPECT().Retry(mock.Anything, mock.Anything, mock.Anything).Return(assert.AnError)
+			},
+		},
+		{
+			name:       "last failure moves the post to dead-letter queue",
+			wantResult: deliveryDead,
+			attempts:   2,
+			setupMocks: func(t *testing.T, users *MockuserRepo, pub *MockPublisher, posts *Mockoutbox) {
+				t.Helper()
+				users.EXPECT().GetSubscriptionSettings(mock.Anything, int64(1), "f").Return(&SubscriptionSettings{}, nil)
+				pub.EXPECT().Publish(mock.Anything, &post).Return(assert.AnError)
+				posts.EXPECT().DeadLetter(mock.Anything, &OutboxMessage{ID: "1-0", Post: post, Attempts: 2}, assert.AnError.Error()).
+					Return(assert.AnError)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := NewMockuserRepo(t)
+			posts := NewMockoutbox(t)
+			pub := NewMockPublisher(t)
+			s := New(&Config{Outbox: OutboxConfig{RetryBackoff: time.Second, MaxAttempts: 3}},
+				users, NewMocksomeAPIProv(t), NewMockfeedProv(t), NewMockfetchCache(t), NewMockseenStore(t), NewMockarticleProv(t),
+				NewMocksummarizer(t), posts)
+
+			tt.setupMocks(t, users, pub, posts)
+
+			before := testutil.ToFloat64(postDeliveries.WithLabelValues(tt.wantResult))
+
+			s.deliver(t.Context(), pub, &OutboxMessage{ID: "1-0", Post: post, Attempts: tt.attempts})
+
+			assert.Equal(t, before+1, testutil.ToFloat64(postDeliveries.WithLabelValues(tt.wantResult)))
+		})
+	}
+}
+
+func TestService_DeliverInterrupted(t *testing.T) {
+	users := NewMockuserRepo(t)
+	pub := NewMockPublisher(t)
+	s := New(&Config{}, users, NewMocksomeAPIProv(t), NewMockfeedProv(t), NewMockfetchCache(t), NewMockseenStore(t),
+		NewMockarticleProv(t), NewMocksummarizer(t), NewMockoutbox(t))
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	users.EXPECT().GetSubscriptionSettings(mock.Anything, int64(1), "f").Return(&SubscriptionSettings{}, nil)
+	pub.EXPECT().Publish(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, _ *Post) error {
+		cancel()
+		return ctx.Err()
+	})
+
+	s.deliver(ctx, pub, &OutboxMessage{ID: "1-0", Post: Post{FeedID: "f", Target: Target{ChatID: 1}}})
+}
+
+func TestService_RetryDelay(t *testing.T) {
+	s := &Service{outboxCfg: OutboxConfig{RetryBackoff: time.Second, MaxBackoff: 10 * time.Second}}
+
+	assert.Equal(t, time.Second, s.retryDelay(0))
+	assert.Equal(t, 2*time.Second, s.retryDelay(1))
+	assert.Equal(t, 8*time.Second, s.retryDelay(3))
+	assert.Equal(t, 10*time.Second, s.retryDelay(4))
+	assert.Equal(t, 10*time.Second, s.retryDelay(100))
+}
+
+func TestService_RunOutbox(t *testing.T) {
+	users := NewMockuserRepo(t)
+	posts := NewMockoutbox(t)
+	pub := NewMockPublisher(t)
+	s := New(&Config{Outbox: OutboxConfig{Workers: 2}}, users, NewMocksomeAPIProv(t), NewMockfeedProv(t), NewMockfetchCache(t),
+		NewMockseenStore(t), NewMockarticleProv(t), NewMocksummarizer(t), posts)
+
+	msgs := []OutboxMessage{
+		{ID: "1-0", Post: Post{FeedID: "f", Target: Target{ChatID: -3}}},
+		{ID: "2-0", Post: Post{FeedID: "f", Target: Target{ChatID: 4}}},
+		{ID: "3-0", Post: Post{FeedID: "f", Target: Target{ChatID: -3}}},
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+
+	posts.EXPECT().Stats(mock.Anything).Return(&OutboxStats{Queued: 3, Retrying: 2, Dead: 1, OldestAge: time.Minute}, nil)
+	posts.EXPECT().Receive(mock.Anything, defaultOutboxBatch).Return(nil, assert.AnError).Once()
+	posts.EXPECT().Receive(mock.Anything, defaultOutboxBatch).Return(msgs, nil).Once()
+	posts.EXPECT().Receive(mock.Anything, defaultOutboxBatch).RunAndReturn(func(ctx context.Context, _ int) ([]OutboxMessage, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	users.EXPECT().GetSubscriptionSettings(mock.Anything, mock.Anything, "f").Return(&SubscriptionSettings{}, nil)
+	pub.EXPECT().Publish(mock.Anything, mock.Anything).Return(nil)
+	posts.EXPECT().Ack(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, id string) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if order = append(order, id); len(order) == len(msgs) {
+			cancel()
+		}
+
+		return nil
+	})
+
+	require.NoError(t, s.runOutbox(ctx, pub))
+
+	assert.ElementsMatch(t, []string{"1-0", "2-0", "3-0"}, order)
+	assert.Less(t, slices.Index(order, "1-0"), slices.Index(order, "3-0"), "posts of a chat should be delivered in order")
+
+	assert.Equal(t, 3.0, testutil.ToFloat64(outboxQueued))
+	assert.Equal(t, 2.0, testutil.ToFloat64(outboxRetrying))
+	assert.Equal(t, 1.0, testutil.ToFloat64(outboxDead))
+	assert.Equal(t, 60.0, testutil.ToFloat64(outboxOldestAge))
+}
+
+func TestService_OutboxStats(t *testing.T) {
+	posts := NewMockoutbox(t)
+	s := New(&Config{}, NewMockuserRepo(t), NewMocksomeAPIProv(t), NewMockfeedProv(t), NewMockfetchCache(t), NewMockseenStore(t),
+		NewMockarticleProv(t), NewMocksummarizer(t), posts)
+
+	want := &OutboxStats{Queued: 3, Retrying: 1, Dead: 2, OldestAge: time.Minute}
+	posts.EXPECT().Stats(mock.Anything).Return(want, nil)
+
+	stats, err := s.OutboxStats(t.Context())
+
+	require.NoError(t, err)
+	assert.Equal(t, want, stats)
+}
@@ -0,0 +1,230 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/ksysoev/tg-feeder/pkg/recovery"
+	"github.com/ksysoev/tg-feeder/pkg/reqctx"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/errgroup"
+)
+
+const pollResultsBuffer = 100
+
+// seenStore defines the interface for tracking the feed items that have already been seen.
+type seenStore interface {
+	Initialized(ctx context.Context, feedID string) (bool, error)
+	FilterUnseen(ctx context.Context, feedID string, items []FeedItem) ([]FeedItem, error)
+	MarkSeen(ctx context.Context, feedID string, items []FeedItem) error
+}
+
+// Run loads the stored feeds, starts the background feed polling scheduler, the processing pipeline
+// consuming its results and queueing new items in the outbox, and the delivery of the queued items with the publisher.
+// It blocks until the context is cancelled and every subsystem has shut down.
+func (s *Service) Run(ctx context.Context, pub Publisher) error {
+	slog.InfoContext(ctx, "Starting feed scheduler")
+
+	if err := s.loadFeeds(ctx); err != nil {
+		return err
+	}
+
+	results := make(chan PollResult, pollResultsBuffer)
+
+	eg, ctx := errgroup.WithContext(ctx)
+
+	eg.Go(func() error {
+		defer close(results)
+
+		return s.scheduler.Run(ctx, results)
+	})
+
+	eg.Go(func() error {
+		return s.runPipeline(ctx, results)
+	})
+
+	eg.Go(func() error {
+		return s.runOutbox(ctx, pub)
+	})
+
+	err := eg.Wait()
+
+	slog.InfoContext(ctx, "Feed scheduler stopped")
+
+	return err
+}
+
+// RegisterFeed adds the feed to the polling schedule.
+func (s *Service) RegisterFeed(src FeedSource) {
+	s.scheduler.Register(src)
+}
+
+// UnregisterFeed removes the feed with the given id from the polling schedule.
+func (s *Service) UnregisterFeed(id string) {
+	s.scheduler.Unregister(id)
+}
+
+// runPipeline consumes poll results until the results channel is closed.
+// A panic while processing a result is recovered, so it only drops that result.
+func (s *Service) runPipeline(ctx context.Context, results <-chan PollResult) error {
+	for res := range results {
+		_ = recovery.Do(ctx, "pipeline", func() error {
+			s.processPollResult(ctx, &res)
+			return nil
+		})
+	}
+
+	return nil
+}
+
+// processPollResult handles the outcome of a single feed poll and records it in the feed status.
+// Not modified feeds have no new items. New items are marked as seen only once they are queued for publishing,
+// and the fetch state is persisted only after that, so a crash or a failure of the dedup store or the outbox
+// in between leads to a refetch delivering the items on the next poll rather than to lost items.
+func (s *Service) processPollResult(ctx context.Context, res *PollResult) {
+	ctx = reqctx.WithFeedID(ctx, res.Source.ID)
+
+	ctx, span := startSpan(ctx, "processPollResult", attribute.String("feed_id", res.Source.ID))
+	defer span.End()
+
+	observePoll(res)
+
+	status := FeedStatus{PolledAt: res.FetchedAt.UTC()}
+
+	if res.Err != nil {
+		status.Error = res.Err.Error()
+	} else if res.Feed != nil {
+		status.Title = res.Feed.Title
+		status.LastItemAt = lastItemTime(res.Feed.Items)
+	}
+
+	if err := s.users.UpdateFeedStatus(ctx, res.Source.ID, &status); err != nil {
+		slog.WarnContext(ctx, "Failed to update feed status", slog.String("feed_id", res.Source.ID), slog.Any("error", err))
+	}
+
+	if res.Err != nil {
+		return
+	}
+
+	if res.NotModified {
+		slog.DebugContext(ctx, "Feed not modified", slog.String("feed_id", res.Source.ID))
+	} else {
+		slog.DebugContext(ctx, "Feed polled",
+			slog.String("feed_id", res.Source.ID),
+			slog.Int("items", len(res.Feed.Items)),
+			slog.Duration("duration", res.Duration),
+		)
+
+		if err := s.handleItems(ctx, &res.Source, res.Feed); err != nil {
+			slog.WarnContext(ctx, "Failed to process feed items", slog.String("feed_id", res.Source.ID), slog.Any("error", err))
+			return
+		}
+	}
+
+	if err := s.cache.SaveFetchState(ctx, res.Source.ID, res.State); err != nil {
+		slog.WarnContext(ctx, "Failed to save feed fetch state", slog.String("feed_id", res.Source.ID), slog.Any("error", err))
+	}
+}
+
+// handleItems queues the new items of the fetched feed for publishing and marks them as seen afterwards.
+// Items that could not be queued stay unseen, so they are delivered by a later poll of the feed.
+func (s *Service) handleItems(ctx context.Context, src *FeedSource, feed *Feed) error {
+	items, err := s.newItems(ctx, src.ID, feed.Items)
+	if err != nil {
+		return err
+	}
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	s.processNewItems(ctx, src, items)
+
+	if err := s.enqueueItems(ctx, src.ID, feed.Title, items); err != nil {
+		return err
+	}
+
+	if err := s.seen.MarkSeen(ctx, src.ID, items); err != nil {
+		return fmt.Errorf("failed to mark items as seen: %w", err)
+	}
+
+	slog.InfoContext(ctx, "New feed items", slog.String("feed_id", src.ID), slog.Int("items", len(items)))
+
+	feedNewItems.Add(float64(len(items)))
+
+	return nil
+}
+
+// newItems returns the items of the feed that have not been seen before, leaving marking them as seen to the caller.
+// On the first fetch of a feed every existing item is marked as seen and none is returned,
+// so subscribing to a feed does not flood the chat with its backlog.
+func (s *Service) newItems(ctx context.Context, feedID string, items []FeedItem) ([]FeedItem, error) {
+	initialized, err := s.seen.Initialized(ctx, feedID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check seen items: %w", err)
+	}
+
+	if !initialized {
+		if err := s.seen.MarkSeen(ctx, feedID, items); err != nil {
+			return nil, fmt.Errorf("failed to mark items as seen: %w", err)
+		}
+
+		slog.InfoContext(ctx, "First fetch of feed, existing items marked as seen",
+			slog.String("feed_id", feedID),
+			slog.Int("items", len(items)),
+		)
+
+		return nil, nil
+	}
+
+	unseen, err := s.seen.FilterUnseen(ctx, feedID, items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter seen items: %w", err)
+	}
+
+	return unseen, nil
+}
+
+// processNewItems prepares the new items of the feed for delivery.
+func (s *Service) processNewItems(ctx context.Context, src *FeedSource, items []FeedItem) {
+	if src.FullText {
+		s.fetchFullText(ctx, items)
+	}
+
+	for i := range items {
+		slog.DebugContext(ctx, "New feed item",
+			slog.String("feed_id", src.ID),
+			slog.String("title", items[i].Title),
+			slog.String("link", items[i].Link),
+		)
+	}
+}
+
+// fetchFullText replaces the content of the items with the main content extracted from their web pages.
+// Items whose page cannot be fetched or has no readable content keep the content provided by the feed.
+func (s *Service) fetchFullText(ctx context.Context, items []FeedItem) {
+	for i := range items {
+		item := &items[i]
+		if item.Link == "" {
+			continue
+		}
+
+		article, err := s.articles.Extract(ctx, item.Link)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to fetch item full text", slog.String("link", item.Link), slog.Any("error", err))
+			continue
+		}
+
+		if strings.TrimSpace(article.HTML) == "" {
+			continue
+		}
+
+		item.Content = article.HTML
+
+		if item.Author == "" {
+			item.Author = article.Byline
+		}
+	}
+}
@@ -0,0 +1,358 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	mock "github.com/stretchr/testify/mock"
+)
+
+func TestService_Run(t *testing.T) {
+	tests := []struct {
+		setupMocks func(t *testing.T, users *MockuserRepo, posts *Mockoutbox)
+		name       string
+		wantErr    bool
+	}{
+		{
+			name: "success",
+			setupMocks: func(t *testing.T, users *MockuserRepo, posts *Mockoutbox) {
+				t.Helper()
+				users.EXPECT().ListFeeds(mock.Anything).Return([]FeedInfo{{ID: "1", URL: "https://example.com/rss"}}, nil)
+				posts.EXPECT().Stats(mock.Anything).Return(&OutboxStats{}, nil)
+				posts.EXPECT().Receive(mock.Anything, defaultOutboxBatch).RunAndReturn(func(ctx context.Context, _ int) ([]OutboxMessage, error) {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				})
+			},
+		},
+		{
+			name: "failed to load feeds",
+			setupMocks: func(t *testing.T, users *MockuserRepo, _ *Mockoutbox) {
+				t.Helper()
+				users.EXPECT().ListFeeds(mock.Anything).Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := NewMockuserRepo(t)
+			posts := NewMockoutbox(t)
+			s := New(&Config{Scheduler: SchedulerConfig{Interval: time.Hour, Jitter: time.Hour}},
+				users, NewMocksomeAPIProv(t), NewMockfeedProv(t), NewMockfetchCache(t), NewMockseenStore(t), NewMockarticleProv(t), NewMocksummarizer(t), posts)
+
+			tt.setupMocks(t, users, posts)
+
+			ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+			defer cancel()
+
+			err := s.Run(ctx, NewMockPublisher(t))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Contains(t, s.scheduler.entries, "1")
+		})
+	}
+}
+
+func TestService_RegisterFeed(t *testing.T) {
+	s := New(&Config{}, NewMockuserRepo(t), NewMocksomeAPIProv(t), NewMockfeedProv(t), NewMockfetchCache(t), NewMockseenStore(t), NewMockarticleProv(t), NewMocksummarizer(t), NewMockoutbox(t))
+
+	s.RegisterFeed(FeedSource{ID: "1", URL: "https://example.com/rss"})
+	assert.Contains(t, s.scheduler.entries, "1")
+
+	s.UnregisterFeed("1")
+	assert.NotContains(t, s.scheduler.entries, "1")
+}
+
+func TestService_RunPipeline(t *testing.T) {
+	users := NewMockuserRepo(t)
+	cache := NewMockfetchCache(t)
+	seen := NewMockseenStore(t)
+	articles := NewMockarticleProv(t)
+	posts := NewMockoutbox(t)
+	s := New(&Config{}, users, NewMocksomeAPIProv(t), NewMockfeedProv(t), cache, seen, articles, NewMocksummarizer(t), posts)
+
+	polledAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	published := time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)
+
+	users.EXPECT().UpdateFeedStatus(mock.Anything, "1", &FeedStatus{PolledAt: polledAt, Title: "Feed", LastItemAt: published}).Return(nil)
+	users.EXPECT().UpdateFeedStatus(mock.Anything, "2", &FeedStatus{PolledAt: polledAt, Error: assert.AnError.Error()}).Return(nil)
+	users.EXPECT().UpdateFeedStatus(mock.Anything, "3", &FeedStatus{PolledAt: polledAt}).Return(assert.AnError)
+	users.EXPECT().UpdateFeedStatus(mock.Anything, "4", &FeedStatus{PolledAt: polledAt, Title: "Feed"}).Return(nil)
+	seen.EXPECT().Initialized(mock.Anything, "1").Return(true, nil)
+	item := FeedItem{GUID: "a", Link: "https://example.com/a", Published: published}
+	seen.EXPECT().FilterUnseen(mock.Anything, "1", []FeedItem{item}).Return([]FeedItem{item}, nil)
+	articles.EXPECT().Extract(mock.Anything, item.Link).Return(&Article{HTML: "<p>Full text</p>"}, nil)
+	users.EXPECT().ListSubscribers(mock.Anything, "1").Return([]int64{10}, nil)
+	users.EXPECT().GetSubscriptionSettings(mock.Anything, int64(10), "1").Return(&SubscriptionSettings{Preview: true}, nil)
+
+	fullItem := item
+	fullItem.Content = "<p>Full text</p>"
+
+	posts.EXPECT().Enqueue(mock.Anything, []Post{{
+		FeedID:    "1",
+		FeedTitle: "Feed",
+		Item:      fullItem,
+		Target:    Target{ChatID: 10},
+		Preview:   true,
+	}}).Return(nil)
+	seen.EXPECT().MarkSeen(mock.Anything, "1", []FeedItem{fullItem}).Return(nil)
+	seen.EXPECT().Initialized(mock.Anything, "4").Return(false, assert.AnError)
+	cache.EXPECT().SaveFetchState(mock.Anything, "1", FetchState{ETag: "v1"}).Return(nil)
+	cache.EXPECT().SaveFetchState(mock.Anything, "3", FetchState{ETag: "v2"}).Return(assert.AnError)
+
+	results := make(chan PollResult, 4)
+	results <- PollResult{
+		Source:    FeedSource{ID: "1", FullText: true},
+		Feed:      &Feed{Title: "Feed", Items: []FeedItem{item}},
+		State:     FetchState{ETag: "v1"},
+		FetchedAt: polledAt,
+	}
+	results <- PollResult{Source: FeedSource{ID: "2"}, Err: assert.AnError, FetchedAt: polledAt}
+	results <- PollResult{Source: FeedSource{ID: "3"}, NotModified: true, State: FetchState{ETag: "v2"}, FetchedAt: polledAt}
+	// Fetch state of feed 4 must not be saved when its items could not be deduplicated.
+	results <- PollResult{Source: FeedSource{ID: "4"}, Feed: &Feed{Title: "Feed"}, State: FetchState{ETag: "v3"}, FetchedAt: polledAt}
+
+	close(results)
+
+	err := s.runPipeline(t.Context(), results)
+
+	assert.NoError(t, err)
+}
+
+func TestService_ProcessPollResult_RetriesFailedEnqueue(t *testing.T) {
+	users := NewMockuserRepo(t)
+	cache := NewMockfetchCache(t)
+	seen := NewMockseenStore(t)
+	posts := NewMockoutbox(t)
+	s := New(&Config{}, users, NewMocksomeAPIProv(t), NewMockfeedProv(t), cache, seen, NewMockarticleProv(t), NewMocksummarizer(t), posts)
+
+	item := FeedItem{GUID: "a", Link: "https://example.com/a"}
+	post := []Post{{FeedID: "1", FeedTitle: "Feed", Item: item, Target: Target{ChatID: 10}}}
+	res := PollResult{
+		Source: FeedSource{ID: "1"},
+		Feed:   &Feed{Title: "Feed", Items: []FeedItem{item}},
+		State:  FetchState{ETag: "v1"},
+	}
+
+	users.EXPECT().UpdateFeedStatus(mock.Anything, "1", mock.Anything).Return(nil).Times(2)
+	seen.EXPECT().Initialized(mock.Anything, "1").Return(true, nil).Times(2)
+	seen.EXPECT().FilterUnseen(mock.Anything, "1", []FeedItem{item}).Return([]FeedItem{item}, nil).Times(2)
+	users.EXPECT().ListSubscribers(mock.Anything, "1").Return([]int64{10}, nil).Times(2)
+	users.EXPECT().GetSubscriptionSettings(mock.Anything, int64(10), "1").Return(&SubscriptionSettings{}, nil).Times(2)
+
+	// The first poll fails to queue the item, so it must stay unseen and the fetch state must not be saved.
+	posts.EXPECT().Enqueue(mock.Anything, post).Return(assert.AnError).Once()
+	s.processPollResult(t.Context(), &res)
+
+	seen.AssertNotCalled(t, "MarkSeen", mock.Anything, mock.Anything, mock.Anything)
+	cache.AssertNotCalled(t, "SaveFetchState", mock.Anything, mock.Anything, mock.Anything)
+
+	// The next poll delivers the item.
+	posts.EXPECT().Enqueue(mock.Anything, post).Return(nil).Once()
+	seen.EXPECT().MarkSeen(mock.Anything, "1", []FeedItem{item}).Return(nil).Once()
+	cache.EXPECT().SaveFetchState(mock.Anything, "1", FetchState{ETag: "v1"}).Return(nil).Once()
+	s.processPollResult(t.Context(), &res)
+}
+
+func TestService_HandleItems(t *testing.T) {
+	item := FeedItem{GUID: "a"}
+	feed := &Feed{Title: "Feed", Items: []FeedItem{item}}
+
+	tests := []struct {
+		setupMocks func(t *testing.T, users *MockuserRepo, seen *MockseenStore, posts *Mockoutbox)
+		name       string
+		wantErr    bool
+	}{
+		{
+			name: "items are queued and marked as seen",
+			setupMocks: func(t *testing.T, users *MockuserRepo, seen *MockseenStore, posts *Mockoutbox) {
+				t.Helper()
+				seen.EXPECT().Initialized(mock.Anything, "1").Return(true, nil)
+				seen.EXPECT().FilterUnseen(mock.Anything, "1", feed.Items).Return(feed.Items, nil)
+				users.EXPECT().ListSubscribers(mock.Anything, "1").Return([]int64{10}, nil)
+				users.EXPECT().GetSubscriptionSettings(mock.Anything, int64(10), "1").Return(&SubscriptionSettings{}, nil)
+				posts.EXPECT().Enqueue(mock.Anything, mock.Anything).Return(nil)
+				seen.EXPECT().MarkSeen(mock.Anything, "1", feed.Items).Return(nil)
+			},
+		},
+		{
+			name: "nothing new",
+			setupMocks: func(t *testing.T, _ *MockuserRepo, seen *MockseenStore, _ *Mockoutbox) {
+				t.Helper()
+				seen.EXPECT().Initialized(mock.Anything, "1").Return(true, nil)
+				seen.EXPECT().FilterUnseen(mock.Anything, "1", feed.Items).Return(nil, nil)
+			},
+		},
+		{
+			name: "failed to filter",
+			setupMocks: func(t *testing.T, _ *MockuserRepo, seen *MockseenStore, _ *Mockoutbox) {
+				t.Helper()
+				seen.EXPECT().Initialized(mock.Anything, "1").Return(true, nil)
+				seen.EXPECT().FilterUnseen(mock.Anything, "1", feed.Items).Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed to enqueue leaves items unseen",
+			setupMocks: func(t *testing.T, users *MockuserRepo, seen *MockseenStore, _ *Mockoutbox) {
+				t.Helper()
+				seen.EXPECT().Initialized(mock.Anything, "1").Return(true, nil)
+				seen.EXPECT().FilterUnseen(mock.Anything, "1", feed.Items).Return(feed.Items, nil)
+				users.EXPECT().ListSubscribers(mock.Anything, "1").Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed to mark new items",
+			setupMocks: func(t *testing.T, users *MockuserRepo, seen *MockseenStore, _ *Mockoutbox) {
+				t.Helper()
+				seen.EXPECT().Initialized(mock.Anything, "1").Return(true, nil)
+				seen.EXPECT().FilterUnseen(mock.Anything, "1", feed.Items).Return(feed.Items, nil)
+				users.EXPECT().ListSubscribers(mock.Anything, "1").Return(nil, nil)
+				seen.EXPECT().MarkSeen(mock.Anything, "1", feed.Items).Return(assert.AnError)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := NewMockuserRepo(t)
+			seen := NewMockseenStore(t)
+			posts := NewMockoutbox(t)
+			s := New(&Config{}, users, NewMocksomeAPIProv(t), NewMockfeedProv(t), NewMockfetchCache(t), seen,
+				NewMockarticleProv(t), NewMocksummarizer(t), posts)
+
+			tt.setupMocks(t, users, seen, posts)
+
+			err := s.handleItems(t.Context(), &FeedSource{ID: "1"}, feed)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestService_NewItems(t *testing.T) {
+	items := []FeedItem{{GUID: "a"}, {GUID: "b"}}
+
+	tests := []struct {
+		setupMocks func(t *testing.T, seen *MockseenStore)
+		name       string
+		want       []FeedItem
+		wantErr    bool
+	}{
+		{
+			name: "first fetch",
+			setupMocks: func(t *testing.T, seen *MockseenStore) {
+				t.Helper()
+				seen.EXPECT().Initialized(mock.Anything, "1").Return(false, nil)
+				seen.EXPECT().MarkSeen(mock.Anything, "1", items).Return(nil)
+			},
+		},
+		{
+			name: "new items",
+			setupMocks: func(t *testing.T, seen *MockseenStore) {
+				t.Helper()
+				seen.EXPECT().Initialized(mock.Anything, "1").Return(true, nil)
+				seen.EXPECT().FilterUnseen(mock.Anything, "1", items).Return(items[1:], nil)
+			},
+			want: items[1:],
+		},
+		{
+			name: "nothing new",
+			setupMocks: func(t *testing.T, seen *MockseenStore) {
+				t.Helper()
+				seen.EXPECT().Initialized(mock.Anything, "1").Return(true, nil)
+				seen.EXPECT().FilterUnseen(mock.Anything, "1", items).Return(nil, nil)
+			},
+		},
+		{
+			name: "failed to check initialization",
+			setupMocks: func(t *testing.T, seen *MockseenStore) {
+				t.Helper()
+				seen.EXPECT().Initialized(mock.Anything, "1").Return(false, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed to mark first fetch",
+			setupMocks: func(t *testing.T, seen *MockseenStore) {
+				t.Helper()
+				seen.EXPECT().Initialized(mock.Anything, "1").Return(false, nil)
+				seen.EXPECT().MarkSeen(mock.Anything, "1", items).Return(assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed to filter",
+			setupMocks: func(t *testing.T, seen *MockseenStore) {
+				t.Helper()
+				seen.EXPECT().Initialized(mock.Anything, "1").Return(true, nil)
+				seen.EXPECT().FilterUnseen(mock.Anything, "1", items).Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen := NewMockseenStore(t)
+			s := New(&Config{}, NewMockuserRepo(t), NewMocksomeAPIProv(t), NewMockfeedProv(t), NewMockfetchCache(t), seen,
+				NewMockarticleProv(t), NewMocksummarizer(t), NewMockoutbox(t))
+
+			tt.setupMocks(t, seen)
+
+			got, err := s.newItems(t.Context(), "1", items)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_FetchFullText(t *testing.T) {
+	articles := NewMockarticleProv(t)
+	s := New(&Config{}, NewMockuserRepo(t), NewMocksomeAPIProv(t), NewMockfeedProv(t), NewMockfetchCache(t), NewMockseenStore(t),
+		articles, NewMocksummarizer(t), NewMockoutbox(t))
+
+	articles.EXPECT().Extract(mock.Anything, "https://example.com/a").
+		Return(&Article{HTML: "<p>Full text</p>", Byline: "Jane"}, nil)
+	articles.EXPECT().Extract(mock.Anything, "https://example.com/b").
+		Return(&Article{HTML: "<p>Full text</p>", Byline: "Jane"}, nil)
+	articles.EXPECT().Extract(mock.Anything, "https://example.com/c").Return(&Article{}, nil)
+	articles.EXPECT().Extract(mock.Anything, "https://example.com/d").Return(nil, assert.AnError)
+
+	items := []FeedItem{
+		{Link: "https://example.com/a", Content: "Teaser"},
+		{Link: "https://example.com/b", Content: "Teaser", Author: "John"},
+		{Link: "https://example.com/c", Content: "Teaser"},
+		{Link: "https://example.com/d", Content: "Teaser"},
+		{Content: "Teaser"},
+	}
+
+	s.fetchFullText(t.Context(), items)
+
+	assert.Equal(t, []FeedItem{
+		{Link: "https://example.com/a", Content: "<p>Full text</p>", Author: "Jane"},
+		{Link: "https://example.com/b", Content: "<p>Full text</p>", Author: "John"},
+		{Link: "https://example.com/c", Content: "Teaser"},
+		{Link: "https://example.com/d", Content: "Teaser"},
+		{Content: "Teaser"},
+	}, items)
+}
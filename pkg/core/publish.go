@@ -0,0 +1,219 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/net/html"
+)
+
+// ErrPublishForbidden is returned by publishers when the bot is not allowed to post to the target chat,
+// for example after it was removed from a channel or lost its administrator rights.
+var ErrPublishForbidden = newError(ErrPermissionDenied, "publishing forbidden")
+
+// Target is a chat feed items are published to. ThreadID selects a forum topic of a supergroup,
+// zero standing for the general topic or for chats without topics.
+type Target struct {
+	ChatID   int64
+	ThreadID int
+}
+
+// Post is a new feed item to publish to a chat subscribed to the feed.
+// OwnerID is the chat to report delivery failures to, zero when the subscription belongs to the target itself.
+type Post struct {
+	FeedID    string
+	FeedTitle string
+	Item      FeedItem
+	Target    Target
+	OwnerID   int64
+	Preview   bool
+}
+
+// Publisher sends posts to Telegram chats.
+// It returns an error wrapping ErrPublishForbidden when the bot cannot post to the target chat anymore.
+type Publisher interface {
+	Publish(ctx context.Context, post *Post) error
+}
+
+// SubscribeTarget subscribes the target chat to the feed on behalf of the owner chat, which is told about
+// delivery failures. Subscribing a target that is already subscribed updates its topic and resumes the delivery,
+// so owners can fix the permissions of the bot and publish the feed again.
+// It returns the subscribed feed or an error if the URL is invalid or the feed cannot be fetched.
+func (s *Service) SubscribeTarget(ctx context.Context, ownerID int64, target Target, rawURL string) (_ *FeedInfo, err error) {
+	ctx, span := startSpan(ctx, "SubscribeTarget",
+		attribute.Int64("owner_id", ownerID),
+		attribute.Int64("chat_id", target.ChatID),
+	)
+	defer func() { endSpan(span, err) }()
+
+	info, err := s.Subscribe(ctx, target.ChatID, rawURL)
+	if errors.Is(err, ErrAlreadySubscribed) {
+		info, err = s.subscribedFeed(ctx, target.ChatID, rawURL)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := s.users.GetSubscriptionSettings(ctx, target.ChatID, info.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription settings: %w", err)
+	}
+
+	if settings == nil {
+		return nil, ErrNotSubscribed
+	}
+
+	settings.OwnerID = ownerID
+	settings.ThreadID = target.ThreadID
+	settings.Paused = false
+
+	if _, err := s.users.UpdateSubscriptionSettings(ctx, target.ChatID, info.ID, settings); err != nil {
+		return nil, fmt.Errorf("failed to update subscription settings: %w", err)
+	}
+
+	return info, nil
+}
+
+// enqueueItems queues the new items of the feed for publishing to every chat subscribed to it, honouring
+// the settings of each subscription. Items are queued oldest first, feeds listing their newest items first.
+// It returns an error if the subscribers cannot be listed or the posts cannot be queued, so the caller keeps
+// the items unseen and retries them.
+func (s *Service) enqueueItems(ctx context.Context, feedID, feedTitle string, items []FeedItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	chatIDs, err := s.users.ListSubscribers(ctx, feedID)
+	if err != nil {
+		return fmt.Errorf("failed to list feed subscribers: %w", err)
+	}
+
+	var posts []Post
+
+	for _, chatID := range chatIDs {
+		settings, err := s.users.GetSubscriptionSettings(ctx, chatID, feedID)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to get subscription settings",
+				slog.String("feed_id", feedID), slog.Int64("chat_id", chatID), slog.Any("error", err))
+
+			continue
+		}
+
+		if settings == nil || settings.Paused {
+			continue
+		}
+
+		for i := len(items) - 1; i >= 0; i-- {
+			if !matchesFilter(&items[i], settings.Filter) {
+				continue
+			}
+
+			posts = append(posts, Post{
+				FeedID:    feedID,
+				FeedTitle: feedTitle,
+				Item:      items[i],
+				Target:    Target{ChatID: chatID, ThreadID: settings.ThreadID},
+				OwnerID:   settings.OwnerID,
+				Preview:   settings.Preview,
+			})
+		}
+	}
+
+	if len(posts) == 0 {
+		return nil
+	}
+
+	if err := s.outbox.Enqueue(ctx, posts); err != nil {
+		return fmt.Errorf("failed to queue %d posts for publishing: %w", len(posts), err)
+	}
+
+	return nil
+}
+
+// pauseForbidden pauses the subscription of a chat the bot is not allowed to post to,
+// so its owner is told about it only once and the posts queued for the chat are dropped.
+func (s *Service) pauseForbidden(ctx context.Context, chatID int64, feedID string, settings *SubscriptionSettings, cause error) {
+	slog.WarnContext(ctx, "Publishing forbidden, pausing subscription",
+		slog.String("feed_id", feedID),
+		slog.Int64("chat_id", chatID),
+		slog.Any("error", cause),
+	)
+
+	settings.Paused = true
+
+	if _, err := s.users.UpdateSubscriptionSettings(ctx, chatID, feedID, settings); err != nil {
+		slog.WarnContext(ctx, "Failed to pause subscription",
+			slog.String("feed_id", feedID), slog.Int64("chat_id", chatID), slog.Any("error", err))
+	}
+}
+
+// matchesFilter reports whether the item mentions any of the space separated keywords of the filter
+// in its title, content or categories, ignoring case. Only the text of the HTML content is searched,
+// so keywords never match its markup. Every item matches an empty filter.
+func matchesFilter(item *FeedItem, filter string) bool {
+	keywords := strings.Fields(strings.ToLower(filter))
+	if len(keywords) == 0 {
+		return true
+	}
+
+	text := strings.ToLower(item.Title + "\n" + htmlText(item.Content) + "\n" + strings.Join(item.Categories, "\n"))
+
+	for _, kw := range keywords {
+		if strings.Contains(text, kw) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// inlineElements lists the HTML elements that do not separate the words around them.
+var inlineElements = map[string]bool{
+	"a": true, "abbr": true, "b": true, "bdi": true, "bdo": true, "cite": true, "code": true, "data": true,
+	"del": true, "dfn": true, "em": true, "i": true, "ins": true, "kbd": true, "mark": true, "q": true,
+	"s": true, "samp": true, "small": true, "span": true, "strong": true, "sub": true, "sup": true,
+	"time": true, "u": true, "var": true, "wbr": true,
+}
+
+// htmlText returns the text of the HTML fragment with its entities decoded and the contents of scripts and styles
+// dropped. Block elements are replaced by a space, while inline elements are removed, so words split by
+// inline markup are kept whole.
+func htmlText(fragment string) string {
+	var (
+		sb      strings.Builder
+		skipped int
+	)
+
+	z := html.NewTokenizer(strings.NewReader(fragment))
+
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return sb.String()
+		case html.TextToken:
+			if skipped == 0 {
+				sb.Write(z.Text())
+			}
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			tt := z.Token()
+
+			if tt.Data == "script" || tt.Data == "style" {
+				switch tt.Type {
+				case html.StartTagToken:
+					skipped++
+				case html.EndTagToken:
+					skipped = max(skipped-1, 0)
+				}
+			}
+
+			if !inlineElements[tt.Data] {
+				sb.WriteByte(' ')
+			}
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_SubscribeTarget(t *testing.T) {
+	const feedURL = "https://example.com/rss"
+
+	feedID := feedIDFromURL(feedURL)
+	target := Target{ChatID: -100, ThreadID: 7}
+
+	tests := []struct {
+		setupMocks func(t *testing.T, users *MockuserRepo, feeds *MockfeedProv)
+		wantErr    error
+		name       string
+	}{
+		{
+			name: "new subscription",
+			setupMocks: func(t *testing.T, users *MockuserRepo, feeds *MockfeedProv) {
+				t.Helper()
+				feeds.EXPECT().Fetch(mock.Anything, feedURL, FetchState{}).Return(&FetchResult{Feed: &Feed{Title: "Example"}}, nil)
+				users.EXPECT().AddSubscription(mock.Anything, int64(-100), mock.Anything).Return(true, nil)
+				users.EXPECT().GetFeed(mock.Anything, feedID).Return(&FeedInfo{ID: feedID, URL: feedURL, Title: "Example"}, nil)
+				users.EXPECT().GetSubscriptionSettings(mock.Anything, int64(-100), feedID).Return(&SubscriptionSettings{Preview: true}, nil)
+				users.EXPECT().UpdateSubscriptionSettings(mock.Anything, int64(-100), feedID,
+					&SubscriptionSettings{Preview: true, OwnerID: 1, ThreadID: 7}).Return(true, nil)
+			},
+		},
+		{
+			name: "paused subscription is resumed",
+			setupMocks: func(t *testing.T, users *MockuserRepo, feeds *MockfeedProv) {
+				t.Helper()
+				feeds.EXPECT().Fetch(mock.Anything, feedURL, FetchState{}).Return(&FetchResult{Feed: &Feed{Title: "Example"}}, nil)
+				users.EXPECT().AddSubscription(mock.Anything, int64(-100), mock.Anything).Return(false, nil)
+				users.EXPECT().GetSubscriptionSettings(mock.Anything, int64(-100), feedID).Return(&SubscriptionSettings{Paused: true, Filter: "go"}, nil)
+				users.EXPECT().GetFeed(mock.Anything, feedID).Return(&FeedInfo{ID: feedID, URL: feedURL, Title: "Example"}, nil)
+				users.EXPECT().UpdateSubscriptionSettings(mock.Anything, int64(-100), feedID,
+					&SubscriptionSettings{Filter: "go", OwnerID: 1, ThreadID: 7}).Return(true, nil)
+			},
+		},
+		{
+			name: "invalid feed",
+			setupMocks: func(t *testing.T, _ *MockuserRepo, feeds *MockfeedProv) {
+				t.Helper()
+				feeds.EXPECT().Fetch(mock.Anything, feedURL, FetchState{}).Return(nil, fmt.Errorf("%w: not xml", ErrInvalidFeed))
+			},
+			wantErr: ErrInvalidFeed,
+		},
+		{
+			name: "failed to update settings",
+			setupMocks: func(t *testing.T, users *MockuserRepo, feeds *MockfeedProv) {
+				t.Helper()
+				feeds.EXPECT().Fetch(mock.Anything, feedURL, FetchState{}).Return(&FetchResult{Feed: &Feed{Title: "Example"}}, nil)
+				users.EXPECT().AddSubscription(mock.Anything, int64(-100), mock.Anything).Return(true, nil)
+				users.EXPECT().GetFeed(mock.Anything, feedID).Return(&FeedInfo{ID: feedID, URL: feedURL, Title: "Example"}, nil)
+				users.EXPECT().GetSubscriptionSettings(mock.Anything, int64(-100), feedID).Return(&SubscriptionSettings{}, nil)
+				users.EXPECT().UpdateSubscriptionSettings(mock.Anything, int64(-100), feedID, mock.Anything).Return(false, assert.AnError)
+			},
+			wantErr: assert.AnError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := NewMockuserRepo(t)
+			feeds := NewMockfeedProv(t)
+			s := New(&Config{}, users, NewMocksomeAPIProv(t), feeds, NewMockfetchCache(t), NewMockseenStore(t), NewMockarticleProv(t), NewMocksummarizer(t), NewMockoutbox(t))
+
+			tt.setupMocks(t, users, feeds)
+
+			info, err := s.SubscribeTarget(t.Context(), 1, target, feedURL)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, feedID, info.ID)
+		})
+	}
+}
+
+func TestService_EnqueueItems(t *testing.T) {
+	older := FeedItem{Title: "Older", Link: "https://example.com/1"}
+	newer := FeedItem{Title: "Newer about Go", Link: "https://example.com/2"}
+	items := []FeedItem{newer, older}
+
+	post := func(chatID int64, item FeedItem) Post {
+		return Post{FeedID: "f", FeedTitle: "Feed", Item: item, Target: Target{ChatID: chatID}}
+	}
+
+	tests := []struct {
+		setupMocks func(t *testing.T, users *MockuserRepo, posts *Mockoutbox)
+		name       string
+		wantErr    bool
+	}{
+		{
+			name: "items are queued oldest first for every active subscriber",
+			setupMocks: func(t *testing.T, users *MockuserRepo, posts *Mockoutbox) {
+				t.Helper()
+				users.EXPECT().ListSubscribers(mock.Anything, "f").Return([]int64{1, 2, 3, 4}, nil)
+				users.EXPECT().GetSubscriptionSettings(mock.Anything, int64(1), "f").Return(&SubscriptionSettings{}, nil)
+				users.EXPECT().GetSubscriptionSettings(mock.Anything, int64(2), "f").Return(&SubscriptionSettings{Paused: true}, nil)
+				users.EXPECT().GetSubscriptionSettings(mock.Anything, int64(3), "f").Return(nil, nil)
+				users.EXPECT().GetSubscriptionSettings(mock.Anything, int64(4), "f").
+					Return(&SubscriptionSettings{Filter: "golang GO", OwnerID: 9, ThreadID: 5, Preview: true}, nil)
+
+				posts.EXPECT().Enqueue(mock.Anything, []Post{
+					post(1, older),
+					post(1, newer),
+					{
+						FeedID:    "f",
+						FeedTitle: "Feed",
+						Item:      newer,
+						Target:    Target{ChatID: 4, ThreadID: 5},
+						OwnerID:   9,
+						Preview:   true,
+					},
+				}).Return(nil)
+			},
+		},
+		{
+			name: "failed to enqueue",
+			setupMocks: func(t *testing.T, users *MockuserRepo, posts *Mockoutbox) {
+				t.Helper()
+				users.EXPECT().ListSubscribers(mock.Anything, "f").Return([]int64{1}, nil)
+				users.EXPECT().GetSubscriptionSettings(mock.Anything, int64(1), "f").Return(&SubscriptionSettings{}, nil)
+				posts.EXPECT().Enqueue(mock.Anything, []Post{post(1, older), post(1, newer)}).Return(assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "nothing to queue",
+			setupMocks: func(t *testing.T, users *MockuserRepo, _ *Mockoutbox) {
+				t.Helper()
+				users.EXPECT().ListSubscribers(mock.Anything, "f").Return([]int64{1}, nil)
+				users.EXPECT().GetSubscriptionSettings(mock.Anything, int64(1), "f").Return(&SubscriptionSettings{Filter: "rust"}, nil)
+			},
+		},
+		{
+			name: "failed to list subscribers",
+			setupMocks: func(t *testing.T, users *MockuserRepo, _ *Mockoutbox) {
+				t.Helper()
+				users.EXPECT().ListSubscribers(mock.Anything, "f").Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed to get settings",
+			setupMocks: func(t *testing.T, users *MockuserRepo, _ *Mockoutbox) {
+				t.Helper()
+				users.EXPECT().ListSubscribers(mock.Anything, "f").Return([]int64{1}, nil)
+				users.EXPECT().GetSubscriptionSettings(mock.Anything, int64(1), "f").Return(nil, assert.AnError)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := NewMockuserRepo(t)
+			posts := NewMockoutbox(t)
+			s := New(&Config{}, users, NewMocksomeAPIProv(t), NewMockfeedProv(t), NewMockfetchCache(t), NewMockseenStore(t), NewMockarticleProv(t), NewMocksummarizer(t), posts)
+
+			tt.setupMocks(t, users, posts)
+
+			err := s.enqueueItems(t.Context(), "f", "Feed", items)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestMatchesFilter(t *testing.T) {
+	item := &FeedItem{Title: "Generics in Go", Content: "<p>Type parameters</p>", Categories: []string{"Release"}}
+
+	assert.True(t, matchesFilter(item, ""))
+	assert.True(t, matchesFilter(item, "generics"))
+	assert.True(t, matchesFilter(item, "rust PARAMETERS"))
+	assert.True(t, matchesFilter(item, "release"))
+	assert.False(t, matchesFilter(item, "rust zig"))
+
+	htmlItem := &FeedItem{
+		Title:   "Release notes",
+		Content: `<div class="post"><p>Go<b>lang</b> 1.24 &amp; <a href="https://go.dev/">more</a></p><script>var span;</script></div>`,
+	}
+
+	assert.True(t, matchesFilter(htmlItem, "golang"), "words split by inline markup should match")
+	assert.True(t, matchesFilter(htmlItem, "&"), "entities should be decoded")
+	assert.True(t, matchesFilter(htmlItem, "more"))
+	assert.False(t, matchesFilter(htmlItem, "div"), "element names should not match")
+	assert.False(t, matchesFilter(htmlItem, "class href post"), "attributes should not match")
+	assert.False(t, matchesFilter(htmlItem, "span"), "scripts should not match")
+}
+
+func TestHTMLText(t *testing.T) {
+	words := func(fragment string) []string { return strings.Fields(htmlText(fragment)) }
+
+	assert.Equal(t, []string{"plain", "text"}, words("plain text"))
+	assert.Equal(t, []string{"first", "second"}, words("<p>first</p><p>second</p>"))
+	assert.Equal(t, []string{"bold", "&", "italic"}, words("<b>bold</b> &amp; <i>italic</i>"))
+	assert.Equal(t, []string{"text"}, words("<style>p{}</style><p>text</p>"))
+}
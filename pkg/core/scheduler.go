@@ -0,0 +1,426 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ksysoev/tg-feeder/pkg/recovery"
+	"github.com/ksysoev/tg-feeder/pkg/reqctx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	defaultPollInterval  = 15 * time.Minute
+	defaultPollJitter    = time.Minute
+	defaultMaxConcurrent = 10
+	defaultMaxPerHost    = 2
+	defaultMaxBackoff    = 6 * time.Hour
+	defaultMaxInterval   = 24 * time.Hour
+	schedulerTick        = time.Second
+)
+
+// SchedulerConfig holds the configuration of the background feed polling scheduler.
+type SchedulerConfig struct {
+	Interval      time.Duration `mapstructure:"interval"`
+	Jitter        time.Duration `mapstructure:"jitter"`
+	MaxBackoff    time.Duration `mapstructure:"max_backoff"`
+	MaxInterval   time.Duration `mapstructure:"max_interval"`
+	MaxConcurrent int           `mapstructure:"max_concurrent"`
+	MaxPerHost    int           `mapstructure:"max_per_host"`
+}
+
+// FeedSource describes a feed registered for periodic polling.
+// Interval overrides the scheduler default when it is greater than zero.
+// FullText requests the content of new items to be replaced by the text extracted from their web pages.
+type FeedSource struct {
+	ID       string
+	URL      string
+	Interval time.Duration
+	FullText bool
+}
+
+// PollResult holds the outcome of a single feed poll.
+// NotModified is set when the publisher reported no changes since the previous poll, in which case Feed is nil.
+// State holds the cache validators that should be persisted once the result has been processed.
+type PollResult struct {
+	FetchedAt   time.Time
+	Err         error
+	Feed        *Feed
+	State       FetchState
+	Source      FeedSource
+	Duration    time.Duration
+	NotModified bool
+}
+
+type scheduledFeed struct {
+	nextPoll  time.Time
+	skipHours []int
+	skipDays  []time.Weekday
+	source    FeedSource
+	ttl       time.Duration
+	failures  int
+	running   bool
+}
+
+// Scheduler polls registered feeds on their own interval with jitter, a global concurrency cap,
+// per-host concurrency limits and exponential backoff for failing feeds.
+// Polls are conditional requests based on the cached fetch state, and the interval adapts to the
+// publisher's Cache-Control max-age, RSS ttl, skipHours and skipDays hints.
+type Scheduler struct {
+	feeds   feedProv
+	cache   fetchCache
+	entries map[string]*scheduledFeed
+	hosts   map[string]chan struct{}
+	slots   chan struct{}
+	cfg     SchedulerConfig
+	tick    time.Duration
+	mu      sync.Mutex
+}
+
+// NewScheduler creates a new Scheduler that fetches feeds through the given provider using validators from cache,
+// applying defaults for unset values.
+func NewScheduler(cfg SchedulerConfig, feeds feedProv, cache fetchCache) *Scheduler {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultPollInterval
+	}
+
+	if cfg.Jitter < 0 {
+		cfg.Jitter = 0
+	} else if cfg.Jitter == 0 {
+		cfg.Jitter = defaultPollJitter
+	}
+
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+
+	if cfg.MaxInterval <= 0 {
+		cfg.MaxInterval = defaultMaxInterval
+	}
+
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = defaultMaxConcurrent
+	}
+
+	if cfg.MaxPerHost <= 0 {
+		cfg.MaxPerHost = defaultMaxPerHost
+	}
+
+	return &Scheduler{
+		cfg:     cfg,
+		feeds:   feeds,
+		cache:   cache,
+		entries: make(map[string]*scheduledFeed),
+		hosts:   make(map[string]chan struct{}),
+		slots:   make(chan struct{}, cfg.MaxConcurrent),
+		tick:    schedulerTick,
+	}
+}
+
+// Register adds a feed to the polling schedule or updates an existing one.
+// Newly registered feeds are polled after a random delay within the configured jitter to spread the load.
+func (s *Scheduler) Register(src FeedSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[src.ID]; ok {
+		prev := entry.source.URL
+		entry.source = src
+
+		s.pruneHost(hostOf(prev))
+
+		return
+	}
+
+	s.entries[src.ID] = &scheduledFeed{
+		source:   src,
+		nextPoll: time.Now().Add(s.jitter()),
+	}
+}
+
+// Unregister removes a feed from the polling schedule. Polls already in flight are not interrupted.
+// The concurrency slots of its host are dropped once no other feed of the host is scheduled.
+func (s *Scheduler) Unregister(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[id]
+	if !ok {
+		return
+	}
+
+	delete(s.entries, id)
+
+	s.pruneHost(hostOf(entry.source.URL))
+}
+
+// pruneHost drops the concurrency slots of the host when none of the scheduled feeds belongs to it
+// and no poll holds them, so the slots of removed hosts do not accumulate. The caller must hold the lock.
+func (s *Scheduler) pruneHost(host string) {
+	hostSlots, ok := s.hosts[host]
+	if !ok || len(hostSlots) > 0 {
+		return
+	}
+
+	for _, entry := range s.entries {
+		if hostOf(entry.source.URL) == host {
+			return
+		}
+	}
+
+	delete(s.hosts, host)
+}
+
+// Run polls due feeds until the context is cancelled and sends every poll outcome to out.
+// A poll that panics is recovered and the feed is rescheduled as if the fetch had failed,
+// unless the poll had already rescheduled it, so a single poll never counts as two failures.
+// On shutdown it waits for in-flight polls to finish before returning.
+func (s *Scheduler) Run(ctx context.Context, out chan<- PollResult) error {
+	ticker := time.NewTicker(s.tick)
+	defer ticker.Stop()
+
+	var wg sync.WaitGroup
+
+	defer wg.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			for _, src := range s.due(now) {
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+
+					var rescheduled bool
+
+					err := recovery.Do(ctx, "poller", func() error {
+						s.poll(ctx, src, out, &rescheduled)
+						return nil
+					})
+					if err != nil && !rescheduled {
+						s.reschedule(src.ID, nil, err)
+					}
+				}()
+			}
+		}
+	}
+}
+
+// due returns the feeds whose next poll time has passed and marks them as running.
+func (s *Scheduler) due(now time.Time) []FeedSource {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var res []FeedSource
+
+	for _, entry := range s.entries {
+		if entry.running || entry.nextPoll.After(now) {
+			continue
+		}
+
+		entry.running = true
+
+		res = append(res, entry.source)
+	}
+
+	return res
+}
+
+// poll fetches a single feed respecting the global and per-host concurrency limits and forwards the result.
+// rescheduled is set once the next poll of the feed has been scheduled.
+func (s *Scheduler) poll(ctx context.Context, src FeedSource, out chan<- PollResult, rescheduled *bool) {
+	ctx = reqctx.WithFeedID(ctx, src.ID)
+
+	ctx, span := startSpan(ctx, "pollFeed", attribute.String("feed_id", src.ID))
+	defer span.End()
+
+	state, err := s.cache.GetFetchState(ctx, src.ID)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to load feed fetch state", slog.String("feed_id", src.ID), slog.Any("error", err))
+	}
+
+	release, err := s.acquire(ctx, hostOf(src.URL))
+	if err != nil {
+		s.reschedule(src.ID, nil, nil)
+		*rescheduled = true
+
+		return
+	}
+
+	start := time.Now()
+
+	// The slots are released in a deferred call, so a panicking fetch does not hold them forever.
+	fetched, err := func() (*FetchResult, error) {
+		defer release()
+
+		return s.feeds.Fetch(ctx, src.URL, state)
+	}()
+
+	s.reschedule(src.ID, fetched, err)
+	*rescheduled = true
+
+	res := PollResult{
+		Source:    src,
+		Err:       err,
+		FetchedAt: start,
+		Duration:  time.Since(start),
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		slog.WarnContext(ctx, "Failed to poll feed", slog.String("feed_id", src.ID), slog.Any("error", err))
+	} else {
+		res.Feed = fetched.Feed
+		res.State = fetched.State
+		res.NotModified = fetched.NotModified
+	}
+
+	select {
+	case out <- res:
+	case <-ctx.Done():
+	}
+}
+
+// acquire takes a global slot and a slot for the given host, blocking until both are available.
+// It returns a function that releases both slots and drops the host slots if the host is no longer scheduled.
+func (s *Scheduler) acquire(ctx context.Context, host string) (func(), error) {
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	s.mu.Lock()
+
+	hostSlots, ok := s.hosts[host]
+	if !ok {
+		hostSlots = make(chan struct{}, s.cfg.MaxPerHost)
+		s.hosts[host] = hostSlots
+	}
+
+	s.mu.Unlock()
+
+	select {
+	case hostSlots <- struct{}{}:
+	case <-ctx.Done():
+		<-s.slots
+		return nil, ctx.Err()
+	}
+
+	return func() {
+		<-hostSlots
+		<-s.slots
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.pruneHost(host)
+	}, nil
+}
+
+// reschedule computes the next poll time of a feed after a poll attempt.
+// Successful polls reset the failure counter and honour the publisher's caching hints,
+// failed polls back off exponentially up to MaxBackoff.
+func (s *Scheduler) reschedule(id string, res *FetchResult, pollErr error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[id]
+	if !ok {
+		return
+	}
+
+	entry.running = false
+
+	interval := entry.source.Interval
+	if interval <= 0 {
+		interval = s.cfg.Interval
+	}
+
+	if pollErr != nil {
+		entry.failures++
+		entry.nextPoll = time.Now().Add(backoff(interval, entry.failures, s.cfg.MaxBackoff) + s.jitter())
+
+		return
+	}
+
+	entry.failures = 0
+
+	if res != nil && res.Feed != nil {
+		entry.ttl = res.Feed.TTL
+		entry.skipHours = res.Feed.SkipHours
+		entry.skipDays = res.Feed.SkipDays
+	}
+
+	if res != nil {
+		interval = max(interval, res.MaxAge)
+	}
+
+	interval = min(max(interval, entry.ttl), s.cfg.MaxInterval)
+
+	entry.nextPoll = skipUntil(time.Now().Add(interval+s.jitter()), entry.skipHours, entry.skipDays)
+}
+
+// jitter returns a random duration in the range [0, Jitter).
+func (s *Scheduler) jitter() time.Duration {
+	if s.cfg.Jitter <= 0 {
+		return 0
+	}
+
+	return rand.N(s.cfg.Jitter) //nolint:gosec // jitter does not need a cryptographically secure source
+}
+
+// backoff returns interval doubled for every consecutive failure, capped at maxBackoff.
+func backoff(interval time.Duration, failures int, maxBackoff time.Duration) time.Duration {
+	for range failures {
+		interval *= 2
+		if interval >= maxBackoff {
+			return maxBackoff
+		}
+	}
+
+	return interval
+}
+
+// skipUntil moves t forward to the beginning of the first hour that is not listed in skipHours
+// and does not fall on one of skipDays. Hours and days are interpreted in UTC as defined by RSS.
+func skipUntil(t time.Time, skipHours []int, skipDays []time.Weekday) time.Time {
+	if len(skipHours) == 0 && len(skipDays) == 0 {
+		return t
+	}
+
+	const hoursInWeek = 7 * 24
+
+	next := t.UTC()
+
+	for range hoursInWeek {
+		if !slices.Contains(skipHours, next.Hour()) && !slices.Contains(skipDays, next.Weekday()) {
+			return next
+		}
+
+		next = next.Truncate(time.Hour).Add(time.Hour)
+	}
+
+	// Every hour of the week is skipped, which is a publisher misconfiguration; ignore the hints.
+	return t
+}
+
+// hostOf extracts the host name of a feed URL used as the key for per-host limits.
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	return u.Hostname()
+}
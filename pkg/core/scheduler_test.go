@@ -0,0 +1,305 @@
+package core
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewScheduler_Defaults(t *testing.T) {
+	s := NewScheduler(SchedulerConfig{}, NewMockfeedProv(t), NewMockfetchCache(t))
+
+	assert.Equal(t, defaultPollInterval, s.cfg.Interval)
+	assert.Equal(t, defaultPollJitter, s.cfg.Jitter)
+	assert.Equal(t, defaultMaxBackoff, s.cfg.MaxBackoff)
+	assert.Equal(t, defaultMaxInterval, s.cfg.MaxInterval)
+	assert.Equal(t, defaultMaxConcurrent, s.cfg.MaxConcurrent)
+	assert.Equal(t, defaultMaxPerHost, s.cfg.MaxPerHost)
+}
+
+func TestScheduler_RegisterUnregister(t *testing.T) {
+	s := NewScheduler(SchedulerConfig{Jitter: -1}, NewMockfeedProv(t), NewMockfetchCache(t))
+
+	s.Register(FeedSource{ID: "1", URL: "https://example.com/rss"})
+	s.Register(FeedSource{ID: "1", URL: "https://example.com/atom"})
+
+	require.Len(t, s.entries, 1)
+	assert.Equal(t, "https://example.com/atom", s.entries["1"].source.URL)
+
+	s.Unregister("1")
+
+	assert.Empty(t, s.entries)
+}
+
+func TestScheduler_Reschedule(t *testing.T) {
+	cfg := SchedulerConfig{Interval: time.Minute, MaxBackoff: 5 * time.Minute, MaxInterval: time.Hour, Jitter: -1}
+	s := NewScheduler(cfg, NewMockfeedProv(t), NewMockfetchCache(t))
+
+	s.Register(FeedSource{ID: "1", URL: "https://example.com/rss"})
+
+	s.reschedule("1", nil, assert.AnError)
+	assert.Equal(t, 1, s.entries["1"].failures)
+	assert.WithinDuration(t, time.Now().Add(2*time.Minute), s.entries["1"].nextPoll, time.Second)
+
+	s.reschedule("1", nil, assert.AnError)
+	s.reschedule("1", nil, assert.AnError)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), s.entries["1"].nextPoll, time.Second)
+
+	s.reschedule("1", &FetchResult{Feed: &Feed{}}, nil)
+	assert.Equal(t, 0, s.entries["1"].failures)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), s.entries["1"].nextPoll, time.Second)
+
+	s.reschedule("1", &FetchResult{MaxAge: 10 * time.Minute, NotModified: true}, nil)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), s.entries["1"].nextPoll, time.Second)
+
+	s.reschedule("1", &FetchResult{Feed: &Feed{TTL: 30 * time.Minute}}, nil)
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), s.entries["1"].nextPoll, time.Second)
+
+	s.reschedule("1", &FetchResult{NotModified: true}, nil)
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), s.entries["1"].nextPoll, time.Second, "ttl should be remembered")
+
+	s.reschedule("1", &FetchResult{Feed: &Feed{TTL: 48 * time.Hour}}, nil)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), s.entries["1"].nextPoll, time.Second, "interval should be capped")
+
+	s.reschedule("unknown", nil, nil)
+}
+
+func TestSkipUntil(t *testing.T) {
+	// 2024-01-06 is a Saturday.
+	start := time.Date(2024, 1, 6, 22, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		want      time.Time
+		name      string
+		skipHours []int
+		skipDays  []time.Weekday
+	}{
+		{
+			name: "no hints",
+			want: start,
+		},
+		{
+			name:      "allowed hour",
+			skipHours: []int{1, 2},
+			want:      start,
+		},
+		{
+			name:      "skipped hours",
+			skipHours: []int{22, 23},
+			want:      time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "skipped days and hours",
+			skipHours: []int{0},
+			skipDays:  []time.Weekday{time.Saturday, time.Sunday},
+			want:      time.Date(2024, 1, 8, 1, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "every day skipped",
+			skipDays: []time.Weekday{0, 1, 2, 3, 4, 5, 6},
+			want:     start,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, skipUntil(start, tt.skipHours, tt.skipDays))
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		want     time.Duration
+	}{
+		{name: "no failures", failures: 0, want: time.Minute},
+		{name: "one failure", failures: 1, want: 2 * time.Minute},
+		{name: "three failures", failures: 3, want: 8 * time.Minute},
+		{name: "capped", failures: 20, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, backoff(time.Minute, tt.failures, time.Hour))
+		})
+	}
+}
+
+func TestScheduler_Run(t *testing.T) {
+	feeds := NewMockfeedProv(t)
+	cache := NewMockfetchCache(t)
+	s := NewScheduler(SchedulerConfig{Jitter: -1, MaxConcurrent: 1}, feeds, cache)
+	s.tick = 10 * time.Millisecond
+
+	cache.EXPECT().GetFetchState(mock.Anything, "a").Return(FetchState{ETag: "etag-a"}, nil)
+	cache.EXPECT().GetFetchState(mock.Anything, "b").Return(FetchState{}, assert.AnError)
+
+	var inFlight, maxInFlight atomic.Int32
+
+	feeds.EXPECT().Fetch(mock.Anything, mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, url string, state FetchState) (*FetchResult, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		if n > maxInFlight.Load() {
+			maxInFlight.Store(n)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+
+		if url == "https://b.example.com/rss" {
+			return nil, assert.AnError
+		}
+
+		return &FetchResult{Feed: &Feed{Title: url}, State: state}, nil
+	})
+
+	s.Register(FeedSource{ID: "a", URL: "https://a.example.com/rss"})
+	s.Register(FeedSource{ID: "b", URL: "https://b.example.com/rss"})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	out := make(chan PollResult, 2)
+	done := make(chan error)
+
+	go func() { done <- s.Run(ctx, out) }()
+
+	results := make(map[string]PollResult)
+
+	for range 2 {
+		select {
+		case res := <-out:
+			results[res.Source.ID] = res
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for poll results")
+		}
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.NoError(t, results["a"].Err)
+	assert.Equal(t, "https://a.example.com/rss", results["a"].Feed.Title)
+	assert.Equal(t, FetchState{ETag: "etag-a"}, results["a"].State)
+	assert.ErrorIs(t, results["b"].Err, assert.AnError)
+	assert.Equal(t, int32(1), maxInFlight.Load(), "global concurrency limit should be respected")
+	assert.Equal(t, 1, s.entries["b"].failures)
+}
+
+func TestHostOf(t *testing.T) {
+	assert.Equal(t, "example.com", hostOf("https://example.com:8443/rss"))
+	assert.Equal(t, "", hostOf("/relative"))
+}
+
+func TestScheduler_Run_RecoversPanic(t *testing.T) {
+	feeds := NewMockfeedProv(t)
+	cache := NewMockfetchCache(t)
+	s := NewScheduler(SchedulerConfig{Jitter: -1, MaxConcurrent: 1}, feeds, cache)
+	s.tick = 10 * time.Millisecond
+
+	cache.EXPECT().GetFetchState(mock.Anything, mock.Anything).Return(FetchState{}, nil)
+
+	feeds.EXPECT().Fetch(mock.Anything, "https://a.example.com/rss", mock.Anything).Run(func(_ context.Context, _ string, _ FetchState) {
+		panic("boom")
+	}).Once()
+	feeds.EXPECT().Fetch(mock.Anything, "https://b.example.com/rss", mock.Anything).Return(&FetchResult{Feed: &Feed{Title: "b"}}, nil)
+
+	s.Register(FeedSource{ID: "a", URL: "https://a.example.com/rss"})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	out := make(chan PollResult, 1)
+	done := make(chan error)
+
+	go func() { done <- s.Run(ctx, out) }()
+
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		return s.entries["a"].failures == 1 && !s.entries["a"].running
+	}, time.Second, 5*time.Millisecond, "panicking poll should be rescheduled as a failure")
+
+	s.Register(FeedSource{ID: "b", URL: "https://b.example.com/rss"})
+
+	select {
+	case res := <-out:
+		assert.Equal(t, "b", res.Source.ID, "concurrency slots of the panicking poll should be released")
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for poll results")
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestScheduler_Run_PanicAfterReschedule(t *testing.T) {
+	feeds := NewMockfeedProv(t)
+	cache := NewMockfetchCache(t)
+	s := NewScheduler(SchedulerConfig{Jitter: -1}, feeds, cache)
+	s.tick = 10 * time.Millisecond
+
+	cache.EXPECT().GetFetchState(mock.Anything, "a").Return(FetchState{}, nil).Once()
+	feeds.EXPECT().Fetch(mock.Anything, "https://a.example.com/rss", mock.Anything).Return(&FetchResult{Feed: &Feed{Title: "a"}}, nil).Once()
+
+	s.Register(FeedSource{ID: "a", URL: "https://a.example.com/rss"})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	out := make(chan PollResult)
+	done := make(chan error)
+
+	// Sending the result to a closed channel panics after the feed has been rescheduled.
+	close(out)
+
+	go func() { done <- s.Run(ctx, out) }()
+
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		return s.entries["a"].nextPoll.After(time.Now()) && !s.entries["a"].running
+	}, time.Second, 5*time.Millisecond, "feed should be rescheduled")
+
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.Zero(t, s.entries["a"].failures, "a poll rescheduled before panicking should not count as a failure")
+	assert.WithinDuration(t, time.Now().Add(defaultPollInterval), s.entries["a"].nextPoll, time.Second)
+}
+
+func TestScheduler_PruneHosts(t *testing.T) {
+	s := NewScheduler(SchedulerConfig{Jitter: -1}, NewMockfeedProv(t), NewMockfetchCache(t))
+
+	s.Register(FeedSource{ID: "1", URL: "https://a.example.com/rss"})
+	s.Register(FeedSource{ID: "2", URL: "https://a.example.com/atom"})
+	s.Register(FeedSource{ID: "3", URL: "https://b.example.com/rss"})
+
+	for _, host := range []string{"a.example.com", "b.example.com"} {
+		release, err := s.acquire(t.Context(), host)
+		require.NoError(t, err)
+		release()
+	}
+
+	require.Len(t, s.hosts, 2)
+
+	s.Unregister("1")
+	assert.Contains(t, s.hosts, "a.example.com", "host slots should be kept while the host has feeds")
+
+	s.Register(FeedSource{ID: "3", URL: "https://c.example.com/rss"})
+	assert.NotContains(t, s.hosts, "b.example.com", "host slots should be dropped when the feed moves to another host")
+
+	release, err := s.acquire(t.Context(), "a.example.com")
+	require.NoError(t, err)
+
+	s.Unregister("2")
+	assert.Contains(t, s.hosts, "a.example.com", "host slots should be kept while a poll holds them")
+
+	release()
+	assert.Empty(t, s.hosts, "host slots should be dropped once the last poll of a removed host ends")
+
+	s.Unregister("unknown")
+}
@@ -0,0 +1,203 @@
+// Code generated by mockery. DO NOT EDIT.
+
+//go:build !compile
+
+package core
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockseenStore is an autogenerated mock type for the seenStore type
+type MockseenStore struct {
+	mock.Mock
+}
+
+type MockseenStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockseenStore) EXPECT() *MockseenStore_Expecter {
+	return &MockseenStore_Expecter{mock: &_m.Mock}
+}
+
+// FilterUnseen provides a mock function with given fields: ctx, feedID, items
+func (_m *MockseenStore) FilterUnseen(ctx context.Context, feedID string, items []FeedItem) ([]FeedItem, error) {
+	ret := _m.Called(ctx, feedID, items)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FilterUnseen")
+	}
+
+	var r0 []FeedItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []FeedItem) ([]FeedItem, error)); ok {
+		return rf(ctx, feedID, items)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []FeedItem) []FeedItem); ok {
+		r0 = rf(ctx, feedID, items)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]FeedItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []FeedItem) error); ok {
+		r1 = rf(ctx, feedID, items)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockseenStore_FilterUnseen_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FilterUnseen'
+type MockseenStore_FilterUnseen_Call struct {
+	*mock.Call
+}
+
+// FilterUnseen is a helper method to define mock.On call
+//   - ctx context.Context
+//   - feedID string
+//   - items []FeedItem
+func (_e *MockseenStore_Expecter) FilterUnseen(ctx interface{}, feedID interface{}, items interface{}) *MockseenStore_FilterUnseen_Call {
+	return &MockseenStore_FilterUnseen_Call{Call: _e.mock.On("FilterUnseen", ctx, feedID, items)}
+}
+
+func (_c *MockseenStore_FilterUnseen_Call) Run(run func(ctx context.Context, feedID string, items []FeedItem)) *MockseenStore_FilterUnseen_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]FeedItem))
+	})
+	return _c
+}
+
+func (_c *MockseenStore_FilterUnseen_Call) Return(_a0 []FeedItem, _a1 error) *MockseenStore_FilterUnseen_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockseenStore_FilterUnseen_Call) RunAndReturn(run func(context.Context, string, []FeedItem) ([]FeedItem, error)) *MockseenStore_FilterUnseen_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Initialized provides a mock function with given fields: ctx, feedID
+func (_m *MockseenStore) Initialized(ctx context.Context, feedID string) (bool, error) {
+	ret := _m.Called(ctx, feedID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Initialized")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, feedID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, feedID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, feedID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockseenStore_Initialized_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Initialized'
+type MockseenStore_Initialized_Call struct {
+	*mock.Call
+}
+
+// Initialized is a helper method to define mock.On call
+//   - ctx context.Context
+//   - feedID string
+func (_e *MockseenStore_Expecter) Initialized(ctx interface{}, feedID interface{}) *MockseenStore_Initialized_Call {
+	return &MockseenStore_Initialized_Call{Call: _e.mock.On("Initialized", ctx, feedID)}
+}
+
+func (_c *MockseenStore_Initialized_Call) Run(run func(ctx context.Context, feedID string)) *MockseenStore_Initialized_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockseenStore_Initialized_Call) Return(_a0 bool, _a1 error) *MockseenStore_Initialized_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockseenStore_Initialized_Call) RunAndReturn(run func(context.Context, string) (bool, error)) *MockseenStore_Initialized_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkSeen provides a mock function with given fields: ctx, feedID, items
+func (_m *MockseenStore) MarkSeen(ctx context.Context, feedID string, items []FeedItem) error {
+	ret := _m.Called(ctx, feedID, items)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkSeen")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []FeedItem) error); ok {
+		r0 = rf(ctx, feedID, items)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockseenStore_MarkSeen_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkSeen'
+type MockseenStore_MarkSeen_Call struct {
+	*mock.Call
+}
+
+// MarkSeen is a helper method to define mock.On call
+//   - ctx context.Context
+//   - feedID string
+//   - items []FeedItem
+func (_e *MockseenStore_Expecter) MarkSeen(ctx interface{}, feedID interface{}, items interface{}) *MockseenStore_MarkSeen_Call {
+	return &MockseenStore_MarkSeen_Call{Call: _e.mock.On("MarkSeen", ctx, feedID, items)}
+}
+
+func (_c *MockseenStore_MarkSeen_Call) Run(run func(ctx context.Context, feedID string, items []FeedItem)) *MockseenStore_MarkSeen_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]FeedItem))
+	})
+	return _c
+}
+
+func (_c *MockseenStore_MarkSeen_Call) Return(_a0 error) *MockseenStore_MarkSeen_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockseenStore_MarkSeen_Call) RunAndReturn(run func(context.Context, string, []FeedItem) error) *MockseenStore_MarkSeen_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockseenStore creates a new instance of MockseenStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockseenStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockseenStore {
+	mock := &MockseenStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
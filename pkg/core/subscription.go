@@ -0,0 +1,427 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const feedIDLength = 16
+
+var (
+	// ErrInvalidURL is returned when a URL is malformed or uses an unsupported scheme.
+	ErrInvalidURL = newError(ErrValidation, "invalid url")
+	// ErrInvalidFeed is returned when the document behind a URL is not a feed that can be parsed.
+	// Feed providers wrap their parsing failures with it.
+	ErrInvalidFeed = newError(ErrValidation, "invalid feed")
+	// ErrAlreadySubscribed is returned when the chat is already subscribed to the feed.
+	ErrAlreadySubscribed = newError(ErrAlreadyExists, "already subscribed")
+	// ErrNotSubscribed is returned when the chat is not subscribed to the feed.
+	ErrNotSubscribed = newError(ErrNotFound, "not subscribed")
+	// ErrInvalidInterval is returned when a polling interval is negative.
+	ErrInvalidInterval = newError(ErrValidation, "invalid interval")
+	// ErrSharedFeed is returned when a chat changes a setting of a feed other chats are subscribed to as well.
+	ErrSharedFeed = newError(ErrPermissionDenied, "feed shared with other chats")
+)
+
+// FeedInfo describes a feed known to the service together with the state of its last poll.
+// FullText is set when the content of new items is replaced by the text extracted from their web pages.
+// Interval overrides the default polling interval when it is greater than zero.
+type FeedInfo struct {
+	LastItemAt   time.Time
+	LastPolledAt time.Time
+	ID           string
+	URL          string
+	Title        string
+	LastError    string
+	Interval     time.Duration
+	FullText     bool
+}
+
+// Healthy reports whether the last poll of the feed succeeded.
+func (f *FeedInfo) Healthy() bool {
+	return f.LastError == ""
+}
+
+// FeedStatus holds the outcome of a poll to be recorded for a feed.
+// Zero values of Title and LastItemAt leave the stored values untouched.
+type FeedStatus struct {
+	LastItemAt time.Time
+	PolledAt   time.Time
+	Title      string
+	Error      string
+}
+
+// SubscriptionSettings holds the preferences a chat has for one of its subscriptions.
+// Preview enables link previews of delivered items and Filter holds the keywords
+// an item has to contain to be delivered, every item being delivered when it is empty.
+// OwnerID is the chat that published the feed to a channel or group and is told about delivery failures,
+// zero for subscriptions a chat made for itself. ThreadID selects the forum topic items are posted to.
+type SubscriptionSettings struct {
+	CreatedAt time.Time
+	Filter    string
+	OwnerID   int64
+	ThreadID  int
+	Paused    bool
+	Summarize bool
+	Preview   bool
+}
+
+// Subscription is a feed a chat is subscribed to together with the chat preferences for it.
+type Subscription struct {
+	Feed     FeedInfo
+	Settings SubscriptionSettings
+}
+
+// Subscribe validates the feed URL, fetches the feed once to make sure it can be parsed,
+// stores the subscription of the chat and schedules the feed for polling.
+// The feed is scheduled with its stored settings, so subscribing to a feed other chats are subscribed to
+// keeps the settings they chose for it.
+// It returns the subscribed feed or an error if the URL is invalid, the feed cannot be fetched
+// or the chat is already subscribed. Failures to fetch the feed keep the class given by the feed provider,
+// so only documents that are not feeds are reported as ErrInvalidFeed.
+func (s *Service) Subscribe(ctx context.Context, chatID int64, rawURL string) (_ *FeedInfo, err error) {
+	ctx, span := startSpan(ctx, "Subscribe", attribute.Int64("chat_id", chatID))
+	defer func() { endSpan(span, err) }()
+
+	feedURL, err := canonicalURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	feed, err := s.FetchFeed(ctx, feedURL)
+	if err != nil {
+		return nil, err
+	}
+
+	info := FeedInfo{
+		ID:           feedIDFromURL(feedURL),
+		URL:          feedURL,
+		Title:        feed.Title,
+		LastItemAt:   lastItemTime(feed.Items),
+		LastPolledAt: time.Now().UTC(),
+	}
+
+	if info.Title == "" {
+		info.Title = feedURL
+	}
+
+	added, err := s.users.AddSubscription(ctx, chatID, &info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add subscription: %w", err)
+	}
+
+	if !added {
+		return nil, ErrAlreadySubscribed
+	}
+
+	stored, err := s.users.GetFeed(ctx, info.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed: %w", err)
+	}
+
+	if stored != nil {
+		info = *stored
+	}
+
+	s.RegisterFeed(feedSource(&info))
+
+	return &info, nil
+}
+
+// Unsubscribe removes the subscription of the chat to the feed identified either by its URL or by its id.
+// The feed is removed from the polling schedule once it has no subscribers left.
+// It returns the removed feed or ErrNotSubscribed if the chat is not subscribed to it.
+func (s *Service) Unsubscribe(ctx context.Context, chatID int64, urlOrID string) (_ *FeedInfo, err error) {
+	ctx, span := startSpan(ctx, "Unsubscribe", attribute.Int64("chat_id", chatID))
+	defer func() { endSpan(span, err) }()
+
+	feedID, err := resolveFeedID(urlOrID)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := s.users.GetFeed(ctx, feedID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed: %w", err)
+	}
+
+	if info == nil {
+		return nil, ErrNotSubscribed
+	}
+
+	removed, remaining, err := s.users.RemoveSubscription(ctx, chatID, feedID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove subscription: %w", err)
+	}
+
+	if !removed {
+		return nil, ErrNotSubscribed
+	}
+
+	if remaining == 0 {
+		s.UnregisterFeed(feedID)
+	}
+
+	return info, nil
+}
+
+// SetFullText enables or disables fetching the full text of new items of the feed identified either by its URL
+// or by its id. The setting is shared by every chat subscribed to the feed.
+// It returns the updated feed or ErrNotSubscribed if the chat is not subscribed to it.
+func (s *Service) SetFullText(ctx context.Context, chatID int64, urlOrID string, enabled bool) (_ *FeedInfo, err error) {
+	ctx, span := startSpan(ctx, "SetFullText", attribute.Int64("chat_id", chatID))
+	defer func() { endSpan(span, err) }()
+
+	info, err := s.subscribedFeed(ctx, chatID, urlOrID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.users.SetFeedFullText(ctx, info.ID, enabled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set feed full text: %w", err)
+	}
+
+	if !updated {
+		return nil, ErrNotSubscribed
+	}
+
+	info.FullText = enabled
+
+	s.RegisterFeed(feedSource(info))
+
+	return info, nil
+}
+
+// SetInterval sets the polling interval of the feed identified either by its URL or by its id,
+// zero restoring the default one. The setting is shared by every chat subscribed to the feed,
+// so only a chat that is the only subscriber of the feed may change it.
+// It returns the updated feed, ErrInvalidInterval for negative intervals, ErrNotSubscribed
+// if the chat is not subscribed to the feed or ErrSharedFeed if other chats are subscribed to it.
+func (s *Service) SetInterval(ctx context.Context, chatID int64, urlOrID string, interval time.Duration) (_ *FeedInfo, err error) {
+	ctx, span := startSpan(ctx, "SetInterval", attribute.Int64("chat_id", chatID))
+	defer func() { endSpan(span, err) }()
+
+	if interval < 0 {
+		return nil, ErrInvalidInterval
+	}
+
+	info, err := s.subscribedFeed(ctx, chatID, urlOrID)
+	if err != nil {
+		return nil, err
+	}
+
+	subscribers, err := s.users.ListSubscribers(ctx, info.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feed subscribers: %w", err)
+	}
+
+	if slices.ContainsFunc(subscribers, func(id int64) bool { return id != chatID }) {
+		return nil, ErrSharedFeed
+	}
+
+	updated, err := s.users.SetFeedInterval(ctx, info.ID, interval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set feed interval: %w", err)
+	}
+
+	if !updated {
+		return nil, ErrNotSubscribed
+	}
+
+	info.Interval = interval
+
+	s.RegisterFeed(feedSource(info))
+
+	return info, nil
+}
+
+// GetSubscription returns the subscription of the chat to the feed identified either by its URL or by its id,
+// or ErrNotSubscribed if the chat is not subscribed to it.
+func (s *Service) GetSubscription(ctx context.Context, chatID int64, urlOrID string) (_ *Subscription, err error) {
+	ctx, span := startSpan(ctx, "GetSubscription", attribute.Int64("chat_id", chatID))
+	defer func() { endSpan(span, err) }()
+
+	feedID, err := resolveFeedID(urlOrID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := s.users.GetSubscriptionSettings(ctx, chatID, feedID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription settings: %w", err)
+	}
+
+	if settings == nil {
+		return nil, ErrNotSubscribed
+	}
+
+	info, err := s.users.GetFeed(ctx, feedID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feed: %w", err)
+	}
+
+	if info == nil {
+		return nil, ErrNotSubscribed
+	}
+
+	return &Subscription{Feed: *info, Settings: *settings}, nil
+}
+
+// UpdateSubscriptionSettings stores the preferences of the chat for the feed identified either by its URL
+// or by its id. The creation time of the subscription is never changed.
+// It returns the updated subscription or ErrNotSubscribed if the chat is not subscribed to the feed.
+func (s *Service) UpdateSubscriptionSettings(
+	ctx context.Context,
+	chatID int64,
+	urlOrID string,
+	settings *SubscriptionSettings,
+) (_ *Subscription, err error) {
+	ctx, span := startSpan(ctx, "UpdateSubscriptionSettings", attribute.Int64("chat_id", chatID))
+	defer func() { endSpan(span, err) }()
+
+	sub, err := s.GetSubscription(ctx, chatID, urlOrID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.users.UpdateSubscriptionSettings(ctx, chatID, sub.Feed.ID, settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update subscription settings: %w", err)
+	}
+
+	if !updated {
+		return nil, ErrNotSubscribed
+	}
+
+	createdAt := sub.Settings.CreatedAt
+	sub.Settings = *settings
+	sub.Settings.CreatedAt = createdAt
+
+	return sub, nil
+}
+
+// ListSubscriptions returns the feeds the chat is subscribed to, ordered by title.
+func (s *Service) ListSubscriptions(ctx context.Context, chatID int64) (_ []FeedInfo, err error) {
+	ctx, span := startSpan(ctx, "ListSubscriptions", attribute.Int64("chat_id", chatID))
+	defer func() { endSpan(span, err) }()
+
+	feeds, err := s.users.ListSubscriptions(ctx, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	return feeds, nil
+}
+
+// loadFeeds registers every stored feed in the polling schedule.
+func (s *Service) loadFeeds(ctx context.Context) error {
+	feeds, err := s.users.ListFeeds(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load feeds: %w", err)
+	}
+
+	for i := range feeds {
+		s.RegisterFeed(feedSource(&feeds[i]))
+	}
+
+	slog.InfoContext(ctx, "Feeds loaded", slog.Int("count", len(feeds)))
+
+	return nil
+}
+
+// subscribedFeed returns the feed identified either by its URL or by its id
+// or ErrNotSubscribed if the chat is not subscribed to it.
+func (s *Service) subscribedFeed(ctx context.Context, chatID int64, urlOrID string) (*FeedInfo, error) {
+	sub, err := s.GetSubscription(ctx, chatID, urlOrID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sub.Feed, nil
+}
+
+// feedSource returns the polling schedule entry of the feed.
+func feedSource(info *FeedInfo) FeedSource {
+	return FeedSource{ID: info.ID, URL: info.URL, Interval: info.Interval, FullText: info.FullText}
+}
+
+// resolveFeedID returns the id of the feed referenced either by its URL or by its id.
+func resolveFeedID(urlOrID string) (string, error) {
+	urlOrID = strings.TrimSpace(urlOrID)
+	if urlOrID == "" {
+		return "", ErrNotSubscribed
+	}
+
+	if !strings.Contains(urlOrID, "://") {
+		return urlOrID, nil
+	}
+
+	feedURL, err := canonicalURL(urlOrID)
+	if err != nil {
+		return "", err
+	}
+
+	return feedIDFromURL(feedURL), nil
+}
+
+// canonicalURL validates a feed URL and normalizes it so that equivalent URLs map to the same feed.
+func canonicalURL(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("%w: unsupported scheme %q", ErrInvalidURL, u.Scheme)
+	}
+
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("%w: missing host", ErrInvalidURL)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+
+	u.Host = host
+	u.Fragment = ""
+	u.User = nil
+
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	return u.String(), nil
+}
+
+// feedIDFromURL derives a short stable identifier of a feed from its canonical URL.
+func feedIDFromURL(feedURL string) string {
+	hash := sha256.Sum256([]byte(feedURL))
+
+	return hex.EncodeToString(hash[:])[:feedIDLength]
+}
+
+// lastItemTime returns the most recent publication time among the items.
+func lastItemTime(items []FeedItem) time.Time {
+	var last time.Time
+
+	for i := range items {
+		if items[i].Published.After(last) {
+			last = items[i].Published
+		}
+	}
+
+	return last
+}
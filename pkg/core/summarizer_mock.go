@@ -0,0 +1,95 @@
+// Code generated by mockery. DO NOT EDIT.
+
+//go:build !compile
+
+package core
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Mocksummarizer is an autogenerated mock type for the summarizer type
+type Mocksummarizer struct {
+	mock.Mock
+}
+
+type Mocksummarizer_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Mocksummarizer) EXPECT() *Mocksummarizer_Expecter {
+	return &Mocksummarizer_Expecter{mock: &_m.Mock}
+}
+
+// Summarize provides a mock function with given fields: ctx, article
+func (_m *Mocksummarizer) Summarize(ctx context.Context, article *Article) (string, error) {
+	ret := _m.Called(ctx, article)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Summarize")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *Article) (string, error)); ok {
+		return rf(ctx, article)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *Article) string); ok {
+		r0 = rf(ctx, article)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *Article) error); ok {
+		r1 = rf(ctx, article)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Mocksummarizer_Summarize_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Summarize'
+type Mocksummarizer_Summarize_Call struct {
+	*mock.Call
+}
+
+// Summarize is a helper method to define mock.On call
+//   - ctx context.Context
+//   - article *Article
+func (_e *Mocksummarizer_Expecter) Summarize(ctx interface{}, article interface{}) *Mocksummarizer_Summarize_Call {
+	return &Mocksummarizer_Summarize_Call{Call: _e.mock.On("Summarize", ctx, article)}
+}
+
+func (_c *Mocksummarizer_Summarize_Call) Run(run func(ctx context.Context, article *Article)) *Mocksummarizer_Summarize_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*Article))
+	})
+	return _c
+}
+
+func (_c *Mocksummarizer_Summarize_Call) Return(_a0 string, _a1 error) *Mocksummarizer_Summarize_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Mocksummarizer_Summarize_Call) RunAndReturn(run func(context.Context, *Article) (string, error)) *Mocksummarizer_Summarize_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMocksummarizer creates a new instance of Mocksummarizer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMocksummarizer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Mocksummarizer {
+	mock := &Mocksummarizer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
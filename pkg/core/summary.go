@@ -3,8 +3,81 @@ package core
 import (
 	"context"
 	"fmt"
+	"html"
+	"strings"
+	"time"
 )
 
-func (s *Service) Summary(ctx context.Context, url string) (*Response, error) {
-	return nil, fmt.Errorf("not implemented")
+// ErrNoContent is returned when no readable text can be extracted from an article.
+var ErrNoContent = newError(ErrNotFound, "no readable content")
+
+// Article holds the main content and metadata extracted from a web page.
+// Text holds the plain text of the content with paragraphs separated by blank lines,
+// HTML holds the same content with its markup and absolute links.
+type Article struct {
+	Published time.Time
+	URL       string
+	Title     string
+	Byline    string
+	SiteName  string
+	ImageURL  string
+	Text      string
+	HTML      string
+}
+
+// articleProv defines the interface for a provider that downloads web pages and extracts their main content.
+type articleProv interface {
+	Extract(ctx context.Context, url string) (*Article, error)
+}
+
+// summarizer defines the interface for a provider that condenses an article into a short summary.
+type summarizer interface {
+	Summarize(ctx context.Context, article *Article) (string, error)
+}
+
+// Summary fetches the article located at rawURL, extracts its main text and summarizes it.
+// The response message is an HTML fragment holding the article title as a heading followed by the summary.
+// It returns ErrInvalidURL if the URL is malformed and ErrNoContent if the page has no readable text.
+func (s *Service) Summary(ctx context.Context, rawURL string) (_ *Response, err error) {
+	ctx, span := startSpan(ctx, "Summary")
+	defer func() { endSpan(span, err) }()
+
+	articleURL, err := canonicalURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	article, err := s.articles.Extract(ctx, articleURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract article: %w", err)
+	}
+
+	if strings.TrimSpace(article.Text) == "" {
+		return nil, ErrNoContent
+	}
+
+	summary, err := s.summarizer.Summarize(ctx, article)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize article: %w", err)
+	}
+
+	return &Response{Message: summaryHTML(article.Title, summary)}, nil
+}
+
+// summaryHTML renders the title and the plain text summary as an HTML fragment,
+// keeping the paragraphs and line breaks of the summary.
+func summaryHTML(title, summary string) string {
+	var sb strings.Builder
+
+	if title = strings.TrimSpace(title); title != "" {
+		sb.WriteString("<h1>" + html.EscapeString(title) + "</h1>")
+	}
+
+	for _, p := range strings.Split(strings.TrimSpace(summary), "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			sb.WriteString("<p>" + strings.ReplaceAll(html.EscapeString(p), "\n", "<br>") + "</p>")
+		}
+	}
+
+	return sb.String()
 }
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Summary(t *testing.T) {
+	const articleURL = "https://example.com/post"
+
+	article := &Article{URL: articleURL, Title: "Post", Text: "Long article text"}
+
+	tests := []struct {
+		setupMocks func(t *testing.T, articles *MockarticleProv, sum *Mocksummarizer)
+		wantErr    error
+		name       string
+		url        string
+		want       string
+	}{
+		{
+			name: "success",
+			url:  "HTTPS://example.com/post#intro",
+			setupMocks: func(t *testing.T, articles *MockarticleProv, sum *Mocksummarizer) {
+				t.Helper()
+				articles.EXPECT().Extract(mock.Anything, articleURL).Return(article, nil)
+				sum.EXPECT().Summarize(mock.Anything, article).Return("  Short <summary>\n\n- one\n- two\n", nil)
+			},
+			want: "<h1>Post</h1><p>Short &lt;summary&gt;</p><p>- one<br>- two</p>",
+		},
+		{
+			name: "untitled article",
+			url:  articleURL,
+			setupMocks: func(t *testing.T, articles *MockarticleProv, sum *Mocksummarizer) {
+				t.Helper()
+				untitled := &Article{URL: articleURL, Text: "text"}
+				articles.EXPECT().Extract(mock.Anything, articleURL).Return(untitled, nil)
+				sum.EXPECT().Summarize(mock.Anything, untitled).Return("Short summary", nil)
+			},
+			want: "<p>Short summary</p>",
+		},
+		{
+			name:       "invalid url",
+			url:        "not a url",
+			setupMocks: func(t *testing.T, _ *MockarticleProv, _ *Mocksummarizer) { t.Helper() },
+			wantErr:    ErrInvalidURL,
+		},
+		{
+			name: "extraction failure",
+			url:  articleURL,
+			setupMocks: func(t *testing.T, articles *MockarticleProv, _ *Mocksummarizer) {
+				t.Helper()
+				articles.EXPECT().Extract(mock.Anything, articleURL).Return(nil, assert.AnError)
+			},
+			wantErr: assert.AnError,
+		},
+		{
+			name: "no content",
+			url:  articleURL,
+			setupMocks: func(t *testing.T, articles *MockarticleProv, _ *Mocksummarizer) {
+				t.Helper()
+				articles.EXPECT().Extract(mock.Anything, articleURL).Return(&Article{URL: articleURL, Text: " \n "}, nil)
+			},
+			wantErr: ErrNoContent,
+		},
+		{
+			name: "summarizer failure",
+			url:  articleURL,
+			setupMocks: func(t *testing.T, articles *MockarticleProv, sum *Mocksummarizer) {
+				t.Helper()
+				articles.EXPECT().Extract(mock.Anything, articleURL).Return(article, nil)
+				sum.EXPECT().Summarize(mock.Anything, article).Return("", assert.AnError)
+			},
+			wantErr: assert.AnError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			articles := NewMockarticleProv(t)
+			sum := NewMocksummarizer(t)
+			s := New(&Config{}, NewMockuserRepo(t), NewMocksomeAPIProv(t), NewMockfeedProv(t), NewMockfetchCache(t),
+				NewMockseenStore(t), articles, sum, NewMockoutbox(t))
+
+			tt.setupMocks(t, articles, sum)
+
+			resp, err := s.Summary(t.Context(), tt.url)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, resp.Message)
+		})
+	}
+}
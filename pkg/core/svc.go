@@ -3,6 +3,9 @@ package core
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -10,6 +13,20 @@ import (
 // userRepo defines the interface for user repository operations.
 type userRepo interface {
 	CheckHealth(ctx context.Context) error
+	AddSubscription(ctx context.Context, chatID int64, feed *FeedInfo) (bool, error)
+	RemoveSubscription(ctx context.Context, chatID int64, feedID string) (removed bool, remaining int64, err error)
+	ListSubscriptions(ctx context.Context, chatID int64) ([]FeedInfo, error)
+	ListSubscribers(ctx context.Context, feedID string) ([]int64, error)
+	ListFeeds(ctx context.Context) ([]FeedInfo, error)
+	GetFeed(ctx context.Context, feedID string) (*FeedInfo, error)
+	UpdateFeedStatus(ctx context.Context, feedID string, status *FeedStatus) error
+	SetFeedFullText(ctx context.Context, feedID string, enabled bool) (bool, error)
+	SetFeedInterval(ctx context.Context, feedID string, interval time.Duration) (bool, error)
+	GetSubscriptionSettings(ctx context.Context, chatID int64, feedID string) (*SubscriptionSettings, error)
+	UpdateSubscriptionSettings(ctx context.Context, chatID int64, feedID string, settings *SubscriptionSettings) (bool, error)
+	GetUser(ctx context.Context, userID int64) (*User, error)
+	SaveUser(ctx context.Context, user *User) error
+	TouchUser(ctx context.Context, userID int64, seenAt time.Time) error
 }
 
 // someAPIProv defines the interface for a provider that can check health status.
@@ -17,30 +34,98 @@ type someAPIProv interface {
 	CheckHealth(ctx context.Context) error
 }
 
+// Config holds the configuration of the core service.
+type Config struct {
+	Scheduler SchedulerConfig `mapstructure:"scheduler"`
+	Outbox    OutboxConfig    `mapstructure:"outbox"`
+}
+
 type Response struct {
 	Message string `json:"message"` // Main response message
 }
 
 // Service encapsulates core business logic and dependencies.
 type Service struct {
-	users   userRepo
-	someAPI someAPIProv
+	users      userRepo
+	someAPI    someAPIProv
+	feeds      feedProv
+	cache      fetchCache
+	seen       seenStore
+	articles   articleProv
+	summarizer summarizer
+	outbox     outbox
+	scheduler  *Scheduler
+	outboxCfg  OutboxConfig
 }
 
-// New creates a new Service instance with the provided userRepo and someAPI.
-func New(users userRepo, someAPI someAPIProv) *Service {
+// New creates a new Service instance with the provided configuration, userRepo, someAPI, feed provider,
+// fetch state cache, seen items store, article provider, summarizer and the outbox queueing posts for publishing.
+func New(
+	cfg *Config,
+	users userRepo,
+	someAPI someAPIProv,
+	feeds feedProv,
+	cache fetchCache,
+	seen seenStore,
+	articles articleProv,
+	sum summarizer,
+	posts outbox,
+) *Service {
 	return &Service{
-		users:   users,
-		someAPI: someAPI,
+		users:      users,
+		someAPI:    someAPI,
+		feeds:      feeds,
+		cache:      cache,
+		seen:       seen,
+		articles:   articles,
+		summarizer: sum,
+		outbox:     posts,
+		scheduler:  NewScheduler(cfg.Scheduler, feeds, cache),
+		outboxCfg:  newOutboxConfig(cfg.Outbox),
 	}
 }
 
-// CheckHealth checks the health of the core service and its dependencies.
-func (s *Service) CheckHealth(ctx context.Context) error {
-	eg, ctx := errgroup.WithContext(ctx)
+// DependencyHealth is the result of the health check of a dependency of the service.
+// Err is nil when the dependency is healthy.
+type DependencyHealth struct {
+	Err  error
+	Name string
+}
+
+// CheckHealth checks the health of the dependencies of the core service concurrently and returns
+// the result for each of them. The returned error joins the failures of the unhealthy dependencies.
+func (s *Service) CheckHealth(ctx context.Context) (_ []DependencyHealth, err error) {
+	ctx, span := startSpan(ctx, "CheckHealth")
+	defer func() { endSpan(span, err) }()
+
+	checks := []struct {
+		check func(ctx context.Context) error
+		name  string
+	}{
+		{name: "redis", check: s.users.CheckHealth},
+		{name: "some_api", check: s.someAPI.CheckHealth},
+	}
+
+	deps := make([]DependencyHealth, len(checks))
 
-	eg.Go(func() error { return s.someAPI.CheckHealth(ctx) })
-	eg.Go(func() error { return s.users.CheckHealth(ctx) })
+	var eg errgroup.Group
+
+	for i, c := range checks {
+		eg.Go(func() error {
+			deps[i] = DependencyHealth{Name: c.name, Err: c.check(ctx)}
+			return nil
+		})
+	}
+
+	_ = eg.Wait()
+
+	errs := make([]error, 0, len(deps))
+
+	for _, dep := range deps {
+		if dep.Err != nil {
+			errs = append(errs, fmt.Errorf("%s is unhealthy: %w", dep.Name, dep.Err))
+		}
+	}
 
-	return eg.Wait()
+	return deps, errors.Join(errs...)
 }
@@ -10,7 +10,8 @@ import (
 func TestNew(t *testing.T) {
 	users := NewMockuserRepo(t)
 	someAPI := NewMocksomeAPIProv(t)
-	svc := New(users, someAPI)
+	feeds := NewMockfeedProv(t)
+	svc := New(&Config{}, users, someAPI, feeds, NewMockfetchCache(t), NewMockseenStore(t), NewMockarticleProv(t), NewMocksummarizer(t), NewMockoutbox(t))
 
 	assert.NotNil(t, svc, "New() should return a non-nil Service instance")
 }
@@ -19,6 +20,7 @@ func TestService_CheckHealth(t *testing.T) {
 	tests := []struct {
 		setupMocks func(t *testing.T, users *MockuserRepo, someAPI *MocksomeAPIProv)
 		name       string
+		wantDeps   []DependencyHealth
 		wantErr    bool
 	}{
 		{
@@ -28,7 +30,8 @@ func TestService_CheckHealth(t *testing.T) {
 				users.EXPECT().CheckHealth(mock.Anything).Return(nil)
 				someAPI.EXPECT().CheckHealth(mock.Anything).Return(nil)
 			},
-			wantErr: false,
+			wantDeps: []DependencyHealth{{Name: "redis"}, {Name: "some_api"}},
+			wantErr:  false,
 		},
 		{
 			name: "userRepo failure",
@@ -37,7 +40,8 @@ func TestService_CheckHealth(t *testing.T) {
 				users.EXPECT().CheckHealth(mock.Anything).Return(assert.AnError)
 				someAPI.EXPECT().CheckHealth(mock.Anything).Return(nil)
 			},
-			wantErr: true,
+			wantDeps: []DependencyHealth{{Name: "redis", Err: assert.AnError}, {Name: "some_api"}},
+			wantErr:  true,
 		},
 		{
 			name: "someAPI failure",
@@ -46,18 +50,21 @@ func TestService_CheckHealth(t *testing.T) {
 				users.EXPECT().CheckHealth(mock.Anything).Return(nil)
 				someAPI.EXPECT().CheckHealth(mock.Anything).Return(assert.AnError)
 			},
-			wantErr: true,
+			wantDeps: []DependencyHealth{{Name: "redis"}, {Name: "some_api", Err: assert.AnError}},
+			wantErr:  true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			users := NewMockuserRepo(t)
 			someAPI := NewMocksomeAPIProv(t)
-			s := New(users, someAPI)
+			s := New(&Config{}, users, someAPI, NewMockfeedProv(t), NewMockfetchCache(t), NewMockseenStore(t), NewMockarticleProv(t), NewMocksummarizer(t), NewMockoutbox(t))
 
 			tt.setupMocks(t, users, someAPI)
 
-			err := s.CheckHealth(t.Context())
+			deps, err := s.CheckHealth(t.Context())
+
+			assert.Equal(t, tt.wantDeps, deps)
 
 			if tt.wantErr {
 				assert.Error(t, err, "CheckHealth() should return an error")
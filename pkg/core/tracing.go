@@ -0,0 +1,31 @@
+package core
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/ksysoev/tg-feeder/pkg/core"
+
+// tracer creates the spans of the service calls and the background work of the feed pipeline.
+// It resolves the global tracer provider lazily, so the provider configured at startup is used.
+var tracer = otel.Tracer(tracerName)
+
+// startSpan starts a span named after the operation of the service as a child of the span in the context.
+func startSpan(ctx context.Context, op string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "core."+op, trace.WithAttributes(attrs...))
+}
+
+// endSpan records the error of the operation, if any, and ends the span.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var (
+	spanProvider     *sdktrace.TracerProvider
+	spanProviderOnce sync.Once
+)
+
+// recordSpans returns a recorder of the spans ended during the test.
+// The global tracer provider can be set only once, so it is shared by the tests of the package.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	spanProviderOnce.Do(func() {
+		spanProvider = sdktrace.NewTracerProvider()
+		otel.SetTracerProvider(spanProvider)
+	})
+
+	rec := tracetest.NewSpanRecorder()
+	spanProvider.RegisterSpanProcessor(rec)
+	t.Cleanup(func() { spanProvider.UnregisterSpanProcessor(rec) })
+
+	return rec
+}
+
+func TestService_TracesCalls(t *testing.T) {
+	rec := recordSpans(t)
+
+	feeds := NewMockfeedProv(t)
+	feeds.EXPECT().Fetch(mock.Anything, "https://example.com/rss", FetchState{}).Return(nil, assert.AnError)
+
+	s := New(&Config{}, NewMockuserRepo(t), NewMocksomeAPIProv(t), feeds, NewMockfetchCache(t), NewMockseenStore(t), NewMockarticleProv(t), NewMocksummarizer(t), NewMockoutbox(t))
+
+	_, err := s.Subscribe(t.Context(), 42, "https://example.com/rss")
+	require.Error(t, err)
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+
+	fetch, subscribe := spans[0], spans[1]
+
+	assert.Equal(t, "core.FetchFeed", fetch.Name())
+	assert.Equal(t, "core.Subscribe", subscribe.Name())
+	assert.Equal(t, subscribe.SpanContext().SpanID(), fetch.Parent().SpanID())
+	assert.Contains(t, subscribe.Attributes(), attribute.Int64("chat_id", 42))
+	assert.Equal(t, codes.Error, subscribe.Status().Code)
+	assert.Equal(t, codes.Error, fetch.Status().Code)
+}
+
+func TestEndSpan(t *testing.T) {
+	rec := recordSpans(t)
+
+	_, span := startSpan(t.Context(), "ok")
+	endSpan(span, nil)
+
+	_, span = startSpan(t.Context(), "failed")
+	endSpan(span, assert.AnError)
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "core.ok", spans[0].Name())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Empty(t, spans[0].Events())
+
+	assert.Equal(t, "core.failed", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, assert.AnError.Error(), spans[1].Status().Description)
+	require.Len(t, spans[1].Events(), 1)
+	assert.Equal(t, "exception", spans[1].Events()[0].Name)
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ErrInvalidLanguage is returned when the language chosen by a user is empty.
+var ErrInvalidLanguage = newError(ErrValidation, "invalid language")
+
+// User describes a Telegram user known to the bot.
+type User struct {
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	Language   string
+	Timezone   string
+	ID         int64
+}
+
+// Language returns the language the user has chosen for the messages of the bot,
+// or an empty string if the user has not chosen any.
+func (s *Service) Language(ctx context.Context, userID int64) (_ string, err error) {
+	ctx, span := startSpan(ctx, "Language", attribute.Int64("user_id", userID))
+	defer func() { endSpan(span, err) }()
+
+	user, err := s.users.GetUser(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user == nil {
+		return "", nil
+	}
+
+	return user.Language, nil
+}
+
+// SetLanguage stores the language the user has chosen for the messages of the bot.
+// It returns ErrInvalidLanguage if the language is empty.
+func (s *Service) SetLanguage(ctx context.Context, userID int64, lang string) (err error) {
+	ctx, span := startSpan(ctx, "SetLanguage", attribute.Int64("user_id", userID), attribute.String("language", lang))
+	defer func() { endSpan(span, err) }()
+
+	lang = strings.TrimSpace(lang)
+	if lang == "" {
+		return ErrInvalidLanguage
+	}
+
+	if err := s.users.SaveUser(ctx, &User{ID: userID, Language: lang, LastSeenAt: time.Now()}); err != nil {
+		return fmt.Errorf("failed to save user: %w", err)
+	}
+
+	return nil
+}
+
+// TouchUser records that the user has interacted with the bot, creating the user profile if needed,
+// so the profile tells when the user was last seen.
+func (s *Service) TouchUser(ctx context.Context, userID int64) (err error) {
+	ctx, span := startSpan(ctx, "TouchUser", attribute.Int64("user_id", userID))
+	defer func() { endSpan(span, err) }()
+
+	if err := s.users.TouchUser(ctx, userID, time.Now()); err != nil {
+		return fmt.Errorf("failed to touch user: %w", err)
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ package core
 
 import (
 	context "context"
+	time "time"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -23,6 +24,64 @@ func (_m *MockuserRepo) EXPECT() *MockuserRepo_Expecter {
 	return &MockuserRepo_Expecter{mock: &_m.Mock}
 }
 
+// AddSubscription provides a mock function with given fields: ctx, chatID, feed
+func (_m *MockuserRepo) AddSubscription(ctx context.Context, chatID int64, feed *FeedInfo) (bool, error) {
+	ret := _m.Called(ctx, chatID, feed)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddSubscription")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *FeedInfo) (bool, error)); ok {
+		return rf(ctx, chatID, feed)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *FeedInfo) bool); ok {
+		r0 = rf(ctx, chatID, feed)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, *FeedInfo) error); ok {
+		r1 = rf(ctx, chatID, feed)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockuserRepo_AddSubscription_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddSubscription'
+type MockuserRepo_AddSubscription_Call struct {
+	*mock.Call
+}
+
+// AddSubscription is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID int64
+//   - feed *FeedInfo
+func (_e *MockuserRepo_Expecter) AddSubscription(ctx interface{}, chatID interface{}, feed interface{}) *MockuserRepo_AddSubscription_Call {
+	return &MockuserRepo_AddSubscription_Call{Call: _e.mock.On("AddSubscription", ctx, chatID, feed)}
+}
+
+func (_c *MockuserRepo_AddSubscription_Call) Run(run func(ctx context.Context, chatID int64, feed *FeedInfo)) *MockuserRepo_AddSubscription_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(*FeedInfo))
+	})
+	return _c
+}
+
+func (_c *MockuserRepo_AddSubscription_Call) Return(_a0 bool, _a1 error) *MockuserRepo_AddSubscription_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockuserRepo_AddSubscription_Call) RunAndReturn(run func(context.Context, int64, *FeedInfo) (bool, error)) *MockuserRepo_AddSubscription_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // CheckHealth provides a mock function with given fields: ctx
 func (_m *MockuserRepo) CheckHealth(ctx context.Context) error {
 	ret := _m.Called(ctx)
@@ -69,6 +128,743 @@ func (_c *MockuserRepo_CheckHealth_Call) RunAndReturn(run func(context.Context)
 	return _c
 }
 
+// GetFeed provides a mock function with given fields: ctx, feedID
+func (_m *MockuserRepo) GetFeed(ctx context.Context, feedID string) (*FeedInfo, error) {
+	ret := _m.Called(ctx, feedID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFeed")
+	}
+
+	var r0 *FeedInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*FeedInfo, error)); ok {
+		return rf(ctx, feedID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *FeedInfo); ok {
+		r0 = rf(ctx, feedID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*FeedInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, feedID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockuserRepo_GetFeed_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetFeed'
+type MockuserRepo_GetFeed_Call struct {
+	*mock.Call
+}
+
+// GetFeed is a helper method to define mock.On call
+//   - ctx context.Context
+//   - feedID string
+func (_e *MockuserRepo_Expecter) GetFeed(ctx interface{}, feedID interface{}) *MockuserRepo_GetFeed_Call {
+	return &MockuserRepo_GetFeed_Call{Call: _e.mock.On("GetFeed", ctx, feedID)}
+}
+
+func (_c *MockuserRepo_GetFeed_Call) Run(run func(ctx context.Context, feedID string)) *MockuserRepo_GetFeed_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockuserRepo_GetFeed_Call) Return(_a0 *FeedInfo, _a1 error) *MockuserRepo_GetFeed_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockuserRepo_GetFeed_Call) RunAndReturn(run func(context.Context, string) (*FeedInfo, error)) *MockuserRepo_GetFeed_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSubscriptionSettings provides a mock function with given fields: ctx, chatID, feedID
+func (_m *MockuserRepo) GetSubscriptionSettings(ctx context.Context, chatID int64, feedID string) (*SubscriptionSettings, error) {
+	ret := _m.Called(ctx, chatID, feedID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSubscriptionSettings")
+	}
+
+	var r0 *SubscriptionSettings
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) (*SubscriptionSettings, error)); ok {
+		return rf(ctx, chatID, feedID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) *SubscriptionSettings); ok {
+		r0 = rf(ctx, chatID, feedID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*SubscriptionSettings)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, chatID, feedID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockuserRepo_GetSubscriptionSettings_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSubscriptionSettings'
+type MockuserRepo_GetSubscriptionSettings_Call struct {
+	*mock.Call
+}
+
+// GetSubscriptionSettings is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID int64
+//   - feedID string
+func (_e *MockuserRepo_Expecter) GetSubscriptionSettings(ctx interface{}, chatID interface{}, feedID interface{}) *MockuserRepo_GetSubscriptionSettings_Call {
+	return &MockuserRepo_GetSubscriptionSettings_Call{Call: _e.mock.On("GetSubscriptionSettings", ctx, chatID, feedID)}
+}
+
+func (_c *MockuserRepo_GetSubscriptionSettings_Call) Run(run func(ctx context.Context, chatID int64, feedID string)) *MockuserRepo_GetSubscriptionSettings_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockuserRepo_GetSubscriptionSettings_Call) Return(_a0 *SubscriptionSettings, _a1 error) *MockuserRepo_GetSubscriptionSettings_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockuserRepo_GetSubscriptionSettings_Call) RunAndReturn(run func(context.Context, int64, string) (*SubscriptionSettings, error)) *MockuserRepo_GetSubscriptionSettings_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUser provides a mock function with given fields: ctx, userID
+func (_m *MockuserRepo) GetUser(ctx context.Context, userID int64) (*User, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUser")
+	}
+
+	var r0 *User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*User, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *User); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockuserRepo_GetUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUser'
+type MockuserRepo_GetUser_Call struct {
+	*mock.Call
+}
+
+// GetUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+func (_e *MockuserRepo_Expecter) GetUser(ctx interface{}, userID interface{}) *MockuserRepo_GetUser_Call {
+	return &MockuserRepo_GetUser_Call{Call: _e.mock.On("GetUser", ctx, userID)}
+}
+
+func (_c *MockuserRepo_GetUser_Call) Run(run func(ctx context.Context, userID int64)) *MockuserRepo_GetUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockuserRepo_GetUser_Call) Return(_a0 *User, _a1 error) *MockuserRepo_GetUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockuserRepo_GetUser_Call) RunAndReturn(run func(context.Context, int64) (*User, error)) *MockuserRepo_GetUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListFeeds provides a mock function with given fields: ctx
+func (_m *MockuserRepo) ListFeeds(ctx context.Context) ([]FeedInfo, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListFeeds")
+	}
+
+	var r0 []FeedInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]FeedInfo, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []FeedInfo); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]FeedInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockuserRepo_ListFeeds_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListFeeds'
+type MockuserRepo_ListFeeds_Call struct {
+	*mock.Call
+}
+
+// ListFeeds is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockuserRepo_Expecter) ListFeeds(ctx interface{}) *MockuserRepo_ListFeeds_Call {
+	return &MockuserRepo_ListFeeds_Call{Call: _e.mock.On("ListFeeds", ctx)}
+}
+
+func (_c *MockuserRepo_ListFeeds_Call) Run(run func(ctx context.Context)) *MockuserRepo_ListFeeds_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockuserRepo_ListFeeds_Call) Return(_a0 []FeedInfo, _a1 error) *MockuserRepo_ListFeeds_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockuserRepo_ListFeeds_Call) RunAndReturn(run func(context.Context) ([]FeedInfo, error)) *MockuserRepo_ListFeeds_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListSubscribers provides a mock function with given fields: ctx, feedID
+func (_m *MockuserRepo) ListSubscribers(ctx context.Context, feedID string) ([]int64, error) {
+	ret := _m.Called(ctx, feedID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSubscribers")
+	}
+
+	var r0 []int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]int64, error)); ok {
+		return rf(ctx, feedID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []int64); ok {
+		r0 = rf(ctx, feedID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int64)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, feedID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockuserRepo_ListSubscribers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListSubscribers'
+type MockuserRepo_ListSubscribers_Call struct {
+	*mock.Call
+}
+
+// ListSubscribers is a helper method to define mock.On call
+//   - ctx context.Context
+//   - feedID string
+func (_e *MockuserRepo_Expecter) ListSubscribers(ctx interface{}, feedID interface{}) *MockuserRepo_ListSubscribers_Call {
+	return &MockuserRepo_ListSubscribers_Call{Call: _e.mock.On("ListSubscribers", ctx, feedID)}
+}
+
+func (_c *MockuserRepo_ListSubscribers_Call) Run(run func(ctx context.Context, feedID string)) *MockuserRepo_ListSubscribers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockuserRepo_ListSubscribers_Call) Return(_a0 []int64, _a1 error) *MockuserRepo_ListSubscribers_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockuserRepo_ListSubscribers_Call) RunAndReturn(run func(context.Context, string) ([]int64, error)) *MockuserRepo_ListSubscribers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListSubscriptions provides a mock function with given fields: ctx, chatID
+func (_m *MockuserRepo) ListSubscriptions(ctx context.Context, chatID int64) ([]FeedInfo, error) {
+	ret := _m.Called(ctx, chatID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSubscriptions")
+	}
+
+	var r0 []FeedInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]FeedInfo, error)); ok {
+		return rf(ctx, chatID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []FeedInfo); ok {
+		r0 = rf(ctx, chatID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]FeedInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, chatID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockuserRepo_ListSubscriptions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListSubscriptions'
+type MockuserRepo_ListSubscriptions_Call struct {
+	*mock.Call
+}
+
+// ListSubscriptions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID int64
+func (_e *MockuserRepo_Expecter) ListSubscriptions(ctx interface{}, chatID interface{}) *MockuserRepo_ListSubscriptions_Call {
+	return &MockuserRepo_ListSubscriptions_Call{Call: _e.mock.On("ListSubscriptions", ctx, chatID)}
+}
+
+func (_c *MockuserRepo_ListSubscriptions_Call) Run(run func(ctx context.Context, chatID int64)) *MockuserRepo_ListSubscriptions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockuserRepo_ListSubscriptions_Call) Return(_a0 []FeedInfo, _a1 error) *MockuserRepo_ListSubscriptions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockuserRepo_ListSubscriptions_Call) RunAndReturn(run func(context.Context, int64) ([]FeedInfo, error)) *MockuserRepo_ListSubscriptions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RemoveSubscription provides a mock function with given fields: ctx, chatID, feedID
+func (_m *MockuserRepo) RemoveSubscription(ctx context.Context, chatID int64, feedID string) (bool, int64, error) {
+	ret := _m.Called(ctx, chatID, feedID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveSubscription")
+	}
+
+	var r0 bool
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) (bool, int64, error)); ok {
+		return rf(ctx, chatID, feedID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) bool); ok {
+		r0 = rf(ctx, chatID, feedID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) int64); ok {
+		r1 = rf(ctx, chatID, feedID)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int64, string) error); ok {
+		r2 = rf(ctx, chatID, feedID)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockuserRepo_RemoveSubscription_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveSubscription'
+type MockuserRepo_RemoveSubscription_Call struct {
+	*mock.Call
+}
+
+// RemoveSubscription is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID int64
+//   - feedID string
+func (_e *MockuserRepo_Expecter) RemoveSubscription(ctx interface{}, chatID interface{}, feedID interface{}) *MockuserRepo_RemoveSubscription_Call {
+	return &MockuserRepo_RemoveSubscription_Call{Call: _e.mock.On("RemoveSubscription", ctx, chatID, feedID)}
+}
+
+func (_c *MockuserRepo_RemoveSubscription_Call) Run(run func(ctx context.Context, chatID int64, feedID string)) *MockuserRepo_RemoveSubscription_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockuserRepo_RemoveSubscription_Call) Return(removed bool, remaining int64, err error) *MockuserRepo_RemoveSubscription_Call {
+	_c.Call.Return(removed, remaining, err)
+	return _c
+}
+
+func (_c *MockuserRepo_RemoveSubscription_Call) RunAndReturn(run func(context.Context, int64, string) (bool, int64, error)) *MockuserRepo_RemoveSubscription_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveUser provides a mock function with given fields: ctx, user
+func (_m *MockuserRepo) SaveUser(ctx context.Context, user *User) error {
+	ret := _m.Called(ctx, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *User) error); ok {
+		r0 = rf(ctx, user)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockuserRepo_SaveUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveUser'
+type MockuserRepo_SaveUser_Call struct {
+	*mock.Call
+}
+
+// SaveUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - user *User
+func (_e *MockuserRepo_Expecter) SaveUser(ctx interface{}, user interface{}) *MockuserRepo_SaveUser_Call {
+	return &MockuserRepo_SaveUser_Call{Call: _e.mock.On("SaveUser", ctx, user)}
+}
+
+func (_c *MockuserRepo_SaveUser_Call) Run(run func(ctx context.Context, user *User)) *MockuserRepo_SaveUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*User))
+	})
+	return _c
+}
+
+func (_c *MockuserRepo_SaveUser_Call) Return(_a0 error) *MockuserRepo_SaveUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockuserRepo_SaveUser_Call) RunAndReturn(run func(context.Context, *User) error) *MockuserRepo_SaveUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetFeedFullText provides a mock function with given fields: ctx, feedID, enabled
+func (_m *MockuserRepo) SetFeedFullText(ctx context.Context, feedID string, enabled bool) (bool, error) {
+	ret := _m.Called(ctx, feedID, enabled)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetFeedFullText")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) (bool, error)); ok {
+		return rf(ctx, feedID, enabled)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) bool); ok {
+		r0 = rf(ctx, feedID, enabled)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = rf(ctx, feedID, enabled)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockuserRepo_SetFeedFullText_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetFeedFullText'
+type MockuserRepo_SetFeedFullText_Call struct {
+	*mock.Call
+}
+
+// SetFeedFullText is a helper method to define mock.On call
+//   - ctx context.Context
+//   - feedID string
+//   - enabled bool
+func (_e *MockuserRepo_Expecter) SetFeedFullText(ctx interface{}, feedID interface{}, enabled interface{}) *MockuserRepo_SetFeedFullText_Call {
+	return &MockuserRepo_SetFeedFullText_Call{Call: _e.mock.On("SetFeedFullText", ctx, feedID, enabled)}
+}
+
+func (_c *MockuserRepo_SetFeedFullText_Call) Run(run func(ctx context.Context, feedID string, enabled bool)) *MockuserRepo_SetFeedFullText_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *MockuserRepo_SetFeedFullText_Call) Return(_a0 bool, _a1 error) *MockuserRepo_SetFeedFullText_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockuserRepo_SetFeedFullText_Call) RunAndReturn(run func(context.Context, string, bool) (bool, error)) *MockuserRepo_SetFeedFullText_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetFeedInterval provides a mock function with given fields: ctx, feedID, interval
+func (_m *MockuserRepo) SetFeedInterval(ctx context.Context, feedID string, interval time.Duration) (bool, error) {
+	ret := _m.Called(ctx, feedID, interval)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetFeedInterval")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) (bool, error)); ok {
+		return rf(ctx, feedID, interval)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) bool); ok {
+		r0 = rf(ctx, feedID, interval)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Duration) error); ok {
+		r1 = rf(ctx, feedID, interval)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockuserRepo_SetFeedInterval_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetFeedInterval'
+type MockuserRepo_SetFeedInterval_Call struct {
+	*mock.Call
+}
+
+// SetFeedInterval is a helper method to define mock.On call
+//   - ctx context.Context
+//   - feedID string
+//   - interval time.Duration
+func (_e *MockuserRepo_Expecter) SetFeedInterval(ctx interface{}, feedID interface{}, interval interface{}) *MockuserRepo_SetFeedInterval_Call {
+	return &MockuserRepo_SetFeedInterval_Call{Call: _e.mock.On("SetFeedInterval", ctx, feedID, interval)}
+}
+
+func (_c *MockuserRepo_SetFeedInterval_Call) Run(run func(ctx context.Context, feedID string, interval time.Duration)) *MockuserRepo_SetFeedInterval_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockuserRepo_SetFeedInterval_Call) Return(_a0 bool, _a1 error) *MockuserRepo_SetFeedInterval_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockuserRepo_SetFeedInterval_Call) RunAndReturn(run func(context.Context, string, time.Duration) (bool, error)) *MockuserRepo_SetFeedInterval_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TouchUser provides a mock function with given fields: ctx, userID, seenAt
+func (_m *MockuserRepo) TouchUser(ctx context.Context, userID int64, seenAt time.Time) error {
+	ret := _m.Called(ctx, userID, seenAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TouchUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time) error); ok {
+		r0 = rf(ctx, userID, seenAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockuserRepo_TouchUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TouchUser'
+type MockuserRepo_TouchUser_Call struct {
+	*mock.Call
+}
+
+// TouchUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - seenAt time.Time
+func (_e *MockuserRepo_Expecter) TouchUser(ctx interface{}, userID interface{}, seenAt interface{}) *MockuserRepo_TouchUser_Call {
+	return &MockuserRepo_TouchUser_Call{Call: _e.mock.On("TouchUser", ctx, userID, seenAt)}
+}
+
+func (_c *MockuserRepo_TouchUser_Call) Run(run func(ctx context.Context, userID int64, seenAt time.Time)) *MockuserRepo_TouchUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockuserRepo_TouchUser_Call) Return(_a0 error) *MockuserRepo_TouchUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockuserRepo_TouchUser_Call) RunAndReturn(run func(context.Context, int64, time.Time) error) *MockuserRepo_TouchUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateFeedStatus provides a mock function with given fields: ctx, feedID, status
+func (_m *MockuserRepo) UpdateFeedStatus(ctx context.Context, feedID string, status *FeedStatus) error {
+	ret := _m.Called(ctx, feedID, status)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateFeedStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *FeedStatus) error); ok {
+		r0 = rf(ctx, feedID, status)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockuserRepo_UpdateFeedStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateFeedStatus'
+type MockuserRepo_UpdateFeedStatus_Call struct {
+	*mock.Call
+}
+
+// UpdateFeedStatus is a helper method to define mock.On call
+//   - ctx context.Context
+//   - feedID string
+//   - status *FeedStatus
+func (_e *MockuserRepo_Expecter) UpdateFeedStatus(ctx interface{}, feedID interface{}, status interface{}) *MockuserRepo_UpdateFeedStatus_Call {
+	return &MockuserRepo_UpdateFeedStatus_Call{Call: _e.mock.On("UpdateFeedStatus", ctx, feedID, status)}
+}
+
+func (_c *MockuserRepo_UpdateFeedStatus_Call) Run(run func(ctx context.Context, feedID string, status *FeedStatus)) *MockuserRepo_UpdateFeedStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*FeedStatus))
+	})
+	return _c
+}
+
+func (_c *MockuserRepo_UpdateFeedStatus_Call) Return(_a0 error) *MockuserRepo_UpdateFeedStatus_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockuserRepo_UpdateFeedStatus_Call) RunAndReturn(run func(context.Context, string, *FeedStatus) error) *MockuserRepo_UpdateFeedStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateSubscriptionSettings provides a mock function with given fields: ctx, chatID, feedID, settings
+func (_m *MockuserRepo) UpdateSubscriptionSettings(ctx context.Context, chatID int64, feedID string, settings *SubscriptionSettings) (bool, error) {
+	ret := _m.Called(ctx, chatID, feedID, settings)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSubscriptionSettings")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, *SubscriptionSettings) (bool, error)); ok {
+		return rf(ctx, chatID, feedID, settings)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, *SubscriptionSettings) bool); ok {
+		r0 = rf(ctx, chatID, feedID, settings)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, *SubscriptionSettings) error); ok {
+		r1 = rf(ctx, chatID, feedID, settings)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockuserRepo_UpdateSubscriptionSettings_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateSubscriptionSettings'
+type MockuserRepo_UpdateSubscriptionSettings_Call struct {
+	*mock.Call
+}
+
+// UpdateSubscriptionSettings is a helper method to define mock.On call
+//   - ctx context.Context
+//   - chatID int64
+//   - feedID string
+//   - settings *SubscriptionSettings
+func (_e *MockuserRepo_Expecter) UpdateSubscriptionSettings(ctx interface{}, chatID interface{}, feedID interface{}, settings interface{}) *MockuserRepo_UpdateSubscriptionSettings_Call {
+	return &MockuserRepo_UpdateSubscriptionSettings_Call{Call: _e.mock.On("UpdateSubscriptionSettings", ctx, chatID, feedID, settings)}
+}
+
+func (_c *MockuserRepo_UpdateSubscriptionSettings_Call) Run(run func(ctx context.Context, chatID int64, feedID string, settings *SubscriptionSettings)) *MockuserRepo_UpdateSubscriptionSettings_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string), args[3].(*SubscriptionSettings))
+	})
+	return _c
+}
+
+func (_c *MockuserRepo_UpdateSubscriptionSettings_Call) Return(_a0 bool, _a1 error) *MockuserRepo_UpdateSubscriptionSettings_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockuserRepo_UpdateSubscriptionSettings_Call) RunAndReturn(run func(context.Context, int64, string, *SubscriptionSettings) (bool, error)) *MockuserRepo_UpdateSubscriptionSettings_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockuserRepo creates a new instance of MockuserRepo. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockuserRepo(t interface {
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newUserTestService(t *testing.T, users *MockuserRepo) *Service {
+	t.Helper()
+
+	return New(&Config{}, users, NewMocksomeAPIProv(t), NewMockfeedProv(t), NewMockfetchCache(t), NewMockseenStore(t),
+		NewMockarticleProv(t), NewMocksummarizer(t), NewMockoutbox(t))
+}
+
+func TestService_Language(t *testing.T) {
+	tests := []struct {
+		setupMocks func(t *testing.T, users *MockuserRepo)
+		name       string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name: "chosen language",
+			setupMocks: func(t *testing.T, users *MockuserRepo) {
+				t.Helper()
+				users.EXPECT().GetUser(mock.Anything, int64(42)).Return(&User{ID: 42, Language: "ru"}, nil)
+			},
+			want: "ru",
+		},
+		{
+			name: "unknown user",
+			setupMocks: func(t *testing.T, users *MockuserRepo) {
+				t.Helper()
+				users.EXPECT().GetUser(mock.Anything, int64(42)).Return(nil, nil)
+			},
+		},
+		{
+			name: "repository error",
+			setupMocks: func(t *testing.T, users *MockuserRepo) {
+				t.Helper()
+				users.EXPECT().GetUser(mock.Anything, int64(42)).Return(nil, assert.AnError)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := NewMockuserRepo(t)
+			tt.setupMocks(t, users)
+
+			got, err := newUserTestService(t, users).Language(context.Background(), 42)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, assert.AnError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_SetLanguage(t *testing.T) {
+	tests := []struct {
+		setupMocks func(t *testing.T, users *MockuserRepo)
+		wantErr    error
+		name       string
+		lang       string
+	}{
+		{
+			name: "language stored",
+			lang: " ru ",
+			setupMocks: func(t *testing.T, users *MockuserRepo) {
+				t.Helper()
+				users.EXPECT().SaveUser(mock.Anything, mock.MatchedBy(func(u *User) bool {
+					return u.ID == 42 && u.Language == "ru" && !u.LastSeenAt.IsZero()
+				})).Return(nil)
+			},
+		},
+		{
+			name:       "empty language",
+			lang:       " ",
+			setupMocks: func(_ *testing.T, _ *MockuserRepo) {},
+			wantErr:    ErrInvalidLanguage,
+		},
+		{
+			name: "repository error",
+			lang: "ru",
+			setupMocks: func(t *testing.T, users *MockuserRepo) {
+				t.Helper()
+				users.EXPECT().SaveUser(mock.Anything, mock.Anything).Return(assert.AnError)
+			},
+			wantErr: assert.AnError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := NewMockuserRepo(t)
+			tt.setupMocks(t, users)
+
+			err := newUserTestService(t, users).SetLanguage(context.Background(), 42, tt.lang)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestService_TouchUser(t *testing.T) {
+	users := NewMockuserRepo(t)
+	s := newUserTestService(t, users)
+
+	users.EXPECT().TouchUser(mock.Anything, int64(42), mock.AnythingOfType("time.Time")).Return(nil).Once()
+	require.NoError(t, s.TouchUser(context.Background(), 42))
+
+	users.EXPECT().TouchUser(mock.Anything, int64(42), mock.Anything).Return(assert.AnError).Once()
+	assert.ErrorIs(t, s.TouchUser(context.Background(), 42), assert.AnError)
+}
@@ -0,0 +1,22 @@
+package i18n
+
+import "context"
+
+// key is the type of the context keys of the package, which cannot collide with the keys of other packages.
+type key int
+
+const printerKey key = iota
+
+// WithPrinter returns a copy of the context holding the printer of the language of the user being served.
+func WithPrinter(ctx context.Context, p *Printer) context.Context {
+	return context.WithValue(ctx, printerKey, p)
+}
+
+// FromContext returns the printer stored in the context, or the printer of the default language when there is none.
+func FromContext(ctx context.Context) *Printer {
+	if p, ok := ctx.Value(printerKey).(*Printer); ok && p != nil {
+		return p
+	}
+
+	return defaultPrinter
+}
@@ -0,0 +1,145 @@
+// Package i18n translates the messages of the bot into the languages of its users.
+// Messages are identified by their English text, like gettext message ids, so the code keeps its English messages
+// inline and the catalogs embedded from the locales directory map them to their translations.
+// Messages missing from a catalog are shown in English.
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// DefaultLanguage is the language of the message ids, used for the users whose language has no catalog.
+const DefaultLanguage = "en"
+
+//go:embed locales/*.yaml
+var locales embed.FS
+
+// Language describes a language the bot speaks.
+type Language struct {
+	Code string
+	Name string
+}
+
+// Bundle holds the message catalogs of the languages the bot speaks.
+type Bundle struct {
+	catalogs  map[string]*catalog
+	languages []Language
+}
+
+// catalogFile is the content of a catalog file. Messages map the English text to its translation, or to the
+// translations for every plural form of the language for the messages depending on a number.
+type catalogFile struct {
+	Messages map[string]message `yaml:"messages"`
+	Name     string             `yaml:"name"`
+}
+
+// New loads the message catalogs embedded into the package.
+// It returns an error if a catalog cannot be parsed or does not match the plural rules of its language.
+func New() (*Bundle, error) {
+	return load(locales)
+}
+
+// load loads the catalogs from the locales directory of the file system, one file per language named after its code.
+func load(fsys fs.FS) (*Bundle, error) {
+	files, err := fs.Glob(fsys, "locales/*.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list catalogs: %w", err)
+	}
+
+	b := &Bundle{catalogs: make(map[string]*catalog, len(files))}
+
+	for _, file := range files {
+		lang := strings.TrimSuffix(path.Base(file), path.Ext(file))
+
+		c, err := loadCatalog(fsys, file, lang)
+		if err != nil {
+			return nil, err
+		}
+
+		b.catalogs[lang] = c
+		b.languages = append(b.languages, Language{Code: lang, Name: c.name})
+	}
+
+	if _, ok := b.catalogs[DefaultLanguage]; !ok {
+		return nil, fmt.Errorf("catalog of the default language %q is missing", DefaultLanguage)
+	}
+
+	slices.SortFunc(b.languages, func(a, b Language) int {
+		return strings.Compare(a.Code, b.Code)
+	})
+
+	return b, nil
+}
+
+// loadCatalog parses the catalog file of the language and checks its plural forms.
+func loadCatalog(fsys fs.FS, file, lang string) (*catalog, error) {
+	rule, ok := pluralRules[lang]
+	if !ok {
+		return nil, fmt.Errorf("no plural rule for the language of catalog %s", file)
+	}
+
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog %s: %w", file, err)
+	}
+
+	var content catalogFile
+	if err := yaml.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("failed to parse catalog %s: %w", file, err)
+	}
+
+	if content.Name == "" {
+		return nil, fmt.Errorf("catalog %s has no language name", file)
+	}
+
+	for id, msg := range content.Messages {
+		if err := msg.check(rule); err != nil {
+			return nil, fmt.Errorf("invalid message %q in catalog %s: %w", id, file, err)
+		}
+	}
+
+	return &catalog{lang: lang, name: content.Name, rule: rule, messages: content.Messages}, nil
+}
+
+// Languages returns the languages the bot speaks ordered by their codes.
+func (b *Bundle) Languages() []Language {
+	if b == nil {
+		return []Language{{Code: DefaultLanguage, Name: defaultPrinter.catalog.name}}
+	}
+
+	return slices.Clone(b.languages)
+}
+
+// Match returns the code of the language of the bundle matching the language tag, such as the IETF tag
+// reported by Telegram for the user, and whether there is one. Regional variants match their base language.
+func (b *Bundle) Match(tag string) (string, bool) {
+	lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	lang, _, _ = strings.Cut(lang, "_")
+
+	if b == nil {
+		return DefaultLanguage, lang == DefaultLanguage
+	}
+
+	if _, ok := b.catalogs[lang]; !ok {
+		return "", false
+	}
+
+	return lang, true
+}
+
+// Printer returns the printer of the language matching the tag, falling back to the default language.
+func (b *Bundle) Printer(tag string) *Printer {
+	lang, ok := b.Match(tag)
+	if !ok || b == nil {
+		return defaultPrinter
+	}
+
+	return &Printer{catalog: b.catalogs[lang]}
+}
@@ -0,0 +1,231 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// verbPattern matches the formatting verbs of a message, escaped percent signs included.
+var verbPattern = regexp.MustCompile(`%[-+# 0]*\d*(\.\d+)?[a-zA-Z%]`)
+
+func TestNew(t *testing.T) {
+	b, err := New()
+	require.NoError(t, err)
+
+	assert.Equal(t, []Language{{Code: "en", Name: "English"}, {Code: "ru", Name: "Русский"}}, b.Languages())
+}
+
+func TestCatalogs_FormatVerbs(t *testing.T) {
+	b, err := New()
+	require.NoError(t, err)
+
+	for lang, c := range b.catalogs {
+		for id, msg := range c.messages {
+			args := formatArgs(id)
+
+			texts := []string{msg.text}
+			if msg.forms != nil {
+				texts = texts[:0]
+				for _, text := range msg.forms {
+					texts = append(texts, text)
+				}
+			}
+
+			for _, text := range texts {
+				out := fmt.Sprintf(text, args...)
+				assert.NotContains(t, out, "%!", "%s translation of %q does not match its format verbs", lang, id)
+			}
+		}
+	}
+}
+
+// formatArgs returns arguments of the types expected by the formatting verbs of the message.
+func formatArgs(msg string) []any {
+	var args []any
+
+	for _, verb := range verbPattern.FindAllString(msg, -1) {
+		switch {
+		case strings.HasSuffix(verb, "%"):
+		case strings.HasSuffix(verb, "d"):
+			args = append(args, 1)
+		default:
+			args = append(args, "x")
+		}
+	}
+
+	return args
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		files   fstest.MapFS
+		name    string
+		wantErr string
+	}{
+		{
+			name:    "no default language",
+			files:   fstest.MapFS{"locales/ru.yaml": {Data: []byte("name: Русский\n")}},
+			wantErr: "catalog of the default language",
+		},
+		{
+			name:    "no plural rule",
+			files:   fstest.MapFS{"locales/xx.yaml": {Data: []byte("name: X\n")}},
+			wantErr: "no plural rule",
+		},
+		{
+			name:    "invalid yaml",
+			files:   fstest.MapFS{"locales/en.yaml": {Data: []byte("name: [\n")}},
+			wantErr: "failed to parse catalog",
+		},
+		{
+			name:    "no name",
+			files:   fstest.MapFS{"locales/en.yaml": {Data: []byte("messages: {}\n")}},
+			wantErr: "has no language name",
+		},
+		{
+			name:    "list translation",
+			files:   fstest.MapFS{"locales/en.yaml": {Data: []byte("name: English\nmessages:\n  a: [b]\n")}},
+			wantErr: "must be a string or a mapping of plural forms",
+		},
+		{
+			name:    "empty translation",
+			files:   fstest.MapFS{"locales/en.yaml": {Data: []byte("name: English\nmessages:\n  a: \"\"\n")}},
+			wantErr: "translation is empty",
+		},
+		{
+			name: "missing plural form",
+			files: fstest.MapFS{
+				"locales/en.yaml": {Data: []byte("name: English\n")},
+				"locales/ru.yaml": {Data: []byte("name: Русский\nmessages:\n  a:\n    one: b\n    few: c\n    other: d\n")},
+			},
+			wantErr: `plural form "many" is missing`,
+		},
+		{
+			name: "extra plural form",
+			files: fstest.MapFS{
+				"locales/en.yaml": {Data: []byte("name: English\nmessages:\n  a:\n    one: b\n    few: c\n    other: d\n")},
+			},
+			wantErr: "translation must have the plural forms one, other",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := load(tt.files)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestBundle_Match(t *testing.T) {
+	b, err := New()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		tag    string
+		want   string
+		wantOK bool
+	}{
+		{name: "exact", tag: "ru", want: "ru", wantOK: true},
+		{name: "region", tag: "en-US", want: "en", wantOK: true},
+		{name: "underscore and case", tag: "RU_ru", want: "ru", wantOK: true},
+		{name: "unknown", tag: "de", wantOK: false},
+		{name: "empty", tag: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := b.Match(tt.tag)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBundle_Printer(t *testing.T) {
+	b, err := New()
+	require.NoError(t, err)
+
+	assert.Equal(t, "ru", b.Printer("ru-RU").Language())
+	assert.Equal(t, "en", b.Printer("de").Language(), "unknown languages fall back to the default one")
+	assert.Equal(t, "en", b.Printer("").Language())
+
+	var nilBundle *Bundle
+
+	assert.Equal(t, "en", nilBundle.Printer("ru").Language())
+	assert.Equal(t, []Language{{Code: "en", Name: "English"}}, nilBundle.Languages())
+}
+
+func TestPrinter(t *testing.T) {
+	b, err := New()
+	require.NoError(t, err)
+
+	ru := b.Printer("ru")
+	en := b.Printer("en")
+
+	var nilPrinter *Printer
+
+	assert.Equal(t, "Отменено.", ru.Text("Cancelled."))
+	assert.Equal(t, "Cancelled.", en.Text("Cancelled."))
+	assert.Equal(t, "Cancelled.", nilPrinter.Text("Cancelled."))
+	assert.Equal(t, "not translated", ru.Text("not translated"), "missing messages are shown in English")
+
+	assert.Equal(t, "Фильтр: go", ru.Sprintf("Filter: %s", "go"))
+	assert.Equal(t, "Filter: go", en.Sprintf("Filter: %s", "go"))
+	assert.Equal(t, "Filter: go", nilPrinter.Sprintf("Filter: %s", "go"))
+}
+
+func TestPrinter_Plural(t *testing.T) {
+	b, err := New()
+	require.NoError(t, err)
+
+	const (
+		one   = "You are subscribed to %d feed:"
+		other = "You are subscribed to %d feeds:"
+	)
+
+	tests := []struct {
+		lang string
+		want string
+		n    int
+	}{
+		{lang: "en", n: 0, want: "You are subscribed to 0 feeds:"},
+		{lang: "en", n: 1, want: "You are subscribed to 1 feed:"},
+		{lang: "en", n: 2, want: "You are subscribed to 2 feeds:"},
+		{lang: "ru", n: 1, want: "Вы подписаны на 1 ленту:"},
+		{lang: "ru", n: 3, want: "Вы подписаны на 3 ленты:"},
+		{lang: "ru", n: 5, want: "Вы подписаны на 5 лент:"},
+		{lang: "ru", n: 11, want: "Вы подписаны на 11 лент:"},
+		{lang: "ru", n: 21, want: "Вы подписаны на 21 ленту:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s %d", tt.lang, tt.n), func(t *testing.T) {
+			assert.Equal(t, tt.want, b.Printer(tt.lang).Plural(tt.n, one, other, tt.n))
+		})
+	}
+
+	assert.Equal(t, "2 items", b.Printer("ru").Plural(2, "%d item", "%d items", 2), "missing messages use English plurals")
+}
+
+func TestContext(t *testing.T) {
+	b, err := New()
+	require.NoError(t, err)
+
+	assert.Equal(t, "en", FromContext(context.Background()).Language())
+
+	ctx := WithPrinter(context.Background(), b.Printer("ru"))
+	assert.Equal(t, "ru", FromContext(ctx).Language())
+
+	ctx = WithPrinter(context.Background(), nil)
+	assert.Equal(t, "en", FromContext(ctx).Language())
+}
@@ -0,0 +1,95 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Plural forms, named after the CLDR plural categories.
+const (
+	formOne   = "one"
+	formFew   = "few"
+	formMany  = "many"
+	formOther = "other"
+)
+
+// pluralRule selects the plural form of a language for a number.
+type pluralRule struct {
+	form  func(n int) string
+	forms []string
+}
+
+// pluralRules lists the plural rules of the languages having a catalog.
+var pluralRules = map[string]pluralRule{
+	"en": {forms: []string{formOne, formOther}, form: oneOther},
+	"ru": {forms: []string{formOne, formFew, formMany}, form: slavic},
+}
+
+// oneOther is the plural rule of the languages telling only one from the other numbers, such as English.
+func oneOther(n int) string {
+	if n == 1 {
+		return formOne
+	}
+
+	return formOther
+}
+
+// slavic is the plural rule of the East Slavic languages: one for 1, 21, 31..., few for 2-4, 22-24...
+// and many for the other numbers, 11-14 included.
+func slavic(n int) string {
+	if n < 0 {
+		n = -n
+	}
+
+	switch mod10, mod100 := n%10, n%100; {
+	case mod10 == 1 && mod100 != 11:
+		return formOne
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return formFew
+	default:
+		return formMany
+	}
+}
+
+// message is the translation of a message: a text, or the texts for the plural forms of the language.
+type message struct {
+	forms map[string]string
+	text  string
+}
+
+// UnmarshalYAML decodes a translation given either as a string or as a mapping of plural forms to strings.
+func (m *message) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Decode(&m.text)
+	case yaml.MappingNode:
+		return node.Decode(&m.forms)
+	default:
+		return fmt.Errorf("line %d: translation must be a string or a mapping of plural forms", node.Line)
+	}
+}
+
+// check reports whether the translation is not empty and has exactly the plural forms of the rule when it has any.
+func (m *message) check(rule pluralRule) error {
+	if m.forms == nil {
+		if strings.TrimSpace(m.text) == "" {
+			return fmt.Errorf("translation is empty")
+		}
+
+		return nil
+	}
+
+	if len(m.forms) != len(rule.forms) {
+		return fmt.Errorf("translation must have the plural forms %s", strings.Join(rule.forms, ", "))
+	}
+
+	for _, form := range rule.forms {
+		if strings.TrimSpace(m.forms[form]) == "" {
+			return fmt.Errorf("plural form %q is missing", form)
+		}
+	}
+
+	return nil
+}
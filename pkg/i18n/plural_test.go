@@ -0,0 +1,38 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluralRules(t *testing.T) {
+	tests := []struct {
+		lang string
+		want string
+		n    int
+	}{
+		{lang: "en", n: 0, want: formOther},
+		{lang: "en", n: 1, want: formOne},
+		{lang: "en", n: 2, want: formOther},
+		{lang: "ru", n: 0, want: formMany},
+		{lang: "ru", n: 1, want: formOne},
+		{lang: "ru", n: 2, want: formFew},
+		{lang: "ru", n: 4, want: formFew},
+		{lang: "ru", n: 5, want: formMany},
+		{lang: "ru", n: 11, want: formMany},
+		{lang: "ru", n: 12, want: formMany},
+		{lang: "ru", n: 14, want: formMany},
+		{lang: "ru", n: 21, want: formOne},
+		{lang: "ru", n: 22, want: formFew},
+		{lang: "ru", n: 111, want: formMany},
+		{lang: "ru", n: 101, want: formOne},
+		{lang: "ru", n: -1, want: formOne},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			assert.Equal(t, tt.want, pluralRules[tt.lang].form(tt.n), "n = %d", tt.n)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package i18n
+
+import "fmt"
+
+// defaultPrinter prints the messages as they are, in the default language.
+var defaultPrinter = &Printer{catalog: &catalog{lang: DefaultLanguage, name: "English", rule: pluralRules[DefaultLanguage]}}
+
+// catalog holds the translations of the messages into a language.
+type catalog struct {
+	messages map[string]message
+	lang     string
+	name     string
+	rule     pluralRule
+}
+
+// Printer translates the messages into a language. A nil Printer prints the messages in the default language.
+type Printer struct {
+	catalog *catalog
+}
+
+// Language returns the code of the language of the printer.
+func (p *Printer) Language() string {
+	return p.cat().lang
+}
+
+// Text returns the translation of the message.
+func (p *Printer) Text(msg string) string {
+	if m, ok := p.cat().messages[msg]; ok && m.text != "" {
+		return m.text
+	}
+
+	return msg
+}
+
+// Sprintf formats the arguments according to the translation of the format.
+func (p *Printer) Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(p.Text(format), args...)
+}
+
+// Plural formats the arguments according to the translation of the message for the number n.
+// The message is identified by its English singular form one, other being its English plural form,
+// and both are used for the languages with no translation of it.
+func (p *Printer) Plural(n int, one, other string, args ...any) string {
+	if m, ok := p.cat().messages[one]; ok && m.forms != nil {
+		return fmt.Sprintf(m.forms[p.cat().rule.form(n)], args...)
+	}
+
+	format := other
+	if pluralRules[DefaultLanguage].form(n) == formOne {
+		format = one
+	}
+
+	return fmt.Sprintf(format, args...)
+}
+
+// cat returns the catalog of the printer, the default one for a nil printer.
+func (p *Printer) cat() *catalog {
+	if p == nil || p.catalog == nil {
+		return defaultPrinter.catalog
+	}
+
+	return p.catalog
+}
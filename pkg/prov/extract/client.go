@@ -0,0 +1,194 @@
+// Package extract provides a client that downloads web pages and extracts their main content and metadata
+// using a readability style algorithm complemented by OpenGraph and JSON-LD metadata.
+package extract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/ksysoev/tg-feeder/pkg/prov/safenet"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	defaultTimeout       = 15 * time.Second
+	defaultMaxBodySize   = 5 << 20 // 5 MiB
+	defaultMaxTextLength = 100_000
+	defaultUserAgent     = "tg-feeder/1.0 (+https://github.com/ksysoev/tg-feeder)"
+)
+
+// ErrNotHTML is returned when the fetched document is not an HTML page.
+// Extract wraps it with core.ErrValidation, as such a link cannot be used to read an article.
+var ErrNotHTML = errors.New("document is not html")
+
+// Config holds configuration for the extract Client.
+// Pages larger than MaxBodySize bytes are truncated before parsing and the extracted text
+// is cut to MaxTextLength characters. Pages on loopback, private and link-local addresses are refused
+// unless AllowPrivateNetworks is set.
+type Config struct {
+	UserAgent            string        `mapstructure:"user_agent"`
+	Timeout              time.Duration `mapstructure:"timeout"`
+	MaxBodySize          int64         `mapstructure:"max_body_size"`
+	MaxTextLength        int           `mapstructure:"max_text_length"`
+	AllowPrivateNetworks bool          `mapstructure:"allow_private_networks"`
+}
+
+// Client downloads web pages and converts them into core.Article values.
+type Client struct {
+	cli *http.Client
+	cfg Config
+}
+
+// New creates a new Client with the provided configuration, applying defaults for unset values.
+func New(cfg Config) *Client {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = defaultMaxBodySize
+	}
+
+	if cfg.MaxTextLength <= 0 {
+		cfg.MaxTextLength = defaultMaxTextLength
+	}
+
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = defaultUserAgent
+	}
+
+	var transport http.RoundTripper = safenet.NewTransport()
+	if cfg.AllowPrivateNetworks {
+		transport = http.DefaultTransport
+	}
+
+	return &Client{
+		cfg: cfg,
+		cli: &http.Client{
+			Transport: otelhttp.NewTransport(transport),
+			Timeout:   cfg.Timeout,
+		},
+	}
+}
+
+// Extract downloads the page located at url and extracts its main content, title, byline,
+// lead image, site name and publish date. The charset is detected from the Content-Type header,
+// the byte order mark or the meta tags of the page.
+// It returns an error if the request fails, and ErrNotHTML classified as core.ErrValidation
+// if the document is not an HTML page.
+func (c *Client) Extract(ctx context.Context, url string) (*core.Article, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", c.cfg.UserAgent)
+	req.Header.Set("Accept", "text/html, application/xhtml+xml;q=0.9, */*;q=0.1")
+
+	resp, err := c.cli.Do(req)
+
+	switch {
+	case errors.Is(err, safenet.ErrForbiddenAddress):
+		return nil, fmt.Errorf("%w: failed to fetch page: %w", core.ErrValidation, err)
+	case err != nil:
+		return nil, fmt.Errorf("%w: failed to fetch page: %w", core.ErrUpstreamUnavailable, err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, core.StatusError(resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("%w: %w: %s", core.ErrValidation, ErrNotHTML, mediaType)
+	}
+
+	body, err := charset.NewReader(io.LimitReader(resp.Body, c.cfg.MaxBodySize), contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode page: %w", err)
+	}
+
+	doc, err := html.Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse page: %w", err)
+	}
+
+	return c.parse(doc, resp.Request.URL), nil
+}
+
+// parse extracts the article from the parsed page located at pageURL.
+func (c *Client) parse(doc *html.Node, pageURL *url.URL) *core.Article {
+	base := baseURL(doc, pageURL)
+	md := readMetadata(doc)
+	main := extractContent(doc)
+
+	imageURL := md.image
+	if imageURL == "" {
+		imageURL = firstImage(main.nodes)
+	}
+
+	return &core.Article{
+		URL:       pageURL.String(),
+		Title:     md.title,
+		Byline:    md.byline,
+		SiteName:  md.siteName,
+		ImageURL:  resolveURL(base, imageURL),
+		Published: md.published,
+		Text:      truncate(main.Text(), c.cfg.MaxTextLength),
+		HTML:      main.HTML(base),
+	}
+}
+
+// baseURL returns the URL relative links of the page are resolved against, honouring the base element.
+func baseURL(doc *html.Node, pageURL *url.URL) *url.URL {
+	if b := findFirst(doc, "base"); b != nil {
+		if u, err := pageURL.Parse(attr(b, "href")); err == nil && attr(b, "href") != "" {
+			return u
+		}
+	}
+
+	return pageURL
+}
+
+// firstImage returns the source of the first image of the content.
+func firstImage(nodes []*html.Node) string {
+	for _, n := range nodes {
+		if img := findFirst(n, "img"); img != nil {
+			return firstNonEmpty(attr(img, "src"), attr(img, "data-src"))
+		}
+	}
+
+	return ""
+}
+
+// truncate shortens the text to at most limit characters, cutting at the last paragraph or word boundary.
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+
+	cut := string(runes[:limit])
+
+	if i := strings.LastIndex(cut, "\n\n"); i > len(cut)/2 {
+		return cut[:i]
+	}
+
+	if i := strings.LastIndexAny(cut, " \n"); i > 0 {
+		return cut[:i] + "…"
+	}
+
+	return cut + "…"
+}
@@ -0,0 +1,219 @@
+package extract
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	cli := New(Config{})
+
+	assert.Equal(t, defaultTimeout, cli.cfg.Timeout)
+	assert.Equal(t, int64(defaultMaxBodySize), cli.cfg.MaxBodySize)
+	assert.Equal(t, defaultMaxTextLength, cli.cfg.MaxTextLength)
+	assert.Equal(t, defaultUserAgent, cli.cfg.UserAgent)
+}
+
+func TestClient_ExtractArticle(t *testing.T) {
+	page, err := os.ReadFile("testdata/article.html")
+	require.NoError(t, err)
+
+	srv := newPageServer(t, "text/html; charset=utf-8", page)
+
+	article, err := New(Config{AllowPrivateNetworks: true}).Extract(t.Context(), srv.URL)
+	require.NoError(t, err)
+
+	assert.Equal(t, srv.URL, article.URL)
+	assert.Equal(t, "How we ship Go services", article.Title)
+	assert.Equal(t, "Jane Doe, John Roe", article.Byline)
+	assert.Equal(t, "Example Blog", article.SiteName)
+	assert.Equal(t, "https://cdn.example.com/lead.jpg", article.ImageURL)
+	assert.Equal(t, time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC), article.Published)
+
+	assert.Equal(t, strings.Join([]string{
+		"How we ship Go services",
+		"We deploy dozens of Go services every day, and over the years we have settled on a small set of practices.",
+		"First, every service is built as a static binary, packaged into a minimal image, and tagged with the commit hash.",
+		"Second, we roll out gradually, watching error rates, latency, and saturation before moving on to the next region.",
+		"Finally, we keep rollbacks boring: the previous image is always one command away, and nobody needs to be paged.",
+	}, "\n\n"), article.Text)
+
+	assert.Contains(t, article.HTML, `<a href="https://blog.example.com/tools/static-binary">static binary</a>`)
+	assert.Contains(t, article.HTML, `<img src="https://blog.example.com/posts/diagram.png" alt="Rollout diagram"/>`)
+	assert.NotContains(t, article.HTML, "class=")
+	assert.NotContains(t, article.HTML, "Share on Twitter")
+	assert.NotContains(t, article.HTML, "Popular posts")
+	assert.NotContains(t, article.HTML, "Great post")
+	assert.NotContains(t, article.HTML, "ad slot")
+}
+
+func TestClient_ExtractCharset(t *testing.T) {
+	page, err := os.ReadFile("testdata/cp1251.html")
+	require.NoError(t, err)
+
+	srv := newPageServer(t, "text/html", page)
+
+	article, err := New(Config{AllowPrivateNetworks: true}).Extract(t.Context(), srv.URL)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Новости", article.Title)
+	assert.Equal(t, "Привет, мир! Это текст статьи, достаточно длинный.", article.Text)
+}
+
+func TestClient_ExtractFallbacks(t *testing.T) {
+	tests := []struct {
+		name      string
+		page      string
+		wantTitle string
+		wantText  string
+		wantImage string
+		wantBy    string
+	}{
+		{
+			name: "opengraph metadata",
+			page: `<html><head><title>Page</title>
+				<meta property="og:title" content="OG title">
+				<meta property="article:author" content="https://example.com/authors/jane">
+				<meta name="twitter:creator" content="@jane">
+				<meta name="twitter:image" content="https://example.com/tw.png">
+				</head><body><main><p>Some article text that is long enough to be scored.</p></main></body></html>`,
+			wantTitle: "OG title",
+			wantText:  "Some article text that is long enough to be scored.",
+			wantImage: "https://example.com/tw.png",
+			wantBy:    "@jane",
+		},
+		{
+			name:      "no scorable paragraphs",
+			page:      `<html><head><title>Page</title></head><body><header><h1>Site</h1></header><p>Text</p></body></html>`,
+			wantTitle: "Page",
+			wantText:  "Text",
+		},
+		{
+			name: "lazy loaded content image",
+			page: `<html><body><article><img data-src="/lazy.png"><img src="/second.png">
+				<p>Some article text that is long enough to be scored.</p></article></body></html>`,
+			wantText:  "Some article text that is long enough to be scored.",
+			wantImage: "/lazy.png",
+		},
+		{
+			name: "invalid json-ld",
+			page: `<html><head><script type="application/ld+json">{not json</script><title>Page</title></head>
+				<body><p>Some article text that is long enough to be scored.</p></body></html>`,
+			wantTitle: "Page",
+			wantText:  "Some article text that is long enough to be scored.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newPageServer(t, "text/html", []byte(tt.page))
+
+			article, err := New(Config{AllowPrivateNetworks: true}).Extract(t.Context(), srv.URL)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantTitle, article.Title)
+			assert.Equal(t, tt.wantText, article.Text)
+			assert.Equal(t, tt.wantBy, article.Byline)
+
+			if strings.HasPrefix(tt.wantImage, "/") {
+				assert.Equal(t, srv.URL+tt.wantImage, article.ImageURL)
+			} else {
+				assert.Equal(t, tt.wantImage, article.ImageURL)
+			}
+		})
+	}
+}
+
+func TestClient_ExtractLimits(t *testing.T) {
+	page := "<html><body><article>" + strings.Repeat("<p>Some article text that is long enough to be scored.</p>", 100) +
+		"</article></body></html>"
+
+	srv := newPageServer(t, "text/html", []byte(page))
+
+	article, err := New(Config{AllowPrivateNetworks: true, MaxTextLength: 200}).Extract(t.Context(), srv.URL)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len([]rune(article.Text)), 200)
+	assert.True(t, strings.HasSuffix(article.Text, "scored."), "text should be cut at a paragraph boundary")
+
+	article, err = New(Config{AllowPrivateNetworks: true, MaxBodySize: 100}).Extract(t.Context(), srv.URL)
+	require.NoError(t, err)
+	assert.Less(t, len(article.Text), 100, "body should be truncated to the size limit")
+}
+
+func TestClient_ExtractErrors(t *testing.T) {
+	tests := []struct {
+		wantErr     error
+		name        string
+		contentType string
+		status      int
+	}{
+		{name: "not html", contentType: "application/pdf", status: http.StatusOK, wantErr: ErrNotHTML},
+		{name: "not found", contentType: "text/html", status: http.StatusNotFound, wantErr: core.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			_, err := New(Config{AllowPrivateNetworks: true}).Extract(t.Context(), srv.URL)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	_, err := New(Config{AllowPrivateNetworks: true}).Extract(t.Context(), "http://127.0.0.1:0")
+	assert.Error(t, err)
+
+	_, err = New(Config{AllowPrivateNetworks: true}).Extract(t.Context(), "://bad")
+	assert.Error(t, err)
+}
+
+func TestClient_ExtractPrivateNetworks(t *testing.T) {
+	var requests int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+	}))
+	defer srv.Close()
+
+	for _, url := range []string{srv.URL, strings.Replace(srv.URL, "127.0.0.1", "localhost", 1), "http://[::1]/", "http://10.0.0.1/"} {
+		_, err := New(Config{Timeout: time.Second}).Extract(t.Context(), url)
+		assert.ErrorIs(t, err, core.ErrValidation, url)
+	}
+
+	assert.Zero(t, requests, "internal addresses should never be requested")
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", truncate("short", 10))
+	assert.Equal(t, "first paragraph", truncate("first paragraph\n\nsecond paragraph", 20))
+	assert.Equal(t, "one two…", truncate("one two three", 10))
+	assert.Equal(t, "abcd…", truncate("abcdefgh", 4))
+}
+
+// newPageServer starts a test server that replies to every request with the page.
+func newPageServer(t *testing.T, contentType string, page []byte) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, defaultUserAgent, r.Header.Get("User-Agent"))
+
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write(page)
+	}))
+
+	t.Cleanup(srv.Close)
+
+	return srv
+}
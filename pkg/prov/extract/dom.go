@@ -0,0 +1,164 @@
+package extract
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// removedElements lists elements that never hold the main content of a page and are dropped before scoring.
+var removedElements = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true, "svg": true, "canvas": true,
+	"nav": true, "footer": true, "aside": true, "form": true, "button": true, "input": true,
+	"select": true, "textarea": true, "iframe": true, "object": true, "embed": true, "link": true, "meta": true,
+}
+
+// blockElements lists elements whose text is extracted as a paragraph.
+var blockElements = map[string]bool{
+	"p": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"li": true, "blockquote": true, "pre": true, "dd": true, "dt": true, "figcaption": true,
+}
+
+// containerElements lists block level elements that may contain paragraphs.
+var containerElements = map[string]bool{
+	"div": true, "section": true, "article": true, "main": true, "table": true, "ul": true, "ol": true,
+	"dl": true, "blockquote": true, "pre": true, "p": true, "figure": true, "header": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// keptAttributes lists the attributes preserved when rendering the extracted content.
+var keptAttributes = map[string]bool{"href": true, "src": true, "alt": true, "title": true, "datetime": true}
+
+// walk visits the node and its descendants depth-first; returning false from fn skips the children of the node.
+func walk(n *html.Node, fn func(*html.Node) bool) {
+	if !fn(n) {
+		return
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c, fn)
+	}
+}
+
+// findFirst returns the first element with the given tag name or nil if there is none.
+func findFirst(root *html.Node, tag string) *html.Node {
+	var found *html.Node
+
+	walk(root, func(n *html.Node) bool {
+		if found != nil {
+			return false
+		}
+
+		if n.Type == html.ElementNode && n.Data == tag {
+			found = n
+			return false
+		}
+
+		return true
+	})
+
+	return found
+}
+
+// findAll returns every element with one of the given tag names in document order.
+func findAll(root *html.Node, tags ...string) []*html.Node {
+	var found []*html.Node
+
+	walk(root, func(n *html.Node) bool {
+		if n.Type == html.ElementNode {
+			for _, tag := range tags {
+				if n.Data == tag {
+					found = append(found, n)
+					break
+				}
+			}
+		}
+
+		return true
+	})
+
+	return found
+}
+
+// textOf returns the concatenated text of the node.
+func textOf(n *html.Node) string {
+	var sb strings.Builder
+
+	walk(n, func(c *html.Node) bool {
+		switch {
+		case c.Type == html.TextNode:
+			sb.WriteString(c.Data)
+		case c.Type == html.ElementNode && c.Data == "br":
+			sb.WriteByte(' ')
+		}
+
+		return true
+	})
+
+	return sb.String()
+}
+
+// linkDensity returns the share of the node text that belongs to links.
+func linkDensity(n *html.Node, textLen int) float64 {
+	if textLen == 0 {
+		return 0
+	}
+
+	linkLen := 0
+
+	for _, a := range findAll(n, "a") {
+		linkLen += len(collapseSpaces(textOf(a)))
+	}
+
+	return float64(linkLen) / float64(textLen)
+}
+
+// hasBlockChildren reports whether the element contains block level elements.
+func hasBlockChildren(n *html.Node) bool {
+	found := false
+
+	walk(n, func(c *html.Node) bool {
+		if found {
+			return false
+		}
+
+		if c != n && c.Type == html.ElementNode && (containerElements[c.Data] || blockElements[c.Data]) {
+			found = true
+			return false
+		}
+
+		return true
+	})
+
+	return found
+}
+
+// attr returns the value of the attribute of the element or an empty string if it is not set.
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+
+	return ""
+}
+
+// classAndID returns the class and id attributes of the element joined by a space.
+func classAndID(n *html.Node) string {
+	return attr(n, "class") + " " + attr(n, "id")
+}
+
+// removeNodes detaches the nodes from the tree.
+func removeNodes(nodes []*html.Node) {
+	for _, n := range nodes {
+		if n.Parent != nil {
+			n.Parent.RemoveChild(n)
+		}
+	}
+}
+
+// collapseSpaces trims the text and replaces runs of whitespace with a single space.
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
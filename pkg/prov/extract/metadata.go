@@ -0,0 +1,241 @@
+package extract
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// articleTypes lists the JSON-LD types that describe an article.
+var articleTypes = map[string]bool{
+	"Article": true, "NewsArticle": true, "BlogPosting": true, "TechArticle": true, "Report": true,
+	"ScholarlyArticle": true, "SocialMediaPosting": true, "WebPage": true, "AnalysisNewsArticle": true,
+}
+
+// dateLayouts lists the layouts tried when parsing publish dates found in metadata.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// metadata holds the article properties found in meta tags and JSON-LD blocks of a page.
+type metadata struct {
+	published time.Time
+	title     string
+	byline    string
+	image     string
+	siteName  string
+}
+
+// jsonLD holds the subset of schema.org properties used to describe an article.
+type jsonLD struct {
+	Type          any             `json:"@type"`
+	Headline      string          `json:"headline"`
+	Name          string          `json:"name"`
+	DatePublished string          `json:"datePublished"`
+	Author        json.RawMessage `json:"author"`
+	Image         json.RawMessage `json:"image"`
+	Publisher     json.RawMessage `json:"publisher"`
+	Graph         []jsonLD        `json:"@graph"`
+}
+
+// readMetadata collects the article metadata from the head of the page. JSON-LD takes precedence
+// over OpenGraph and Twitter cards, which take precedence over plain meta tags and the title element.
+// It must be called before extractContent, which drops script and meta elements.
+func readMetadata(doc *html.Node) *metadata {
+	var (
+		ld   *jsonLD
+		meta = make(map[string]string)
+		md   metadata
+	)
+
+	walk(doc, func(n *html.Node) bool {
+		if n.Type != html.ElementNode {
+			return true
+		}
+
+		switch n.Data {
+		case "meta":
+			key := strings.ToLower(firstNonEmpty(attr(n, "property"), attr(n, "name"), attr(n, "itemprop")))
+			if _, ok := meta[key]; key != "" && !ok {
+				meta[key] = strings.TrimSpace(attr(n, "content"))
+			}
+		case "title":
+			if md.title == "" {
+				md.title = collapseSpaces(textOf(n))
+			}
+		case "script":
+			if ld == nil && strings.EqualFold(strings.TrimSpace(attr(n, "type")), "application/ld+json") {
+				ld = parseJSONLD(textOf(n))
+			}
+
+			return false
+		}
+
+		return true
+	})
+
+	if ld == nil {
+		ld = &jsonLD{}
+	}
+
+	md.title = firstNonEmpty(ld.Headline, meta["og:title"], meta["twitter:title"], md.title)
+	md.byline = firstNonEmpty(names(ld.Author), meta["author"], nonURL(meta["article:author"]), meta["twitter:creator"])
+	md.image = firstNonEmpty(imageURL(ld.Image), meta["og:image"], meta["og:image:url"], meta["twitter:image"])
+	md.siteName = firstNonEmpty(meta["og:site_name"], names(ld.Publisher), meta["application-name"])
+	md.published = parseDate(firstNonEmpty(
+		ld.DatePublished, meta["article:published_time"], meta["datepublished"], meta["date"], meta["pubdate"],
+	))
+
+	return &md
+}
+
+// parseJSONLD returns the first article described in the JSON-LD block or nil if there is none.
+func parseJSONLD(data string) *jsonLD {
+	data = strings.TrimSpace(data)
+
+	var items []jsonLD
+
+	if strings.HasPrefix(data, "[") {
+		if err := json.Unmarshal([]byte(data), &items); err != nil {
+			return nil
+		}
+	} else {
+		var item jsonLD
+		if err := json.Unmarshal([]byte(data), &item); err != nil {
+			return nil
+		}
+
+		items = append([]jsonLD{item}, item.Graph...)
+	}
+
+	for i := range items {
+		if isArticleType(items[i].Type) {
+			return &items[i]
+		}
+	}
+
+	return nil
+}
+
+// isArticleType reports whether the JSON-LD @type, a string or a list of strings, describes an article.
+func isArticleType(t any) bool {
+	switch v := t.(type) {
+	case string:
+		return articleTypes[v]
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok && articleTypes[s] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// names returns the names of a JSON-LD person or organization given as a string, an object or a list of them.
+func names(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var name string
+	if err := json.Unmarshal(raw, &name); err == nil {
+		return strings.TrimSpace(name)
+	}
+
+	var entity struct {
+		Name string `json:"name"`
+	}
+
+	if err := json.Unmarshal(raw, &entity); err == nil && entity.Name != "" {
+		return strings.TrimSpace(entity.Name)
+	}
+
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return ""
+	}
+
+	result := make([]string, 0, len(list))
+
+	for _, item := range list {
+		if name := names(item); name != "" {
+			result = append(result, name)
+		}
+	}
+
+	return strings.Join(result, ", ")
+}
+
+// imageURL returns the URL of a JSON-LD image given as a string, an ImageObject or a list of them.
+func imageURL(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var u string
+	if err := json.Unmarshal(raw, &u); err == nil {
+		return strings.TrimSpace(u)
+	}
+
+	var image struct {
+		URL string `json:"url"`
+	}
+
+	if err := json.Unmarshal(raw, &image); err == nil && image.URL != "" {
+		return strings.TrimSpace(image.URL)
+	}
+
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err != nil || len(list) == 0 {
+		return ""
+	}
+
+	return imageURL(list[0])
+}
+
+// parseDate parses a publish date in one of the known layouts, returning the zero time if none matches.
+func parseDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC()
+		}
+	}
+
+	return time.Time{}
+}
+
+// nonURL returns the value unless it is a URL, as article:author often points to a profile page.
+func nonURL(value string) string {
+	if strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://") {
+		return ""
+	}
+
+	return value
+}
+
+// firstNonEmpty returns the first value that is not empty after trimming spaces.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
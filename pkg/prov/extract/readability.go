@@ -0,0 +1,329 @@
+package extract
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	minParagraphLength  = 25
+	minSiblingScore     = 10
+	siblingScoreRatio   = 0.2
+	longParagraphLength = 80
+	lowLinkDensity      = 0.25
+	classWeight         = 25
+	scoreAncestorLevels = 3
+)
+
+var (
+	unlikelyRe = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|cookie|cover-wrap|disqus|extra|` +
+		`footer|gdpr|header|legends|menu|modal|newsletter|pager|pagination|popup|related|remark|replies|rss|share|` +
+		`shoutbox|sidebar|skyscraper|social|sponsor|subscribe|supplemental|ad-break|agegate|yom-remote`)
+	maybeRe    = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	positiveRe = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|post|text|blog|story`)
+	negativeRe = regexp.MustCompile(`(?i)-ad-|hidden|banner|combx|comment|com-|contact|foot|footnote|gdpr|masthead|` +
+		`media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+)
+
+// content holds the main content of a page selected by the readability algorithm.
+type content struct {
+	nodes []*html.Node
+}
+
+// extractContent finds the main content of the page using a readability style heuristic:
+// unlikely elements are dropped, paragraphs are scored by their length and punctuation, their scores
+// are propagated to their ancestors, and the best scoring container is selected together with
+// the siblings that look like part of the same article. The document is modified in place.
+func extractContent(doc *html.Node) *content {
+	body := findFirst(doc, "body")
+	if body == nil {
+		return &content{}
+	}
+
+	prepare(body)
+
+	scores := scoreParagraphs(body)
+
+	var (
+		top      *html.Node
+		topScore float64
+	)
+
+	// Candidates are visited in document order so that ties are resolved deterministically.
+	walk(body, func(n *html.Node) bool {
+		score, ok := scores[n]
+		if !ok {
+			return true
+		}
+
+		score *= 1 - linkDensity(n, len(collapseSpaces(textOf(n))))
+		scores[n] = score
+
+		if top == nil || score > topScore {
+			top, topScore = n, score
+		}
+
+		return true
+	})
+
+	if top == nil {
+		return &content{nodes: []*html.Node{body}}
+	}
+
+	return &content{nodes: withSiblings(top, topScore, scores)}
+}
+
+// prepare drops elements that never hold the main content and elements whose class or id
+// makes them unlikely to be part of it.
+func prepare(body *html.Node) {
+	var removed []*html.Node
+
+	walk(body, func(n *html.Node) bool {
+		switch {
+		case n.Type == html.CommentNode:
+			removed = append(removed, n)
+			return false
+		case n.Type != html.ElementNode:
+			return true
+		case removedElements[n.Data], n.Data == "header" && !insideContent(n):
+			removed = append(removed, n)
+			return false
+		case n.Data == "body" || n.Data == "article" || n.Data == "main" || n.Data == "a":
+			return true
+		}
+
+		if hint := classAndID(n); unlikelyRe.MatchString(hint) && !maybeRe.MatchString(hint) {
+			removed = append(removed, n)
+			return false
+		}
+
+		if attr(n, "hidden") != "" || attr(n, "aria-hidden") == "true" {
+			removed = append(removed, n)
+			return false
+		}
+
+		return true
+	})
+
+	removeNodes(removed)
+}
+
+// insideContent reports whether the node is nested in an article or main element.
+func insideContent(n *html.Node) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && (p.Data == "article" || p.Data == "main") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scoreParagraphs scores paragraph like elements and propagates their score to their ancestors.
+// The parent receives the full score, the grandparent half of it and further ancestors a third of that.
+func scoreParagraphs(body *html.Node) map[*html.Node]float64 {
+	scores := make(map[*html.Node]float64)
+
+	for _, n := range findAll(body, "p", "pre", "td", "div", "section") {
+		if (n.Data == "div" || n.Data == "section") && hasBlockChildren(n) {
+			continue
+		}
+
+		text := collapseSpaces(textOf(n))
+		if len(text) < minParagraphLength {
+			continue
+		}
+
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(len(text))/100, 3)
+
+		ancestor := n.Parent
+		for level := 0; level < scoreAncestorLevels && ancestor != nil && ancestor.Type == html.ElementNode; level++ {
+			if _, ok := scores[ancestor]; !ok {
+				scores[ancestor] = initialScore(ancestor)
+			}
+
+			switch level {
+			case 0:
+				scores[ancestor] += score
+			case 1:
+				scores[ancestor] += score / 2
+			default:
+				scores[ancestor] += score / float64(level*3)
+			}
+
+			ancestor = ancestor.Parent
+		}
+	}
+
+	return scores
+}
+
+// initialScore returns the base score of a candidate container depending on its tag and class or id.
+func initialScore(n *html.Node) float64 {
+	var score float64
+
+	switch n.Data {
+	case "article":
+		score = 10
+	case "div", "main", "section":
+		score = 5
+	case "pre", "td", "blockquote":
+		score = 3
+	case "address", "ol", "ul", "dl", "dd", "dt", "li", "form":
+		score = -3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		score = -5
+	}
+
+	hint := classAndID(n)
+
+	if negativeRe.MatchString(hint) {
+		score -= classWeight
+	}
+
+	if positiveRe.MatchString(hint) {
+		score += classWeight
+	}
+
+	return score
+}
+
+// withSiblings returns the top candidate together with its siblings that score well enough
+// or look like standalone paragraphs of the same article.
+func withSiblings(top *html.Node, topScore float64, scores map[*html.Node]float64) []*html.Node {
+	if top.Parent == nil || top.Data == "body" {
+		return []*html.Node{top}
+	}
+
+	threshold := max(minSiblingScore, topScore*siblingScoreRatio)
+
+	var nodes []*html.Node
+
+	for s := top.Parent.FirstChild; s != nil; s = s.NextSibling {
+		if s.Type != html.ElementNode {
+			continue
+		}
+
+		if s == top {
+			nodes = append(nodes, s)
+			continue
+		}
+
+		if score, ok := scores[s]; ok && score >= threshold {
+			nodes = append(nodes, s)
+			continue
+		}
+
+		if s.Data != "p" {
+			continue
+		}
+
+		text := collapseSpaces(textOf(s))
+		density := linkDensity(s, len(text))
+
+		if (len(text) > longParagraphLength && density < lowLinkDensity) ||
+			(len(text) > 0 && density == 0 && strings.Contains(text, ". ")) {
+			nodes = append(nodes, s)
+		}
+	}
+
+	return nodes
+}
+
+// Text returns the text of the content with one paragraph per block element.
+func (c *content) Text() string {
+	var paragraphs []string
+
+	for _, root := range c.nodes {
+		if blockElements[root.Data] {
+			if text := collapseSpaces(textOf(root)); text != "" {
+				paragraphs = append(paragraphs, text)
+			}
+
+			continue
+		}
+
+		walk(root, func(n *html.Node) bool {
+			if n.Type != html.ElementNode {
+				return true
+			}
+
+			if blockElements[n.Data] || (n.Data == "div" && !hasBlockChildren(n)) {
+				if text := collapseSpaces(textOf(n)); text != "" {
+					paragraphs = append(paragraphs, text)
+				}
+
+				return false
+			}
+
+			return true
+		})
+	}
+
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// HTML renders the content keeping only presentational attributes and resolving links against base.
+func (c *content) HTML(base *url.URL) string {
+	var sb strings.Builder
+
+	for _, root := range c.nodes {
+		walk(root, func(n *html.Node) bool {
+			if n.Type == html.ElementNode {
+				cleanAttributes(n, base)
+			}
+
+			return true
+		})
+
+		if root.Data == "body" {
+			for child := root.FirstChild; child != nil; child = child.NextSibling {
+				_ = html.Render(&sb, child)
+			}
+
+			continue
+		}
+
+		_ = html.Render(&sb, root)
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+// cleanAttributes drops the attributes that are not needed to render the content
+// and makes links and image sources absolute.
+func cleanAttributes(n *html.Node, base *url.URL) {
+	attrs := n.Attr[:0]
+
+	for _, a := range n.Attr {
+		if !keptAttributes[a.Key] {
+			continue
+		}
+
+		if a.Key == "href" || a.Key == "src" {
+			a.Val = resolveURL(base, a.Val)
+		}
+
+		attrs = append(attrs, a)
+	}
+
+	n.Attr = attrs
+}
+
+// resolveURL resolves ref against base, returning ref unchanged if it cannot be parsed.
+func resolveURL(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if base == nil || ref == "" {
+		return ref
+	}
+
+	u, err := base.Parse(ref)
+	if err != nil {
+		return ref
+	}
+
+	return u.String()
+}
@@ -0,0 +1,140 @@
+package feed
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ksysoev/tg-feeder/pkg/core"
+)
+
+type atomDocument struct {
+	Title    atomText    `xml:"title"`
+	Subtitle atomText    `xml:"subtitle"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      atomText       `xml:"title"`
+	Summary    atomText       `xml:"summary"`
+	Content    atomText       `xml:"content"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Links      []atomLink     `xml:"link"`
+	Authors    []atomPerson   `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+type atomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr"`
+}
+
+// String returns the textual value of an Atom text construct.
+// Plain text and escaped HTML are unescaped, while inline XHTML is returned as markup.
+func (t atomText) String() string {
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.Inner)
+	}
+
+	return strings.TrimSpace(t.Text)
+}
+
+// parseAtom converts an Atom 1.0 document into a core.Feed.
+func parseAtom(data []byte) (*core.Feed, error) {
+	var doc atomDocument
+	if err := newXMLDecoder(data).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode Atom feed: %w", err)
+	}
+
+	feed := &core.Feed{
+		Title:       doc.Title.String(),
+		Link:        alternateLink(doc.Links),
+		Description: doc.Subtitle.String(),
+		Items:       make([]core.FeedItem, 0, len(doc.Entries)),
+	}
+
+	for i := range doc.Entries {
+		e := &doc.Entries[i]
+
+		item := core.FeedItem{
+			GUID:      strings.TrimSpace(e.ID),
+			Title:     e.Title.String(),
+			Link:      alternateLink(e.Links),
+			Content:   firstNonEmpty(e.Content.String(), e.Summary.String()),
+			Published: parseTime(e.Published),
+			Updated:   parseTime(e.Updated),
+		}
+
+		if item.Published.IsZero() {
+			item.Published = item.Updated
+		}
+
+		if item.GUID == "" {
+			item.GUID = item.Link
+		}
+
+		names := make([]string, 0, len(e.Authors))
+		for _, a := range e.Authors {
+			if name := strings.TrimSpace(a.Name); name != "" {
+				names = append(names, name)
+			}
+		}
+
+		item.Author = strings.Join(names, ", ")
+
+		for _, c := range e.Categories {
+			if cat := firstNonEmpty(c.Label, c.Term); cat != "" {
+				item.Categories = append(item.Categories, cat)
+			}
+		}
+
+		for _, l := range e.Links {
+			if l.Rel != "enclosure" || l.Href == "" {
+				continue
+			}
+
+			length, _ := strconv.ParseInt(strings.TrimSpace(l.Length), 10, 64)
+
+			item.Enclosures = append(item.Enclosures, core.Enclosure{
+				URL:    strings.TrimSpace(l.Href),
+				Type:   strings.TrimSpace(l.Type),
+				Length: length,
+			})
+		}
+
+		feed.Items = append(feed.Items, item)
+	}
+
+	return feed, nil
+}
+
+// alternateLink picks the most suitable human readable link from a list of Atom links.
+func alternateLink(links []atomLink) string {
+	for _, l := range links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return strings.TrimSpace(l.Href)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,233 @@
+// Package feed provides a client for fetching and parsing RSS, Atom and JSON feeds.
+package feed
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/ksysoev/tg-feeder/pkg/prov/safenet"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	defaultTimeout     = 10 * time.Second
+	defaultMaxBodySize = 5 << 20 // 5 MiB
+	defaultUserAgent   = "tg-feeder/1.0 (+https://github.com/ksysoev/tg-feeder)"
+)
+
+// ErrUnknownFormat is returned when the fetched document is not a supported feed format.
+var ErrUnknownFormat = errors.New("unknown feed format")
+
+// Config holds configuration for the feed Client.
+// Feeds on loopback, private and link-local addresses are refused unless AllowPrivateNetworks is set.
+type Config struct {
+	UserAgent            string        `mapstructure:"user_agent"`
+	Timeout              time.Duration `mapstructure:"timeout"`
+	MaxBodySize          int64         `mapstructure:"max_body_size"`
+	AllowPrivateNetworks bool          `mapstructure:"allow_private_networks"`
+}
+
+// Client fetches remote feeds and converts them into core.Feed values.
+type Client struct {
+	cli *http.Client
+	cfg Config
+}
+
+// New creates a new Client with the provided configuration, applying defaults for unset values.
+func New(cfg Config) *Client {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = defaultMaxBodySize
+	}
+
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = defaultUserAgent
+	}
+
+	var transport http.RoundTripper = safenet.NewTransport()
+	if cfg.AllowPrivateNetworks {
+		transport = http.DefaultTransport
+	}
+
+	return &Client{
+		cfg: cfg,
+		cli: &http.Client{
+			Transport: otelhttp.NewTransport(transport),
+			Timeout:   cfg.Timeout,
+		},
+	}
+}
+
+// Fetch downloads the document located at url and parses it as RSS 2.0, RSS 1.0 (RDF), Atom 1.0 or JSON Feed.
+// The cache validators from state are sent as If-None-Match and If-Modified-Since headers; a 304 response
+// or a body identical to the previous one is reported as not modified without parsing.
+// It returns the fetch result or an error if the request fails or the format is not supported.
+// Documents that are not feeds, are too large or cannot be parsed are reported as core.ErrInvalidFeed.
+func (c *Client) Fetch(ctx context.Context, url string, state core.FetchState) (*core.FetchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", c.cfg.UserAgent)
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/feed+json, application/xml;q=0.9, */*;q=0.8")
+
+	if state.ETag != "" {
+		req.Header.Set("If-None-Match", state.ETag)
+	}
+
+	if state.LastModified != "" {
+		req.Header.Set("If-Modified-Since", state.LastModified)
+	}
+
+	resp, err := c.cli.Do(req)
+
+	switch {
+	case errors.Is(err, safenet.ErrForbiddenAddress):
+		return nil, fmt.Errorf("%w: failed to fetch feed: %w", core.ErrValidation, err)
+	case err != nil:
+		fetchResponses.WithLabelValues(transportError).Inc()
+		return nil, fmt.Errorf("%w: failed to fetch feed: %w", core.ErrUpstreamUnavailable, err)
+	}
+
+	fetchResponses.WithLabelValues(strconv.Itoa(resp.StatusCode)).Inc()
+
+	defer func() { _ = resp.Body.Close() }()
+
+	res := &core.FetchResult{
+		State: core.FetchState{
+			ETag:         firstNonEmpty(resp.Header.Get("ETag"), state.ETag),
+			LastModified: firstNonEmpty(resp.Header.Get("Last-Modified"), state.LastModified),
+			ContentHash:  state.ContentHash,
+		},
+		MaxAge: parseMaxAge(resp.Header.Get("Cache-Control")),
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		res.NotModified = true
+		return res, nil
+	default:
+		return nil, core.StatusError(resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, c.cfg.MaxBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read feed body: %w", core.ErrUpstreamUnavailable, err)
+	}
+
+	if int64(len(data)) > c.cfg.MaxBodySize {
+		return nil, fmt.Errorf("%w: feed body exceeds %d bytes", core.ErrInvalidFeed, c.cfg.MaxBodySize)
+	}
+
+	hash := sha256.Sum256(data)
+	res.State.ContentHash = hex.EncodeToString(hash[:])
+
+	if res.State.ContentHash == state.ContentHash {
+		res.NotModified = true
+		return res, nil
+	}
+
+	if res.Feed, err = Parse(data); err != nil {
+		return nil, fmt.Errorf("%w: %w", core.ErrInvalidFeed, err)
+	}
+
+	return res, nil
+}
+
+// Parse detects the format of the provided document and converts it into a core.Feed.
+// It returns ErrUnknownFormat if the document is neither a supported XML feed nor a JSON Feed.
+func Parse(data []byte) (*core.Feed, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	trimmed := bytes.TrimSpace(data)
+
+	if len(trimmed) == 0 {
+		return nil, ErrUnknownFormat
+	}
+
+	if trimmed[0] == '{' {
+		return parseJSONFeed(trimmed)
+	}
+
+	root, err := rootElement(trimmed)
+	if err != nil {
+		return nil, err
+	}
+
+	switch root.Local {
+	case "rss":
+		return parseRSS(trimmed)
+	case "RDF":
+		return parseRDF(trimmed)
+	case "feed":
+		return parseAtom(trimmed)
+	default:
+		return nil, fmt.Errorf("%w: root element %q", ErrUnknownFormat, root.Local)
+	}
+}
+
+// rootElement returns the name of the first start element of an XML document.
+func rootElement(data []byte) (xml.Name, error) {
+	dec := newXMLDecoder(data)
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return xml.Name{}, fmt.Errorf("%w: %w", ErrUnknownFormat, err)
+		}
+
+		if se, ok := tok.(xml.StartElement); ok {
+			return se.Name, nil
+		}
+	}
+}
+
+// newXMLDecoder creates a lenient XML decoder that understands non UTF-8 encodings and HTML entities.
+func newXMLDecoder(data []byte) *xml.Decoder {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.CharsetReader = charset.NewReaderLabel
+	dec.Strict = false
+	dec.Entity = xml.HTMLEntity
+
+	return dec
+}
+
+// parseMaxAge extracts the max-age directive from a Cache-Control header.
+// It returns zero if the directive is missing, invalid or caching is disabled.
+func parseMaxAge(header string) time.Duration {
+	var maxAge time.Duration
+
+	for _, directive := range strings.Split(header, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+
+		switch {
+		case directive == "no-cache" || directive == "no-store":
+			return 0
+		case strings.HasPrefix(directive, "max-age="):
+			seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age="))
+			if err != nil || seconds < 0 {
+				return 0
+			}
+
+			maxAge = time.Duration(seconds) * time.Second
+		}
+	}
+
+	return maxAge
+}
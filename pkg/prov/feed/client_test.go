@@ -0,0 +1,363 @@
+package feed
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	cli := New(Config{})
+
+	require.NotNil(t, cli, "New() should return a non-nil Client")
+	assert.Equal(t, defaultTimeout, cli.cfg.Timeout)
+	assert.Equal(t, int64(defaultMaxBodySize), cli.cfg.MaxBodySize)
+	assert.Equal(t, defaultUserAgent, cli.cfg.UserAgent)
+}
+
+func TestClient_Fetch(t *testing.T) {
+	data, err := os.ReadFile("testdata/rss2.xml")
+	require.NoError(t, err)
+
+	tests := []struct {
+		handler http.HandlerFunc
+		name    string
+		maxBody int64
+		wantErr bool
+	}{
+		{
+			name: "success",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, defaultUserAgent, r.Header.Get("User-Agent"))
+				_, _ = w.Write(data)
+			},
+		},
+		{
+			name: "non 200 status",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			wantErr: true,
+		},
+		{
+			name: "body too large",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write(data)
+			},
+			maxBody: 10,
+			wantErr: true,
+		},
+		{
+			name: "not a feed",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("<html><body>hello</body></html>"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(tt.handler)
+			defer ts.Close()
+
+			cli := New(Config{AllowPrivateNetworks: true, MaxBodySize: tt.maxBody})
+
+			res, err := cli.Fetch(t.Context(), ts.URL, core.FetchState{})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, res.Feed)
+			assert.Equal(t, "Example RSS", res.Feed.Title)
+			assert.NotEmpty(t, res.State.ContentHash)
+		})
+	}
+}
+
+func TestClient_Fetch_ErrorClasses(t *testing.T) {
+	tests := []struct {
+		want error
+		name string
+		code int
+	}{
+		{name: "server error", code: http.StatusServiceUnavailable, want: core.ErrUpstreamUnavailable},
+		{name: "too many requests", code: http.StatusTooManyRequests, want: core.ErrQuotaExceeded},
+		{name: "not found", code: http.StatusNotFound, want: core.ErrNotFound},
+		{name: "not a feed", code: http.StatusOK, want: core.ErrInvalidFeed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.code)
+			}))
+			defer ts.Close()
+
+			_, err := New(Config{AllowPrivateNetworks: true}).Fetch(t.Context(), ts.URL, core.FetchState{})
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+
+	t.Run("too large", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(strings.Repeat("x", 100)))
+		}))
+		defer ts.Close()
+
+		_, err := New(Config{AllowPrivateNetworks: true, MaxBodySize: 10}).Fetch(t.Context(), ts.URL, core.FetchState{})
+		assert.ErrorIs(t, err, core.ErrInvalidFeed)
+	})
+
+	t.Run("unreachable server", func(t *testing.T) {
+		ts := httptest.NewServer(http.NotFoundHandler())
+		ts.Close()
+
+		_, err := New(Config{AllowPrivateNetworks: true}).Fetch(t.Context(), ts.URL, core.FetchState{})
+		assert.ErrorIs(t, err, core.ErrUpstreamUnavailable)
+	})
+}
+
+func TestClient_Fetch_PrivateNetworks(t *testing.T) {
+	var requests int
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+	}))
+	defer ts.Close()
+
+	for _, url := range []string{ts.URL, strings.Replace(ts.URL, "127.0.0.1", "localhost", 1), "http://169.254.169.254/latest/meta-data"} {
+		_, err := New(Config{}).Fetch(t.Context(), url, core.FetchState{})
+		assert.ErrorIs(t, err, core.ErrValidation, url)
+		assert.NotErrorIs(t, err, core.ErrUpstreamUnavailable, url)
+	}
+
+	assert.Zero(t, requests, "internal addresses should never be requested")
+}
+
+func TestClient_Fetch_Conditional(t *testing.T) {
+	data, err := os.ReadFile("testdata/rss2.xml")
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		w.Header().Set("Cache-Control", "public, max-age=600")
+		_, _ = w.Write(data)
+	}))
+	defer ts.Close()
+
+	cli := New(Config{AllowPrivateNetworks: true})
+
+	ok := testutil.ToFloat64(fetchResponses.WithLabelValues("200"))
+	notModified := testutil.ToFloat64(fetchResponses.WithLabelValues("304"))
+
+	first, err := cli.Fetch(t.Context(), ts.URL, core.FetchState{})
+	require.NoError(t, err)
+	assert.False(t, first.NotModified)
+	assert.Equal(t, `"v1"`, first.State.ETag)
+	assert.Equal(t, "Mon, 02 Jan 2006 15:04:05 GMT", first.State.LastModified)
+	assert.Equal(t, 10*time.Minute, first.MaxAge)
+
+	second, err := cli.Fetch(t.Context(), ts.URL, first.State)
+	require.NoError(t, err)
+	assert.True(t, second.NotModified)
+	assert.Nil(t, second.Feed)
+	assert.Equal(t, first.State, second.State)
+
+	third, err := cli.Fetch(t.Context(), ts.URL, core.FetchState{ContentHash: first.State.ContentHash})
+	require.NoError(t, err)
+	assert.True(t, third.NotModified, "unchanged content should be reported as not modified")
+	assert.Nil(t, third.Feed)
+
+	assert.Equal(t, ok+2, testutil.ToFloat64(fetchResponses.WithLabelValues("200")))
+	assert.Equal(t, notModified+1, testutil.ToFloat64(fetchResponses.WithLabelValues("304")))
+}
+
+func TestParseMaxAge(t *testing.T) {
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{header: "", want: 0},
+		{header: "max-age=60", want: time.Minute},
+		{header: "public, Max-Age=3600", want: time.Hour},
+		{header: "no-cache, max-age=60", want: 0},
+		{header: "max-age=abc", want: 0},
+		{header: "max-age=-1", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseMaxAge(tt.header))
+		})
+	}
+}
+
+func TestParse_RSS(t *testing.T) {
+	data, err := os.ReadFile("testdata/rss2.xml")
+	require.NoError(t, err)
+
+	feed, err := Parse(data)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Example RSS", feed.Title)
+	assert.Equal(t, "https://example.com/", feed.Link, "atom:link of the channel should not replace its link")
+	assert.Equal(t, "Example RSS feed", feed.Description)
+	assert.Equal(t, time.Hour, feed.TTL)
+	assert.Equal(t, []int{0, 1}, feed.SkipHours)
+	assert.Equal(t, []time.Weekday{time.Saturday, time.Sunday}, feed.SkipDays)
+	require.Len(t, feed.Items, 2)
+
+	first := feed.Items[0]
+	assert.Equal(t, "item-1", first.GUID)
+	assert.Equal(t, "First & foremost", first.Title)
+	assert.Equal(t, "https://example.com/1", first.Link)
+	assert.Equal(t, "Jane Doe", first.Author)
+	assert.Equal(t, "<p>Full <b>content</b></p>", first.Content)
+	assert.Equal(t, time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC), first.Published)
+	assert.Equal(t, []string{"go", "news"}, first.Categories)
+	require.Len(t, first.Enclosures, 1)
+	assert.Equal(t, "https://example.com/1.mp3", first.Enclosures[0].URL)
+	assert.Equal(t, "audio/mpeg", first.Enclosures[0].Type)
+	assert.Equal(t, int64(1024), first.Enclosures[0].Length)
+
+	second := feed.Items[1]
+	assert.Equal(t, "https://example.com/2", second.Link, "atom:link of the item should not replace its link")
+	assert.Equal(t, "https://example.com/2", second.GUID, "GUID should fall back to the link")
+	assert.Equal(t, "Only description", second.Content)
+	assert.Equal(t, "john@example.com (John)", second.Author)
+	assert.True(t, second.Published.IsZero())
+}
+
+func TestParse_RDF(t *testing.T) {
+	data, err := os.ReadFile("testdata/rdf.xml")
+	require.NoError(t, err)
+
+	feed, err := Parse(data)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Example RDF", feed.Title)
+	assert.Equal(t, "https://example.org/", feed.Link)
+	require.Len(t, feed.Items, 1)
+	assert.Equal(t, "RDF item", feed.Items[0].Title)
+	assert.Equal(t, "https://example.org/a", feed.Items[0].Link)
+	assert.Equal(t, "https://example.org/a", feed.Items[0].GUID)
+	assert.Equal(t, "Alice", feed.Items[0].Author)
+	assert.Equal(t, time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC), feed.Items[0].Published)
+}
+
+func TestParse_Atom(t *testing.T) {
+	data, err := os.ReadFile("testdata/atom.xml")
+	require.NoError(t, err)
+
+	feed, err := Parse(data)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Example Atom", feed.Title)
+	assert.Equal(t, "https://example.net/", feed.Link)
+	assert.Equal(t, "Example Atom feed", feed.Description)
+	require.Len(t, feed.Items, 1)
+
+	item := feed.Items[0]
+	assert.Equal(t, "urn:uuid:1225c695-cfb8-4ebb-aaaa-80da344efa6a", item.GUID)
+	assert.Equal(t, "Atom <i>entry</i>", item.Title)
+	assert.Equal(t, "https://example.net/entry", item.Link)
+	assert.Equal(t, "Bob, Carol", item.Author)
+	assert.Contains(t, item.Content, "<p>Atom content</p>")
+	assert.Equal(t, time.Date(2006, 1, 2, 14, 4, 5, 0, time.UTC), item.Published)
+	assert.Equal(t, time.Date(2006, 1, 3, 10, 0, 0, 0, time.UTC), item.Updated)
+	assert.Equal(t, []string{"Technology"}, item.Categories)
+	require.Len(t, item.Enclosures, 1)
+	assert.Equal(t, "video/mp4", item.Enclosures[0].Type)
+}
+
+func TestParse_JSONFeed(t *testing.T) {
+	data, err := os.ReadFile("testdata/jsonfeed.json")
+	require.NoError(t, err)
+
+	feed, err := Parse(data)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Example JSON Feed", feed.Title)
+	assert.Equal(t, "https://example.io/", feed.Link)
+	require.Len(t, feed.Items, 2)
+
+	first := feed.Items[0]
+	assert.Equal(t, "json-1", first.GUID)
+	assert.Equal(t, "Feed Author", first.Author)
+	assert.Equal(t, "<p>JSON content</p>", first.Content)
+	assert.Equal(t, []string{"a", "b"}, first.Categories)
+	require.Len(t, first.Enclosures, 1)
+	assert.Equal(t, int64(512), first.Enclosures[0].Length)
+
+	second := feed.Items[1]
+	assert.Equal(t, "2", second.GUID)
+	assert.Equal(t, "Item Author", second.Author)
+	assert.Equal(t, "Plain text", second.Content)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "empty", data: "   "},
+		{name: "html document", data: "<html><body></body></html>"},
+		{name: "json without version", data: `{"title": "x"}`},
+		{name: "broken json", data: `{"version": `},
+		{name: "garbage", data: "not a feed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParse_NonUTF8Charset(t *testing.T) {
+	data := "<?xml version=\"1.0\" encoding=\"windows-1251\"?>\n" +
+		"<rss version=\"2.0\"><channel><title>\xcf\xf0\xe8\xe2\xe5\xf2</title></channel></rss>"
+
+	feed, err := Parse([]byte(data))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Привет", strings.TrimSpace(feed.Title))
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		want  time.Time
+		value string
+	}{
+		{value: "Mon, 02 Jan 2006 15:04:05 +0000", want: time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{value: "Mon, 2 Jan 2006 15:04:05 +0100", want: time.Date(2006, 1, 2, 14, 4, 5, 0, time.UTC)},
+		{value: "2006-01-02T15:04:05Z", want: time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{value: "2006-01-02", want: time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{value: "", want: time.Time{}},
+		{value: "yesterday", want: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseTime(tt.value))
+		})
+	}
+}
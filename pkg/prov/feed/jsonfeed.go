@@ -0,0 +1,132 @@
+package feed
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ksysoev/tg-feeder/pkg/core"
+)
+
+type jsonFeedDocument struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	Description string         `json:"description"`
+	Authors     []jsonAuthor   `json:"authors"`
+	Author      *jsonAuthor    `json:"author"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            json.RawMessage `json:"id"`
+	URL           string          `json:"url"`
+	ExternalURL   string          `json:"external_url"`
+	Title         string          `json:"title"`
+	ContentHTML   string          `json:"content_html"`
+	ContentText   string          `json:"content_text"`
+	Summary       string          `json:"summary"`
+	DatePublished string          `json:"date_published"`
+	DateModified  string          `json:"date_modified"`
+	Author        *jsonAuthor     `json:"author"`
+	Authors       []jsonAuthor    `json:"authors"`
+	Tags          []string        `json:"tags"`
+	Attachments   []jsonAttach    `json:"attachments"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonAttach struct {
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type"`
+	Size     int64  `json:"size_in_bytes"`
+}
+
+// parseJSONFeed converts a JSON Feed 1.0 or 1.1 document into a core.Feed.
+func parseJSONFeed(data []byte) (*core.Feed, error) {
+	var doc jsonFeedDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON feed: %w", err)
+	}
+
+	if !strings.HasPrefix(doc.Version, "https://jsonfeed.org/version/") {
+		return nil, fmt.Errorf("%w: unsupported JSON feed version %q", ErrUnknownFormat, doc.Version)
+	}
+
+	feed := &core.Feed{
+		Title:       strings.TrimSpace(doc.Title),
+		Link:        strings.TrimSpace(doc.HomePageURL),
+		Description: strings.TrimSpace(doc.Description),
+		Items:       make([]core.FeedItem, 0, len(doc.Items)),
+	}
+
+	feedAuthor := authorNames(doc.Authors, doc.Author)
+
+	for i := range doc.Items {
+		it := &doc.Items[i]
+
+		item := core.FeedItem{
+			GUID:       jsonID(it.ID),
+			Title:      strings.TrimSpace(it.Title),
+			Link:       strings.TrimSpace(firstNonEmpty(it.URL, it.ExternalURL)),
+			Author:     firstNonEmpty(authorNames(it.Authors, it.Author), feedAuthor),
+			Content:    firstNonEmpty(it.ContentHTML, it.ContentText, it.Summary),
+			Published:  parseTime(it.DatePublished),
+			Updated:    parseTime(it.DateModified),
+			Categories: trimAll(it.Tags),
+		}
+
+		if item.GUID == "" {
+			item.GUID = item.Link
+		}
+
+		for _, a := range it.Attachments {
+			if a.URL == "" {
+				continue
+			}
+
+			item.Enclosures = append(item.Enclosures, core.Enclosure{
+				URL:    strings.TrimSpace(a.URL),
+				Type:   strings.TrimSpace(a.MimeType),
+				Length: a.Size,
+			})
+		}
+
+		feed.Items = append(feed.Items, item)
+	}
+
+	return feed, nil
+}
+
+// authorNames joins author names using the JSON Feed 1.1 authors list with a fallback to the 1.0 author object.
+func authorNames(authors []jsonAuthor, author *jsonAuthor) string {
+	if len(authors) == 0 && author != nil {
+		authors = []jsonAuthor{*author}
+	}
+
+	names := make([]string, 0, len(authors))
+
+	for _, a := range authors {
+		if name := strings.TrimSpace(a.Name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// jsonID returns the item id as a string; the spec requires a string but numbers are common in the wild.
+func jsonID(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return strings.TrimSpace(s)
+	}
+
+	return strings.TrimSpace(string(raw))
+}
@@ -0,0 +1,16 @@
+package feed
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// transportError labels the fetches that failed without an HTTP response.
+const transportError = "error"
+
+var fetchResponses = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "feeder",
+	Subsystem: "feed",
+	Name:      "http_responses_total",
+	Help:      "Number of feed fetches, by HTTP status code or error when no response was received.",
+}, []string{"code"})
@@ -0,0 +1,189 @@
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ksysoev/tg-feeder/pkg/core"
+)
+
+// rssNamespace is the namespace of the elements of RSS 1.0 (RDF) documents.
+const rssNamespace = "http://purl.org/rss/1.0/"
+
+type rssDocument struct {
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        rssLinks  `xml:"link"`
+	Description string    `xml:"description"`
+	TTL         string    `xml:"ttl"`
+	Items       []rssItem `xml:"item"`
+	SkipHours   []string  `xml:"skipHours>hour"`
+	SkipDays    []string  `xml:"skipDays>day"`
+}
+
+type rssItem struct {
+	GUID           rssGUID        `xml:"guid"`
+	Title          string         `xml:"title"`
+	Link           rssLinks       `xml:"link"`
+	Description    string         `xml:"description"`
+	ContentEncoded string         `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Author         string         `xml:"author"`
+	Creator        string         `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	PubDate        string         `xml:"pubDate"`
+	DCDate         string         `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Enclosures     []rssEnclosure `xml:"enclosure"`
+	Categories     []string       `xml:"category"`
+}
+
+// rssLinks holds every link element of a channel or an item, including the ones of other namespaces
+// such as atom:link, which share the local name with the RSS link.
+type rssLinks []rssLink
+
+type rssLink struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+// String returns the RSS link, ignoring the link elements of other namespaces.
+func (l rssLinks) String() string {
+	for _, link := range l {
+		if link.XMLName.Space == "" || link.XMLName.Space == rssNamespace {
+			return strings.TrimSpace(link.Value)
+		}
+	}
+
+	return ""
+}
+
+type rssGUID struct {
+	Value string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length string `xml:"length,attr"`
+}
+
+type rdfDocument struct {
+	Channel rssChannel `xml:"channel"`
+	Items   []rssItem  `xml:"item"`
+}
+
+// parseRSS converts an RSS 2.0 document into a core.Feed.
+func parseRSS(data []byte) (*core.Feed, error) {
+	var doc rssDocument
+	if err := newXMLDecoder(data).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode RSS feed: %w", err)
+	}
+
+	return convertRSS(&doc.Channel, doc.Channel.Items), nil
+}
+
+// parseRDF converts an RSS 1.0 (RDF) document into a core.Feed.
+// In RDF feeds items are siblings of the channel element rather than its children.
+func parseRDF(data []byte) (*core.Feed, error) {
+	var doc rdfDocument
+	if err := newXMLDecoder(data).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode RDF feed: %w", err)
+	}
+
+	return convertRSS(&doc.Channel, doc.Items), nil
+}
+
+// convertRSS maps RSS channel and item elements onto the normalized feed model.
+func convertRSS(ch *rssChannel, items []rssItem) *core.Feed {
+	feed := &core.Feed{
+		Title:       strings.TrimSpace(ch.Title),
+		Link:        ch.Link.String(),
+		Description: strings.TrimSpace(ch.Description),
+		Items:       make([]core.FeedItem, 0, len(items)),
+		TTL:         parseTTL(ch.TTL),
+		SkipHours:   parseSkipHours(ch.SkipHours),
+		SkipDays:    parseSkipDays(ch.SkipDays),
+	}
+
+	for i := range items {
+		it := &items[i]
+
+		item := core.FeedItem{
+			GUID:       strings.TrimSpace(it.GUID.Value),
+			Title:      strings.TrimSpace(it.Title),
+			Link:       it.Link.String(),
+			Author:     firstNonEmpty(it.Creator, it.Author),
+			Content:    firstNonEmpty(it.ContentEncoded, it.Description),
+			Published:  parseTime(firstNonEmpty(it.PubDate, it.DCDate)),
+			Categories: trimAll(it.Categories),
+		}
+
+		if item.GUID == "" {
+			item.GUID = item.Link
+		}
+
+		for _, enc := range it.Enclosures {
+			if enc.URL == "" {
+				continue
+			}
+
+			length, _ := strconv.ParseInt(strings.TrimSpace(enc.Length), 10, 64)
+
+			item.Enclosures = append(item.Enclosures, core.Enclosure{
+				URL:    strings.TrimSpace(enc.URL),
+				Type:   strings.TrimSpace(enc.Type),
+				Length: length,
+			})
+		}
+
+		feed.Items = append(feed.Items, item)
+	}
+
+	return feed
+}
+
+// parseTTL converts the RSS ttl element, expressed in minutes, into a duration.
+func parseTTL(value string) time.Duration {
+	minutes, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || minutes <= 0 {
+		return 0
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+// parseSkipHours converts the RSS skipHours elements into a list of valid GMT hours.
+func parseSkipHours(values []string) []int {
+	var hours []int
+
+	for _, v := range values {
+		hour, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || hour < 0 || hour > 23 {
+			continue
+		}
+
+		hours = append(hours, hour)
+	}
+
+	return hours
+}
+
+// parseSkipDays converts the RSS skipDays elements into weekdays, ignoring unknown names.
+func parseSkipDays(values []string) []time.Weekday {
+	var days []time.Weekday
+
+	for _, v := range values {
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if strings.EqualFold(strings.TrimSpace(v), d.String()) {
+				days = append(days, d)
+				break
+			}
+		}
+	}
+
+	return days
+}
@@ -0,0 +1,230 @@
+// Package llm provides a summarizer backed by an OpenAI compatible chat completions API,
+// such as OpenAI itself or a local llama.cpp or Ollama server.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const (
+	defaultBaseURL         = "https://api.openai.com/v1"
+	defaultModel           = "gpt-4o-mini"
+	defaultTimeout         = 60 * time.Second
+	defaultMaxInputTokens  = 4000
+	defaultMaxOutputTokens = 512
+	defaultSystemPrompt    = "You are a helpful assistant that writes concise, neutral summaries of articles."
+	defaultPromptTemplate  = "Summarize the following article in 3-5 short sentences. " +
+		"Reply in the language of the article and output plain text only.\n\n" +
+		"Title: {{.Title}}\nURL: {{.URL}}\n\n{{.Text}}"
+
+	// charsPerToken is a rough estimate used to fit the article into the token budget without a tokenizer.
+	charsPerToken   = 4
+	maxErrorBodyLen = 1 << 10
+)
+
+var (
+	// ErrEmptyCompletion is returned when the API responds without any generated text.
+	ErrEmptyCompletion = errors.New("empty completion")
+	// ErrMisconfigured is returned when the API refuses the key or does not know the endpoint or the model.
+	// It is left unclassified, as only the operator can fix the configuration.
+	ErrMisconfigured = errors.New("chat completions api is misconfigured")
+)
+
+// Config holds configuration for the LLM Client.
+type Config struct {
+	BaseURL         string        `mapstructure:"base_url"`
+	APIKey          string        `mapstructure:"api_key"`
+	Model           string        `mapstructure:"model"`
+	SystemPrompt    string        `mapstructure:"system_prompt"`
+	PromptTemplate  string        `mapstructure:"prompt_template"`
+	Timeout         time.Duration `mapstructure:"timeout"`
+	MaxInputTokens  int           `mapstructure:"max_input_tokens"`
+	MaxOutputTokens int           `mapstructure:"max_output_tokens"`
+}
+
+// Client summarizes articles using a chat completions endpoint.
+type Client struct {
+	cli    *http.Client
+	prompt *template.Template
+	cfg    Config
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model     string        `json:"model"`
+	Messages  []chatMessage `json:"messages"`
+	MaxTokens int           `json:"max_tokens,omitempty"`
+}
+
+type chatResponse struct {
+	Error   *apiError `json:"error,omitempty"`
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+type apiError struct {
+	Message string `json:"message"`
+}
+
+// New creates a new Client with the provided configuration, applying defaults for unset values.
+// It returns an error if the prompt template cannot be parsed.
+func New(cfg Config) (*Client, error) {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultBaseURL
+	}
+
+	if cfg.Model == "" {
+		cfg.Model = defaultModel
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	if cfg.MaxInputTokens <= 0 {
+		cfg.MaxInputTokens = defaultMaxInputTokens
+	}
+
+	if cfg.MaxOutputTokens <= 0 {
+		cfg.MaxOutputTokens = defaultMaxOutputTokens
+	}
+
+	if cfg.SystemPrompt == "" {
+		cfg.SystemPrompt = defaultSystemPrompt
+	}
+
+	if cfg.PromptTemplate == "" {
+		cfg.PromptTemplate = defaultPromptTemplate
+	}
+
+	prompt, err := template.New("prompt").Option("missingkey=error").Parse(cfg.PromptTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template: %w", err)
+	}
+
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+
+	return &Client{
+		cfg:    cfg,
+		prompt: prompt,
+		cli: &http.Client{
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+			Timeout:   cfg.Timeout,
+		},
+	}, nil
+}
+
+// Summarize renders the prompt template for the article, truncating its text to the input token budget,
+// and returns the text generated by the model.
+func (c *Client) Summarize(ctx context.Context, article *core.Article) (string, error) {
+	var prompt strings.Builder
+
+	truncated := *article
+	truncated.Text = truncate(article.Text, c.cfg.MaxInputTokens*charsPerToken)
+
+	if err := c.prompt.Execute(&prompt, &truncated); err != nil {
+		return "", fmt.Errorf("failed to render prompt: %w", err)
+	}
+
+	body, err := json.Marshal(chatRequest{
+		Model: c.cfg.Model,
+		Messages: []chatMessage{
+			{Role: "system", Content: c.cfg.SystemPrompt},
+			{Role: "user", Content: prompt.String()},
+		},
+		MaxTokens: c.cfg.MaxOutputTokens,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.BaseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if c.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.APIKey)
+	}
+
+	resp, err := c.cli.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to call chat completions: %w", core.ErrUpstreamUnavailable, err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", statusError(resp)
+	}
+
+	var res chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if res.Error != nil {
+		return "", fmt.Errorf("chat completions error: %s", res.Error.Message)
+	}
+
+	if len(res.Choices) == 0 || strings.TrimSpace(res.Choices[0].Message.Content) == "" {
+		return "", ErrEmptyCompletion
+	}
+
+	return strings.TrimSpace(res.Choices[0].Message.Content), nil
+}
+
+// statusError builds an error for a non successful response, including the API error message when available.
+// A refused key and an unknown endpoint or model are reported as ErrMisconfigured rather than classified
+// by core.StatusError, since they are not something the user can act upon.
+func statusError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyLen))
+
+	err := core.StatusError(resp.StatusCode)
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		err = fmt.Errorf("%w: unexpected status code: %d", ErrMisconfigured, resp.StatusCode)
+	}
+
+	var res chatResponse
+	if json.Unmarshal(data, &res) == nil && res.Error != nil && res.Error.Message != "" {
+		return fmt.Errorf("%w: %s", err, res.Error.Message)
+	}
+
+	return err
+}
+
+// truncate shortens the text to at most limit runes, cutting at the last whitespace and appending an ellipsis.
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+
+	cut := string(runes[:limit])
+	if i := strings.LastIndexAny(cut, " \n\t"); i > 0 {
+		cut = cut[:i]
+	}
+
+	return cut + "…"
+}
@@ -0,0 +1,173 @@
+package llm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	cli, err := New(Config{BaseURL: "http://localhost:8080/v1/"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://localhost:8080/v1", cli.cfg.BaseURL)
+	assert.Equal(t, defaultModel, cli.cfg.Model)
+	assert.Equal(t, defaultMaxInputTokens, cli.cfg.MaxInputTokens)
+	assert.Equal(t, defaultMaxOutputTokens, cli.cfg.MaxOutputTokens)
+	assert.Equal(t, defaultTimeout, cli.cfg.Timeout)
+
+	_, err = New(Config{PromptTemplate: "{{.Title"})
+	assert.Error(t, err)
+}
+
+func TestClient_Summarize(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		want    string
+		status  int
+		wantErr bool
+	}{
+		{
+			name:   "success",
+			status: http.StatusOK,
+			reply:  `{"choices":[{"message":{"role":"assistant","content":"  The summary.\n"}}]}`,
+			want:   "The summary.",
+		},
+		{
+			name:    "empty completion",
+			status:  http.StatusOK,
+			reply:   `{"choices":[]}`,
+			wantErr: true,
+		},
+		{
+			name:    "api error in body",
+			status:  http.StatusOK,
+			reply:   `{"error":{"message":"model not loaded"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "unauthorized",
+			status:  http.StatusUnauthorized,
+			reply:   `{"error":{"message":"invalid api key"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			status:  http.StatusOK,
+			reply:   `not json`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+				assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+				var req chatRequest
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				assert.Equal(t, "llama", req.Model)
+				assert.Equal(t, 100, req.MaxTokens)
+
+				if assert.Len(t, req.Messages, 2) {
+					assert.Equal(t, "system", req.Messages[0].Role)
+					assert.Equal(t, "Post: Some article text", req.Messages[1].Content)
+				}
+
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.reply))
+			}))
+			defer srv.Close()
+
+			cli, err := New(Config{
+				BaseURL:         srv.URL + "/v1",
+				APIKey:          "secret",
+				Model:           "llama",
+				PromptTemplate:  "{{.Title}}: {{.Text}}",
+				MaxOutputTokens: 100,
+			})
+			require.NoError(t, err)
+
+			got, err := cli.Summarize(t.Context(), &core.Article{Title: "Post", Text: "Some article text"})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClient_SummarizeStatusErrors(t *testing.T) {
+	tests := []struct {
+		want   error
+		name   string
+		status int
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, want: ErrMisconfigured},
+		{name: "forbidden", status: http.StatusForbidden, want: ErrMisconfigured},
+		{name: "unknown model", status: http.StatusNotFound, want: ErrMisconfigured},
+		{name: "too many requests", status: http.StatusTooManyRequests, want: core.ErrQuotaExceeded},
+		{name: "server error", status: http.StatusBadGateway, want: core.ErrUpstreamUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{"error":{"message":"request failed"}}`))
+			}))
+			defer srv.Close()
+
+			cli, err := New(Config{BaseURL: srv.URL})
+			require.NoError(t, err)
+
+			_, err = cli.Summarize(t.Context(), &core.Article{Title: "Post", Text: "Some article text"})
+			assert.ErrorIs(t, err, tt.want)
+			assert.ErrorContains(t, err, "request failed")
+
+			if tt.want == ErrMisconfigured {
+				assert.NotErrorIs(t, err, core.ErrPermissionDenied)
+				assert.NotErrorIs(t, err, core.ErrNotFound)
+			}
+		})
+	}
+}
+
+func TestClient_SummarizeTruncatesInput(t *testing.T) {
+	var prompt string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req chatRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		prompt = req.Messages[1].Content
+
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}]}`))
+	}))
+	defer srv.Close()
+
+	cli, err := New(Config{BaseURL: srv.URL, PromptTemplate: "{{.Text}}", MaxInputTokens: 3})
+	require.NoError(t, err)
+
+	_, err = cli.Summarize(t.Context(), &core.Article{Text: "one two three four five"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "one two…", prompt)
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", truncate("short", 10))
+	assert.Equal(t, "hello…", truncate("hello world", 8))
+	assert.Equal(t, "привет…", truncate("привет мир", 8))
+	assert.Equal(t, "abcd…", truncate("abcdefgh", 4))
+}
@@ -0,0 +1,85 @@
+// Package safenet provides an HTTP transport that refuses to connect to internal network addresses,
+// so URLs sent by users cannot make the bot reach services on its own host or in its private network.
+package safenet
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+const (
+	dialTimeout = 30 * time.Second
+	keepAlive   = 30 * time.Second
+)
+
+// ErrForbiddenAddress is returned when connecting to an address that is not publicly routable.
+var ErrForbiddenAddress = errors.New("forbidden address")
+
+// forbiddenPrefixes lists the ranges that are not publicly routable but not covered by the netip predicates:
+// the "this network" range, the shared address space used for carrier-grade NAT and by some cloud providers
+// for their internal services, and the NAT64 prefix through which IPv6 hosts reach IPv4 addresses, private ones included.
+var forbiddenPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// NewTransport returns an HTTP transport based on the default one that checks the address of every connection
+// after the host name is resolved, so host names resolving to internal addresses and redirects to them are refused
+// as well. Proxies from the environment are not used, as connecting through them would bypass the check.
+func NewTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: keepAlive,
+		Control:   control,
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = nil
+	t.DialContext = dialer.DialContext
+
+	return t
+}
+
+// control refuses connections to addresses that are not publicly routable.
+func control(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+
+	ip, err := netip.ParseAddr(host)
+	if err != nil || !Allowed(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+
+	return nil
+}
+
+// Allowed reports whether the address is publicly routable, that is neither a loopback, private, link-local,
+// unspecified, multicast, shared nor NAT64 address. IPv4 addresses mapped to IPv6 are checked as IPv4 addresses.
+func Allowed(ip netip.Addr) bool {
+	ip = ip.Unmap()
+
+	if !ip.IsValid() ||
+		ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsUnspecified() ||
+		ip.IsMulticast() {
+		return false
+	}
+
+	for _, prefix := range forbiddenPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,94 @@
+package safenet
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "93.184.215.14", want: true},
+		{addr: "2606:2800:21f:cb07:6820:80da:af6b:8b2c", want: true},
+		{addr: "127.0.0.1"},
+		{addr: "::1"},
+		{addr: "10.1.2.3"},
+		{addr: "172.16.0.1"},
+		{addr: "192.168.1.1"},
+		{addr: "fd00::1"},
+		{addr: "169.254.169.254"},
+		{addr: "fe80::1%eth0"},
+		{addr: "0.0.0.0"},
+		{addr: "::"},
+		{addr: "224.0.0.1"},
+		{addr: "ff02::1"},
+		{addr: "::ffff:127.0.0.1"},
+		{addr: "::ffff:10.0.0.1"},
+		{addr: "0.1.2.3"},
+		{addr: "100.64.0.1"},
+		{addr: "100.100.100.200"},
+		{addr: "100.127.255.254"},
+		{addr: "100.128.0.1", want: true},
+		{addr: "64:ff9b::a00:1"},
+		{addr: "64:ff9b::5db8:d70e"},
+		{addr: "::ffff:100.64.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			assert.Equal(t, tt.want, Allowed(netip.MustParseAddr(tt.addr)))
+		})
+	}
+
+	assert.False(t, Allowed(netip.Addr{}))
+}
+
+func TestControl(t *testing.T) {
+	assert.NoError(t, control("tcp4", "93.184.215.14:443", nil))
+	assert.ErrorIs(t, control("tcp4", "127.0.0.1:80", nil), ErrForbiddenAddress)
+	assert.ErrorIs(t, control("tcp6", "[::1]:80", nil), ErrForbiddenAddress)
+	assert.ErrorIs(t, control("tcp4", "not an address", nil), ErrForbiddenAddress)
+}
+
+func TestNewTransport(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer internal.Close()
+
+	u, err := url.Parse(internal.URL)
+	require.NoError(t, err)
+
+	cli := &http.Client{Transport: NewTransport()}
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{name: "loopback address", url: internal.URL},
+		{name: "host name resolving to loopback", url: "http://localhost:" + u.Port()},
+		{name: "unspecified address", url: "http://0.0.0.0:" + u.Port()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, tt.url, http.NoBody)
+			require.NoError(t, err)
+
+			resp, err := cli.Do(req)
+			if resp != nil {
+				_ = resp.Body.Close()
+			}
+
+			assert.ErrorIs(t, err, ErrForbiddenAddress)
+		})
+	}
+}
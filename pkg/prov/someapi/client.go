@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"net/http"
 	"time"
+
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 const defaultTimeout = 5 * time.Second
@@ -26,16 +29,22 @@ func New(cfg Config) *APIClient {
 	return &APIClient{
 		cfg: cfg,
 		cli: &http.Client{
-			Timeout: defaultTimeout,
+			Transport: otelhttp.NewTransport(http.DefaultTransport),
+			Timeout:   defaultTimeout,
 		},
 	}
 }
 
 // CheckHealth checks the health status of the SomeAPI service.
 func (a *APIClient) CheckHealth(ctx context.Context) error {
-	resp, err := a.cli.Get(a.cfg.BaseURL + "/livez")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.cfg.BaseURL+"/livez", http.NoBody)
+	if err != nil {
+		return fmt.Errorf("fail to create health check request for someapi: %w", err)
+	}
+
+	resp, err := a.cli.Do(req)
 	if err != nil {
-		return fmt.Errorf("fail to check health status for someapi: %w", err)
+		return fmt.Errorf("%w: fail to check health status for someapi: %w", core.ErrUpstreamUnavailable, err)
 	}
 
 	if resp.Body != nil {
@@ -43,7 +52,7 @@ func (a *APIClient) CheckHealth(ctx context.Context) error {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("status of someapi is unhealthy")
+		return fmt.Errorf("%w: status of someapi is unhealthy", core.ErrUpstreamUnavailable)
 	}
 
 	return nil
@@ -0,0 +1,66 @@
+// Package recovery turns panics of request handlers and background workers into errors, so a bug hit
+// by a single update or feed is logged and counted instead of crashing the whole process.
+package recovery
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var panicsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "feeder",
+	Name:      "panics_total",
+	Help:      "Number of panics recovered, by component.",
+}, []string{"component"})
+
+// PanicError is the error a recovered panic is converted into.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+// Error returns the message of the error, holding the value the panic was called with.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the error the panic was called with, if any.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+
+	return nil
+}
+
+// Do calls fn and returns its error. A panic of fn is recovered and returned as a *PanicError
+// after being logged with its stack trace and counted for the component.
+func Do(ctx context.Context, component string, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = report(ctx, component, r)
+		}
+	}()
+
+	return fn()
+}
+
+// report logs and counts the value recovered from a panic of the component and returns it as an error.
+func report(ctx context.Context, component string, r any) error {
+	err := &PanicError{Value: r, Stack: debug.Stack()}
+
+	panicsTotal.WithLabelValues(component).Inc()
+
+	slog.ErrorContext(ctx, "Recovered from panic",
+		slog.String("component", component),
+		slog.Any("error", err),
+		slog.String("stack", string(err.Stack)),
+	)
+
+	return err
+}
@@ -0,0 +1,68 @@
+package recovery
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDo(t *testing.T) {
+	tests := []struct {
+		fn        func() error
+		wantErr   error
+		name      string
+		wantPanic bool
+	}{
+		{
+			name: "no error",
+			fn:   func() error { return nil },
+		},
+		{
+			name:    "error",
+			fn:      func() error { return assert.AnError },
+			wantErr: assert.AnError,
+		},
+		{
+			name:      "panic with value",
+			fn:        func() error { panic("boom") },
+			wantPanic: true,
+		},
+		{
+			name:      "panic with error",
+			fn:        func() error { panic(assert.AnError) },
+			wantErr:   assert.AnError,
+			wantPanic: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := "test_" + tt.name
+			before := testutil.ToFloat64(panicsTotal.WithLabelValues(component))
+
+			err := Do(context.Background(), component, tt.fn)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+
+			var panicErr *PanicError
+
+			if !tt.wantPanic {
+				assert.False(t, errors.As(err, &panicErr))
+				assert.Zero(t, testutil.ToFloat64(panicsTotal.WithLabelValues(component))-before)
+
+				return
+			}
+
+			require.ErrorAs(t, err, &panicErr)
+			assert.Contains(t, err.Error(), "panic: ")
+			assert.Contains(t, string(panicErr.Stack), "recovery.TestDo")
+			assert.InDelta(t, 1, testutil.ToFloat64(panicsTotal.WithLabelValues(component))-before, 0)
+		})
+	}
+}
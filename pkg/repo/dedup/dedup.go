@@ -0,0 +1,164 @@
+// Package dedup provides a Redis backed store of feed items that have already been seen,
+// so that the same entry is never published twice.
+package dedup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultTTL      = 90 * 24 * time.Hour
+	defaultMaxItems = 1000
+
+	// initMarker is stored with an infinite score in every seen set, so it is never trimmed
+	// and tells initialized feeds apart from feeds that were never fetched.
+	initMarker = "~init"
+)
+
+// Config holds the configuration of the dedup store.
+type Config struct {
+	TTL      time.Duration `mapstructure:"ttl"`
+	MaxItems int           `mapstructure:"max_items"`
+}
+
+// dedupDAO defines the interface for seen item data access operations.
+type dedupDAO interface {
+	ZMScore(ctx context.Context, key string, members ...string) *redis.FloatSliceCmd
+	ZScore(ctx context.Context, key, member string) *redis.FloatCmd
+	TxPipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error)
+}
+
+// Store keeps, per feed, a bounded sorted set of item keys scored by the time they were last seen.
+// Sets expire when a feed has not been seen for the configured TTL.
+type Store struct {
+	dao      dedupDAO
+	ttl      time.Duration
+	maxItems int
+}
+
+// New creates a new instance of Store using the provided configuration and dedupDAO.
+func New(cfg Config, dao dedupDAO) *Store {
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultTTL
+	}
+
+	if cfg.MaxItems <= 0 {
+		cfg.MaxItems = defaultMaxItems
+	}
+
+	return &Store{
+		dao:      dao,
+		ttl:      cfg.TTL,
+		maxItems: cfg.MaxItems,
+	}
+}
+
+// Initialized reports whether items of the feed have been recorded before.
+// Feeds that were never fetched, or whose seen set has expired, are not initialized.
+func (s *Store) Initialized(ctx context.Context, feedID string) (bool, error) {
+	err := s.dao.ZScore(ctx, seenKey(feedID), initMarker).Err()
+
+	switch {
+	case errors.Is(err, redis.Nil):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("fail to check seen items: %w", err)
+	}
+
+	return true, nil
+}
+
+// FilterUnseen returns the items that have not been seen yet, preserving their order.
+// Items are identified by their GUID, or by their link or content when they have none.
+// Duplicates within the batch are dropped as well.
+func (s *Store) FilterUnseen(ctx context.Context, feedID string, items []core.FeedItem) ([]core.FeedItem, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	itemKeys := make([]string, len(items))
+	members := make([]string, 0, len(items))
+
+	for i := range items {
+		if itemKeys[i] = keyOf(&items[i]); itemKeys[i] != "" {
+			members = append(members, itemKeys[i])
+		}
+	}
+
+	if len(members) == 0 {
+		return nil, nil
+	}
+
+	scores, err := s.dao.ZMScore(ctx, seenKey(feedID), members...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("fail to check seen items: %w", err)
+	}
+
+	seen := make(map[string]struct{}, len(members))
+
+	for i, member := range members {
+		if scores[i] > 0 {
+			seen[member] = struct{}{}
+		}
+	}
+
+	unseen := make([]core.FeedItem, 0, len(items))
+
+	for i := range items {
+		if itemKeys[i] == "" {
+			continue
+		}
+
+		if _, ok := seen[itemKeys[i]]; ok {
+			continue
+		}
+
+		seen[itemKeys[i]] = struct{}{}
+
+		unseen = append(unseen, items[i])
+	}
+
+	return unseen, nil
+}
+
+// MarkSeen records the items as seen, trims the seen set of the feed to its maximum size
+// dropping the least recently seen keys, and refreshes its expiration.
+func (s *Store) MarkSeen(ctx context.Context, feedID string, items []core.FeedItem) error {
+	now := float64(time.Now().Unix())
+
+	members := []redis.Z{{Score: math.Inf(1), Member: initMarker}}
+
+	for i := range items {
+		if key := keyOf(&items[i]); key != "" {
+			members = append(members, redis.Z{Score: now, Member: key})
+		}
+	}
+
+	key := seenKey(feedID)
+	// The marker is always ranked last, so keep one extra entry for it.
+	maxKeys := int64(s.maxItems) + 1
+
+	_, err := s.dao.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, key, members...)
+		pipe.ZRemRangeByRank(ctx, key, 0, -maxKeys-1)
+		pipe.Expire(ctx, key, s.ttl)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("fail to mark items as seen: %w", err)
+	}
+
+	return nil
+}
+
+func seenKey(feedID string) string {
+	return "feed:" + feedID + ":seen"
+}
@@ -0,0 +1,155 @@
+package dedup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStore returns a Store backed by an in-process Redis server.
+func newTestStore(t *testing.T, cfg Config) (*Store, *miniredis.Miniredis) {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	return New(cfg, rdb), srv
+}
+
+func TestNew(t *testing.T) {
+	s := New(Config{}, nil)
+
+	assert.Equal(t, defaultTTL, s.ttl)
+	assert.Equal(t, defaultMaxItems, s.maxItems)
+
+	s = New(Config{TTL: time.Hour, MaxItems: 10}, nil)
+
+	assert.Equal(t, time.Hour, s.ttl)
+	assert.Equal(t, 10, s.maxItems)
+}
+
+func TestStore_Initialized(t *testing.T) {
+	s, srv := newTestStore(t, Config{TTL: time.Hour})
+
+	ok, err := s.Initialized(t.Context(), "f1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, s.MarkSeen(t.Context(), "f1", nil))
+
+	ok, err = s.Initialized(t.Context(), "f1")
+	require.NoError(t, err)
+	assert.True(t, ok, "marking an empty first fetch should initialize the feed")
+
+	srv.FastForward(2 * time.Hour)
+
+	ok, err = s.Initialized(t.Context(), "f1")
+	require.NoError(t, err)
+	assert.False(t, ok, "expired feeds should be treated as never fetched")
+}
+
+func TestStore_FilterUnseen(t *testing.T) {
+	s, _ := newTestStore(t, Config{})
+
+	require.NoError(t, s.MarkSeen(t.Context(), "f1", []core.FeedItem{
+		{GUID: "1", Link: "https://example.com/1"},
+		{Link: "https://example.com/no-guid"},
+		{Title: "No identifiers", Content: "Some   content"},
+	}))
+
+	items := []core.FeedItem{
+		{GUID: "1", Link: "https://example.com/1"},
+		{Link: "http://EXAMPLE.com/no-guid/?utm_source=rss#comments"},
+		{Title: "no identifiers", Content: "Some content"},
+		{GUID: "2", Link: "https://example.com/2"},
+		{GUID: "2", Link: "https://example.com/2"},
+		{},
+		{GUID: "3"},
+	}
+
+	unseen, err := s.FilterUnseen(t.Context(), "f1", items)
+	require.NoError(t, err)
+
+	assert.Equal(t, []core.FeedItem{
+		{GUID: "2", Link: "https://example.com/2"},
+		{GUID: "3"},
+	}, unseen)
+
+	unseen, err = s.FilterUnseen(t.Context(), "f2", items[:1])
+	require.NoError(t, err)
+	assert.Equal(t, items[:1], unseen, "seen items should be tracked per feed")
+
+	unseen, err = s.FilterUnseen(t.Context(), "f1", nil)
+	require.NoError(t, err)
+	assert.Empty(t, unseen)
+}
+
+func TestStore_FilterUnseenSharedLink(t *testing.T) {
+	s, _ := newTestStore(t, Config{})
+
+	const link = "https://status.example.com/"
+
+	require.NoError(t, s.MarkSeen(t.Context(), "f1", []core.FeedItem{{GUID: "incident-1", Link: link}}))
+
+	items := []core.FeedItem{
+		{GUID: "incident-1", Link: link},
+		{GUID: "incident-2", Link: link},
+		{GUID: "incident-3", Link: link},
+	}
+
+	unseen, err := s.FilterUnseen(t.Context(), "f1", items)
+	require.NoError(t, err)
+	assert.Equal(t, items[1:], unseen, "items with distinct GUIDs should not be deduplicated by their shared link")
+}
+
+func TestStore_MarkSeen(t *testing.T) {
+	s, srv := newTestStore(t, Config{TTL: time.Hour, MaxItems: 2})
+
+	for _, id := range []string{"1", "2", "3"} {
+		require.NoError(t, s.MarkSeen(t.Context(), "f1", []core.FeedItem{{GUID: id, Link: "https://example.com/" + id}}))
+	}
+
+	members, err := srv.ZMembers("feed:f1:seen")
+	require.NoError(t, err)
+	assert.Len(t, members, 3, "seen set should be bounded")
+	assert.Contains(t, members, initMarker)
+
+	assert.Equal(t, time.Hour, srv.TTL("feed:f1:seen"))
+}
+
+func TestStore_MarkSeenTrimsOldest(t *testing.T) {
+	s, srv := newTestStore(t, Config{MaxItems: 2})
+
+	_, err := srv.ZAdd("feed:f1:seen", 1, guidPrefix+hashOf("old"))
+	require.NoError(t, err)
+
+	require.NoError(t, s.MarkSeen(t.Context(), "f1", []core.FeedItem{{GUID: "new1"}, {GUID: "new2"}}))
+
+	unseen, err := s.FilterUnseen(t.Context(), "f1", []core.FeedItem{{GUID: "old"}, {GUID: "new1"}, {GUID: "new2"}})
+	require.NoError(t, err)
+	assert.Equal(t, []core.FeedItem{{GUID: "old"}}, unseen, "least recently seen keys should be trimmed first")
+
+	ok, err := s.Initialized(t.Context(), "f1")
+	require.NoError(t, err)
+	assert.True(t, ok, "marker should survive trimming")
+}
+
+func TestStore_RedisFailure(t *testing.T) {
+	s, srv := newTestStore(t, Config{})
+	srv.Close()
+
+	_, err := s.Initialized(t.Context(), "f1")
+	assert.Error(t, err)
+
+	_, err = s.FilterUnseen(t.Context(), "f1", []core.FeedItem{{GUID: "1"}})
+	assert.Error(t, err)
+
+	assert.Error(t, s.MarkSeen(t.Context(), "f1", nil))
+}
@@ -0,0 +1,105 @@
+package dedup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/ksysoev/tg-feeder/pkg/core"
+)
+
+const (
+	keyHashLength = 16
+
+	guidPrefix        = "g:"
+	linkPrefix        = "l:"
+	fingerprintPrefix = "c:"
+)
+
+// trackingParams lists query parameters that do not change the resource a link points to.
+var trackingParams = []string{"fbclid", "gclid", "mc_cid", "mc_eid", "ref", "yclid"}
+
+// keyOf returns the dedup key of the item: its GUID when the feed provides one, so that distinct entries
+// pointing to the same page are all delivered. Items without a GUID fall back to their normalized link
+// and items with neither to a fingerprint of their content.
+// It returns an empty string for an item without any identifying data.
+func keyOf(item *core.FeedItem) string {
+	if guid := strings.TrimSpace(item.GUID); guid != "" {
+		return guidPrefix + hashOf(guid)
+	}
+
+	if link := normalizeLink(item.Link); link != "" {
+		return linkPrefix + hashOf(link)
+	}
+
+	if fp := fingerprint(item); fp != "" {
+		return fingerprintPrefix + hashOf(fp)
+	}
+
+	return ""
+}
+
+// normalizeLink canonicalizes the link so that cosmetic differences do not produce different keys:
+// the scheme and host are lowercased, the default port, fragment, trailing slash and
+// tracking parameters are dropped, and the remaining query parameters are sorted.
+func normalizeLink(link string) string {
+	link = strings.TrimSpace(link)
+	if link == "" {
+		return ""
+	}
+
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return link
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+
+	if port := u.Port(); (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		u.Host = u.Hostname()
+	}
+
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.User = nil
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = ""
+
+	query := u.Query()
+	for name := range query {
+		if strings.HasPrefix(name, "utm_") || slices.Contains(trackingParams, name) {
+			query.Del(name)
+		}
+	}
+
+	u.RawQuery = query.Encode()
+
+	// Treat http and https variants of the same link as equal.
+	if u.Scheme == "http" {
+		u.Scheme = "https"
+	}
+
+	return u.String()
+}
+
+// fingerprint returns the whitespace and case normalized title and content of the item.
+func fingerprint(item *core.FeedItem) string {
+	title := strings.Join(strings.Fields(strings.ToLower(item.Title)), " ")
+	content := strings.Join(strings.Fields(strings.ToLower(item.Content)), " ")
+
+	if title == "" && content == "" {
+		return ""
+	}
+
+	return title + "\n" + content
+}
+
+// hashOf returns a truncated hex encoded sha256 of the value, keeping the seen sets small.
+func hashOf(value string) string {
+	sum := sha256.Sum256([]byte(value))
+
+	return hex.EncodeToString(sum[:])[:keyHashLength]
+}
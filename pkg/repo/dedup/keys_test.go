@@ -0,0 +1,63 @@
+package dedup
+
+import (
+	"testing"
+
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyOf(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		item core.FeedItem
+	}{
+		{
+			name: "guid and link",
+			item: core.FeedItem{GUID: " 1 ", Link: "https://example.com/1", Title: "Title"},
+			want: guidPrefix + hashOf("1"),
+		},
+		{
+			name: "link only",
+			item: core.FeedItem{Link: "https://example.com/1/?utm_source=rss", Title: "Title"},
+			want: linkPrefix + hashOf("https://example.com/1"),
+		},
+		{
+			name: "content fingerprint",
+			item: core.FeedItem{Title: " Hello  World ", Content: "Body"},
+			want: fingerprintPrefix + hashOf("hello world\nbody"),
+		},
+		{
+			name: "nothing to identify",
+			item: core.FeedItem{},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, keyOf(&tt.item))
+		})
+	}
+}
+
+func TestNormalizeLink(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+	}{
+		{link: "", want: ""},
+		{link: "https://example.com/post", want: "https://example.com/post"},
+		{link: " HTTP://Example.COM:80/post/#comments ", want: "https://example.com/post"},
+		{link: "https://example.com:443/post?utm_source=rss&b=2&a=1&fbclid=x", want: "https://example.com/post?a=1&b=2"},
+		{link: "https://user@example.com:8443/post", want: "https://example.com:8443/post"},
+		{link: "/relative/path", want: "/relative/path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.link, func(t *testing.T) {
+			assert.Equal(t, tt.want, normalizeLink(tt.link))
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Package dialog provides a Redis backed store of the state of the active bot dialog of every user in a chat.
+package dialog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ksysoev/tg-feeder/pkg/bot/dialog"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultTTL = 30 * time.Minute
+	keyPrefix  = "dialog:"
+)
+
+// Config holds the configuration of the dialog state store.
+type Config struct {
+	TTL time.Duration `mapstructure:"ttl"`
+}
+
+// dialogDAO defines the interface for dialog state data access operations.
+type dialogDAO interface {
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Set(ctx context.Context, key string, value any, expiration time.Duration) *redis.StatusCmd
+	Del(ctx context.Context, keys ...string) *redis.IntCmd
+}
+
+// Store keeps the state of the active dialog of every user in a chat as a JSON document.
+// The state expires when the user has not answered for the configured TTL, abandoning the dialog.
+type Store struct {
+	dao dialogDAO
+	ttl time.Duration
+}
+
+// New creates a new instance of Store using the provided configuration and dialogDAO.
+func New(cfg Config, dao dialogDAO) *Store {
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultTTL
+	}
+
+	return &Store{
+		dao: dao,
+		ttl: cfg.TTL,
+	}
+}
+
+// Get returns the state of the active dialog of the user in the chat or nil if there is none.
+func (s *Store) Get(ctx context.Context, key dialog.Key) (*dialog.State, error) {
+	data, err := s.dao.Get(ctx, stateKey(key)).Bytes()
+
+	switch {
+	case errors.Is(err, redis.Nil):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("fail to get dialog state: %w", err)
+	}
+
+	var state dialog.State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("fail to decode dialog state: %w", err)
+	}
+
+	return &state, nil
+}
+
+// Save stores the state of the active dialog of the user in the chat, restarting its TTL.
+func (s *Store) Save(ctx context.Context, key dialog.Key, state *dialog.State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("fail to encode dialog state: %w", err)
+	}
+
+	if err := s.dao.Set(ctx, stateKey(key), data, s.ttl).Err(); err != nil {
+		return fmt.Errorf("fail to save dialog state: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the state of the active dialog of the user in the chat.
+func (s *Store) Delete(ctx context.Context, key dialog.Key) error {
+	if err := s.dao.Del(ctx, stateKey(key)).Err(); err != nil {
+		return fmt.Errorf("fail to delete dialog state: %w", err)
+	}
+
+	return nil
+}
+
+// stateKey returns the key holding the dialog state of the user in the chat.
+func stateKey(key dialog.Key) string {
+	return keyPrefix + strconv.FormatInt(key.ChatID, 10) + ":" + strconv.FormatInt(key.UserID, 10)
+}
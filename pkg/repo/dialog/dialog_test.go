@@ -0,0 +1,88 @@
+package dialog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ksysoev/tg-feeder/pkg/bot/dialog"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStore returns a Store backed by an in-process Redis server.
+func newTestStore(t *testing.T, cfg Config) (*Store, *miniredis.Miniredis) {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	return New(cfg, rdb), srv
+}
+
+func TestNew(t *testing.T) {
+	assert.Equal(t, defaultTTL, New(Config{}, nil).ttl)
+	assert.Equal(t, time.Hour, New(Config{TTL: time.Hour}, nil).ttl)
+}
+
+func TestStore(t *testing.T) {
+	s, srv := newTestStore(t, Config{TTL: time.Minute})
+	key := dialog.Key{ChatID: -100, UserID: 1}
+
+	state, err := s.Get(t.Context(), key)
+	require.NoError(t, err)
+	assert.Nil(t, state)
+
+	want := &dialog.State{Dialog: "subscribe", Step: "filter", Values: map[string]string{"url": "https://example.com/rss"}}
+
+	require.NoError(t, s.Save(t.Context(), key, want))
+
+	state, err = s.Get(t.Context(), key)
+	require.NoError(t, err)
+	assert.Equal(t, want, state)
+
+	other, err := s.Get(t.Context(), dialog.Key{ChatID: 100, UserID: 1})
+	require.NoError(t, err)
+	assert.Nil(t, other, "states should be kept per chat")
+
+	other, err = s.Get(t.Context(), dialog.Key{ChatID: -100, UserID: 2})
+	require.NoError(t, err)
+	assert.Nil(t, other, "states should be kept per member of a group")
+
+	require.NoError(t, s.Delete(t.Context(), key))
+
+	state, err = s.Get(t.Context(), key)
+	require.NoError(t, err)
+	assert.Nil(t, state)
+
+	require.NoError(t, s.Save(t.Context(), key, want))
+	srv.FastForward(2 * time.Minute)
+
+	state, err = s.Get(t.Context(), key)
+	require.NoError(t, err)
+	assert.Nil(t, state, "abandoned dialogs should expire")
+}
+
+func TestStore_Errors(t *testing.T) {
+	s, srv := newTestStore(t, Config{})
+	key := dialog.Key{ChatID: 1, UserID: 1}
+
+	require.NoError(t, srv.Set(stateKey(key), "not json"))
+
+	_, err := s.Get(t.Context(), key)
+	assert.ErrorContains(t, err, "fail to decode dialog state")
+
+	srv.Close()
+
+	_, err = s.Get(t.Context(), key)
+	assert.ErrorContains(t, err, "fail to get dialog state")
+
+	err = s.Save(t.Context(), key, &dialog.State{Dialog: "d", Step: "s"})
+	assert.ErrorContains(t, err, "fail to save dialog state")
+
+	err = s.Delete(t.Context(), key)
+	assert.ErrorContains(t, err, "fail to delete dialog state")
+}
@@ -0,0 +1,370 @@
+// Package outbox provides a Redis Streams backed queue of the posts waiting to be published.
+// Posts are consumed through a consumer group and stay pending until they are acknowledged, so posts received
+// by a process that crashed are delivered again. Failed posts wait for their retry in a sorted set scored by
+// the time of the retry, and posts that failed too many times are moved to a capped dead-letter stream.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultBlock      = 5 * time.Second
+	defaultClaimAfter = 10 * time.Minute
+	defaultDeadMaxLen = 10000
+	defaultConsumer   = "feeder"
+
+	streamKey = "outbox"
+	retryKey  = "outbox:retry"
+	deadKey   = "outbox:dead"
+	groupName = "publishers"
+
+	fieldData     = "data"
+	fieldError    = "error"
+	fieldFailedAt = "failed_at"
+)
+
+// promoteScript moves the posts whose retry is due from the retry set back to the stream.
+// KEYS: retry set, stream. ARGV: current time in milliseconds, maximum number of posts to move.
+// It returns the number of moved posts.
+var promoteScript = redis.NewScript(`
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for _, data in ipairs(due) do
+	redis.call('XADD', KEYS[2], '*', 'data', data)
+	redis.call('ZREM', KEYS[1], data)
+end
+return #due
+`)
+
+// Config holds the configuration of the outbox.
+// Consumer names this process in the consumer group and defaults to the host name. Pending posts of other
+// consumers are claimed once they have not been acknowledged for ClaimAfter, which has to exceed the time
+// it takes to publish a post. Block bounds how long Receive waits for new posts.
+type Config struct {
+	Consumer   string        `mapstructure:"consumer"`
+	Block      time.Duration `mapstructure:"block"`
+	ClaimAfter time.Duration `mapstructure:"claim_after"`
+	DeadMaxLen int64         `mapstructure:"dead_max_len"`
+}
+
+// outboxDAO defines the interface for outbox data access operations.
+type outboxDAO interface {
+	redis.Scripter
+	XGroupCreateMkStream(ctx context.Context, stream, group, start string) *redis.StatusCmd
+	XReadGroup(ctx context.Context, a *redis.XReadGroupArgs) *redis.XStreamSliceCmd
+	XAutoClaim(ctx context.Context, a *redis.XAutoClaimArgs) *redis.XAutoClaimCmd
+	Pipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error)
+	TxPipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error)
+}
+
+// envelope is the queued form of a post.
+type envelope struct {
+	Post     core.Post `json:"post"`
+	Attempts int       `json:"attempts"`
+}
+
+// Outbox is the queue of the posts waiting to be published.
+// Receive is meant to be called from a single goroutine.
+type Outbox struct {
+	dao         outboxDAO
+	pendingFrom string
+	cfg         Config
+	groupReady  bool
+	recovered   bool
+}
+
+// New creates a new instance of Outbox using the provided configuration and outboxDAO.
+func New(cfg Config, dao outboxDAO) *Outbox {
+	if cfg.Consumer == "" {
+		cfg.Consumer = defaultConsumer
+
+		if host, err := os.Hostname(); err == nil && host != "" {
+			cfg.Consumer = host
+		}
+	}
+
+	if cfg.Block <= 0 {
+		cfg.Block = defaultBlock
+	}
+
+	if cfg.ClaimAfter <= 0 {
+		cfg.ClaimAfter = defaultClaimAfter
+	}
+
+	if cfg.DeadMaxLen <= 0 {
+		cfg.DeadMaxLen = defaultDeadMaxLen
+	}
+
+	return &Outbox{
+		dao:         dao,
+		cfg:         cfg,
+		pendingFrom: "0",
+	}
+}
+
+// Enqueue adds the posts to the outbox in order, either all of them or none.
+func (o *Outbox) Enqueue(ctx context.Context, posts []core.Post) error {
+	values := make([]string, 0, len(posts))
+
+	for i := range posts {
+		data, err := json.Marshal(envelope{Post: posts[i]})
+		if err != nil {
+			return fmt.Errorf("fail to encode post: %w", err)
+		}
+
+		values = append(values, string(data))
+	}
+
+	_, err := o.dao.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, data := range values {
+			pipe.XAdd(ctx, &redis.XAddArgs{Stream: streamKey, Values: []string{fieldData, data}})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("fail to enqueue posts: %w", err)
+	}
+
+	return nil
+}
+
+// Receive returns up to count posts to deliver. The posts this consumer received before a restart come first,
+// then the posts whose retry is due and the posts abandoned by other consumers, and finally new posts,
+// waiting for them for the configured block time. It returns no posts when none arrived in time.
+func (o *Outbox) Receive(ctx context.Context, count int) ([]core.OutboxMessage, error) {
+	if err := o.ensureGroup(ctx); err != nil {
+		return nil, err
+	}
+
+	if !o.recovered {
+		entries, err := o.read(ctx, o.pendingFrom, count, -1)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(entries) > 0 {
+			o.pendingFrom = entries[len(entries)-1].ID
+			return o.decode(ctx, entries), nil
+		}
+
+		o.recovered = true
+	}
+
+	if err := promoteScript.Run(ctx, o.dao, []string{retryKey, streamKey}, time.Now().UnixMilli(), count).Err(); err != nil {
+		return nil, fmt.Errorf("fail to promote due retries: %w", err)
+	}
+
+	claimed, _, err := o.dao.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   streamKey,
+		Group:    groupName,
+		Consumer: o.cfg.Consumer,
+		MinIdle:  o.cfg.ClaimAfter,
+		Start:    "0-0",
+		Count:    int64(count),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("fail to claim abandoned posts: %w", err)
+	}
+
+	if msgs := o.decode(ctx, claimed); len(msgs) > 0 {
+		return msgs, nil
+	}
+
+	entries, err := o.read(ctx, ">", count, o.cfg.Block)
+	if err != nil {
+		return nil, err
+	}
+
+	return o.decode(ctx, entries), nil
+}
+
+// Ack removes the delivered post from the outbox.
+func (o *Outbox) Ack(ctx context.Context, id string) error {
+	_, err := o.dao.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAck(ctx, streamKey, groupName, id)
+		pipe.XDel(ctx, streamKey, id)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("fail to acknowledge post: %w", err)
+	}
+
+	return nil
+}
+
+// Retry counts the failed delivery of the post and schedules its next delivery at the given time.
+func (o *Outbox) Retry(ctx context.Context, msg *core.OutboxMessage, at time.Time) error {
+	data, err := json.Marshal(envelope{Post: msg.Post, Attempts: msg.Attempts + 1})
+	if err != nil {
+		return fmt.Errorf("fail to encode post: %w", err)
+	}
+
+	_, err = o.dao.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, retryKey, redis.Z{Score: float64(at.UnixMilli()), Member: string(data)})
+		pipe.XAck(ctx, streamKey, groupName, msg.ID)
+		pipe.XDel(ctx, streamKey, msg.ID)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("fail to schedule post retry: %w", err)
+	}
+
+	return nil
+}
+
+// DeadLetter counts the failed delivery of the post and moves it to the dead-letter stream with the reason.
+func (o *Outbox) DeadLetter(ctx context.Context, msg *core.OutboxMessage, reason string) error {
+	data, err := json.Marshal(envelope{Post: msg.Post, Attempts: msg.Attempts + 1})
+	if err != nil {
+		return fmt.Errorf("fail to encode post: %w", err)
+	}
+
+	return o.bury(ctx, msg.ID, string(data), reason)
+}
+
+// bury moves the stream entry with the data to the dead-letter stream.
+func (o *Outbox) bury(ctx context.Context, id, data, reason string) error {
+	_, err := o.dao.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: deadKey,
+			MaxLen: o.cfg.DeadMaxLen,
+			Approx: true,
+			Values: []string{fieldData, data, fieldError, reason, fieldFailedAt, strconv.FormatInt(time.Now().Unix(), 10)},
+		})
+		pipe.XAck(ctx, streamKey, groupName, id)
+		pipe.XDel(ctx, streamKey, id)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("fail to move post to dead-letter stream: %w", err)
+	}
+
+	return nil
+}
+
+// Stats returns the backlog of the outbox. The age of the oldest post is derived from its stream entry id,
+// which holds the time the post was queued or its retry became due.
+func (o *Outbox) Stats(ctx context.Context) (*core.OutboxStats, error) {
+	var (
+		queued   *redis.IntCmd
+		retrying *redis.IntCmd
+		dead     *redis.IntCmd
+		oldest   *redis.XMessageSliceCmd
+	)
+
+	_, err := o.dao.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		queued = pipe.XLen(ctx, streamKey)
+		retrying = pipe.ZCard(ctx, retryKey)
+		dead = pipe.XLen(ctx, deadKey)
+		oldest = pipe.XRangeN(ctx, streamKey, "-", "+", 1)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to get outbox stats: %w", err)
+	}
+
+	stats := &core.OutboxStats{
+		Queued:   queued.Val(),
+		Retrying: retrying.Val(),
+		Dead:     dead.Val(),
+	}
+
+	if entries := oldest.Val(); len(entries) > 0 {
+		if queuedAt, ok := entryTime(entries[0].ID); ok {
+			stats.OldestAge = max(time.Since(queuedAt), 0)
+		}
+	}
+
+	return stats, nil
+}
+
+// ensureGroup creates the consumer group, and the stream with it, unless it has been created already.
+// The group starts at the beginning of the stream, so posts queued before it existed are delivered as well.
+func (o *Outbox) ensureGroup(ctx context.Context) error {
+	if o.groupReady {
+		return nil
+	}
+
+	err := o.dao.XGroupCreateMkStream(ctx, streamKey, groupName, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("fail to create consumer group: %w", err)
+	}
+
+	o.groupReady = true
+
+	return nil
+}
+
+// read reads the entries of the consumer group following the given id: the entries pending for this consumer
+// for an actual id and new entries for ">". A negative block does not wait for new entries.
+func (o *Outbox) read(ctx context.Context, id string, count int, block time.Duration) ([]redis.XMessage, error) {
+	streams, err := o.dao.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    groupName,
+		Consumer: o.cfg.Consumer,
+		Streams:  []string{streamKey, id},
+		Count:    int64(count),
+		Block:    block,
+	}).Result()
+
+	switch {
+	case errors.Is(err, redis.Nil):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("fail to read posts: %w", err)
+	}
+
+	var entries []redis.XMessage
+
+	for _, stream := range streams {
+		entries = append(entries, stream.Messages...)
+	}
+
+	return entries, nil
+}
+
+// decode converts the stream entries to outbox messages. Entries that cannot be decoded would fail the same way
+// on every delivery, so they are moved to the dead-letter stream right away.
+func (o *Outbox) decode(ctx context.Context, entries []redis.XMessage) []core.OutboxMessage {
+	msgs := make([]core.OutboxMessage, 0, len(entries))
+
+	for _, entry := range entries {
+		var env envelope
+
+		data, _ := entry.Values[fieldData].(string)
+		if err := json.Unmarshal([]byte(data), &env); err != nil {
+			_ = o.bury(ctx, entry.ID, data, "fail to decode post: "+err.Error())
+			continue
+		}
+
+		msgs = append(msgs, core.OutboxMessage{ID: entry.ID, Post: env.Post, Attempts: env.Attempts})
+	}
+
+	return msgs
+}
+
+// entryTime returns the time encoded in the stream entry id.
+func entryTime(id string) (time.Time, bool) {
+	ms, _, _ := strings.Cut(id, "-")
+
+	n, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.UnixMilli(n), true
+}
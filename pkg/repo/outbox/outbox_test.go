@@ -0,0 +1,242 @@
+package outbox
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestOutbox returns an Outbox backed by an in-process Redis server.
+func newTestOutbox(t *testing.T, cfg Config) (*Outbox, *miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	if cfg.Consumer == "" {
+		cfg.Consumer = "test"
+	}
+
+	if cfg.Block == 0 {
+		cfg.Block = 10 * time.Millisecond
+	}
+
+	return New(cfg, rdb), srv, rdb
+}
+
+// newPost returns a post of the feed item with the given link.
+func newPost(link string) core.Post {
+	return core.Post{
+		FeedID:    "f",
+		FeedTitle: "Feed",
+		Item:      core.FeedItem{Title: "Title", Link: link},
+		Target:    core.Target{ChatID: -100, ThreadID: 3},
+		OwnerID:   9,
+	}
+}
+
+func TestNew(t *testing.T) {
+	o := New(Config{}, nil)
+
+	assert.NotEmpty(t, o.cfg.Consumer)
+	assert.Equal(t, defaultBlock, o.cfg.Block)
+	assert.Equal(t, defaultClaimAfter, o.cfg.ClaimAfter)
+	assert.Equal(t, int64(defaultDeadMaxLen), o.cfg.DeadMaxLen)
+
+	cfg := Config{Consumer: "c", Block: time.Second, ClaimAfter: time.Minute, DeadMaxLen: 5}
+	assert.Equal(t, cfg, New(cfg, nil).cfg)
+}
+
+func TestOutbox_EnqueueReceiveAck(t *testing.T) {
+	o, _, _ := newTestOutbox(t, Config{})
+
+	msgs, err := o.Receive(t.Context(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, msgs)
+
+	require.NoError(t, o.Enqueue(t.Context(), []core.Post{newPost("a"), newPost("b")}))
+
+	msgs, err = o.Receive(t.Context(), 10)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	assert.Equal(t, newPost("a"), msgs[0].Post)
+	assert.Equal(t, newPost("b"), msgs[1].Post)
+	assert.Zero(t, msgs[0].Attempts)
+
+	require.NoError(t, o.Ack(t.Context(), msgs[0].ID))
+
+	stats, err := o.Stats(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Queued, "unacknowledged posts should stay queued")
+
+	require.NoError(t, o.Ack(t.Context(), msgs[1].ID))
+
+	stats, err = o.Stats(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, &core.OutboxStats{}, stats)
+}
+
+func TestOutbox_ReceivePendingAfterRestart(t *testing.T) {
+	o, _, rdb := newTestOutbox(t, Config{})
+
+	require.NoError(t, o.Enqueue(t.Context(), []core.Post{newPost("a"), newPost("b"), newPost("c")}))
+
+	msgs, err := o.Receive(t.Context(), 10)
+	require.NoError(t, err)
+	require.Len(t, msgs, 3)
+	require.NoError(t, o.Ack(t.Context(), msgs[1].ID))
+
+	restarted := New(o.cfg, rdb)
+
+	msgs, err = restarted.Receive(t.Context(), 10)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	assert.Equal(t, "a", msgs[0].Post.Item.Link)
+	assert.Equal(t, "c", msgs[1].Post.Item.Link)
+
+	msgs, err = restarted.Receive(t.Context(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, msgs, "pending posts should be received once")
+}
+
+func TestOutbox_ClaimAbandoned(t *testing.T) {
+	o, _, rdb := newTestOutbox(t, Config{Consumer: "crashed"})
+
+	require.NoError(t, o.Enqueue(t.Context(), []core.Post{newPost("a")}))
+
+	msgs, err := o.Receive(t.Context(), 10)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+
+	other := New(Config{Consumer: "other", Block: 10 * time.Millisecond, ClaimAfter: time.Millisecond}, rdb)
+
+	time.Sleep(5 * time.Millisecond)
+
+	claimed, err := other.Receive(t.Context(), 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, msgs[0].ID, claimed[0].ID)
+}
+
+func TestOutbox_Retry(t *testing.T) {
+	o, _, _ := newTestOutbox(t, Config{})
+
+	require.NoError(t, o.Enqueue(t.Context(), []core.Post{newPost("a"), newPost("b")}))
+
+	msgs, err := o.Receive(t.Context(), 10)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+
+	require.NoError(t, o.Retry(t.Context(), &msgs[0], time.Now()))
+	require.NoError(t, o.Retry(t.Context(), &msgs[1], time.Now().Add(time.Hour)))
+
+	stats, err := o.Stats(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), stats.Queued)
+	assert.Equal(t, int64(2), stats.Retrying)
+
+	retried, err := o.Receive(t.Context(), 10)
+	require.NoError(t, err)
+	require.Len(t, retried, 1, "only due retries should be received")
+	assert.Equal(t, newPost("a"), retried[0].Post)
+	assert.Equal(t, 1, retried[0].Attempts)
+	assert.NotEqual(t, msgs[0].ID, retried[0].ID)
+
+	stats, err = o.Stats(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Queued)
+	assert.Equal(t, int64(1), stats.Retrying)
+}
+
+func TestOutbox_DeadLetter(t *testing.T) {
+	o, srv, _ := newTestOutbox(t, Config{})
+
+	require.NoError(t, o.Enqueue(t.Context(), []core.Post{newPost("a")}))
+
+	msgs, err := o.Receive(t.Context(), 10)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+
+	msgs[0].Attempts = 4
+
+	require.NoError(t, o.DeadLetter(t.Context(), &msgs[0], "boom"))
+
+	stats, err := o.Stats(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, &core.OutboxStats{Dead: 1}, stats)
+
+	entries, err := srv.Stream(deadKey)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Contains(t, entries[0].Values, "boom")
+	assert.Contains(t, entries[0].Values[1], `"attempts":5`)
+}
+
+func TestOutbox_MalformedEntry(t *testing.T) {
+	o, _, rdb := newTestOutbox(t, Config{})
+
+	require.NoError(t, rdb.XAdd(t.Context(), &redis.XAddArgs{Stream: streamKey, Values: []string{fieldData, "garbage"}}).Err())
+	require.NoError(t, o.Enqueue(t.Context(), []core.Post{newPost("a")}))
+
+	msgs, err := o.Receive(t.Context(), 10)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, newPost("a"), msgs[0].Post)
+
+	stats, err := o.Stats(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Queued)
+	assert.Equal(t, int64(1), stats.Dead, "malformed entries should be moved to the dead-letter stream")
+}
+
+func TestOutbox_StatsOldestAge(t *testing.T) {
+	o, _, rdb := newTestOutbox(t, Config{})
+
+	queuedAt := time.Now().Add(-time.Minute).UnixMilli()
+	require.NoError(t, rdb.XAdd(t.Context(), &redis.XAddArgs{
+		Stream: streamKey,
+		ID:     strconv.FormatInt(queuedAt, 10) + "-0",
+		Values: []string{fieldData, "{}"},
+	}).Err())
+	require.NoError(t, o.Enqueue(t.Context(), []core.Post{newPost("a")}))
+
+	stats, err := o.Stats(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stats.Queued)
+	assert.GreaterOrEqual(t, stats.OldestAge, time.Minute)
+	assert.Less(t, stats.OldestAge, 2*time.Minute)
+}
+
+func TestOutbox_RedisFailure(t *testing.T) {
+	o, srv, _ := newTestOutbox(t, Config{})
+	srv.Close()
+
+	assert.Error(t, o.Enqueue(t.Context(), []core.Post{newPost("a")}))
+
+	_, err := o.Receive(t.Context(), 10)
+	assert.Error(t, err)
+
+	assert.Error(t, o.Ack(t.Context(), "1-0"))
+	assert.Error(t, o.Retry(t.Context(), &core.OutboxMessage{ID: "1-0"}, time.Now()))
+	assert.Error(t, o.DeadLetter(t.Context(), &core.OutboxMessage{ID: "1-0"}, "boom"))
+
+	_, err = o.Stats(t.Context())
+	assert.Error(t, err)
+}
+
+func TestEntryTime(t *testing.T) {
+	at, ok := entryTime("1700000000000-3")
+	assert.True(t, ok)
+	assert.Equal(t, time.UnixMilli(1700000000000), at)
+
+	_, ok = entryTime("garbage")
+	assert.False(t, ok)
+}
@@ -0,0 +1,236 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	feedsKey = "feeds"
+
+	fieldURL          = "url"
+	fieldTitle        = "title"
+	fieldLastItemAt   = "last_item_at"
+	fieldLastPolledAt = "last_polled_at"
+	fieldLastError    = "last_error"
+	fieldETag         = "etag"
+	fieldLastModified = "last_modified"
+	fieldContentHash  = "content_hash"
+	fieldFullText     = "full_text"
+	fieldInterval     = "interval"
+)
+
+// updateIfExistsScript sets the hash fields only if the hash exists, so that late writes
+// do not resurrect feeds or subscriptions that were deleted in the meantime.
+// KEYS[1] is the hash, ARGV holds field/value pairs. It returns 1 if the hash was updated.
+var updateIfExistsScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[1], unpack(ARGV))
+return 1
+`)
+
+// ListFeeds returns every feed that has at least one subscriber, ordered by title.
+func (u *UserRepo) ListFeeds(ctx context.Context) ([]core.FeedInfo, error) {
+	ids, err := u.dao.SMembers(ctx, feedsKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("fail to list feeds: %w", err)
+	}
+
+	return u.getFeeds(ctx, ids)
+}
+
+// GetFeed returns the feed with the given id or nil if it does not exist.
+func (u *UserRepo) GetFeed(ctx context.Context, feedID string) (*core.FeedInfo, error) {
+	values, err := u.dao.HGetAll(ctx, feedKey(feedID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("fail to get feed: %w", err)
+	}
+
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	feed := feedFromHash(feedID, values)
+
+	return &feed, nil
+}
+
+// UpdateFeedStatus records the outcome of a feed poll. Feeds that were deleted in the meantime are left untouched.
+func (u *UserRepo) UpdateFeedStatus(ctx context.Context, feedID string, status *core.FeedStatus) error {
+	values := []any{
+		fieldLastPolledAt, formatTime(status.PolledAt),
+		fieldLastError, status.Error,
+	}
+
+	if status.Title != "" {
+		values = append(values, fieldTitle, status.Title)
+	}
+
+	if !status.LastItemAt.IsZero() {
+		values = append(values, fieldLastItemAt, formatTime(status.LastItemAt))
+	}
+
+	if err := updateIfExistsScript.Run(ctx, u.dao, []string{feedKey(feedID)}, values...).Err(); err != nil {
+		return fmt.Errorf("fail to update feed status: %w", err)
+	}
+
+	return nil
+}
+
+// SetFeedFullText enables or disables fetching the full text of the feed items from their web pages.
+// It returns false if the feed does not exist.
+func (u *UserRepo) SetFeedFullText(ctx context.Context, feedID string, enabled bool) (bool, error) {
+	updated, err := updateIfExistsScript.Run(ctx, u.dao, []string{feedKey(feedID)}, fieldFullText, formatBool(enabled)).Int64()
+	if err != nil {
+		return false, fmt.Errorf("fail to set feed full text: %w", err)
+	}
+
+	return updated == 1, nil
+}
+
+// SetFeedInterval sets the polling interval of the feed, zero meaning the scheduler default.
+// It returns false if the feed does not exist.
+func (u *UserRepo) SetFeedInterval(ctx context.Context, feedID string, interval time.Duration) (bool, error) {
+	updated, err := updateIfExistsScript.Run(ctx, u.dao, []string{feedKey(feedID)}, fieldInterval, formatDuration(interval)).Int64()
+	if err != nil {
+		return false, fmt.Errorf("fail to set feed interval: %w", err)
+	}
+
+	return updated == 1, nil
+}
+
+// GetFetchState loads the cache validators stored for the feed.
+// It returns an empty state if nothing has been stored yet.
+func (u *UserRepo) GetFetchState(ctx context.Context, feedID string) (core.FetchState, error) {
+	values, err := u.dao.HGetAll(ctx, feedKey(feedID)).Result()
+	if err != nil {
+		return core.FetchState{}, fmt.Errorf("fail to get fetch state: %w", err)
+	}
+
+	return core.FetchState{
+		ETag:         values[fieldETag],
+		LastModified: values[fieldLastModified],
+		ContentHash:  values[fieldContentHash],
+	}, nil
+}
+
+// SaveFetchState stores the cache validators of the feed alongside its metadata.
+// The state of feeds that were deleted in the meantime is discarded.
+func (u *UserRepo) SaveFetchState(ctx context.Context, feedID string, state core.FetchState) error {
+	err := updateIfExistsScript.Run(ctx, u.dao, []string{feedKey(feedID)},
+		fieldETag, state.ETag,
+		fieldLastModified, state.LastModified,
+		fieldContentHash, state.ContentHash,
+	).Err()
+	if err != nil {
+		return fmt.Errorf("fail to save fetch state: %w", err)
+	}
+
+	return nil
+}
+
+// getFeeds loads the feeds with the given ids in a single round trip, skipping the ones that no longer exist.
+func (u *UserRepo) getFeeds(ctx context.Context, ids []string) ([]core.FeedInfo, error) {
+	if len(ids) == 0 {
+		return []core.FeedInfo{}, nil
+	}
+
+	cmds := make([]*redis.MapStringStringCmd, len(ids))
+
+	_, err := u.dao.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, id := range ids {
+			cmds[i] = pipe.HGetAll(ctx, feedKey(id))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to get feeds: %w", err)
+	}
+
+	feeds := make([]core.FeedInfo, 0, len(ids))
+
+	for i, id := range ids {
+		values := cmds[i].Val()
+		if len(values) == 0 {
+			continue
+		}
+
+		feeds = append(feeds, feedFromHash(id, values))
+	}
+
+	slices.SortFunc(feeds, func(a, b core.FeedInfo) int {
+		return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	})
+
+	return feeds, nil
+}
+
+// feedFromHash converts the fields of a feed hash into core.FeedInfo.
+func feedFromHash(feedID string, values map[string]string) core.FeedInfo {
+	return core.FeedInfo{
+		ID:           feedID,
+		URL:          values[fieldURL],
+		Title:        values[fieldTitle],
+		LastItemAt:   parseTime(values[fieldLastItemAt]),
+		LastPolledAt: parseTime(values[fieldLastPolledAt]),
+		LastError:    values[fieldLastError],
+		FullText:     values[fieldFullText] == "1",
+		Interval:     parseDuration(values[fieldInterval]),
+	}
+}
+
+func feedKey(feedID string) string {
+	return "feed:" + feedID
+}
+
+func feedChatsKey(feedID string) string {
+	return "feed:" + feedID + ":chats"
+}
+
+// formatTime encodes a time as unix seconds, keeping the zero time as an empty string.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return strconv.FormatInt(t.Unix(), 10)
+}
+
+// parseTime decodes a time stored by formatTime.
+func parseTime(value string) time.Time {
+	sec, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || sec == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(sec, 0).UTC()
+}
+
+// formatDuration encodes a duration as whole seconds, keeping zero as an empty string.
+func formatDuration(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+
+	return strconv.FormatInt(int64(d/time.Second), 10)
+}
+
+// parseDuration decodes a duration stored by formatDuration.
+func parseDuration(value string) time.Duration {
+	sec, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || sec <= 0 {
+		return 0
+	}
+
+	return time.Duration(sec) * time.Second
+}
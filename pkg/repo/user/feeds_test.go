@@ -0,0 +1,181 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRepo_ListFeeds(t *testing.T) {
+	u, srv := newTestRepo(t)
+
+	feeds, err := u.ListFeeds(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, feeds)
+
+	_, err = u.AddSubscription(t.Context(), 1, &core.FeedInfo{ID: "a", URL: "https://a.example.com", Title: "A"})
+	require.NoError(t, err)
+
+	_, err = u.AddSubscription(t.Context(), 2, &core.FeedInfo{ID: "b", URL: "https://b.example.com", Title: "B"})
+	require.NoError(t, err)
+
+	_, err = srv.SetAdd("feeds", "gone")
+	require.NoError(t, err)
+
+	feeds, err = u.ListFeeds(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, []core.FeedInfo{
+		{ID: "a", URL: "https://a.example.com", Title: "A"},
+		{ID: "b", URL: "https://b.example.com", Title: "B"},
+	}, feeds, "feeds without metadata should be skipped")
+}
+
+func TestUserRepo_GetFeed(t *testing.T) {
+	u, _ := newTestRepo(t)
+
+	feed, err := u.GetFeed(t.Context(), "f1")
+	require.NoError(t, err)
+	assert.Nil(t, feed)
+
+	_, err = u.AddSubscription(t.Context(), 1, &core.FeedInfo{ID: "f1", URL: "https://example.com/rss", Title: "Example"})
+	require.NoError(t, err)
+
+	feed, err = u.GetFeed(t.Context(), "f1")
+	require.NoError(t, err)
+	assert.Equal(t, &core.FeedInfo{ID: "f1", URL: "https://example.com/rss", Title: "Example"}, feed)
+}
+
+func TestUserRepo_UpdateFeedStatus(t *testing.T) {
+	u, srv := newTestRepo(t)
+
+	require.NoError(t, u.UpdateFeedStatus(t.Context(), "f1", &core.FeedStatus{PolledAt: time.Unix(200, 0)}))
+	assert.False(t, srv.Exists("feed:f1"), "status of a deleted feed should not recreate it")
+
+	_, err := u.AddSubscription(t.Context(), 1, &core.FeedInfo{ID: "f1", URL: "https://example.com/rss", Title: "Example"})
+	require.NoError(t, err)
+
+	require.NoError(t, u.UpdateFeedStatus(t.Context(), "f1", &core.FeedStatus{
+		PolledAt:   time.Unix(200, 0),
+		Title:      "New title",
+		LastItemAt: time.Unix(150, 0),
+	}))
+
+	require.NoError(t, u.UpdateFeedStatus(t.Context(), "f1", &core.FeedStatus{PolledAt: time.Unix(300, 0), Error: "timeout"}))
+
+	feed, err := u.GetFeed(t.Context(), "f1")
+	require.NoError(t, err)
+
+	assert.Equal(t, &core.FeedInfo{
+		ID:           "f1",
+		URL:          "https://example.com/rss",
+		Title:        "New title",
+		LastItemAt:   time.Unix(150, 0).UTC(),
+		LastPolledAt: time.Unix(300, 0).UTC(),
+		LastError:    "timeout",
+	}, feed)
+}
+
+func TestUserRepo_SetFeedFullText(t *testing.T) {
+	u, srv := newTestRepo(t)
+
+	updated, err := u.SetFeedFullText(t.Context(), "f1", true)
+	require.NoError(t, err)
+	assert.False(t, updated)
+	assert.False(t, srv.Exists("feed:f1"), "setting of a deleted feed should not recreate it")
+
+	_, err = u.AddSubscription(t.Context(), 1, &core.FeedInfo{ID: "f1", URL: "https://example.com/rss", Title: "Example"})
+	require.NoError(t, err)
+
+	updated, err = u.SetFeedFullText(t.Context(), "f1", true)
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	feed, err := u.GetFeed(t.Context(), "f1")
+	require.NoError(t, err)
+	assert.True(t, feed.FullText)
+
+	updated, err = u.SetFeedFullText(t.Context(), "f1", false)
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	feed, err = u.GetFeed(t.Context(), "f1")
+	require.NoError(t, err)
+	assert.False(t, feed.FullText)
+}
+
+func TestUserRepo_SetFeedInterval(t *testing.T) {
+	u, srv := newTestRepo(t)
+
+	updated, err := u.SetFeedInterval(t.Context(), "f1", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, updated)
+	assert.False(t, srv.Exists("feed:f1"), "setting of a deleted feed should not recreate it")
+
+	_, err = u.AddSubscription(t.Context(), 1, &core.FeedInfo{ID: "f1", URL: "https://example.com/rss", Title: "Example"})
+	require.NoError(t, err)
+
+	updated, err = u.SetFeedInterval(t.Context(), "f1", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, updated)
+	assert.Equal(t, "3600", srv.HGet("feed:f1", "interval"))
+
+	feed, err := u.GetFeed(t.Context(), "f1")
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, feed.Interval)
+
+	updated, err = u.SetFeedInterval(t.Context(), "f1", 0)
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	feed, err = u.GetFeed(t.Context(), "f1")
+	require.NoError(t, err)
+	assert.Zero(t, feed.Interval)
+}
+
+func TestUserRepo_FetchState(t *testing.T) {
+	u, _ := newTestRepo(t)
+	state := core.FetchState{ETag: `"v1"`, LastModified: "Mon, 01 Jan 2024 00:00:00 GMT", ContentHash: "abc"}
+
+	require.NoError(t, u.SaveFetchState(t.Context(), "f1", state))
+
+	got, err := u.GetFetchState(t.Context(), "f1")
+	require.NoError(t, err)
+	assert.Equal(t, core.FetchState{}, got, "state of a missing feed should be discarded")
+
+	_, err = u.AddSubscription(t.Context(), 1, &core.FeedInfo{ID: "f1", URL: "https://example.com/rss"})
+	require.NoError(t, err)
+
+	require.NoError(t, u.SaveFetchState(t.Context(), "f1", state))
+
+	got, err = u.GetFetchState(t.Context(), "f1")
+	require.NoError(t, err)
+	assert.Equal(t, state, got)
+}
+
+func TestUserRepo_FeedsRedisFailure(t *testing.T) {
+	u, srv := newTestRepo(t)
+	srv.Close()
+
+	_, err := u.ListFeeds(t.Context())
+	assert.Error(t, err)
+
+	_, err = u.GetFeed(t.Context(), "f1")
+	assert.Error(t, err)
+
+	assert.Error(t, u.UpdateFeedStatus(t.Context(), "f1", &core.FeedStatus{}))
+
+	_, err = u.SetFeedFullText(t.Context(), "f1", true)
+	assert.Error(t, err)
+
+	_, err = u.SetFeedInterval(t.Context(), "f1", time.Hour)
+	assert.Error(t, err)
+
+	_, err = u.GetFetchState(t.Context(), "f1")
+	assert.Error(t, err)
+
+	assert.Error(t, u.SaveFetchState(t.Context(), "f1", core.FetchState{}))
+}
@@ -0,0 +1,192 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	fieldCreatedAt = "created_at"
+	fieldPaused    = "paused"
+	fieldSummarize = "summarize"
+	fieldPreview   = "preview"
+	fieldFilter    = "filter"
+	fieldOwner     = "owner"
+	fieldThread    = "thread"
+)
+
+// subscribeScript atomically links a chat and a feed, creating the feed on first subscription.
+// The title and last item time of an existing feed are kept since they are maintained by the poller.
+// KEYS: chat feeds, feed, feed chats, feeds, subscription.
+// ARGV: feed id, chat id, url, title, last item at, created at.
+// It returns 0 if the chat is already subscribed to the feed.
+var subscribeScript = redis.NewScript(`
+if redis.call('SADD', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[2], 'url', ARGV[3])
+redis.call('HSETNX', KEYS[2], 'title', ARGV[4])
+redis.call('HSETNX', KEYS[2], 'last_item_at', ARGV[5])
+redis.call('SADD', KEYS[3], ARGV[2])
+redis.call('SADD', KEYS[4], ARGV[1])
+redis.call('HSET', KEYS[5], 'created_at', ARGV[6], 'paused', '0', 'summarize', '0', 'preview', '1', 'filter', '')
+return 1
+`)
+
+// unsubscribeScript atomically unlinks a chat and a feed, deleting the feed once nobody is subscribed to it.
+// KEYS: chat feeds, feed, feed chats, feeds, subscription.
+// ARGV: feed id, chat id.
+// It returns whether the subscription existed and the number of subscribers left.
+var unsubscribeScript = redis.NewScript(`
+if redis.call('SREM', KEYS[1], ARGV[1]) == 0 then
+	return {0, 0}
+end
+redis.call('SREM', KEYS[3], ARGV[2])
+redis.call('DEL', KEYS[5])
+local remaining = redis.call('SCARD', KEYS[3])
+if remaining == 0 then
+	redis.call('SREM', KEYS[4], ARGV[1])
+	redis.call('DEL', KEYS[2], KEYS[3])
+end
+return {1, remaining}
+`)
+
+// AddSubscription subscribes the chat to the feed and stores the feed metadata.
+// It returns false if the chat is already subscribed to the feed.
+func (u *UserRepo) AddSubscription(ctx context.Context, chatID int64, feed *core.FeedInfo) (bool, error) {
+	added, err := subscribeScript.Run(ctx, u.dao, subscriptionKeys(chatID, feed.ID),
+		feed.ID, chatID, feed.URL, feed.Title, formatTime(feed.LastItemAt), formatTime(time.Now()),
+	).Int64()
+	if err != nil {
+		return false, fmt.Errorf("fail to add subscription: %w", err)
+	}
+
+	return added == 1, nil
+}
+
+// RemoveSubscription unsubscribes the chat from the feed and deletes the feed once nobody is subscribed to it.
+// It returns whether the subscription existed and how many subscribers the feed has left.
+func (u *UserRepo) RemoveSubscription(ctx context.Context, chatID int64, feedID string) (removed bool, remaining int64, err error) {
+	res, err := unsubscribeScript.Run(ctx, u.dao, subscriptionKeys(chatID, feedID), feedID, chatID).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("fail to remove subscription: %w", err)
+	}
+
+	if len(res) != 2 {
+		return false, 0, fmt.Errorf("fail to remove subscription: unexpected script result %v", res)
+	}
+
+	return res[0] == 1, res[1], nil
+}
+
+// ListSubscriptions returns the feeds the chat is subscribed to, ordered by title.
+func (u *UserRepo) ListSubscriptions(ctx context.Context, chatID int64) ([]core.FeedInfo, error) {
+	ids, err := u.dao.SMembers(ctx, chatFeedsKey(chatID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("fail to list subscriptions: %w", err)
+	}
+
+	return u.getFeeds(ctx, ids)
+}
+
+// ListSubscribers returns the ids of the chats subscribed to the feed.
+func (u *UserRepo) ListSubscribers(ctx context.Context, feedID string) ([]int64, error) {
+	members, err := u.dao.SMembers(ctx, feedChatsKey(feedID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("fail to list subscribers: %w", err)
+	}
+
+	chatIDs := make([]int64, 0, len(members))
+
+	for _, member := range members {
+		chatID, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("fail to parse subscriber id %q: %w", member, err)
+		}
+
+		chatIDs = append(chatIDs, chatID)
+	}
+
+	return chatIDs, nil
+}
+
+// GetSubscriptionSettings returns the settings of the chat subscription to the feed or nil if the chat is not subscribed.
+// Link previews are enabled for subscriptions created before the setting existed.
+func (u *UserRepo) GetSubscriptionSettings(ctx context.Context, chatID int64, feedID string) (*core.SubscriptionSettings, error) {
+	values, err := u.dao.HGetAll(ctx, subscriptionKey(chatID, feedID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("fail to get subscription settings: %w", err)
+	}
+
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	return &core.SubscriptionSettings{
+		CreatedAt: parseTime(values[fieldCreatedAt]),
+		Paused:    values[fieldPaused] == "1",
+		Summarize: values[fieldSummarize] == "1",
+		Preview:   values[fieldPreview] != "0",
+		Filter:    values[fieldFilter],
+		OwnerID:   parseInt(values[fieldOwner]),
+		ThreadID:  int(parseInt(values[fieldThread])),
+	}, nil
+}
+
+// UpdateSubscriptionSettings stores the settings of the chat subscription to the feed.
+// It returns false if the chat is not subscribed to the feed. The creation time is never changed.
+func (u *UserRepo) UpdateSubscriptionSettings(ctx context.Context, chatID int64, feedID string, settings *core.SubscriptionSettings) (bool, error) {
+	updated, err := updateIfExistsScript.Run(ctx, u.dao, []string{subscriptionKey(chatID, feedID)},
+		fieldPaused, formatBool(settings.Paused),
+		fieldSummarize, formatBool(settings.Summarize),
+		fieldPreview, formatBool(settings.Preview),
+		fieldFilter, settings.Filter,
+		fieldOwner, settings.OwnerID,
+		fieldThread, settings.ThreadID,
+	).Int64()
+	if err != nil {
+		return false, fmt.Errorf("fail to update subscription settings: %w", err)
+	}
+
+	return updated == 1, nil
+}
+
+// subscriptionKeys returns the keys touched by subscribeScript and unsubscribeScript in the expected order.
+func subscriptionKeys(chatID int64, feedID string) []string {
+	return []string{
+		chatFeedsKey(chatID),
+		feedKey(feedID),
+		feedChatsKey(feedID),
+		feedsKey,
+		subscriptionKey(chatID, feedID),
+	}
+}
+
+func chatFeedsKey(chatID int64) string {
+	return "chat:" + strconv.FormatInt(chatID, 10) + ":feeds"
+}
+
+func subscriptionKey(chatID int64, feedID string) string {
+	return "chat:" + strconv.FormatInt(chatID, 10) + ":feed:" + feedID
+}
+
+// parseInt decodes an integer, treating missing and malformed values as zero.
+func parseInt(v string) int64 {
+	n, _ := strconv.ParseInt(v, 10, 64)
+
+	return n
+}
+
+// formatBool encodes a flag as "1" or "0".
+func formatBool(v bool) string {
+	if v {
+		return "1"
+	}
+
+	return "0"
+}
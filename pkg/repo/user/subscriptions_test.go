@@ -0,0 +1,200 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRepo_AddSubscription(t *testing.T) {
+	u, srv := newTestRepo(t)
+
+	feed := &core.FeedInfo{
+		ID:         "f1",
+		URL:        "https://example.com/rss",
+		Title:      "Example",
+		LastItemAt: time.Unix(100, 0),
+	}
+
+	added, err := u.AddSubscription(t.Context(), 1, feed)
+	require.NoError(t, err)
+	assert.True(t, added)
+
+	added, err = u.AddSubscription(t.Context(), 1, feed)
+	require.NoError(t, err)
+	assert.False(t, added, "second subscription of the same chat should be ignored")
+
+	require.NoError(t, u.UpdateFeedStatus(t.Context(), "f1", &core.FeedStatus{PolledAt: time.Unix(200, 0), Title: "Polled title"}))
+
+	added, err = u.AddSubscription(t.Context(), 2, &core.FeedInfo{ID: "f1", URL: feed.URL, Title: "Other title"})
+	require.NoError(t, err)
+	assert.True(t, added)
+
+	assert.Equal(t, "Polled title", srv.HGet("feed:f1", "title"), "title maintained by the poller should be kept")
+	assert.Equal(t, "100", srv.HGet("feed:f1", "last_item_at"))
+
+	members, err := srv.Members("feed:f1:chats")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"1", "2"}, members)
+
+	members, err = srv.Members("feeds")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"f1"}, members)
+
+	settings, err := u.GetSubscriptionSettings(t.Context(), 2, "f1")
+	require.NoError(t, err)
+	require.NotNil(t, settings)
+	assert.False(t, settings.CreatedAt.IsZero())
+	assert.False(t, settings.Paused)
+	assert.True(t, settings.Preview)
+	assert.Empty(t, settings.Filter)
+}
+
+func TestUserRepo_RemoveSubscription(t *testing.T) {
+	u, srv := newTestRepo(t)
+	feed := &core.FeedInfo{ID: "f1", URL: "https://example.com/rss", Title: "Example"}
+
+	for _, chatID := range []int64{1, 2} {
+		_, err := u.AddSubscription(t.Context(), chatID, feed)
+		require.NoError(t, err)
+	}
+
+	removed, remaining, err := u.RemoveSubscription(t.Context(), 3, "f1")
+	require.NoError(t, err)
+	assert.False(t, removed)
+	assert.Zero(t, remaining)
+
+	removed, remaining, err = u.RemoveSubscription(t.Context(), 1, "f1")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	assert.Equal(t, int64(1), remaining)
+	assert.True(t, srv.Exists("feed:f1"))
+	assert.False(t, srv.Exists("chat:1:feed:f1"))
+
+	removed, remaining, err = u.RemoveSubscription(t.Context(), 2, "f1")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	assert.Zero(t, remaining)
+
+	assert.Empty(t, srv.Keys(), "last unsubscribe should delete the feed and every index")
+}
+
+func TestUserRepo_ListSubscriptions(t *testing.T) {
+	u, _ := newTestRepo(t)
+
+	feeds, err := u.ListSubscriptions(t.Context(), 1)
+	require.NoError(t, err)
+	assert.Empty(t, feeds)
+
+	for _, feed := range []*core.FeedInfo{
+		{ID: "b", URL: "https://b.example.com", Title: "Beta"},
+		{ID: "a", URL: "https://a.example.com", Title: "alpha", LastItemAt: time.Unix(100, 0)},
+	} {
+		_, err := u.AddSubscription(t.Context(), 1, feed)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, u.UpdateFeedStatus(t.Context(), "b", &core.FeedStatus{PolledAt: time.Unix(200, 0), Error: "timeout"}))
+
+	feeds, err = u.ListSubscriptions(t.Context(), 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, []core.FeedInfo{
+		{ID: "a", URL: "https://a.example.com", Title: "alpha", LastItemAt: time.Unix(100, 0).UTC()},
+		{ID: "b", URL: "https://b.example.com", Title: "Beta", LastPolledAt: time.Unix(200, 0).UTC(), LastError: "timeout"},
+	}, feeds)
+}
+
+func TestUserRepo_ListSubscribers(t *testing.T) {
+	u, srv := newTestRepo(t)
+
+	for _, chatID := range []int64{-100123, 42} {
+		_, err := u.AddSubscription(t.Context(), chatID, &core.FeedInfo{ID: "f1", URL: "https://example.com/rss"})
+		require.NoError(t, err)
+	}
+
+	chatIDs, err := u.ListSubscribers(t.Context(), "f1")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int64{-100123, 42}, chatIDs)
+
+	_, err = srv.SetAdd("feed:f1:chats", "garbage")
+	require.NoError(t, err)
+
+	_, err = u.ListSubscribers(t.Context(), "f1")
+	assert.Error(t, err)
+}
+
+func TestUserRepo_SubscriptionSettings(t *testing.T) {
+	u, _ := newTestRepo(t)
+
+	settings, err := u.GetSubscriptionSettings(t.Context(), 1, "f1")
+	require.NoError(t, err)
+	assert.Nil(t, settings)
+
+	updated, err := u.UpdateSubscriptionSettings(t.Context(), 1, "f1", &core.SubscriptionSettings{Paused: true})
+	require.NoError(t, err)
+	assert.False(t, updated, "settings of a missing subscription should not be created")
+
+	_, err = u.AddSubscription(t.Context(), 1, &core.FeedInfo{ID: "f1", URL: "https://example.com/rss"})
+	require.NoError(t, err)
+
+	updated, err = u.UpdateSubscriptionSettings(t.Context(), 1, "f1", &core.SubscriptionSettings{
+		Paused:    true,
+		Summarize: true,
+		Filter:    "go rust",
+		OwnerID:   42,
+		ThreadID:  7,
+	})
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	settings, err = u.GetSubscriptionSettings(t.Context(), 1, "f1")
+	require.NoError(t, err)
+	require.NotNil(t, settings)
+	assert.True(t, settings.Paused)
+	assert.True(t, settings.Summarize)
+	assert.False(t, settings.Preview)
+	assert.Equal(t, "go rust", settings.Filter)
+	assert.Equal(t, int64(42), settings.OwnerID)
+	assert.Equal(t, 7, settings.ThreadID)
+	assert.False(t, settings.CreatedAt.IsZero())
+}
+
+func TestUserRepo_SubscriptionSettingsPreviewDefault(t *testing.T) {
+	u, srv := newTestRepo(t)
+
+	srv.HSet("chat:1:feed:f1", "created_at", "100", "paused", "0")
+
+	settings, err := u.GetSubscriptionSettings(t.Context(), 1, "f1")
+	require.NoError(t, err)
+	require.NotNil(t, settings)
+	assert.True(t, settings.Preview, "subscriptions created before the preview setting should keep previews")
+	assert.Zero(t, settings.OwnerID)
+	assert.Zero(t, settings.ThreadID)
+}
+
+func TestUserRepo_SubscriptionsRedisFailure(t *testing.T) {
+	u, srv := newTestRepo(t)
+	srv.Close()
+
+	_, err := u.AddSubscription(t.Context(), 1, &core.FeedInfo{ID: "f1"})
+	assert.Error(t, err)
+
+	_, _, err = u.RemoveSubscription(t.Context(), 1, "f1")
+	assert.Error(t, err)
+
+	_, err = u.ListSubscriptions(t.Context(), 1)
+	assert.Error(t, err)
+
+	_, err = u.ListSubscribers(t.Context(), "f1")
+	assert.Error(t, err)
+
+	_, err = u.GetSubscriptionSettings(t.Context(), 1, "f1")
+	assert.Error(t, err)
+
+	_, err = u.UpdateSubscriptionSettings(t.Context(), 1, "f1", &core.SubscriptionSettings{})
+	assert.Error(t, err)
+}
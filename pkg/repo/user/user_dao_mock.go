@@ -24,6 +24,299 @@ func (_m *MockuserDAO) EXPECT() *MockuserDAO_Expecter {
 	return &MockuserDAO_Expecter{mock: &_m.Mock}
 }
 
+// Eval provides a mock function with given fields: ctx, script, keys, args
+func (_m *MockuserDAO) Eval(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd {
+	var _ca []interface{}
+	_ca = append(_ca, ctx, script, keys)
+	_ca = append(_ca, args...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Eval")
+	}
+
+	var r0 *redis.Cmd
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, ...interface{}) *redis.Cmd); ok {
+		r0 = rf(ctx, script, keys, args...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*redis.Cmd)
+		}
+	}
+
+	return r0
+}
+
+// MockuserDAO_Eval_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Eval'
+type MockuserDAO_Eval_Call struct {
+	*mock.Call
+}
+
+// Eval is a helper method to define mock.On call
+//   - ctx context.Context
+//   - script string
+//   - keys []string
+//   - args ...interface{}
+func (_e *MockuserDAO_Expecter) Eval(ctx interface{}, script interface{}, keys interface{}, args ...interface{}) *MockuserDAO_Eval_Call {
+	return &MockuserDAO_Eval_Call{Call: _e.mock.On("Eval",
+		append([]interface{}{ctx, script, keys}, args...)...)}
+}
+
+func (_c *MockuserDAO_Eval_Call) Run(run func(ctx context.Context, script string, keys []string, args ...interface{})) *MockuserDAO_Eval_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]interface{}, len(args)-3)
+		for i, a := range args[3:] {
+			if a != nil {
+				variadicArgs[i] = a.(interface{})
+			}
+		}
+		run(args[0].(context.Context), args[1].(string), args[2].([]string), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *MockuserDAO_Eval_Call) Return(_a0 *redis.Cmd) *MockuserDAO_Eval_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockuserDAO_Eval_Call) RunAndReturn(run func(context.Context, string, []string, ...interface{}) *redis.Cmd) *MockuserDAO_Eval_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EvalRO provides a mock function with given fields: ctx, script, keys, args
+func (_m *MockuserDAO) EvalRO(ctx context.Context, script string, keys []string, args ...interface{}) *redis.Cmd {
+	var _ca []interface{}
+	_ca = append(_ca, ctx, script, keys)
+	_ca = append(_ca, args...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EvalRO")
+	}
+
+	var r0 *redis.Cmd
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, ...interface{}) *redis.Cmd); ok {
+		r0 = rf(ctx, script, keys, args...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*redis.Cmd)
+		}
+	}
+
+	return r0
+}
+
+// MockuserDAO_EvalRO_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EvalRO'
+type MockuserDAO_EvalRO_Call struct {
+	*mock.Call
+}
+
+// EvalRO is a helper method to define mock.On call
+//   - ctx context.Context
+//   - script string
+//   - keys []string
+//   - args ...interface{}
+func (_e *MockuserDAO_Expecter) EvalRO(ctx interface{}, script interface{}, keys interface{}, args ...interface{}) *MockuserDAO_EvalRO_Call {
+	return &MockuserDAO_EvalRO_Call{Call: _e.mock.On("EvalRO",
+		append([]interface{}{ctx, script, keys}, args...)...)}
+}
+
+func (_c *MockuserDAO_EvalRO_Call) Run(run func(ctx context.Context, script string, keys []string, args ...interface{})) *MockuserDAO_EvalRO_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]interface{}, len(args)-3)
+		for i, a := range args[3:] {
+			if a != nil {
+				variadicArgs[i] = a.(interface{})
+			}
+		}
+		run(args[0].(context.Context), args[1].(string), args[2].([]string), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *MockuserDAO_EvalRO_Call) Return(_a0 *redis.Cmd) *MockuserDAO_EvalRO_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockuserDAO_EvalRO_Call) RunAndReturn(run func(context.Context, string, []string, ...interface{}) *redis.Cmd) *MockuserDAO_EvalRO_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EvalSha provides a mock function with given fields: ctx, sha1, keys, args
+func (_m *MockuserDAO) EvalSha(ctx context.Context, sha1 string, keys []string, args ...interface{}) *redis.Cmd {
+	var _ca []interface{}
+	_ca = append(_ca, ctx, sha1, keys)
+	_ca = append(_ca, args...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EvalSha")
+	}
+
+	var r0 *redis.Cmd
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, ...interface{}) *redis.Cmd); ok {
+		r0 = rf(ctx, sha1, keys, args...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*redis.Cmd)
+		}
+	}
+
+	return r0
+}
+
+// MockuserDAO_EvalSha_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EvalSha'
+type MockuserDAO_EvalSha_Call struct {
+	*mock.Call
+}
+
+// EvalSha is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sha1 string
+//   - keys []string
+//   - args ...interface{}
+func (_e *MockuserDAO_Expecter) EvalSha(ctx interface{}, sha1 interface{}, keys interface{}, args ...interface{}) *MockuserDAO_EvalSha_Call {
+	return &MockuserDAO_EvalSha_Call{Call: _e.mock.On("EvalSha",
+		append([]interface{}{ctx, sha1, keys}, args...)...)}
+}
+
+func (_c *MockuserDAO_EvalSha_Call) Run(run func(ctx context.Context, sha1 string, keys []string, args ...interface{})) *MockuserDAO_EvalSha_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]interface{}, len(args)-3)
+		for i, a := range args[3:] {
+			if a != nil {
+				variadicArgs[i] = a.(interface{})
+			}
+		}
+		run(args[0].(context.Context), args[1].(string), args[2].([]string), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *MockuserDAO_EvalSha_Call) Return(_a0 *redis.Cmd) *MockuserDAO_EvalSha_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockuserDAO_EvalSha_Call) RunAndReturn(run func(context.Context, string, []string, ...interface{}) *redis.Cmd) *MockuserDAO_EvalSha_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EvalShaRO provides a mock function with given fields: ctx, sha1, keys, args
+func (_m *MockuserDAO) EvalShaRO(ctx context.Context, sha1 string, keys []string, args ...interface{}) *redis.Cmd {
+	var _ca []interface{}
+	_ca = append(_ca, ctx, sha1, keys)
+	_ca = append(_ca, args...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EvalShaRO")
+	}
+
+	var r0 *redis.Cmd
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, ...interface{}) *redis.Cmd); ok {
+		r0 = rf(ctx, sha1, keys, args...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*redis.Cmd)
+		}
+	}
+
+	return r0
+}
+
+// MockuserDAO_EvalShaRO_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EvalShaRO'
+type MockuserDAO_EvalShaRO_Call struct {
+	*mock.Call
+}
+
+// EvalShaRO is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sha1 string
+//   - keys []string
+//   - args ...interface{}
+func (_e *MockuserDAO_Expecter) EvalShaRO(ctx interface{}, sha1 interface{}, keys interface{}, args ...interface{}) *MockuserDAO_EvalShaRO_Call {
+	return &MockuserDAO_EvalShaRO_Call{Call: _e.mock.On("EvalShaRO",
+		append([]interface{}{ctx, sha1, keys}, args...)...)}
+}
+
+func (_c *MockuserDAO_EvalShaRO_Call) Run(run func(ctx context.Context, sha1 string, keys []string, args ...interface{})) *MockuserDAO_EvalShaRO_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]interface{}, len(args)-3)
+		for i, a := range args[3:] {
+			if a != nil {
+				variadicArgs[i] = a.(interface{})
+			}
+		}
+		run(args[0].(context.Context), args[1].(string), args[2].([]string), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *MockuserDAO_EvalShaRO_Call) Return(_a0 *redis.Cmd) *MockuserDAO_EvalShaRO_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockuserDAO_EvalShaRO_Call) RunAndReturn(run func(context.Context, string, []string, ...interface{}) *redis.Cmd) *MockuserDAO_EvalShaRO_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// HGetAll provides a mock function with given fields: ctx, key
+func (_m *MockuserDAO) HGetAll(ctx context.Context, key string) *redis.MapStringStringCmd {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HGetAll")
+	}
+
+	var r0 *redis.MapStringStringCmd
+	if rf, ok := ret.Get(0).(func(context.Context, string) *redis.MapStringStringCmd); ok {
+		r0 = rf(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*redis.MapStringStringCmd)
+		}
+	}
+
+	return r0
+}
+
+// MockuserDAO_HGetAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HGetAll'
+type MockuserDAO_HGetAll_Call struct {
+	*mock.Call
+}
+
+// HGetAll is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+func (_e *MockuserDAO_Expecter) HGetAll(ctx interface{}, key interface{}) *MockuserDAO_HGetAll_Call {
+	return &MockuserDAO_HGetAll_Call{Call: _e.mock.On("HGetAll", ctx, key)}
+}
+
+func (_c *MockuserDAO_HGetAll_Call) Run(run func(ctx context.Context, key string)) *MockuserDAO_HGetAll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockuserDAO_HGetAll_Call) Return(_a0 *redis.MapStringStringCmd) *MockuserDAO_HGetAll_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockuserDAO_HGetAll_Call) RunAndReturn(run func(context.Context, string) *redis.MapStringStringCmd) *MockuserDAO_HGetAll_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Ping provides a mock function with given fields: ctx
 func (_m *MockuserDAO) Ping(ctx context.Context) *redis.StatusCmd {
 	ret := _m.Called(ctx)
@@ -72,6 +365,285 @@ func (_c *MockuserDAO_Ping_Call) RunAndReturn(run func(context.Context) *redis.S
 	return _c
 }
 
+// Pipelined provides a mock function with given fields: ctx, fn
+func (_m *MockuserDAO) Pipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
+	ret := _m.Called(ctx, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Pipelined")
+	}
+
+	var r0 []redis.Cmder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(redis.Pipeliner) error) ([]redis.Cmder, error)); ok {
+		return rf(ctx, fn)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, func(redis.Pipeliner) error) []redis.Cmder); ok {
+		r0 = rf(ctx, fn)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]redis.Cmder)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, func(redis.Pipeliner) error) error); ok {
+		r1 = rf(ctx, fn)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockuserDAO_Pipelined_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Pipelined'
+type MockuserDAO_Pipelined_Call struct {
+	*mock.Call
+}
+
+// Pipelined is a helper method to define mock.On call
+//   - ctx context.Context
+//   - fn func(redis.Pipeliner) error
+func (_e *MockuserDAO_Expecter) Pipelined(ctx interface{}, fn interface{}) *MockuserDAO_Pipelined_Call {
+	return &MockuserDAO_Pipelined_Call{Call: _e.mock.On("Pipelined", ctx, fn)}
+}
+
+func (_c *MockuserDAO_Pipelined_Call) Run(run func(ctx context.Context, fn func(redis.Pipeliner) error)) *MockuserDAO_Pipelined_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(func(redis.Pipeliner) error))
+	})
+	return _c
+}
+
+func (_c *MockuserDAO_Pipelined_Call) Return(_a0 []redis.Cmder, _a1 error) *MockuserDAO_Pipelined_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockuserDAO_Pipelined_Call) RunAndReturn(run func(context.Context, func(redis.Pipeliner) error) ([]redis.Cmder, error)) *MockuserDAO_Pipelined_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SMembers provides a mock function with given fields: ctx, key
+func (_m *MockuserDAO) SMembers(ctx context.Context, key string) *redis.StringSliceCmd {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SMembers")
+	}
+
+	var r0 *redis.StringSliceCmd
+	if rf, ok := ret.Get(0).(func(context.Context, string) *redis.StringSliceCmd); ok {
+		r0 = rf(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*redis.StringSliceCmd)
+		}
+	}
+
+	return r0
+}
+
+// MockuserDAO_SMembers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SMembers'
+type MockuserDAO_SMembers_Call struct {
+	*mock.Call
+}
+
+// SMembers is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+func (_e *MockuserDAO_Expecter) SMembers(ctx interface{}, key interface{}) *MockuserDAO_SMembers_Call {
+	return &MockuserDAO_SMembers_Call{Call: _e.mock.On("SMembers", ctx, key)}
+}
+
+func (_c *MockuserDAO_SMembers_Call) Run(run func(ctx context.Context, key string)) *MockuserDAO_SMembers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockuserDAO_SMembers_Call) Return(_a0 *redis.StringSliceCmd) *MockuserDAO_SMembers_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockuserDAO_SMembers_Call) RunAndReturn(run func(context.Context, string) *redis.StringSliceCmd) *MockuserDAO_SMembers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ScriptExists provides a mock function with given fields: ctx, hashes
+func (_m *MockuserDAO) ScriptExists(ctx context.Context, hashes ...string) *redis.BoolSliceCmd {
+	_va := make([]interface{}, len(hashes))
+	for _i := range hashes {
+		_va[_i] = hashes[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ScriptExists")
+	}
+
+	var r0 *redis.BoolSliceCmd
+	if rf, ok := ret.Get(0).(func(context.Context, ...string) *redis.BoolSliceCmd); ok {
+		r0 = rf(ctx, hashes...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*redis.BoolSliceCmd)
+		}
+	}
+
+	return r0
+}
+
+// MockuserDAO_ScriptExists_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ScriptExists'
+type MockuserDAO_ScriptExists_Call struct {
+	*mock.Call
+}
+
+// ScriptExists is a helper method to define mock.On call
+//   - ctx context.Context
+//   - hashes ...string
+func (_e *MockuserDAO_Expecter) ScriptExists(ctx interface{}, hashes ...interface{}) *MockuserDAO_ScriptExists_Call {
+	return &MockuserDAO_ScriptExists_Call{Call: _e.mock.On("ScriptExists",
+		append([]interface{}{ctx}, hashes...)...)}
+}
+
+func (_c *MockuserDAO_ScriptExists_Call) Run(run func(ctx context.Context, hashes ...string)) *MockuserDAO_ScriptExists_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]string, len(args)-1)
+		for i, a := range args[1:] {
+			if a != nil {
+				variadicArgs[i] = a.(string)
+			}
+		}
+		run(args[0].(context.Context), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *MockuserDAO_ScriptExists_Call) Return(_a0 *redis.BoolSliceCmd) *MockuserDAO_ScriptExists_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockuserDAO_ScriptExists_Call) RunAndReturn(run func(context.Context, ...string) *redis.BoolSliceCmd) *MockuserDAO_ScriptExists_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ScriptLoad provides a mock function with given fields: ctx, script
+func (_m *MockuserDAO) ScriptLoad(ctx context.Context, script string) *redis.StringCmd {
+	ret := _m.Called(ctx, script)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ScriptLoad")
+	}
+
+	var r0 *redis.StringCmd
+	if rf, ok := ret.Get(0).(func(context.Context, string) *redis.StringCmd); ok {
+		r0 = rf(ctx, script)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*redis.StringCmd)
+		}
+	}
+
+	return r0
+}
+
+// MockuserDAO_ScriptLoad_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ScriptLoad'
+type MockuserDAO_ScriptLoad_Call struct {
+	*mock.Call
+}
+
+// ScriptLoad is a helper method to define mock.On call
+//   - ctx context.Context
+//   - script string
+func (_e *MockuserDAO_Expecter) ScriptLoad(ctx interface{}, script interface{}) *MockuserDAO_ScriptLoad_Call {
+	return &MockuserDAO_ScriptLoad_Call{Call: _e.mock.On("ScriptLoad", ctx, script)}
+}
+
+func (_c *MockuserDAO_ScriptLoad_Call) Run(run func(ctx context.Context, script string)) *MockuserDAO_ScriptLoad_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockuserDAO_ScriptLoad_Call) Return(_a0 *redis.StringCmd) *MockuserDAO_ScriptLoad_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockuserDAO_ScriptLoad_Call) RunAndReturn(run func(context.Context, string) *redis.StringCmd) *MockuserDAO_ScriptLoad_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TxPipelined provides a mock function with given fields: ctx, fn
+func (_m *MockuserDAO) TxPipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
+	ret := _m.Called(ctx, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TxPipelined")
+	}
+
+	var r0 []redis.Cmder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(redis.Pipeliner) error) ([]redis.Cmder, error)); ok {
+		return rf(ctx, fn)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, func(redis.Pipeliner) error) []redis.Cmder); ok {
+		r0 = rf(ctx, fn)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]redis.Cmder)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, func(redis.Pipeliner) error) error); ok {
+		r1 = rf(ctx, fn)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockuserDAO_TxPipelined_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TxPipelined'
+type MockuserDAO_TxPipelined_Call struct {
+	*mock.Call
+}
+
+// TxPipelined is a helper method to define mock.On call
+//   - ctx context.Context
+//   - fn func(redis.Pipeliner) error
+func (_e *MockuserDAO_Expecter) TxPipelined(ctx interface{}, fn interface{}) *MockuserDAO_TxPipelined_Call {
+	return &MockuserDAO_TxPipelined_Call{Call: _e.mock.On("TxPipelined", ctx, fn)}
+}
+
+func (_c *MockuserDAO_TxPipelined_Call) Run(run func(ctx context.Context, fn func(redis.Pipeliner) error)) *MockuserDAO_TxPipelined_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(func(redis.Pipeliner) error))
+	})
+	return _c
+}
+
+func (_c *MockuserDAO_TxPipelined_Call) Return(_a0 []redis.Cmder, _a1 error) *MockuserDAO_TxPipelined_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockuserDAO_TxPipelined_Call) RunAndReturn(run func(context.Context, func(redis.Pipeliner) error) ([]redis.Cmder, error)) *MockuserDAO_TxPipelined_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockuserDAO creates a new instance of MockuserDAO. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockuserDAO(t interface {
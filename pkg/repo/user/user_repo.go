@@ -9,8 +9,15 @@ import (
 )
 
 // userDAO defines the interface for user data access operations.
+// Multi-key updates are executed either as Lua scripts through redis.Scripter or inside TxPipelined
+// so that subscriptions, feeds and their indexes never get out of sync.
 type userDAO interface {
+	redis.Scripter
 	Ping(ctx context.Context) *redis.StatusCmd
+	HGetAll(ctx context.Context, key string) *redis.MapStringStringCmd
+	SMembers(ctx context.Context, key string) *redis.StringSliceCmd
+	Pipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error)
+	TxPipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error)
 }
 
 // UserRepo provides methods to interact with the user data store.
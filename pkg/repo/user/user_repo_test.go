@@ -3,6 +3,7 @@ package user
 import (
 	"testing"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	mock "github.com/stretchr/testify/mock"
@@ -55,3 +56,15 @@ func TestUserRepo_CheckHealth(t *testing.T) {
 		})
 	}
 }
+
+// newTestRepo returns a UserRepo backed by an in-process Redis server.
+func newTestRepo(t *testing.T) (*UserRepo, *miniredis.Miniredis) {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	return New(rdb), srv
+}
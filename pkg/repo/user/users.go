@@ -0,0 +1,91 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	fieldLanguage   = "language"
+	fieldTimezone   = "timezone"
+	fieldLastSeenAt = "last_seen_at"
+)
+
+// SaveUser creates or updates the user profile. The creation time is only set when the user is first stored,
+// empty language and timezone values leave the stored ones untouched.
+func (u *UserRepo) SaveUser(ctx context.Context, user *core.User) error {
+	key := userKey(user.ID)
+
+	createdAt := user.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	values := []any{fieldLastSeenAt, formatTime(user.LastSeenAt)}
+
+	if user.Language != "" {
+		values = append(values, fieldLanguage, user.Language)
+	}
+
+	if user.Timezone != "" {
+		values = append(values, fieldTimezone, user.Timezone)
+	}
+
+	_, err := u.dao.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSetNX(ctx, key, fieldCreatedAt, formatTime(createdAt))
+		pipe.HSet(ctx, key, values...)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("fail to save user: %w", err)
+	}
+
+	return nil
+}
+
+// TouchUser records that the user has interacted with the bot, creating the user profile if needed.
+func (u *UserRepo) TouchUser(ctx context.Context, userID int64, seenAt time.Time) error {
+	key := userKey(userID)
+
+	_, err := u.dao.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSetNX(ctx, key, fieldCreatedAt, formatTime(seenAt))
+		pipe.HSet(ctx, key, fieldLastSeenAt, formatTime(seenAt))
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("fail to touch user: %w", err)
+	}
+
+	return nil
+}
+
+// GetUser returns the user profile or nil if the user is not known.
+func (u *UserRepo) GetUser(ctx context.Context, userID int64) (*core.User, error) {
+	values, err := u.dao.HGetAll(ctx, userKey(userID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("fail to get user: %w", err)
+	}
+
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	return &core.User{
+		ID:         userID,
+		Language:   values[fieldLanguage],
+		Timezone:   values[fieldTimezone],
+		CreatedAt:  parseTime(values[fieldCreatedAt]),
+		LastSeenAt: parseTime(values[fieldLastSeenAt]),
+	}, nil
+}
+
+func userKey(userID int64) string {
+	return "user:" + strconv.FormatInt(userID, 10)
+}
@@ -0,0 +1,71 @@
+package user
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ksysoev/tg-feeder/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRepo_SaveUser(t *testing.T) {
+	u, _ := newTestRepo(t)
+
+	user, err := u.GetUser(t.Context(), 1)
+	require.NoError(t, err)
+	assert.Nil(t, user)
+
+	require.NoError(t, u.SaveUser(t.Context(), &core.User{
+		ID:         1,
+		Language:   "en",
+		Timezone:   "Europe/Berlin",
+		CreatedAt:  time.Unix(100, 0),
+		LastSeenAt: time.Unix(100, 0),
+	}))
+
+	require.NoError(t, u.SaveUser(t.Context(), &core.User{
+		ID:         1,
+		Language:   "de",
+		CreatedAt:  time.Unix(500, 0),
+		LastSeenAt: time.Unix(200, 0),
+	}))
+
+	user, err = u.GetUser(t.Context(), 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, &core.User{
+		ID:         1,
+		Language:   "de",
+		Timezone:   "Europe/Berlin",
+		CreatedAt:  time.Unix(100, 0).UTC(),
+		LastSeenAt: time.Unix(200, 0).UTC(),
+	}, user)
+}
+
+func TestUserRepo_TouchUser(t *testing.T) {
+	u, _ := newTestRepo(t)
+
+	require.NoError(t, u.TouchUser(t.Context(), 1, time.Unix(100, 0)))
+	require.NoError(t, u.TouchUser(t.Context(), 1, time.Unix(200, 0)))
+
+	user, err := u.GetUser(t.Context(), 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, &core.User{
+		ID:         1,
+		CreatedAt:  time.Unix(100, 0).UTC(),
+		LastSeenAt: time.Unix(200, 0).UTC(),
+	}, user)
+}
+
+func TestUserRepo_UsersRedisFailure(t *testing.T) {
+	u, srv := newTestRepo(t)
+	srv.Close()
+
+	assert.Error(t, u.SaveUser(t.Context(), &core.User{ID: 1}))
+	assert.Error(t, u.TouchUser(t.Context(), 1, time.Now()))
+
+	_, err := u.GetUser(t.Context(), 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,83 @@
+// Package reqctx stores request-scoped values in a context under typed keys, so the packages handling
+// an update or a feed share them without depending on each other and the logger can attach them to every record.
+package reqctx
+
+import "context"
+
+// key is the type of the context keys of the package, which cannot collide with the keys of other packages.
+type key int
+
+const (
+	requestIDKey key = iota
+	updateIDKey
+	chatIDKey
+	userIDKey
+	commandKey
+	feedIDKey
+)
+
+// WithRequestID returns a copy of the context holding the ID of the request.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the ID of the request stored in the context and whether it is present.
+func RequestID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey).(string)
+	return id, ok
+}
+
+// WithUpdateID returns a copy of the context holding the ID of the Telegram update being handled.
+func WithUpdateID(ctx context.Context, id int) context.Context {
+	return context.WithValue(ctx, updateIDKey, id)
+}
+
+// UpdateID returns the ID of the Telegram update stored in the context and whether it is present.
+func UpdateID(ctx context.Context) (int, bool) {
+	id, ok := ctx.Value(updateIDKey).(int)
+	return id, ok
+}
+
+// WithChatID returns a copy of the context holding the ID of the chat the request belongs to.
+func WithChatID(ctx context.Context, id int64) context.Context {
+	return context.WithValue(ctx, chatIDKey, id)
+}
+
+// ChatID returns the ID of the chat stored in the context and whether it is present.
+func ChatID(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(chatIDKey).(int64)
+	return id, ok
+}
+
+// WithUserID returns a copy of the context holding the ID of the user who caused the request.
+func WithUserID(ctx context.Context, id int64) context.Context {
+	return context.WithValue(ctx, userIDKey, id)
+}
+
+// UserID returns the ID of the user stored in the context and whether it is present.
+func UserID(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(userIDKey).(int64)
+	return id, ok
+}
+
+// WithCommand returns a copy of the context holding the name of the bot command being handled.
+func WithCommand(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, commandKey, name)
+}
+
+// Command returns the name of the bot command stored in the context and whether it is present.
+func Command(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(commandKey).(string)
+	return name, ok
+}
+
+// WithFeedID returns a copy of the context holding the ID of the feed being processed.
+func WithFeedID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, feedIDKey, id)
+}
+
+// FeedID returns the ID of the feed stored in the context and whether it is present.
+func FeedID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(feedIDKey).(string)
+	return id, ok
+}
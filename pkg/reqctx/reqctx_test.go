@@ -0,0 +1,69 @@
+package reqctx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValues(t *testing.T) {
+	ctx := context.Background()
+
+	_, ok := RequestID(ctx)
+	assert.False(t, ok)
+
+	_, ok = UpdateID(ctx)
+	assert.False(t, ok)
+
+	_, ok = ChatID(ctx)
+	assert.False(t, ok)
+
+	_, ok = UserID(ctx)
+	assert.False(t, ok)
+
+	_, ok = Command(ctx)
+	assert.False(t, ok)
+
+	_, ok = FeedID(ctx)
+	assert.False(t, ok)
+
+	ctx = WithRequestID(ctx, "req")
+	ctx = WithUpdateID(ctx, 7)
+	ctx = WithChatID(ctx, 42)
+	ctx = WithUserID(ctx, 43)
+	ctx = WithCommand(ctx, "start")
+	ctx = WithFeedID(ctx, "feed")
+
+	reqID, ok := RequestID(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "req", reqID)
+
+	updateID, ok := UpdateID(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, 7, updateID)
+
+	chatID, ok := ChatID(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, int64(42), chatID)
+
+	userID, ok := UserID(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, int64(43), userID)
+
+	cmd, ok := Command(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "start", cmd)
+
+	feedID, ok := FeedID(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "feed", feedID)
+}
+
+func TestValues_StringKeysDoNotCollide(t *testing.T) {
+	//nolint:staticcheck // the bare string key is what the typed keys must not collide with
+	ctx := context.WithValue(context.Background(), "chat_id", int64(42))
+
+	_, ok := ChatID(ctx)
+	assert.False(t, ok)
+}